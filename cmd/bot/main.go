@@ -1,9 +1,13 @@
 package main
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,9 +17,15 @@ import (
 	"anor-kids/internal/config"
 	"anor-kids/internal/database"
 	"anor-kids/internal/handlers"
+	"anor-kids/internal/models"
+	"anor-kids/internal/registry"
 	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
 )
 
+// maxVerifyFileSize limits PDFs uploaded to /api/verify
+const maxVerifyFileSize = 20 << 20
+
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -43,6 +53,20 @@ func main() {
 		"internal/database/migrations/001_initial_sqlite.sql",
 		"internal/database/migrations/002_add_proposals_and_announcements.sql",
 		"internal/database/migrations/003_add_announcement_is_document.sql",
+		"internal/database/migrations/004_registration_approval.sql",
+		"internal/database/migrations/005_data_retention.sql",
+		"internal/database/migrations/006_field_encryption.sql",
+		"internal/database/migrations/007_document_jobs.sql",
+		"internal/database/migrations/008_document_registry.sql",
+		"internal/database/migrations/009_parent_roster.sql",
+		"internal/database/migrations/010_admin_reports.sql",
+		"internal/database/migrations/011_review_sla.sql",
+		"internal/database/migrations/012_complaint_assignment.sql",
+		"internal/database/migrations/013_complaint_categories.sql",
+		"internal/database/migrations/014_urgent_complaints.sql",
+		"internal/database/migrations/015_anonymous_complaints.sql",
+		"internal/database/migrations/016_complaint_versions.sql",
+		"internal/database/migrations/017_complaint_ratings.sql",
 	}
 
 	for _, migrationPath := range migrations {
@@ -67,6 +91,18 @@ func main() {
 
 	log.Printf("✓ Bot authorized: @%s", botService.Bot.Self.UserName)
 
+	// Encrypt existing plaintext rows and fill phone blind indexes. Runs before
+	// admins are initialized so their lookups by phone hash find existing rows.
+	updated, err := botService.EncryptionService.ReencryptStale()
+	if err != nil {
+		log.Fatalf("Failed to update encrypted fields: %v", err)
+	}
+	if botService.EncryptionService.Enabled() {
+		log.Printf("✓ Field encryption enabled (%d rows updated)", updated)
+	} else if updated > 0 {
+		log.Printf("✓ Phone indexes updated for %d rows", updated)
+	}
+
 	// Initialize admins
 	err = botService.InitializeAdmins()
 	if err != nil {
@@ -75,6 +111,20 @@ func main() {
 		log.Println("✓ Admins initialized")
 	}
 
+	// Start document generation worker
+	botService.DocumentJobService.Start(func(job *models.DocumentJob, err error) {
+		handlers.HandleDocumentJobResult(botService, job, err)
+	})
+	log.Println("✓ Document generation worker started")
+
+	// Start scheduled admin reports
+	botService.ReportService.Start()
+	log.Println("✓ Report scheduler started")
+
+	// Start review SLA and urgent complaint checkers
+	botService.SLAService.Start()
+	log.Println("✓ SLA and urgent complaint checkers started")
+
 	// Start background cleanup routine
 	go startCleanupRoutine(botService)
 	log.Println("✓ Background cleanup routine started")
@@ -130,9 +180,61 @@ func startWebhookMode(cfg *config.Config, botService *services.BotService) {
 	// Admin API endpoints
 	api := router.Group("/api")
 	{
+		// Document verification, public like /verify in the bot:
+		// GET /api/verify?code=XXXX-XXXX-XXXX checks a code from a PDF footer,
+		// POST /api/verify with the PDF as multipart "file" checks the file itself
+		api.GET("/verify", func(c *gin.Context) {
+			code := registry.NormalizeCode(c.Query("code"))
+			if code == "" {
+				c.JSON(400, gin.H{"error": "invalid verification code"})
+				return
+			}
+
+			result, err := botService.RegistryService.VerifyCode(code)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(200, gin.H{"authentic": result.Authentic(), "verification": result})
+		})
+
+		api.POST("/verify", func(c *gin.Context) {
+			header, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(400, gin.H{"error": "file is required"})
+				return
+			}
+
+			if header.Size > maxVerifyFileSize {
+				c.JSON(413, gin.H{"error": "file too large"})
+				return
+			}
+
+			file, err := header.Open()
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			defer file.Close()
+
+			data, err := io.ReadAll(io.LimitReader(file, maxVerifyFileSize))
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			result, err := botService.RegistryService.VerifyDocument(data)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(200, gin.H{"authentic": result.Authentic(), "verification": result})
+		})
+
 		admin := api.Group("/admin")
 		{
-			admin.GET("/users", func(c *gin.Context) {
+			// Requires "Authorization: Bearer <ADMIN_API_TOKEN>", like all admin endpoints
+			admin.GET("/users", requireAPIToken(cfg.Admin.APIToken), func(c *gin.Context) {
 				users, err := botService.UserService.GetAllUsers(100, 0)
 				if err != nil {
 					c.JSON(500, gin.H{"error": err.Error()})
@@ -141,8 +243,16 @@ func startWebhookMode(cfg *config.Config, botService *services.BotService) {
 				c.JSON(200, gin.H{"users": users})
 			})
 
-			admin.GET("/complaints", func(c *gin.Context) {
-				complaints, err := botService.ComplaintService.GetAllComplaintsWithUser(100, 0)
+			// GET /api/admin/complaints?category=<id>
+			// Requires "Authorization: Bearer <ADMIN_API_TOKEN>"
+			admin.GET("/complaints", requireAPIToken(cfg.Admin.APIToken), func(c *gin.Context) {
+				categoryID, err := queryCategoryID(c)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				complaints, err := botService.ComplaintService.GetAllComplaintsWithUser(categoryID, 100, 0)
 				if err != nil {
 					c.JSON(500, gin.H{"error": err.Error()})
 					return
@@ -150,10 +260,34 @@ func startWebhookMode(cfg *config.Config, botService *services.BotService) {
 				c.JSON(200, gin.H{"complaints": complaints})
 			})
 
-			admin.GET("/stats", func(c *gin.Context) {
+			// Requires "Authorization: Bearer <ADMIN_API_TOKEN>", like the stats below
+			admin.GET("/categories", requireAPIToken(cfg.Admin.APIToken), func(c *gin.Context) {
+				categories, err := botService.CategoryRepo.GetAll()
+				if err != nil {
+					c.JSON(500, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(200, gin.H{"categories": categories})
+			})
+
+			// GET /api/admin/stats?category=<id>
+			// Requires "Authorization: Bearer <ADMIN_API_TOKEN>"
+			admin.GET("/stats", requireAPIToken(cfg.Admin.APIToken), func(c *gin.Context) {
+				categoryID, err := queryCategoryID(c)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
 				userCount, _ := botService.UserService.CountUsers()
-				complaintCount, _ := botService.ComplaintService.CountComplaints()
-				pendingCount, _ := botService.ComplaintService.CountComplaintsByStatus("pending")
+				var complaintCount, pendingCount int
+				if categoryID != 0 {
+					complaintCount, _ = botService.ComplaintService.CountComplaintsByCategory(categoryID, "")
+					pendingCount, _ = botService.ComplaintService.CountComplaintsByCategory(categoryID, "pending")
+				} else {
+					complaintCount, _ = botService.ComplaintService.CountComplaints()
+					pendingCount, _ = botService.ComplaintService.CountComplaintsByStatus("pending")
+				}
 
 				c.JSON(200, gin.H{
 					"total_users":        userCount,
@@ -161,6 +295,114 @@ func startWebhookMode(cfg *config.Config, botService *services.BotService) {
 					"pending_complaints": pendingCount,
 				})
 			})
+
+			// Time series of the last weeks (weeks=1..52, default 12), weeks starting on Monday.
+			// category=<id> counts only the complaints of a category:
+			// GET /api/admin/stats/weekly?weeks=12&category=1
+			// Requires "Authorization: Bearer <ADMIN_API_TOKEN>"
+			stats := admin.Group("/stats", requireAPIToken(cfg.Admin.APIToken))
+			stats.GET("/weekly", func(c *gin.Context) {
+				dashboard, ok := statsDashboard(c, botService)
+				if !ok {
+					return
+				}
+
+				c.JSON(200, gin.H{
+					"from":                dashboard.From,
+					"to":                  dashboard.To,
+					"weeks":               dashboard.Weeks,
+					"complaints":          dashboard.Complaints,
+					"proposals":           dashboard.Proposals,
+					"active_parents":      dashboard.ActiveParents,
+					"median_review_hours": dashboard.MedianReviewHours,
+				})
+			})
+
+			stats.GET("/classes", func(c *gin.Context) {
+				dashboard, ok := statsDashboard(c, botService)
+				if !ok {
+					return
+				}
+
+				c.JSON(200, gin.H{
+					"from":    dashboard.From,
+					"to":      dashboard.To,
+					"classes": dashboard.Classes,
+				})
+			})
+
+			stats.GET("/statuses", func(c *gin.Context) {
+				dashboard, ok := statsDashboard(c, botService)
+				if !ok {
+					return
+				}
+
+				c.JSON(200, gin.H{
+					"from":     dashboard.From,
+					"to":       dashboard.To,
+					"statuses": dashboard.Statuses,
+				})
+			})
+
+			stats.GET("/categories", func(c *gin.Context) {
+				dashboard, ok := statsDashboard(c, botService)
+				if !ok {
+					return
+				}
+
+				c.JSON(200, gin.H{
+					"from":       dashboard.From,
+					"to":         dashboard.To,
+					"categories": dashboard.Categories,
+				})
+			})
+
+			stats.GET("/satisfaction", func(c *gin.Context) {
+				dashboard, ok := statsDashboard(c, botService)
+				if !ok {
+					return
+				}
+
+				c.JSON(200, gin.H{
+					"from":         dashboard.From,
+					"to":           dashboard.To,
+					"satisfaction": dashboard.Satisfaction,
+				})
+			})
+
+			// Table exports as CSV or XLSX, streamed while rows are read:
+			// GET /api/admin/export/complaints?format=xlsx&from=2025-09-01&to=2025-09-30&status=pending&class=9A&category=1
+			// Requires "Authorization: Bearer <ADMIN_API_TOKEN>"
+			export := admin.Group("/export", requireAPIToken(cfg.Admin.APIToken))
+			export.GET("/:table", func(c *gin.Context) {
+				table := c.Param("table")
+				if table != services.ExportTableUsers && table != services.ExportTableComplaints && table != services.ExportTableProposals {
+					c.JSON(404, gin.H{"error": "unknown table, expected users, complaints or proposals"})
+					return
+				}
+
+				format, ok := services.ParseExportFormat(c.DefaultQuery("format", string(services.ExportFormatCSV)))
+				if !ok {
+					c.JSON(400, gin.H{"error": "format must be csv or xlsx"})
+					return
+				}
+
+				filter, err := parseExportFilter(c, table)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+
+				filename := fmt.Sprintf("%s_%s.%s", table, time.Now().Format("20060102_150405"), format)
+				c.Header("Content-Type", format.ContentType())
+				c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+				c.Status(200)
+
+				// Headers are already sent, so a failure can only cut the file short
+				if err := botService.ExportService.Export(c.Writer, table, format, filter); err != nil {
+					log.Printf("Export of %s failed: %v", table, err)
+				}
+			})
 		}
 	}
 
@@ -183,6 +425,117 @@ func startWebhookMode(cfg *config.Config, botService *services.BotService) {
 	}
 }
 
+// requireAPIToken lets a request through only with "Authorization: Bearer <token>".
+// Without a configured token the endpoints are disabled.
+func requireAPIToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(503, gin.H{"error": "ADMIN_API_TOKEN is not configured"})
+			return
+		}
+
+		given, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// parseExportFilter reads the from/to (both days included), status, class and
+// category query parameters of an export. Status is the approval status for
+// users; category applies to complaints only.
+func parseExportFilter(c *gin.Context, table string) (models.ExportFilter, error) {
+	var filter models.ExportFilter
+
+	if from := c.Query("from"); from != "" {
+		date, err := utils.ParseDate(from, time.Local)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = date
+	}
+
+	if to := c.Query("to"); to != "" {
+		date, err := utils.ParseDate(to, time.Local)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = date.AddDate(0, 0, 1)
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must not be after to")
+	}
+
+	statuses := []string{models.StatusPending, models.StatusReviewed, models.StatusArchived, models.StatusWithdrawn}
+	if table == services.ExportTableUsers {
+		statuses = []string{models.ApprovalPending, models.ApprovalApproved, models.ApprovalRejected}
+	}
+
+	if status := c.Query("status"); status != "" {
+		if !slices.Contains(statuses, status) {
+			return filter, fmt.Errorf("status must be one of %s", strings.Join(statuses, ", "))
+		}
+		filter.Status = status
+	}
+
+	filter.ChildClass = c.Query("class")
+
+	categoryID, err := queryCategoryID(c)
+	if err != nil {
+		return filter, err
+	}
+	if categoryID != 0 && table != services.ExportTableComplaints {
+		return filter, fmt.Errorf("category applies to complaints only")
+	}
+	filter.CategoryID = categoryID
+
+	return filter, nil
+}
+
+// queryCategoryID reads the optional category query parameter, 0 if absent
+func queryCategoryID(c *gin.Context) (int, error) {
+	category := c.Query("category")
+	if category == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(category)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("category must be a category ID")
+	}
+
+	return id, nil
+}
+
+// statsDashboard computes the statistics of the weeks and category in the
+// query, writing the error response if it fails
+func statsDashboard(c *gin.Context, botService *services.BotService) (*models.StatsDashboard, bool) {
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(services.DefaultStatsWeeks)))
+	if err != nil || weeks < 1 || weeks > services.MaxStatsWeeks {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("weeks must be a number from 1 to %d", services.MaxStatsWeeks)})
+		return nil, false
+	}
+
+	categoryID, err := queryCategoryID(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	dashboard, err := botService.StatsService.Dashboard(weeks, categoryID, time.Now())
+	if err != nil {
+		log.Printf("Failed to compute statistics: %v", err)
+		c.JSON(500, gin.H{"error": "failed to compute statistics"})
+		return nil, false
+	}
+
+	return dashboard, true
+}
+
 // startPollingMode starts the bot with polling (for development/testing)
 func startPollingMode(botService *services.BotService) {
 	// Remove webhook if set
@@ -215,6 +568,9 @@ func startCleanupRoutine(botService *services.BotService) {
 	ticker := time.NewTicker(24 * time.Hour) // Run once per day
 	defer ticker.Stop()
 
+	// Retention also runs at startup so super-admins get the dry-run report right away
+	runRetention(botService)
+
 	for range ticker.C {
 		log.Println("🧹 Running cleanup routine...")
 
@@ -233,5 +589,36 @@ func startCleanupRoutine(botService *services.BotService) {
 		} else {
 			log.Println("✓ Temp directory cleaned")
 		}
+
+		// Clean attachment downloads not used for a week
+		err = botService.DownloadManager.CleanCache()
+		if err != nil {
+			log.Printf("Warning: Failed to clean download cache: %v", err)
+		}
+
+		runRetention(botService)
+	}
+}
+
+// runRetention applies the data retention rules and reports to super-admins
+func runRetention(botService *services.BotService) {
+	report, err := botService.RetentionService.RunScheduled()
+	if err != nil {
+		log.Printf("Warning: Failed to apply retention rules: %v", err)
+		return
+	}
+
+	if report == nil {
+		return
+	}
+
+	if report.DryRun {
+		log.Println("✓ Retention dry run reported to super-admins")
+	} else {
+		log.Printf("✓ Retention rules applied: %d rows affected", report.Total())
+	}
+
+	if report.DryRun || report.Total() > 0 {
+		handlers.SendRetentionReport(botService, report)
 	}
 }
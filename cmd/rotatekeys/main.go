@@ -0,0 +1,57 @@
+// Command rotatekeys rotates the field encryption keys.
+//
+// It creates a new data key, re-encrypts all encrypted columns with it and,
+// if NEW_ENCRYPTION_KEY is set, wraps all keys with that new master key.
+// Stop the bot before running it and set ENCRYPTION_KEY to NEW_ENCRYPTION_KEY afterwards.
+package main
+
+import (
+	"log"
+	"os"
+
+	"anor-kids/internal/config"
+	"anor-kids/internal/database"
+	"anor-kids/internal/repository"
+	"anor-kids/internal/services"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Encryption.MasterKey == "" {
+		log.Fatal("ENCRYPTION_KEY is not set, nothing to rotate")
+	}
+
+	err = database.Connect(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close()
+
+	encryptionService, err := services.NewEncryptionService(repository.NewEncryptionRepository(database.DB), cfg.Encryption.MasterKey)
+	if err != nil {
+		log.Fatalf("Failed to load encryption keys: %v", err)
+	}
+
+	keyID, err := encryptionService.RotateDataKey()
+	if err != nil {
+		log.Fatalf("Failed to create data key: %v", err)
+	}
+	log.Printf("✓ Data key %d created and activated", keyID)
+
+	updated, err := encryptionService.ReencryptStale()
+	if err != nil {
+		log.Fatalf("Failed to re-encrypt rows: %v", err)
+	}
+	log.Printf("✓ %d rows re-encrypted", updated)
+
+	if newMasterKey := os.Getenv("NEW_ENCRYPTION_KEY"); newMasterKey != "" {
+		if err := encryptionService.RewrapKeys(newMasterKey); err != nil {
+			log.Fatalf("Failed to rewrap keys: %v", err)
+		}
+		log.Println("✓ Keys wrapped with the new master key, set ENCRYPTION_KEY to NEW_ENCRYPTION_KEY before restarting the bot")
+	}
+}
@@ -3,12 +3,14 @@ module anor-kids
 go 1.24.5
 
 require (
+	github.com/boombuler/barcode v1.0.0
 	github.com/fumiama/go-docx v0.0.0-20250506085032-0c30fd09304b
 	github.com/gin-gonic/gin v1.11.0
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/joho/godotenv v1.5.1
 	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/mattn/go-sqlite3 v1.14.32
+	golang.org/x/image v0.29.0
 )
 
 require (
@@ -3,18 +3,27 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+
+	"anor-kids/internal/encryption"
 )
 
 type Config struct {
-	Bot       BotConfig
-	Database  DatabaseConfig
-	Server    ServerConfig
-	Admin     AdminConfig
-	RateLimit RateLimitConfig
+	Bot          BotConfig
+	Database     DatabaseConfig
+	Server       ServerConfig
+	Admin        AdminConfig
+	RateLimit    RateLimitConfig
+	Registration RegistrationConfig
+	Retention    RetentionConfig
+	Encryption   EncryptionConfig
+	Document     DocumentConfig
+	Report       ReportConfig
+	SLA          SLAConfig
 }
 
 type BotConfig struct {
@@ -32,14 +41,61 @@ type ServerConfig struct {
 }
 
 type AdminConfig struct {
-	PhoneNumbers []string
+	PhoneNumbers     []string
+	SuperAdminPhones []string // Subset of PhoneNumbers; receives retention reports
+	APIToken         string   // Bearer token for the export API; exports are disabled without it
 }
 
+// minAPITokenLength keeps the export API token from being guessable
+const minAPITokenLength = 16
+
 type RateLimitConfig struct {
 	Requests int
 	Duration time.Duration
 }
 
+type RegistrationConfig struct {
+	RequireApproval bool // New parents wait for teacher/admin approval before submitting
+}
+
+// RetentionConfig holds the data retention rules. A zero value disables the rule.
+type RetentionConfig struct {
+	ArchiveAfterDays   int // Archive reviewed complaints and proposals after N days
+	PurgeAfterMonths   int // Purge text and image references after M months
+	InactiveUserMonths int // Delete users inactive for longer than this
+}
+
+// Enabled reports whether any retention rule is configured
+func (c *RetentionConfig) Enabled() bool {
+	return c.ArchiveAfterDays > 0 || c.PurgeAfterMonths > 0 || c.InactiveUserMonths > 0
+}
+
+// EncryptionConfig holds the master key for field-level encryption of parent data.
+// Without a key, phone numbers, child names and texts are stored in plaintext.
+type EncryptionConfig struct {
+	MasterKey string // 32 bytes, base64 or hex encoded
+}
+
+// DocumentConfig holds the header shown on generated documents
+type DocumentConfig struct {
+	KindergartenName string
+	LogoPath         string // Optional PNG or JPEG logo
+}
+
+// ReportConfig holds the settings of the scheduled admin reports
+type ReportConfig struct {
+	StaleAfterDays int // Pending complaints and proposals older than this are listed as unresolved
+}
+
+// SLAConfig holds the review deadlines of complaints and proposals. A zero
+// deadline disables SLA tracking for that kind.
+type SLAConfig struct {
+	ComplaintHours  int    // Complaints should be reviewed within this many hours
+	ProposalHours   int    // Proposals should be reviewed within this many hours
+	EscalationHours int    // Hours between escalation steps once the deadline has passed
+	DirectorPhone   string // Receives the last escalation step; same format as ADMIN_PHONES
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists
@@ -58,12 +114,43 @@ func Load() (*Config, error) {
 			GinMode: getEnv("GIN_MODE", "debug"),
 		},
 		Admin: AdminConfig{
-			PhoneNumbers: parseAdminPhones(getEnv("ADMIN_PHONES", "")),
+			PhoneNumbers:     parseAdminPhones(getEnv("ADMIN_PHONES", "")),
+			SuperAdminPhones: parseAdminPhones(getEnv("SUPER_ADMIN_PHONES", "")),
+			APIToken:         getEnv("ADMIN_API_TOKEN", ""),
 		},
 		RateLimit: RateLimitConfig{
 			Requests: 20,
 			Duration: 60 * time.Second,
 		},
+		Registration: RegistrationConfig{
+			RequireApproval: getEnvBool("REQUIRE_REGISTRATION_APPROVAL", false),
+		},
+		Retention: RetentionConfig{
+			ArchiveAfterDays:   getEnvInt("RETENTION_ARCHIVE_DAYS", 0),
+			PurgeAfterMonths:   getEnvInt("RETENTION_PURGE_MONTHS", 0),
+			InactiveUserMonths: getEnvInt("RETENTION_INACTIVE_USER_MONTHS", 0),
+		},
+		Encryption: EncryptionConfig{
+			MasterKey: getEnv("ENCRYPTION_KEY", ""),
+		},
+		Document: DocumentConfig{
+			KindergartenName: getEnv("KINDERGARTEN_NAME", "Anor Kids"),
+			LogoPath:         getEnv("KINDERGARTEN_LOGO", ""),
+		},
+		Report: ReportConfig{
+			StaleAfterDays: getEnvInt("REPORT_STALE_DAYS", 7),
+		},
+		SLA: SLAConfig{
+			ComplaintHours:  getEnvInt("SLA_COMPLAINT_HOURS", 48),
+			ProposalHours:   getEnvInt("SLA_PROPOSAL_HOURS", 168),
+			EscalationHours: getEnvInt("SLA_ESCALATION_HOURS", 24),
+			DirectorPhone:   strings.TrimSpace(getEnv("DIRECTOR_PHONE", "")),
+		},
+	}
+
+	// The first admin is the super-admin unless configured otherwise
+	if len(cfg.Admin.SuperAdminPhones) == 0 && len(cfg.Admin.PhoneNumbers) > 0 {
+		cfg.Admin.SuperAdminPhones = cfg.Admin.PhoneNumbers[:1]
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -87,9 +174,61 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("maximum 3 admin phone numbers allowed, got %d", len(c.Admin.PhoneNumbers))
 	}
 
+	for _, phone := range c.Admin.SuperAdminPhones {
+		if !c.Admin.IsAdminPhone(phone) {
+			return fmt.Errorf("super-admin phone %s must also be listed in ADMIN_PHONES", phone)
+		}
+	}
+
+	if c.Admin.APIToken != "" && len(c.Admin.APIToken) < minAPITokenLength {
+		return fmt.Errorf("ADMIN_API_TOKEN must be at least %d characters", minAPITokenLength)
+	}
+
+	if c.Retention.ArchiveAfterDays < 0 || c.Retention.PurgeAfterMonths < 0 || c.Retention.InactiveUserMonths < 0 {
+		return fmt.Errorf("retention periods must not be negative")
+	}
+
+	if c.Report.StaleAfterDays < 1 {
+		return fmt.Errorf("REPORT_STALE_DAYS must be at least 1")
+	}
+
+	if c.SLA.ComplaintHours < 0 || c.SLA.ProposalHours < 0 {
+		return fmt.Errorf("SLA hours cannot be negative")
+	}
+
+	if c.SLA.EscalationHours < 1 {
+		return fmt.Errorf("SLA_ESCALATION_HOURS must be at least 1")
+	}
+
+	if c.Encryption.MasterKey != "" {
+		if _, err := encryption.ParseKey(c.Encryption.MasterKey); err != nil {
+			return fmt.Errorf("invalid ENCRYPTION_KEY: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// IsAdminPhone reports whether the phone is a configured admin phone
+func (c *AdminConfig) IsAdminPhone(phone string) bool {
+	for _, adminPhone := range c.PhoneNumbers {
+		if phone == adminPhone {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSuperAdminPhone reports whether the phone is a configured super-admin phone
+func (c *AdminConfig) IsSuperAdminPhone(phone string) bool {
+	for _, superAdminPhone := range c.SuperAdminPhones {
+		if phone == superAdminPhone {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDBPath returns the SQLite database file path
 func (c *DatabaseConfig) GetDBPath() string {
 	return c.Path
@@ -103,6 +242,24 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvBool gets boolean environment variable with fallback
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvInt gets integer environment variable with fallback
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
 // parseAdminPhones parses comma-separated admin phone numbers
 func parseAdminPhones(phones string) []string {
 	if phones == "" {
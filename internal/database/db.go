@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"anor-kids/internal/config"
@@ -58,6 +59,163 @@ func Close() error {
 	return nil
 }
 
+// columnMigration describes a column added to an existing table by a migration
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// migrationColumns lists the columns each migration adds with ALTER TABLE
+var migrationColumns = map[string][]columnMigration{
+	"internal/database/migrations/003_add_announcement_is_document.sql": {
+		{table: "announcements", column: "is_document", definition: "BOOLEAN DEFAULT 0"},
+	},
+	"internal/database/migrations/004_registration_approval.sql": {
+		{table: "users", column: "approval_status", definition: "TEXT DEFAULT 'approved' CHECK (approval_status IN ('pending', 'approved', 'rejected'))"},
+		{table: "users", column: "approval_reviewed_at", definition: "DATETIME"},
+		{table: "classes", column: "teacher_phone", definition: "TEXT"},
+		{table: "classes", column: "teacher_telegram_id", definition: "INTEGER"},
+	},
+	"internal/database/migrations/005_data_retention.sql": {
+		{table: "complaints", column: "reviewed_at", definition: "DATETIME"},
+		{table: "complaints", column: "purged_at", definition: "DATETIME"},
+		{table: "proposals", column: "reviewed_at", definition: "DATETIME"},
+		{table: "proposals", column: "purged_at", definition: "DATETIME"},
+		{table: "users", column: "last_active_at", definition: "DATETIME"},
+	},
+	"internal/database/migrations/006_field_encryption.sql": {
+		{table: "users", column: "phone_hash", definition: "TEXT"},
+		{table: "admins", column: "phone_hash", definition: "TEXT"},
+	},
+	"internal/database/migrations/010_admin_reports.sql": {
+		{table: "announcements", column: "delivered_count", definition: "INTEGER NOT NULL DEFAULT 0"},
+		{table: "announcements", column: "failed_count", definition: "INTEGER NOT NULL DEFAULT 0"},
+	},
+	"internal/database/migrations/011_review_sla.sql": {
+		{table: "complaints", column: "first_response_at", definition: "DATETIME"},
+		{table: "complaints", column: "escalation_level", definition: "INTEGER NOT NULL DEFAULT 0"},
+		{table: "complaints", column: "escalated_at", definition: "DATETIME"},
+		{table: "proposals", column: "first_response_at", definition: "DATETIME"},
+		{table: "proposals", column: "escalation_level", definition: "INTEGER NOT NULL DEFAULT 0"},
+		{table: "proposals", column: "escalated_at", definition: "DATETIME"},
+	},
+	"internal/database/migrations/012_complaint_assignment.sql": {
+		{table: "complaints", column: "assigned_admin_id", definition: "INTEGER REFERENCES admins(id) ON DELETE SET NULL"},
+		{table: "complaints", column: "assigned_at", definition: "DATETIME"},
+	},
+	"internal/database/migrations/013_complaint_categories.sql": {
+		{table: "complaints", column: "category_id", definition: "INTEGER REFERENCES complaint_categories(id)"},
+	},
+	"internal/database/migrations/014_urgent_complaints.sql": {
+		{table: "complaints", column: "is_urgent", definition: "INTEGER NOT NULL DEFAULT 0"},
+		{table: "complaints", column: "urgent_alerted_at", definition: "DATETIME"},
+		{table: "complaints", column: "urgent_alert_count", definition: "INTEGER NOT NULL DEFAULT 0"},
+		{table: "complaints", column: "urgent_acknowledged_at", definition: "DATETIME"},
+		{table: "complaints", column: "urgent_acknowledged_by", definition: "INTEGER"},
+	},
+	"internal/database/migrations/015_anonymous_complaints.sql": {
+		{table: "complaints", column: "is_anonymous", definition: "INTEGER NOT NULL DEFAULT 0"},
+	},
+	"internal/database/migrations/016_complaint_versions.sql": {
+		{table: "complaints", column: "version", definition: "INTEGER NOT NULL DEFAULT 1"},
+		{table: "complaints", column: "edited_at", definition: "DATETIME"},
+		{table: "complaints", column: "withdrawn_at", definition: "DATETIME"},
+	},
+}
+
+// constraintMigration describes a CHECK constraint of an existing table that a
+// migration widens to allow more values
+type constraintMigration struct {
+	table string
+	old   string
+	new   string
+}
+
+// migrationConstraints lists the CHECK constraints each migration widens
+var migrationConstraints = map[string][]constraintMigration{
+	"internal/database/migrations/016_complaint_versions.sql": {
+		{
+			table: "complaints",
+			old:   "CHECK (status IN ('pending', 'reviewed', 'archived'))",
+			new:   "CHECK (status IN ('pending', 'reviewed', 'archived', 'withdrawn'))",
+		},
+	},
+}
+
+// ensureColumn adds a column to a table unless it already exists
+func ensureColumn(col columnMigration) error {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?", col.table)
+	if err := DB.QueryRow(query, col.column).Scan(&count); err != nil {
+		return fmt.Errorf("failed to inspect %s.%s: %w", col.table, col.column, err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err := DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", col.table, col.column, col.definition))
+	if err != nil {
+		return fmt.Errorf("failed to add %s column: %w", col.column, err)
+	}
+
+	return nil
+}
+
+// ensureConstraint rewrites a CHECK constraint in the stored table definition
+// unless it was already rewritten. SQLite can't alter a constraint; since the
+// new one only allows more values, existing rows stay valid and the schema can
+// be edited in place instead of rebuilding the table.
+func ensureConstraint(c constraintMigration) error {
+	var tableSQL string
+	err := DB.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", c.table).Scan(&tableSQL)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", c.table, err)
+	}
+
+	if !strings.Contains(tableSQL, c.old) {
+		return nil
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow("PRAGMA schema_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{query: "PRAGMA writable_schema = ON"},
+		{query: "UPDATE sqlite_master SET sql = ? WHERE type = 'table' AND name = ?", args: []any{strings.Replace(tableSQL, c.old, c.new, 1), c.table}},
+		// Makes open connections reload the schema
+		{query: fmt.Sprintf("PRAGMA schema_version = %d", version+1)},
+		{query: "PRAGMA writable_schema = OFF"},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return fmt.Errorf("failed to widen %s constraint: %w", c.table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // RunMigrations executes SQL migration files with better error handling
 func RunMigrations(migrationPath string) error {
 	if DB == nil {
@@ -69,18 +227,18 @@ func RunMigrations(migrationPath string) error {
 		return fmt.Errorf("failed to read migration file: %w", err)
 	}
 
-	// Special handling for migration 003 - check if is_document column exists
-	if migrationPath == "internal/database/migrations/003_add_announcement_is_document.sql" {
-		var count int
-		err := DB.QueryRow("SELECT COUNT(*) FROM pragma_table_info('announcements') WHERE name='is_document'").Scan(&count)
-		if err == nil && count == 0 {
-			// Column doesn't exist, add it
-			_, err = DB.Exec("ALTER TABLE announcements ADD COLUMN is_document BOOLEAN DEFAULT 0")
-			if err != nil {
-				return fmt.Errorf("failed to add is_document column: %w", err)
-			}
+	// SQLite has no ADD COLUMN IF NOT EXISTS, so columns introduced by a
+	// migration are added here before the rest of the file runs
+	for _, col := range migrationColumns[migrationPath] {
+		if err := ensureColumn(col); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range migrationConstraints[migrationPath] {
+		if err := ensureConstraint(c); err != nil {
+			return err
 		}
-		// Continue with the rest of the migration (DROP VIEW and CREATE VIEW)
 	}
 
 	_, err = DB.Exec(string(sqlBytes))
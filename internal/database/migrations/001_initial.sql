@@ -27,7 +27,7 @@ CREATE TABLE IF NOT EXISTS complaints (
     telegram_file_id VARCHAR(255) NOT NULL,
     filename VARCHAR(500) NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'reviewed', 'archived'))
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'reviewed', 'archived', 'withdrawn'))
 );
 
 -- Indexes for complaints table (optimized for admin dashboard)
@@ -39,7 +39,7 @@ CREATE TABLE IF NOT EXISTS complaints (
     pdf_telegram_file_id TEXT NOT NULL, -- PDF file stored in Telegram cloud
     pdf_filename TEXT NOT NULL,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    status TEXT DEFAULT 'pending' CHECK (status IN ('pending', 'reviewed', 'archived')),
+    status TEXT DEFAULT 'pending' CHECK (status IN ('pending', 'reviewed', 'archived', 'withdrawn')),
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
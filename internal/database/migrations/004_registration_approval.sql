@@ -0,0 +1,14 @@
+-- Migration 004: Registration approval queue
+-- New registrations can be held as 'pending' until the class teacher or an admin approves them
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   users.approval_status       - 'pending', 'approved' or 'rejected' (existing users stay approved)
+--   users.approval_reviewed_at  - when the registration was approved or rejected
+--   classes.teacher_phone       - phone number of the class teacher, set by admins
+--   classes.teacher_telegram_id - linked when the teacher verifies their phone via /admin_link
+
+-- Index for the pending registrations queue
+CREATE INDEX IF NOT EXISTS idx_users_approval_status ON users(approval_status, registered_at);
+
+-- Index for teacher lookups when linking and authorizing approvals
+CREATE INDEX IF NOT EXISTS idx_classes_teacher_phone ON classes(teacher_phone);
+CREATE INDEX IF NOT EXISTS idx_classes_teacher_telegram_id ON classes(teacher_telegram_id);
@@ -0,0 +1,35 @@
+-- Migration 005: Data retention policy
+-- Reviewed items are archived, old complaint/proposal contents purged and inactive users deleted
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   complaints.reviewed_at / proposals.reviewed_at - when the item was marked reviewed
+--   complaints.purged_at   / proposals.purged_at   - when text and image references were purged
+--   users.last_active_at                           - last message or button press from the user
+
+-- Anonymized counts kept for statistics after users are deleted
+CREATE TABLE IF NOT EXISTS anonymized_stats (
+    kind TEXT NOT NULL CHECK (kind IN ('complaint', 'proposal')),
+    class_name TEXT NOT NULL,
+    month TEXT NOT NULL, -- YYYY-MM of created_at
+    status TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (kind, class_name, month, status)
+);
+
+-- Log of retention runs (dry runs are reported to super-admins before the first real run)
+CREATE TABLE IF NOT EXISTS retention_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dry_run INTEGER NOT NULL DEFAULT 0 CHECK (dry_run IN (0, 1)),
+    archived_complaints INTEGER NOT NULL DEFAULT 0,
+    archived_proposals INTEGER NOT NULL DEFAULT 0,
+    purged_complaints INTEGER NOT NULL DEFAULT 0,
+    purged_proposals INTEGER NOT NULL DEFAULT 0,
+    deleted_users INTEGER NOT NULL DEFAULT 0,
+    run_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_runs_run_at ON retention_runs(dry_run, run_at DESC);
+
+-- Indexes for retention queries
+CREATE INDEX IF NOT EXISTS idx_users_last_active_at ON users(last_active_at);
+CREATE INDEX IF NOT EXISTS idx_complaints_purged_at ON complaints(purged_at, created_at);
+CREATE INDEX IF NOT EXISTS idx_proposals_purged_at ON proposals(purged_at, created_at);
@@ -0,0 +1,20 @@
+-- Migration 006: Field-level encryption of parent data
+-- users.phone_number, users.child_name, complaints.complaint_text and proposals.proposal_text
+-- are stored encrypted when ENCRYPTION_KEY is set (see internal/encryption).
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   users.phone_hash  - blind index of the phone number for exact-match lookups
+--   admins.phone_hash - blind index of the admin phone number
+
+-- Data keys wrapped with the master key. Old data keys are kept so rows
+-- encrypted before a rotation stay readable until they are re-encrypted.
+CREATE TABLE IF NOT EXISTS data_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    purpose TEXT NOT NULL CHECK (purpose IN ('data', 'index')),
+    wrapped_key TEXT NOT NULL,
+    active INTEGER NOT NULL DEFAULT 0 CHECK (active IN (0, 1)),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Phone uniqueness moves to the blind index, since encrypted values never repeat
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_hash ON users(phone_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admins_phone_hash ON admins(phone_hash);
@@ -0,0 +1,19 @@
+-- Migration 007: Asynchronous document generation
+-- Complaints and proposals are saved first (with an empty pdf_telegram_file_id);
+-- a background worker generates and uploads the PDF and fills it in.
+
+CREATE TABLE IF NOT EXISTS document_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL CHECK (kind IN ('complaint', 'proposal')),
+    item_id INTEGER NOT NULL, -- complaints.id or proposals.id
+    chat_id INTEGER NOT NULL, -- parent chat the PDF is uploaded to
+    message_id INTEGER NOT NULL DEFAULT 0, -- progress message edited on completion (0 = none)
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_jobs_due ON document_jobs(status, next_attempt_at);
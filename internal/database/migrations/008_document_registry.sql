@@ -0,0 +1,30 @@
+-- Migration 008: Tamper-evident document registry
+-- Every generated complaint/proposal PDF is recorded with its SHA-256. Entries
+-- form a hash chain: entry_hash covers the entry's fields and prev_hash, the
+-- entry_hash of the entry before it (64 zeros for the first entry).
+
+CREATE TABLE IF NOT EXISTS document_registry (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE, -- verification code printed in the PDF footer
+    kind TEXT NOT NULL CHECK (kind IN ('complaint', 'proposal')),
+    item_id INTEGER NOT NULL, -- complaints.id or proposals.id
+    document_hash TEXT NOT NULL, -- SHA-256 of the PDF, hex
+    prev_hash TEXT NOT NULL UNIQUE, -- unique, so the chain can't fork
+    entry_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_registry_document_hash ON document_registry(document_hash);
+
+-- The registry is append-only
+CREATE TRIGGER IF NOT EXISTS document_registry_no_update
+BEFORE UPDATE ON document_registry
+BEGIN
+    SELECT RAISE(ABORT, 'document_registry is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS document_registry_no_delete
+BEFORE DELETE ON document_registry
+BEGIN
+    SELECT RAISE(ABORT, 'document_registry is append-only');
+END;
@@ -0,0 +1,15 @@
+-- Migration 009: Pre-registered parents
+-- Admins import the expected parents of each class from a CSV/XLSX roster.
+-- When a roster phone shares its contact during registration, the child and
+-- class are filled in from the roster and the entry is removed.
+-- phone_number and child_name are encrypted like the users columns;
+-- phone_hash is the blind index used for lookups.
+
+CREATE TABLE IF NOT EXISTS roster_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    phone_number TEXT NOT NULL,
+    phone_hash TEXT NOT NULL UNIQUE,
+    child_name TEXT NOT NULL,
+    child_class TEXT NOT NULL,
+    imported_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
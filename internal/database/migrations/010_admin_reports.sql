@@ -0,0 +1,16 @@
+-- Migration 010: Scheduled admin reports
+-- Admins subscribe to a weekly (Monday) or monthly (1st of the month) PDF
+-- report, delivered at the hour they choose.
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   announcements.delivered_count - parents the broadcast reached
+--   announcements.failed_count    - parents the broadcast could not be sent to
+
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    admin_id INTEGER NOT NULL UNIQUE,
+    frequency TEXT NOT NULL DEFAULT 'weekly' CHECK (frequency IN ('weekly', 'monthly')),
+    send_hour INTEGER NOT NULL DEFAULT 8 CHECK (send_hour BETWEEN 0 AND 23),
+    last_sent_at DATETIME,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (admin_id) REFERENCES admins(id) ON DELETE CASCADE
+);
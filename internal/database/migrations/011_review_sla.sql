@@ -0,0 +1,11 @@
+-- Migration 011: Review SLA tracking
+-- Pending complaints and proposals past their SLA are escalated step by step:
+-- the class teacher, then all admins, then the director.
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   complaints.first_response_at / proposals.first_response_at - when staff first took the item in hand
+--   complaints.escalation_level  / proposals.escalation_level  - last reminder sent (0 none, 1 teacher, 2 admins, 3 director)
+--   complaints.escalated_at      / proposals.escalated_at      - when the last reminder was sent
+
+-- Index for the overdue queries
+CREATE INDEX IF NOT EXISTS idx_complaints_status_created_at ON complaints(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_proposals_status_created_at ON proposals(status, created_at);
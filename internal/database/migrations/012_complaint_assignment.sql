@@ -0,0 +1,9 @@
+-- Migration 012: Complaint assignment
+-- A complaint can be assigned to one admin, who handles it and receives its
+-- first SLA reminder.
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   complaints.assigned_admin_id - admin handling the complaint
+--   complaints.assigned_at       - when it was (last) assigned
+
+-- Index for the "My assigned" inbox
+CREATE INDEX IF NOT EXISTS idx_complaints_assigned_admin_id ON complaints(assigned_admin_id, status);
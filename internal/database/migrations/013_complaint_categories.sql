@@ -0,0 +1,54 @@
+-- Migration 013: Complaint categories
+-- Parents choose a category for each complaint. Admins manage the list and
+-- can deactivate a category; categories are never deleted, so old complaints
+-- keep theirs.
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   complaints.category_id - category chosen by the parent, NULL for older complaints
+
+CREATE TABLE IF NOT EXISTS complaint_categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name_uz TEXT NOT NULL,
+    name_ru TEXT NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active INTEGER NOT NULL DEFAULT 1 CHECK (is_active IN (0, 1)),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_complaint_categories_active ON complaint_categories(is_active, sort_order);
+
+-- Default categories; renamed or deactivated ones are left alone
+INSERT INTO complaint_categories (id, name_uz, name_ru, sort_order) VALUES
+    (1, 'Ovqatlanish', 'Питание', 10),
+    (2, 'Gigiyena', 'Гигиена', 20),
+    (3, 'Xavfsizlik', 'Безопасность', 30),
+    (4, 'Xodimlar xulqi', 'Поведение персонала', 40),
+    (5, 'Bino va jihozlar', 'Помещения и оборудование', 50),
+    (6, 'Boshqa', 'Другое', 60)
+ON CONFLICT(id) DO NOTHING;
+
+-- Index for filtering complaints by category
+CREATE INDEX IF NOT EXISTS idx_complaints_category_id ON complaints(category_id, created_at DESC);
+
+-- The complaints view gains the category
+DROP VIEW IF EXISTS v_complaints_with_user;
+CREATE VIEW v_complaints_with_user AS
+SELECT
+    c.id,
+    c.user_id,
+    c.complaint_text,
+    c.pdf_telegram_file_id,
+    c.pdf_filename,
+    c.created_at,
+    c.status,
+    u.telegram_id AS user_telegram_id,
+    u.telegram_username,
+    u.phone_number,
+    u.child_name,
+    u.child_class,
+    c.category_id,
+    k.name_uz AS category_name_uz,
+    k.name_ru AS category_name_ru
+FROM complaints c
+INNER JOIN users u ON c.user_id = u.id
+LEFT JOIN complaint_categories k ON k.id = c.category_id
+ORDER BY c.created_at DESC;
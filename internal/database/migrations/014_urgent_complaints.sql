@@ -0,0 +1,37 @@
+-- Migration 014: Urgent complaints
+-- A parent can mark a complaint urgent. All admins are alerted at once and
+-- again every 15 minutes until a staff member acknowledges it.
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   complaints.is_urgent              - marked urgent by the parent
+--   complaints.urgent_alerted_at      - when admins were last alerted
+--   complaints.urgent_alert_count     - how many alerts were sent
+--   complaints.urgent_acknowledged_at - when staff acknowledged it, NULL while alerts continue
+--   complaints.urgent_acknowledged_by - Telegram ID of the staff member who acknowledged it
+
+-- Index for the alert checker
+CREATE INDEX IF NOT EXISTS idx_complaints_urgent ON complaints(is_urgent, urgent_acknowledged_at, status);
+
+-- The complaints view gains the urgency
+DROP VIEW IF EXISTS v_complaints_with_user;
+CREATE VIEW v_complaints_with_user AS
+SELECT
+    c.id,
+    c.user_id,
+    c.complaint_text,
+    c.pdf_telegram_file_id,
+    c.pdf_filename,
+    c.created_at,
+    c.status,
+    u.telegram_id AS user_telegram_id,
+    u.telegram_username,
+    u.phone_number,
+    u.child_name,
+    u.child_class,
+    c.category_id,
+    k.name_uz AS category_name_uz,
+    k.name_ru AS category_name_ru,
+    c.is_urgent
+FROM complaints c
+INNER JOIN users u ON c.user_id = u.id
+LEFT JOIN complaint_categories k ON k.id = c.category_id
+ORDER BY c.created_at DESC;
@@ -0,0 +1,57 @@
+-- Migration 015: Anonymous complaints
+-- A parent can send a complaint anonymously. It stays linked to the parent for
+-- abuse handling, but staff see it without name, phone or child; only a
+-- super-admin can reveal the author, and every reveal is audited.
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   complaints.is_anonymous - sent anonymously by the parent
+
+-- Replies from staff, relayed to the parent through the bot
+CREATE TABLE IF NOT EXISTS complaint_replies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    complaint_id INTEGER NOT NULL REFERENCES complaints(id) ON DELETE CASCADE,
+    admin_telegram_id INTEGER NOT NULL,
+    admin_name TEXT NOT NULL DEFAULT '',
+    reply_text TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_complaint_replies_complaint_id ON complaint_replies(complaint_id, created_at);
+
+-- Audit log of sensitive staff actions, such as revealing an anonymous author
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_telegram_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    item_kind TEXT NOT NULL,
+    item_id INTEGER NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_item ON audit_log(item_kind, item_id, created_at);
+
+-- The complaints view gains the anonymity
+DROP VIEW IF EXISTS v_complaints_with_user;
+CREATE VIEW v_complaints_with_user AS
+SELECT
+    c.id,
+    c.user_id,
+    c.complaint_text,
+    c.pdf_telegram_file_id,
+    c.pdf_filename,
+    c.created_at,
+    c.status,
+    u.telegram_id AS user_telegram_id,
+    u.telegram_username,
+    u.phone_number,
+    u.child_name,
+    u.child_class,
+    c.category_id,
+    k.name_uz AS category_name_uz,
+    k.name_ru AS category_name_ru,
+    c.is_urgent,
+    c.is_anonymous
+FROM complaints c
+INNER JOIN users u ON c.user_id = u.id
+LEFT JOIN complaint_categories k ON k.id = c.category_id
+ORDER BY c.created_at DESC;
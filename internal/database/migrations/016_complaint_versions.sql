@@ -0,0 +1,22 @@
+-- Migration 016: Withdrawn and corrected complaints
+-- Until a complaint is reviewed, the parent can withdraw it (status 'withdrawn')
+-- or send a corrected text, which gets a new PDF. Earlier versions are kept.
+-- Columns are added in the application layer (see migrationColumns in db.go):
+--   complaints.version      - 1 for the original text, incremented on each correction
+--   complaints.edited_at    - when the current version was sent, NULL for the original
+--   complaints.withdrawn_at - when the parent withdrew it
+-- The status CHECK constraint of complaints is widened with 'withdrawn' in the
+-- same place (see migrationConstraints in db.go).
+
+-- Earlier versions of corrected complaints
+CREATE TABLE IF NOT EXISTS complaint_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    complaint_id INTEGER NOT NULL REFERENCES complaints(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    complaint_text TEXT NOT NULL,
+    pdf_telegram_file_id TEXT NOT NULL DEFAULT '',
+    pdf_filename TEXT NOT NULL DEFAULT '',
+    submitted_at DATETIME NOT NULL, -- when this version was sent
+    replaced_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (complaint_id, version)
+);
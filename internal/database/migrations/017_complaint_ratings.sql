@@ -0,0 +1,18 @@
+-- Migration 017: Satisfaction ratings
+-- When a complaint is marked reviewed, its author is asked to rate the handling
+-- from 1 to 5, with an optional comment. The request is stored with the staff
+-- member who reviewed the complaint, so ratings can be averaged per staff member.
+
+CREATE TABLE IF NOT EXISTS complaint_ratings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    complaint_id INTEGER NOT NULL UNIQUE REFERENCES complaints(id) ON DELETE CASCADE,
+    staff_telegram_id INTEGER NOT NULL, -- who marked the complaint reviewed
+    staff_name TEXT NOT NULL DEFAULT '', -- their first name, '' if unknown
+    rating INTEGER CHECK (rating BETWEEN 1 AND 5), -- NULL until the parent answers
+    comment TEXT NOT NULL DEFAULT '',
+    requested_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    rated_at DATETIME
+);
+
+-- Index for statistics by period
+CREATE INDEX IF NOT EXISTS idx_complaint_ratings_rated_at ON complaint_ratings(rated_at);
@@ -0,0 +1,283 @@
+// Package encryption implements field-level envelope encryption for PII stored in the database.
+//
+// A master key from config wraps randomly generated data keys, which are stored
+// (wrapped) in the data_keys table. Field values are encrypted with AES-GCM using
+// the active data key and stored as "enc:v<key id>:<base64 nonce+ciphertext>", so
+// rows encrypted with older keys stay readable after rotation. Values without
+// the prefix are treated as plaintext, which lets existing rows be migrated in place.
+//
+// Phone numbers additionally get a blind index (HMAC-SHA256 with a separate index
+// key) so that exact-match lookups keep working on encrypted columns.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// KeySize is the size of master, data and index keys in bytes (AES-256)
+const KeySize = 32
+
+// prefix marks encrypted field values
+const prefix = "enc:v"
+
+// ErrNoKey is returned when decrypting a value whose data key is not loaded
+var ErrNoKey = errors.New("encryption key not available")
+
+// Cipher encrypts and decrypts field values. A nil *Cipher is valid and passes
+// values through unchanged, which is used when no master key is configured.
+type Cipher struct {
+	master   cipher.AEAD
+	indexKey []byte
+
+	mu       sync.RWMutex
+	dataKeys map[int]cipher.AEAD
+	activeID int
+}
+
+// ParseKey decodes a base64 or hex encoded 32-byte key
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("key must be %d bytes, base64 or hex encoded", KeySize)
+}
+
+// GenerateKey returns a new random key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// New creates a cipher with the given master key. Data keys and the index key
+// are added with AddDataKey and SetIndexKey after unwrapping them.
+func New(masterKey []byte) (*Cipher, error) {
+	master, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{
+		master:   master,
+		dataKeys: make(map[int]cipher.AEAD),
+	}, nil
+}
+
+// newAEAD creates an AES-GCM AEAD for a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d, expected %d", len(key), KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce prepended
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts data produced by seal
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// WrapKey encrypts a data or index key with the master key
+func (c *Cipher) WrapKey(key []byte) (string, error) {
+	sealed, err := seal(c.master, key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// UnwrapKey decrypts a key wrapped with WrapKey
+func (c *Cipher) UnwrapKey(wrapped string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped key: %w", err)
+	}
+
+	key, err := open(c.master, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key (wrong master key?): %w", err)
+	}
+
+	return key, nil
+}
+
+// AddDataKey registers an unwrapped data key. The active key encrypts new values.
+func (c *Cipher) AddDataKey(id int, key []byte, active bool) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.dataKeys[id] = aead
+	if active {
+		c.activeID = id
+	}
+
+	return nil
+}
+
+// SetIndexKey sets the key used for blind indexes
+func (c *Cipher) SetIndexKey(key []byte) error {
+	if len(key) != KeySize {
+		return fmt.Errorf("invalid index key size %d, expected %d", len(key), KeySize)
+	}
+	c.indexKey = key
+	return nil
+}
+
+// ActiveKeyID returns the ID of the data key used for new values, 0 if none
+func (c *Cipher) ActiveKeyID() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.activeID
+}
+
+// Encrypt encrypts a field value with the active data key.
+// Empty values stay empty so that purged fields remain recognizable.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	c.mu.RLock()
+	id := c.activeID
+	aead := c.dataKeys[id]
+	c.mu.RUnlock()
+
+	if aead == nil {
+		return "", fmt.Errorf("no active data key")
+	}
+
+	sealed, err := seal(aead, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + strconv.Itoa(id) + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a field value. Plaintext values are returned unchanged.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	id, payload, ok := parse(value)
+	if !ok {
+		return value, nil
+	}
+
+	if c == nil {
+		return "", ErrNoKey
+	}
+
+	c.mu.RLock()
+	aead := c.dataKeys[id]
+	c.mu.RUnlock()
+
+	if aead == nil {
+		return "", fmt.Errorf("%w: data key %d", ErrNoKey, id)
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+
+	plaintext, err := open(aead, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsReencryption reports whether a value is not encrypted with the active data key
+func (c *Cipher) NeedsReencryption(value string) bool {
+	if c == nil || value == "" {
+		return false
+	}
+
+	id, _, ok := parse(value)
+	return !ok || id != c.ActiveKeyID()
+}
+
+// BlindIndex returns a deterministic keyed hash of a value for exact-match lookups.
+// Without a cipher an unkeyed SHA-256 is used so lookups work the same way.
+func (c *Cipher) BlindIndex(value string) string {
+	if c == nil || c.indexKey == nil {
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:])
+	}
+
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted reports whether a value is an encrypted field value
+func IsEncrypted(value string) bool {
+	_, _, ok := parse(value)
+	return ok
+}
+
+// parse splits an encrypted value into key ID and payload
+func parse(value string) (int, string, bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return 0, "", false
+	}
+
+	rest := value[len(prefix):]
+	sep := strings.IndexByte(rest, ':')
+	if sep <= 0 {
+		return 0, "", false
+	}
+
+	id, err := strconv.Atoi(rest[:sep])
+	if err != nil {
+		return 0, "", false
+	}
+
+	return id, rest[sep+1:], true
+}
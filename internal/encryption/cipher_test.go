@@ -0,0 +1,169 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func newTestCipher(t *testing.T) *Cipher {
+	t.Helper()
+
+	c, err := New(bytes.Repeat([]byte{1}, KeySize))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := c.AddDataKey(1, bytes.Repeat([]byte{2}, KeySize), true); err != nil {
+		t.Fatalf("AddDataKey() error = %v", err)
+	}
+	if err := c.SetIndexKey(bytes.Repeat([]byte{3}, KeySize)); err != nil {
+		t.Fatalf("SetIndexKey() error = %v", err)
+	}
+	return c
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	c := newTestCipher(t)
+
+	tests := []string{"+998901234567", "Aliyev Vali", "Shikoyat matni / Текст жалобы 😀", ""}
+	for _, plaintext := range tests {
+		encrypted, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) error = %v", plaintext, err)
+		}
+
+		if plaintext != "" && (encrypted == plaintext || !IsEncrypted(encrypted)) {
+			t.Errorf("Encrypt(%q) = %q, want encrypted value", plaintext, encrypted)
+		}
+
+		decrypted, err := c.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("Decrypt(%q) error = %v", encrypted, err)
+		}
+		if decrypted != plaintext {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q", plaintext, decrypted)
+		}
+	}
+}
+
+func TestEncryptUsesRandomNonce(t *testing.T) {
+	c := newTestCipher(t)
+
+	a, _ := c.Encrypt("+998901234567")
+	b, _ := c.Encrypt("+998901234567")
+	if a == b {
+		t.Error("Encrypt() returned the same ciphertext twice")
+	}
+}
+
+func TestDecryptPlaintext(t *testing.T) {
+	c := newTestCipher(t)
+
+	got, err := c.Decrypt("+998901234567")
+	if err != nil || got != "+998901234567" {
+		t.Errorf("Decrypt(plaintext) = %q, %v; want value unchanged", got, err)
+	}
+}
+
+func TestNilCipher(t *testing.T) {
+	var c *Cipher
+
+	encrypted, err := c.Encrypt("+998901234567")
+	if err != nil || encrypted != "+998901234567" {
+		t.Errorf("nil Encrypt() = %q, %v; want value unchanged", encrypted, err)
+	}
+
+	if c.NeedsReencryption("+998901234567") {
+		t.Error("nil NeedsReencryption() = true, want false")
+	}
+
+	value, _ := newTestCipher(t).Encrypt("+998901234567")
+	if _, err := c.Decrypt(value); !errors.Is(err, ErrNoKey) {
+		t.Errorf("nil Decrypt(encrypted) error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	c := newTestCipher(t)
+
+	old, _ := c.Encrypt("Aliyev Vali")
+	if c.NeedsReencryption(old) {
+		t.Error("NeedsReencryption() = true for value with active key")
+	}
+	if !c.NeedsReencryption("Aliyev Vali") {
+		t.Error("NeedsReencryption() = false for plaintext")
+	}
+	if c.NeedsReencryption("") {
+		t.Error("NeedsReencryption() = true for empty value")
+	}
+
+	if err := c.AddDataKey(2, bytes.Repeat([]byte{4}, KeySize), true); err != nil {
+		t.Fatalf("AddDataKey() error = %v", err)
+	}
+	if !c.NeedsReencryption(old) {
+		t.Error("NeedsReencryption() = false for value with inactive key")
+	}
+
+	decrypted, err := c.Decrypt(old)
+	if err != nil || decrypted != "Aliyev Vali" {
+		t.Errorf("Decrypt(old key) = %q, %v", decrypted, err)
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	c := newTestCipher(t)
+	key := bytes.Repeat([]byte{5}, KeySize)
+
+	wrapped, err := c.WrapKey(key)
+	if err != nil {
+		t.Fatalf("WrapKey() error = %v", err)
+	}
+
+	unwrapped, err := c.UnwrapKey(wrapped)
+	if err != nil || !bytes.Equal(unwrapped, key) {
+		t.Errorf("UnwrapKey(WrapKey(key)) = %x, %v", unwrapped, err)
+	}
+
+	other, _ := New(bytes.Repeat([]byte{6}, KeySize))
+	if _, err := other.UnwrapKey(wrapped); err == nil {
+		t.Error("UnwrapKey() with wrong master key succeeded")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	c := newTestCipher(t)
+
+	if c.BlindIndex("+998901234567") != c.BlindIndex("+998901234567") {
+		t.Error("BlindIndex() is not deterministic")
+	}
+	if c.BlindIndex("+998901234567") == c.BlindIndex("+998907654321") {
+		t.Error("BlindIndex() is the same for different values")
+	}
+
+	var nilCipher *Cipher
+	if c.BlindIndex("+998901234567") == nilCipher.BlindIndex("+998901234567") {
+		t.Error("keyed BlindIndex() equals unkeyed hash")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{"base64", "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=", false},
+		{"hex", "0101010101010101010101010101010101010101010101010101010101010101", false},
+		{"too short", "AQEB", true},
+		{"invalid", "not a key", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKey(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseKey(%q) error = %v, wantErr %v", tt.encoded, err, tt.wantErr)
+			}
+		})
+	}
+}
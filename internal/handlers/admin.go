@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"anor-kids/internal/i18n"
@@ -45,8 +48,13 @@ func HandleAdminCommand(botService *services.BotService, message *tgbotapi.Messa
 		lang = i18n.GetLanguage(user.Language)
 	}
 
+	// Name the admin for the complaints assigned to them
+	if admin, err := botService.AdminRepo.GetByTelegramID(telegramID); err == nil && admin != nil {
+		syncAdminName(botService, admin, message.From)
+	}
+
 	// Show admin panel
-	text := i18n.Get(i18n.MsgAdminPanel, lang)
+	text := adminPanelText(botService, lang)
 	keyboard := utils.MakeAdminKeyboard(lang)
 
 	return botService.TelegramService.SendMessage(chatID, text, keyboard)
@@ -87,22 +95,47 @@ func HandleAdminUsersCallback(botService *services.BotService, callback *tgbotap
 	return botService.TelegramService.SendMessage(chatID, text, nil)
 }
 
-// HandleAdminComplaintsCallback handles admin complaints list callback
+// HandleAdminComplaintsCallback handles admin complaints list callback.
+// Format: admin_complaints, or admin_complaints_<categoryID> for one category
 func HandleAdminComplaintsCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
 	chatID := callback.Message.Chat.ID
 
+	isAdmin, err := botService.IsAdmin("", callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	categoryID := categoryFilterID(callback.Data, "admin_complaints")
+	categories, err := botService.CategoryRepo.GetAll()
+	if err != nil {
+		text := "Xatolik / Ошибка: " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
 	// Get complaints with user info
-	complaints, err := botService.ComplaintService.GetAllComplaintsWithUser(10, 0)
+	complaints, err := botService.ComplaintService.GetAllComplaintsWithUser(categoryID, 10, 0)
 	if err != nil {
 		text := "Xatolik / Ошибка: " + err.Error()
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
 	// Count total complaints
-	totalCount, _ := botService.ComplaintService.CountComplaints()
+	var totalCount int
+	if categoryID != 0 {
+		totalCount, _ = botService.ComplaintService.CountComplaintsByCategory(categoryID, "")
+	} else {
+		totalCount, _ = botService.ComplaintService.CountComplaints()
+	}
 
 	// Format complaints list
 	text := fmt.Sprintf("📋 Shikoyatlar / Жалобы\n\n")
+	if category := findCategory(categories, categoryID); category != nil {
+		text += fmt.Sprintf("🏷 Toifa / Категория: %s\n", category.Label())
+	}
 	text += fmt.Sprintf("Jami / Всего: %d\n\n", totalCount)
 
 	for i, c := range complaints {
@@ -115,10 +148,24 @@ func HandleAdminComplaintsCallback(botService *services.BotService, callback *tg
 		} else if c.Status == models.StatusArchived {
 			statusEmoji = "📦"
 			statusText = "Arxivlangan / Архивировано"
+		} else if c.Status == models.StatusWithdrawn {
+			statusEmoji = "↩️"
+			statusText = "Qaytarib olingan / Отозвано"
+		}
+
+		urgent := ""
+		if c.IsUrgent {
+			urgent = "🚨 "
+		}
+		if c.IsAnonymous {
+			urgent += "🕶 "
 		}
 
-		text += fmt.Sprintf("%d. %s #%d - %s %s\n", i+1, statusEmoji, c.ID, c.ChildName, c.ChildClass)
+		text += fmt.Sprintf("%d. %s %s#%d - %s %s\n", i+1, statusEmoji, urgent, c.ID, c.ChildName, c.ChildClass)
 		text += fmt.Sprintf("   📱 %s\n", c.PhoneNumber)
+		if c.Category != nil {
+			text += fmt.Sprintf("   🏷 %s\n", utils.EscapeHTML(c.Category.Label()))
+		}
 		preview := utils.TruncateText(c.ComplaintText, 60)
 		text += fmt.Sprintf("   💬 %s\n", preview)
 		text += fmt.Sprintf("   📅 %s\n", utils.FormatDateTime(c.CreatedAt))
@@ -130,7 +177,8 @@ func HandleAdminComplaintsCallback(botService *services.BotService, callback *tg
 	}
 
 	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
-	return botService.TelegramService.SendMessage(chatID, text, nil)
+	keyboard := makeCategoryFilterKeyboard(categories, categoryID, "admin_complaints")
+	return botService.TelegramService.SendMessage(chatID, utils.EscapeHTML(text), keyboard)
 }
 
 // HandleAdminProposalsCallback handles admin proposals list callback
@@ -176,18 +224,45 @@ func HandleAdminProposalsCallback(botService *services.BotService, callback *tgb
 	return botService.TelegramService.SendMessage(chatID, text, nil)
 }
 
-// HandleAdminStatsCallback handles admin statistics callback
+// HandleAdminStatsCallback handles admin statistics callback.
+// Format: admin_stats, or admin_stats_<categoryID> for the complaints of one category
 func HandleAdminStatsCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
 	chatID := callback.Message.Chat.ID
 
+	isAdmin, err := botService.IsAdmin("", callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	categoryID := categoryFilterID(callback.Data, "admin_stats")
+	categories, err := botService.CategoryRepo.GetAll()
+	if err != nil {
+		log.Printf("Failed to get categories: %v", err)
+	}
+	filter := makeCategoryFilterKeyboard(categories, categoryID, "admin_stats")
+
 	// Get statistics
 	totalUsers, _ := botService.UserService.CountUsers()
-	totalComplaints, _ := botService.ComplaintService.CountComplaints()
-	pendingComplaints, _ := botService.ComplaintService.CountComplaintsByStatus(models.StatusPending)
-	reviewedComplaints, _ := botService.ComplaintService.CountComplaintsByStatus(models.StatusReviewed)
+	var totalComplaints, pendingComplaints, reviewedComplaints int
+	if categoryID != 0 {
+		totalComplaints, _ = botService.ComplaintService.CountComplaintsByCategory(categoryID, "")
+		pendingComplaints, _ = botService.ComplaintService.CountComplaintsByCategory(categoryID, models.StatusPending)
+		reviewedComplaints, _ = botService.ComplaintService.CountComplaintsByCategory(categoryID, models.StatusReviewed)
+	} else {
+		totalComplaints, _ = botService.ComplaintService.CountComplaints()
+		pendingComplaints, _ = botService.ComplaintService.CountComplaintsByStatus(models.StatusPending)
+		reviewedComplaints, _ = botService.ComplaintService.CountComplaintsByStatus(models.StatusReviewed)
+	}
 
 	// Format statistics
 	text := "📊 Statistika / Статистика\n\n"
+	if category := findCategory(categories, categoryID); category != nil {
+		text += fmt.Sprintf("🏷 Toifa / Категория: %s\n\n", utils.EscapeHTML(category.Label()))
+	}
 	text += fmt.Sprintf("👥 Foydalanuvchilar / Пользователи: %d\n\n", totalUsers)
 	text += fmt.Sprintf("📋 Jami shikoyatlar / Всего жалоб: %d\n", totalComplaints)
 	text += fmt.Sprintf("⏳ Kutilmoqda / Ожидание: %d\n", pendingComplaints)
@@ -199,7 +274,84 @@ func HandleAdminStatsCallback(botService *services.BotService, callback *tgbotap
 	}
 
 	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
-	return botService.TelegramService.SendMessage(chatID, text, nil)
+
+	// Trends of the last weeks as a chart album
+	dashboard, err := botService.StatsService.Dashboard(services.DefaultStatsWeeks, categoryID, time.Now())
+	if err != nil {
+		log.Printf("Failed to compute statistics: %v", err)
+		return botService.TelegramService.SendMessage(chatID, text, filter)
+	}
+
+	text += fmt.Sprintf("\n📅 Oxirgi %d hafta / Последние %d недель (%s – %s):\n",
+		services.DefaultStatsWeeks, services.DefaultStatsWeeks,
+		dashboard.From.Format("02.01.2006"), dashboard.To.AddDate(0, 0, -1).Format("02.01.2006"))
+	text += fmt.Sprintf("📋 Shikoyatlar / Жалобы: %d\n", dashboard.Complaints)
+	text += fmt.Sprintf("💡 Takliflar / Предложения: %d\n", dashboard.Proposals)
+	text += fmt.Sprintf("👨‍👩‍👧 Faol ota-onalar / Активные родители: %d\n", dashboard.ActiveParents)
+	if dashboard.MedianReviewHours != nil {
+		text += fmt.Sprintf("⏱ Ko'rib chiqish mediana / Медиана рассмотрения: %.1f soat / ч\n", *dashboard.MedianReviewHours)
+	}
+
+	satisfaction := formatSatisfaction(dashboard.Satisfaction)
+
+	charts, err := botService.StatsService.Charts(dashboard)
+	if err != nil {
+		log.Printf("Failed to render statistics charts: %v", err)
+		return botService.TelegramService.SendMessage(chatID, text+satisfaction, filter)
+	}
+
+	if err := botService.TelegramService.SendPhotoAlbum(chatID, charts, text); err != nil {
+		log.Printf("Failed to send statistics charts: %v", err)
+		return botService.TelegramService.SendMessage(chatID, text+satisfaction, filter)
+	}
+
+	// Satisfaction follows the album, its caption is too short for it
+	if satisfaction != "" {
+		if err := botService.TelegramService.SendMessage(chatID, strings.TrimSpace(satisfaction), nil); err != nil {
+			log.Printf("Failed to send satisfaction statistics: %v", err)
+		}
+	}
+
+	// Albums can't carry buttons, the category filter follows
+	if len(categories) > 0 {
+		return botService.TelegramService.SendMessage(chatID, "🏷 Toifa bo'yicha / По категории:", filter)
+	}
+
+	return nil
+}
+
+// formatSatisfaction formats the parents' ratings of reviewed complaints, "" if there are none
+func formatSatisfaction(satisfaction models.Satisfaction) string {
+	if satisfaction.Average == nil {
+		return ""
+	}
+
+	text := fmt.Sprintf("\n⭐ <b>Mamnuniyat / Удовлетворённость: %.1f / 5</b> (%d)\n", *satisfaction.Average, satisfaction.Ratings)
+
+	text += "\n👤 Xodimlar / Сотрудники:\n"
+	for _, staff := range satisfaction.Staff {
+		name := staff.StaffName
+		if name == "" {
+			name = fmt.Sprintf("ID %d", staff.StaffTelegramID)
+		}
+		text += fmt.Sprintf("• %s: %.1f (%d)\n", utils.EscapeHTML(name), staff.Average, staff.Ratings)
+	}
+
+	text += "\n🏷 Toifalar / Категории:\n"
+	for _, category := range satisfaction.Categories {
+		label := "Toifasiz / Без категории"
+		if category.Category != nil {
+			label = category.Category.Label()
+		}
+		text += fmt.Sprintf("• %s: %.1f (%d)\n", utils.EscapeHTML(label), category.Average, category.Ratings)
+	}
+
+	text += "\n📅 Oylar / Месяцы:\n"
+	for _, month := range satisfaction.Months {
+		text += fmt.Sprintf("• %s: %.1f (%d)\n", month.Month.Format("01.2006"), month.Average, month.Ratings)
+	}
+
+	return text
 }
 
 // HandleManageClassesCommand handles /manage_classes command
@@ -524,6 +676,13 @@ func makeClassManagementKeyboard(classes []*models.Class, lang i18n.Language) tg
 	)
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{createBtn})
 
+	// Add roster import button
+	importBtn := tgbotapi.NewInlineKeyboardButtonData(
+		i18n.Get(i18n.BtnImportRoster, lang),
+		"admin_import_roster",
+	)
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{importBtn})
+
 	// Add back button
 	backBtn := tgbotapi.NewInlineKeyboardButtonData(
 		i18n.Get(i18n.BtnBack, lang),
@@ -802,8 +961,25 @@ func HandleAdminBackCallback(botService *services.BotService, callback *tgbotapi
 	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
 
 	// Show admin panel
-	text := i18n.Get(i18n.MsgAdminPanel, lang)
+	text := adminPanelText(botService, lang)
 	keyboard := utils.MakeAdminKeyboard(lang)
 
 	return botService.TelegramService.SendMessage(chatID, text, keyboard)
 }
+
+// adminPanelText returns the admin panel header with the overdue complaints and proposals, if any
+func adminPanelText(botService *services.BotService, lang i18n.Language) string {
+	text := i18n.Get(i18n.MsgAdminPanel, lang)
+
+	overdue, err := botService.SLAService.Overdue(time.Now())
+	if err != nil {
+		log.Printf("Failed to count overdue items: %v", err)
+		return text
+	}
+
+	if overdue.Total() > 0 {
+		text += "\n\n" + fmt.Sprintf(i18n.Get(i18n.MsgAdminOverdue, lang), overdue.Complaints, overdue.Proposals)
+	}
+
+	return text
+}
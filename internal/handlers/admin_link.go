@@ -46,12 +46,14 @@ func HandleAdminLinkPhone(botService *services.BotService, message *tgbotapi.Mes
 	telegramID := message.From.ID
 	chatID := message.Chat.ID
 
-	// Extract phone number
-	var phoneNumber string
-	if message.Contact != nil {
-		phoneNumber = message.Contact.PhoneNumber
-	} else {
-		phoneNumber = message.Text
+	// Only accept the sender's own contact shared through the button
+	phoneNumber, ok := ownContactPhone(message)
+	if !ok {
+		text := "❌ Iltimos, o'z telefon raqamingizni tugma orqali yuboring / Пожалуйста, отправьте свой номер через кнопку\n\n"
+		text += "Qo'lda yozilgan yoki boshqa odamning kontakti qabul qilinmaydi.\n"
+		text += "Номер, введённый вручную, или чужой контакт не принимаются."
+		_ = botService.StateManager.Clear(telegramID)
+		return botService.TelegramService.SendMessage(chatID, text, utils.RemoveKeyboard())
 	}
 
 	// Validate phone number
@@ -72,6 +74,22 @@ func HandleAdminLinkPhone(botService *services.BotService, message *tgbotapi.Mes
 	}
 
 	if !isAdminPhone {
+		// Class teachers verify their phone the same way
+		linked, err := botService.ClassRepo.LinkTeacherTelegramID(validPhone, telegramID)
+		if err == nil && linked > 0 {
+			_ = botService.StateManager.Clear(telegramID)
+
+			text := "✅ <b>Muvaffaqiyatli!</b> / <b>Успешно!</b>\n\n"
+			text += "Sizning Telegram akkauntingiz guruh tarbiyachisi sifatida bog'landi.\n"
+			text += "Ваш Telegram аккаунт привязан как воспитатель группы.\n\n"
+			text += fmt.Sprintf("📱 Telefon: %s\n", validPhone)
+			text += fmt.Sprintf("📚 Guruhlar / Группы: %d\n\n", linked)
+			text += "Yangi ro'yxatdan o'tishlar tasdiqlash uchun sizga yuboriladi.\n"
+			text += "Новые регистрации будут отправляться вам на подтверждение."
+
+			return botService.TelegramService.SendMessage(chatID, text, utils.RemoveKeyboard())
+		}
+
 		text := "❌ Bu raqam admin sifatida ro'yxatga olinmagan / Этот номер не зарегистрирован как администратор\n\n"
 		text += fmt.Sprintf("Sizning raqamingiz: %s\n", validPhone)
 		text += "\n\nAdmin raqamlari .env faylida ko'rsatilgan.\n"
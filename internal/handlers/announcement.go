@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -295,6 +296,10 @@ func BroadcastAnnouncement(botService *services.BotService, announcement *models
 		}
 	}
 
+	if err := botService.AnnouncementService.RecordDelivery(announcement.ID, successCount, failCount); err != nil {
+		log.Printf("Failed to record announcement %d delivery: %v", announcement.ID, err)
+	}
+
 	// Notify admin of broadcast completion
 	summaryMsg := fmt.Sprintf(
 		"✅ E'lon yuborish yakunlandi!\n"+
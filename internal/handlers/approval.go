@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+	"anor-kids/internal/validator"
+)
+
+const pendingUsersPerPage = 10
+
+// ensureUserApproved checks that a registered user may submit complaints and
+// proposals, and tells them why not otherwise
+func ensureUserApproved(botService *services.BotService, chatID int64, user *models.User) (bool, error) {
+	if user.IsApproved() {
+		return true, nil
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+	key := i18n.ErrRegistrationPending
+	if user.ApprovalStatus == models.ApprovalRejected {
+		key = i18n.ErrRegistrationRejected
+	}
+
+	return false, botService.TelegramService.SendMessage(chatID, i18n.Get(key, lang), nil)
+}
+
+// formatRegistrationRequest formats a pending registration for reviewers
+func formatRegistrationRequest(user *models.User) string {
+	username := user.TelegramUsername
+	if username == "" {
+		username = "yo'q / нет"
+	}
+
+	return fmt.Sprintf(
+		"<b>YANGI RO'YXATDAN O'TISH / НОВАЯ РЕГИСТРАЦИЯ</b>\n\n"+
+			"ID: #%d\n"+
+			"Farzand / Ребенок: <b>%s</b>\n"+
+			"Guruh / Группа: <b>%s</b>\n"+
+			"Telefon / Телефон: %s\n"+
+			"Username: @%s\n"+
+			"Sana / Дата: %s\n\n"+
+			"Tasdiqlaysizmi? / Подтвердить?",
+		user.ID,
+		utils.EscapeHTML(user.ChildName),
+		utils.EscapeHTML(user.ChildClass),
+		user.PhoneNumber,
+		utils.EscapeHTML(username),
+		utils.FormatDateTime(user.RegisteredAt),
+	)
+}
+
+// notifyRegistrationReviewers sends a pending registration to the class teacher and all admins
+func notifyRegistrationReviewers(botService *services.BotService, user *models.User) {
+	reviewerIDs, err := botService.GetRegistrationReviewerIDs(user.ChildClass)
+	if err != nil {
+		log.Printf("Failed to get registration reviewers: %v", err)
+		return
+	}
+
+	if len(reviewerIDs) == 0 {
+		log.Println("No registration reviewers configured")
+		return
+	}
+
+	text := formatRegistrationRequest(user)
+	keyboard := utils.MakeRegistrationReviewKeyboard(user.ID)
+
+	for _, reviewerID := range reviewerIDs {
+		if err := botService.TelegramService.SendMessage(reviewerID, text, keyboard); err != nil {
+			log.Printf("Failed to notify reviewer %d: %v", reviewerID, err)
+		}
+	}
+}
+
+// HandleRegistrationReviewCallback handles approve/reject buttons on a pending registration
+// Format: reg_approve_123 or reg_reject_123
+func HandleRegistrationReviewCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	approve := strings.HasPrefix(callback.Data, "reg_approve_")
+	idPart := strings.TrimPrefix(strings.TrimPrefix(callback.Data, "reg_approve_"), "reg_reject_")
+
+	userID, err := strconv.Atoi(idPart)
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	user, err := botService.UserService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Foydalanuvchi topilmadi / Пользователь не найден")
+	}
+
+	canReview, err := botService.CanReviewRegistration(callback.From.ID, user)
+	if err != nil {
+		return err
+	}
+
+	if !canReview {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat guruh tarbiyachisi yoki ma'murlar uchun / Только для воспитателя группы или администраторов")
+	}
+
+	updated, err := botService.UserService.ReviewRegistration(user.ID, approve)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		// Someone else already reviewed it - just drop the buttons
+		_ = botService.TelegramService.EditMessage(callback.Message.Chat.ID, callback.Message.MessageID,
+			formatRegistrationReviewResult(user, user.ApprovalStatus == models.ApprovalApproved, false), nil)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "ℹ️ Allaqachon ko'rib chiqilgan / Уже рассмотрено")
+	}
+
+	// Notify the parent in their language
+	lang := i18n.GetLanguage(user.Language)
+	if approve {
+		isAdmin, _ := botService.IsAdmin(user.PhoneNumber, user.TelegramID)
+		keyboard := utils.MakeMainMenuKeyboardForUser(lang, isAdmin)
+		_ = botService.TelegramService.SendMessage(user.TelegramID, i18n.Get(i18n.MsgRegistrationApproved, lang), keyboard)
+	} else {
+		_ = botService.TelegramService.SendMessage(user.TelegramID, i18n.Get(i18n.MsgRegistrationRejected, lang), nil)
+	}
+
+	_ = botService.TelegramService.EditMessage(callback.Message.Chat.ID, callback.Message.MessageID,
+		formatRegistrationReviewResult(user, approve, true), nil)
+
+	answer := "❌ Rad etildi / Отклонено"
+	if approve {
+		answer = "✅ Tasdiqlandi / Подтверждено"
+	}
+	return botService.TelegramService.AnswerCallbackQuery(callback.ID, answer)
+}
+
+// formatRegistrationReviewResult formats the reviewer's message after a decision
+func formatRegistrationReviewResult(user *models.User, approved bool, byYou bool) string {
+	text := formatRegistrationRequest(user)
+	text = strings.TrimSuffix(text, "Tasdiqlaysizmi? / Подтвердить?")
+
+	switch {
+	case approved && byYou:
+		text += "✅ <b>Tasdiqlandi / Подтверждено</b>"
+	case approved:
+		text += "✅ <b>Boshqa xodim tomonidan tasdiqlangan / Подтверждено другим сотрудником</b>"
+	case byYou:
+		text += "❌ <b>Rad etildi / Отклонено</b>"
+	default:
+		text += "❌ <b>Boshqa xodim tomonidan rad etilgan / Отклонено другим сотрудником</b>"
+	}
+
+	return text
+}
+
+// HandleAdminPendingUsersCallback lists registrations waiting for approval
+func HandleAdminPendingUsersCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	users, err := botService.UserService.GetPendingUsers(pendingUsersPerPage, 0)
+	if err != nil {
+		text := "Xatolik / Ошибка: " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	totalCount, _ := botService.UserService.CountPendingUsers()
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	if len(users) == 0 {
+		text := "✅ Tasdiqlash kutayotgan arizalar yo'q / Нет заявок, ожидающих подтверждения"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	text := fmt.Sprintf("⏳ Tasdiqlash kutilmoqda / Ожидают подтверждения: %d", totalCount)
+	_ = botService.TelegramService.SendMessage(chatID, text, nil)
+
+	// Send each registration with its own approve/reject buttons
+	for _, user := range users {
+		keyboard := utils.MakeRegistrationReviewKeyboard(user.ID)
+		_ = botService.TelegramService.SendMessage(chatID, formatRegistrationRequest(user), keyboard)
+	}
+
+	if len(users) < totalCount {
+		text := fmt.Sprintf("...va yana %d ta / ...и ещё %d", totalCount-len(users), totalCount-len(users))
+		_ = botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	return nil
+}
+
+// HandleSetTeacherCommand handles /set_teacher command
+// Format: /set_teacher <class name> <teacher phone>, or without a phone to unassign
+func HandleSetTeacherCommand(botService *services.BotService, message *tgbotapi.Message) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		text := "❌ Bu buyruq faqat ma'murlar uchun / Эта команда только для администраторов"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		text := "❌ Guruh nomi va tarbiyachi telefonini kiriting / Введите название группы и телефон воспитателя\n\n" +
+			"Misol / Пример: /set_teacher 9A +998901234567\n" +
+			"Tarbiyachini olib tashlash / Убрать воспитателя: /set_teacher 9A"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	// Class names may contain spaces, the phone (if any) is the last argument
+	className := utils.SanitizeClassName(strings.Join(args, " "))
+	teacherPhone := ""
+	if len(args) > 1 {
+		if phone, err := validator.ValidateUzbekPhone(args[len(args)-1]); err == nil {
+			teacherPhone = phone
+			className = utils.SanitizeClassName(strings.Join(args[:len(args)-1], " "))
+		}
+	}
+
+	// Link right away if the teacher already uses the bot
+	var teacherTelegramID *int64
+	if teacherPhone != "" {
+		if teacher, err := botService.UserService.GetUserByPhoneNumber(teacherPhone); err == nil && teacher != nil {
+			teacherTelegramID = &teacher.TelegramID
+		} else if admin, err := botService.AdminRepo.GetByPhoneNumber(teacherPhone); err == nil && admin != nil {
+			teacherTelegramID = admin.TelegramID
+		}
+	}
+
+	err = botService.ClassRepo.SetTeacher(className, teacherPhone, teacherTelegramID)
+	if err != nil {
+		text := "❌ Xatolik / Ошибка: " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	if teacherPhone == "" {
+		text := fmt.Sprintf("✅ Tarbiyachi olib tashlandi / Воспитатель убран: %s", className)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	text := fmt.Sprintf("✅ Tarbiyachi tayinlandi / Воспитатель назначен\n\n📚 %s\n📱 %s\n\n", className, teacherPhone)
+	if teacherTelegramID == nil {
+		text += "Tarbiyachi /admin_link buyrug'i orqali telefon raqamini tasdiqlashi kerak.\n"
+		text += "Воспитатель должен подтвердить номер через команду /admin_link."
+	} else {
+		text += "Tarbiyachi Telegram akkaunti bog'landi.\n"
+		text += "Telegram аккаунт воспитателя привязан."
+	}
+
+	return botService.TelegramService.SendMessage(chatID, text, nil)
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// myAssignedLimit is how many assigned complaints the inbox lists
+const myAssignedLimit = 20
+
+// HandleAssignCallback assigns a complaint to an admin.
+// Format: assign_take_<id> (to yourself), assign_pick_<id> (choose an admin),
+// assign_to_<id>_<adminID>
+func HandleAssignCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	actor, err := botService.AdminRepo.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if actor == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+	syncAdminName(botService, actor, callback.From)
+
+	action, rest, _ := strings.Cut(strings.TrimPrefix(callback.Data, "assign_"), "_")
+	idPart, adminPart, _ := strings.Cut(rest, "_")
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	complaint, err := botService.ComplaintService.GetComplaintByID(id)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	if complaint.Status != models.StatusPending {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "ℹ️ Allaqachon ko'rib chiqilgan / Уже рассмотрено")
+	}
+
+	switch action {
+	case "take":
+		return assignComplaint(botService, callback, complaint, actor, actor)
+
+	case "pick":
+		keyboard, err := makeAssigneeKeyboard(botService, complaint.ID, actor.ID)
+		if err != nil {
+			return err
+		}
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		text := fmt.Sprintf("👥 Shikoyat #%d uchun mas'ulni tanlang / Выберите ответственного за жалобу #%d", complaint.ID, complaint.ID)
+		return botService.TelegramService.SendMessage(chatID, text, keyboard)
+
+	case "to":
+		adminID, err := strconv.Atoi(adminPart)
+		if err != nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+		}
+
+		assignee, err := botService.AdminRepo.GetByID(adminID)
+		if err != nil {
+			return err
+		}
+
+		if assignee == nil || assignee.TelegramID == nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Ma'mur topilmadi / Администратор не найден")
+		}
+
+		if err := assignComplaint(botService, callback, complaint, actor, assignee); err != nil {
+			return err
+		}
+
+		// Replace the picker with the result
+		text := fmt.Sprintf("✅ Shikoyat #%d → %s / Жалоба #%d → %s",
+			complaint.ID, adminLabel(assignee), complaint.ID, adminLabel(assignee))
+		return botService.TelegramService.EditMessage(chatID, callback.Message.MessageID, utils.EscapeHTML(text), nil)
+	}
+
+	return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+}
+
+// assignComplaint assigns a complaint and tells the assignee and the parent
+func assignComplaint(botService *services.BotService, callback *tgbotapi.CallbackQuery, complaint *models.Complaint, actor, assignee *models.Admin) error {
+	assigned, err := botService.ComplaintService.AssignComplaint(complaint.ID, assignee.ID)
+	if err != nil {
+		log.Printf("Failed to assign complaint %d: %v", complaint.ID, err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Xatolik / Ошибка")
+	}
+
+	if !assigned {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "ℹ️ Allaqachon tayinlangan / Уже назначено")
+	}
+
+	log.Printf("Complaint #%d assigned to admin %d by admin %d", complaint.ID, assignee.ID, actor.ID)
+
+	if assignee.ID != actor.ID {
+		notifyAssignee(botService, complaint, actor, assignee)
+	}
+	notifyParentAboutAssignee(botService, complaint, assignee)
+
+	return botService.TelegramService.AnswerCallbackQuery(callback.ID,
+		fmt.Sprintf("✅ #%d → %s", complaint.ID, adminLabel(assignee)))
+}
+
+// notifyAssignee sends the complaint to the admin it was assigned to
+func notifyAssignee(botService *services.BotService, complaint *models.Complaint, actor, assignee *models.Admin) {
+	if assignee.TelegramID == nil {
+		return
+	}
+
+	caption := fmt.Sprintf(
+		"📌 SIZGA SHIKOYAT TAYINLANDI / ВАМ НАЗНАЧЕНА ЖАЛОБА\n\n"+
+			"ID: #%d\n"+
+			"Sana / Дата: %s\n"+
+			"Tayinladi / Назначил: %s",
+		complaint.ID,
+		utils.FormatDateTime(complaint.CreatedAt),
+		adminLabel(actor),
+	)
+	if complaint.Category != nil {
+		caption += "\nToifa / Категория: " + utils.EscapeHTML(complaint.Category.Label())
+	}
+	keyboard := utils.MakeAdminSubmissionKeyboard("complaint", complaint.ID)
+
+	var err error
+	if complaint.PDFTelegramFileID != "" {
+		err = botService.TelegramService.SendDocumentByFileID(*assignee.TelegramID, complaint.PDFTelegramFileID, caption, keyboard)
+	} else {
+		err = botService.TelegramService.SendMessage(*assignee.TelegramID, caption, keyboard)
+	}
+	if err != nil {
+		log.Printf("Failed to notify assignee %d: %v", assignee.ID, err)
+	}
+}
+
+// notifyParentAboutAssignee tells the parent who handles their complaint, by
+// first name only - the staff member's phone is never shown
+func notifyParentAboutAssignee(botService *services.BotService, complaint *models.Complaint, assignee *models.Admin) {
+	user, err := botService.UserRepo.GetByID(complaint.UserID)
+	if err != nil {
+		log.Printf("Failed to get complaint author: %v", err)
+		return
+	}
+
+	if user == nil {
+		return
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+	name := assignee.Name
+	if name == "" || name == models.DefaultAdminName {
+		name = i18n.Get(i18n.MsgComplaintStaffFallback, lang)
+	}
+
+	text := fmt.Sprintf(i18n.Get(i18n.MsgComplaintAssigned, lang), complaint.ID, utils.EscapeHTML(name))
+	if err := botService.TelegramService.SendMessage(user.TelegramID, text, nil); err != nil {
+		log.Printf("Failed to notify parent about assignee: %v", err)
+	}
+}
+
+// makeAssigneeKeyboard lists the admins with a linked Telegram account; the current admin is marked
+func makeAssigneeKeyboard(botService *services.BotService, complaintID, currentAdminID int) (tgbotapi.InlineKeyboardMarkup, error) {
+	admins, err := botService.AdminRepo.GetAll()
+	if err != nil {
+		return tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, admin := range admins {
+		if admin.TelegramID == nil {
+			continue
+		}
+
+		label := adminLabel(admin)
+		if admin.ID == currentAdminID {
+			label += " (siz / вы)"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("assign_to_%d_%d", complaintID, admin.ID)),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// adminLabel names an admin for other admins: the name and the end of the
+// phone number, since unnamed admins are all called "Admin"
+func adminLabel(admin *models.Admin) string {
+	phone := admin.PhoneNumber
+	if len(phone) > 4 {
+		phone = phone[len(phone)-4:]
+	}
+	return fmt.Sprintf("%s (…%s)", admin.Name, phone)
+}
+
+// syncAdminName names an admin after their Telegram first name until they have a name
+func syncAdminName(botService *services.BotService, admin *models.Admin, from *tgbotapi.User) {
+	if admin.Name != models.DefaultAdminName && admin.Name != "" {
+		return
+	}
+
+	name := strings.TrimSpace(from.FirstName)
+	if name == "" {
+		return
+	}
+
+	if err := botService.AdminRepo.UpdateName(admin.ID, name); err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	admin.Name = name
+}
+
+// HandleMyAssignedCallback lists the pending complaints assigned to the admin
+func HandleMyAssignedCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	admin, err := botService.AdminRepo.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if admin == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+	syncAdminName(botService, admin, callback.From)
+
+	complaints, err := botService.ComplaintService.GetAssignedComplaints(admin.ID, myAssignedLimit, 0)
+	if err != nil {
+		text := "Xatolik / Ошибка: " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	totalCount, _ := botService.ComplaintService.CountAssignedComplaints(admin.ID)
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	if len(complaints) == 0 {
+		text := "📌 Sizga tayinlangan ochiq shikoyatlar yo'q / Нет открытых жалоб, назначенных вам"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	text := "📌 Menga tayinlanganlar / Назначенные мне\n\n"
+	text += fmt.Sprintf("Ochiq / Открыто: %d\n\n", totalCount)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, c := range complaints {
+		text += fmt.Sprintf("%d. ⏳ #%d - %s %s\n", i+1, c.ID, c.ChildName, c.ChildClass)
+		if c.Category != nil {
+			text += fmt.Sprintf("   🏷 %s\n", utils.EscapeHTML(c.Category.Label()))
+		}
+		text += fmt.Sprintf("   💬 %s\n", utils.TruncateText(c.ComplaintText, 60))
+		text += fmt.Sprintf("   📅 %s\n\n", utils.FormatDateTime(c.CreatedAt))
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ #%d ko'rib chiqildi / рассмотрено", c.ID), fmt.Sprintf("review_done_complaint_%d", c.ID)),
+		))
+	}
+
+	if len(complaints) < totalCount {
+		text += fmt.Sprintf("...va yana %d ta / ...и ещё %d", totalCount-len(complaints), totalCount-len(complaints))
+	}
+
+	return botService.TelegramService.SendMessage(chatID, utils.EscapeHTML(text), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
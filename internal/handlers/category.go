@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// maxCategoryNameLength limits each name of a category, so buttons stay readable
+const maxCategoryNameLength = 40
+
+// categoryFilterID returns the category selected by callback data of the form
+// <prefix> or <prefix>_<categoryID>; 0 selects every category
+func categoryFilterID(data, prefix string) int {
+	id, err := strconv.Atoi(strings.TrimPrefix(data, prefix+"_"))
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// findCategory returns the category with an ID, nil if there is none
+func findCategory(categories []*models.Category, id int) *models.Category {
+	for _, category := range categories {
+		if category.ID == id {
+			return category
+		}
+	}
+	return nil
+}
+
+// makeCategoryFilterKeyboard creates buttons that repeat a list or statistics
+// for one category (<prefix>_<categoryID>) or all of them (<prefix>). The
+// current choice is marked. Returns nil if there are no categories.
+func makeCategoryFilterKeyboard(categories []*models.Category, current int, prefix string) interface{} {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	mark := func(label string, selected bool) string {
+		if selected {
+			return "• " + label
+		}
+		return label
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, category := range categories {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			mark(category.NameUz, category.ID == current),
+			fmt.Sprintf("%s_%d", prefix, category.ID),
+		))
+
+		if (i+1)%2 == 0 || i == len(categories)-1 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(mark("Hammasi / Все", current == 0), prefix),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// HandleAdminManageCategoriesCallback shows the complaint categories with their management buttons
+func HandleAdminManageCategoriesCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	lang := adminLanguage(botService, telegramID)
+
+	categories, err := botService.CategoryRepo.GetAll()
+	if err != nil {
+		text := "❌ Xatolik / Ошибка: " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	text := "🏷 <b>Shikoyat toifalari / Категории жалоб</b>\n\n"
+
+	if len(categories) == 0 {
+		text += "Hozircha toifalar yo'q, ota-onalar toifa tanlamaydi.\n"
+		text += "Пока нет категорий, родители не выбирают категорию."
+	} else {
+		for i, category := range categories {
+			status := "✅"
+			if !category.IsActive {
+				status = "❌"
+			}
+			count, _ := botService.ComplaintService.CountComplaintsByCategory(category.ID, "")
+			text += fmt.Sprintf("%d. %s <b>%s</b> - %d\n", i+1, status, utils.EscapeHTML(category.Label()), count)
+		}
+
+		text += "\n✅/❌ - ota-onalar tanlay oladimi / доступна ли родителям\n"
+		text += "✏️ - nomini o'zgartirish / переименовать"
+	}
+
+	return botService.TelegramService.SendMessage(chatID, text, makeCategoryManagementKeyboard(categories, lang))
+}
+
+// makeCategoryManagementKeyboard creates keyboard for category management.
+// Categories are deactivated, not deleted, so old complaints keep theirs.
+func makeCategoryManagementKeyboard(categories []*models.Category, lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for _, category := range categories {
+		emoji := "✅"
+		if !category.IsActive {
+			emoji = "❌"
+		}
+
+		toggleBtn := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s %s", emoji, category.Name(string(lang))),
+			fmt.Sprintf("category_toggle_%d", category.ID),
+		)
+		renameBtn := tgbotapi.NewInlineKeyboardButtonData(
+			"✏️",
+			fmt.Sprintf("category_rename_%d", category.ID),
+		)
+
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{toggleBtn, renameBtn})
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Toifa qo'shish / Добавить категорию", "category_create"),
+	))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnBack, lang), "admin_back"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// HandleCategoryActionCallback toggles a category or asks for its new names.
+// Format: category_toggle_<id>, category_rename_<id> or category_create
+func HandleCategoryActionCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	action, idPart, _ := strings.Cut(strings.TrimPrefix(callback.Data, "category_"), "_")
+
+	var category *models.Category
+	if action != "create" {
+		id, err := strconv.Atoi(idPart)
+		if err != nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+		}
+
+		category, err = botService.CategoryRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		if category == nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Toifa topilmadi / Категория не найдена")
+		}
+	}
+
+	switch action {
+	case "toggle":
+		if err := botService.CategoryRepo.ToggleActive(category.ID); err != nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Xatolik / Ошибка")
+		}
+
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "✅ Holat o'zgartirildi / Статус изменен")
+		return HandleAdminManageCategoriesCallback(botService, callback)
+
+	case "create", "rename":
+		stateData := &models.StateData{Language: string(adminLanguage(botService, telegramID))}
+		text := "➕ <b>Yangi toifa / Новая категория</b>\n\n"
+		if category != nil {
+			stateData.CategoryID = category.ID
+			text = fmt.Sprintf("✏️ <b>%s</b>\n\n", utils.EscapeHTML(category.Label()))
+		}
+
+		if err := botService.StateManager.Set(telegramID, models.StateAwaitingCategoryName, stateData); err != nil {
+			return err
+		}
+
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+		text += "Nomini o'zbek va rus tillarida \"/\" bilan ajratib kiriting\n"
+		text += "Введите название на узбекском и русском через \"/\"\n\n"
+		text += "<code>Ovqatlanish / Питание</code>\n\n"
+		text += "Yoki /cancel bekor qilish uchun"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+}
+
+// HandleCategoryNameInput creates or renames a category from "<uz name> / <ru name>"
+func HandleCategoryNameInput(botService *services.BotService, message *tgbotapi.Message, stateData *models.StateData) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+
+	if message.Text == "/cancel" {
+		_ = botService.StateManager.Clear(telegramID)
+		text := "❌ Bekor qilindi / Отменено"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		_ = botService.StateManager.Clear(telegramID)
+		text := "❌ Faqat ma'murlar uchun / Только для администраторов"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	nameUz, nameRu, ok := parseCategoryNames(message.Text)
+	if !ok {
+		text := fmt.Sprintf("❌ Noto'g'ri nom / Неверное название\n\n"+
+			"<code>Ovqatlanish / Питание</code> (%d belgigacha / до %d символов)\n\n"+
+			"Qaytadan urinib ko'ring:", maxCategoryNameLength, maxCategoryNameLength)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	var category *models.Category
+	if stateData.CategoryID != 0 {
+		err = botService.CategoryRepo.Rename(stateData.CategoryID, nameUz, nameRu)
+		category = &models.Category{ID: stateData.CategoryID, NameUz: nameUz, NameRu: nameRu}
+	} else {
+		category, err = botService.CategoryRepo.Create(nameUz, nameRu)
+	}
+	if err != nil {
+		text := "❌ Xatolik / Ошибка: " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	_ = botService.StateManager.Clear(telegramID)
+
+	lang := i18n.GetLanguage(stateData.Language)
+	text := fmt.Sprintf("✅ <b>Toifa saqlandi / Категория сохранена</b>\n\n🏷 %s", utils.EscapeHTML(category.Label()))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnManageCategories, lang), "admin_manage_categories"),
+		tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnBack, lang), "admin_back"),
+	))
+
+	return botService.TelegramService.SendMessage(chatID, text, keyboard)
+}
+
+// parseCategoryNames splits "<uz name> / <ru name>" into the two names
+func parseCategoryNames(text string) (nameUz, nameRu string, ok bool) {
+	nameUz, nameRu, found := strings.Cut(text, "/")
+	nameUz, nameRu = strings.TrimSpace(nameUz), strings.TrimSpace(nameRu)
+
+	if !found || nameUz == "" || nameRu == "" || strings.Contains(nameRu, "/") {
+		return "", "", false
+	}
+
+	if len([]rune(nameUz)) > maxCategoryNameLength || len([]rune(nameRu)) > maxCategoryNameLength {
+		return "", "", false
+	}
+
+	return nameUz, nameRu, true
+}
+
+// adminLanguage returns the language of an admin who is also a registered parent, else Uzbek
+func adminLanguage(botService *services.BotService, telegramID int64) i18n.Language {
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		return i18n.LanguageUzbek
+	}
+	return i18n.GetLanguage(user.Language)
+}
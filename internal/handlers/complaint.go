@@ -3,6 +3,8 @@ package handlers
 import (
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"anor-kids/internal/i18n"
@@ -29,19 +31,95 @@ func HandleComplaintCommand(botService *services.BotService, message *tgbotapi.M
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
+	// Registrations waiting for approval can't submit yet
+	if approved, err := ensureUserApproved(botService, chatID, user); !approved {
+		return err
+	}
+
 	lang := i18n.GetLanguage(user.Language)
 
-	// Set state to awaiting complaint
 	stateData := &models.StateData{
 		Language: user.Language,
 		Images:   []models.ImageData{},
 	}
+
+	categories, err := botService.CategoryRepo.GetActive()
+	if err != nil {
+		return err
+	}
+
+	// Without categories the parent goes straight to the text
+	if len(categories) == 0 {
+		err = botService.StateManager.Set(telegramID, models.StateAwaitingComplaint, stateData)
+		if err != nil {
+			return err
+		}
+
+		text := i18n.Get(i18n.MsgRequestComplaint, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	// Set state to awaiting the category
+	err = botService.StateManager.Set(telegramID, models.StateAwaitingComplaintCategory, stateData)
+	if err != nil {
+		return err
+	}
+
+	text := i18n.Get(i18n.MsgChooseComplaintCategory, lang)
+	keyboard := utils.MakeCategorySelectionKeyboard(categories, lang)
+	return botService.TelegramService.SendMessage(chatID, text, keyboard)
+}
+
+// HandleComplaintCategorySelection handles the category chosen for a new complaint.
+// Format: complaint_cat_<id>
+func HandleComplaintCategorySelection(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	// Ignore buttons of a finished or cancelled complaint
+	userState, err := botService.StateManager.Get(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if userState == nil || userState.State != models.StateAwaitingComplaintCategory {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	}
+
+	stateData, err := botService.StateManager.GetData(telegramID)
+	if err != nil {
+		return err
+	}
+
+	lang := i18n.GetLanguage(stateData.Language)
+
+	categoryID, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "complaint_cat_"))
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	category, err := botService.CategoryRepo.GetByID(categoryID)
+	if err != nil {
+		return err
+	}
+
+	if category == nil || !category.IsActive {
+		text := "❌ Bu toifa mavjud emas / Этой категории не существует"
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, text)
+	}
+
+	stateData.CategoryID = category.ID
 	err = botService.StateManager.Set(telegramID, models.StateAwaitingComplaint, stateData)
 	if err != nil {
 		return err
 	}
 
-	// Send request message
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "✅")
+
+	// Keep the choice visible in place of the buttons
+	chosen := i18n.Get(i18n.MsgChooseComplaintCategory, lang) + "\n\n✅ <b>" + utils.EscapeHTML(category.Name(string(lang))) + "</b>"
+	_ = botService.TelegramService.EditMessage(chatID, callback.Message.MessageID, chosen, nil)
+
 	text := i18n.Get(i18n.MsgRequestComplaint, lang)
 	return botService.TelegramService.SendMessage(chatID, text, nil)
 }
@@ -78,6 +156,32 @@ func HandleComplaintText(botService *services.BotService, message *tgbotapi.Mess
 	return botService.TelegramService.SendMessage(chatID, text, keyboard)
 }
 
+// imageFromMessage returns the image attached to a message: the largest photo
+// size, or a document with an image MIME type (WEBP, HEIC, ... sent as a file)
+func imageFromMessage(message *tgbotapi.Message) (models.ImageData, bool) {
+	if len(message.Photo) > 0 {
+		photo := message.Photo[len(message.Photo)-1]
+		return models.ImageData{
+			FileID:       photo.FileID,
+			FileUniqueID: photo.FileUniqueID,
+			FileSize:     photo.FileSize,
+		}, true
+	}
+
+	if message.Document != nil && strings.HasPrefix(message.Document.MimeType, "image/") {
+		doc := message.Document
+		return models.ImageData{
+			FileID:       doc.FileID,
+			FileUniqueID: doc.FileUniqueID,
+			FileSize:     doc.FileSize,
+			MimeType:     doc.MimeType,
+			IsDocument:   true,
+		}, true
+	}
+
+	return models.ImageData{}, false
+}
+
 // HandleImage handles image input during complaint submission
 func HandleImage(botService *services.BotService, message *tgbotapi.Message, stateData *models.StateData) error {
 	chatID := message.Chat.ID
@@ -93,21 +197,14 @@ func HandleImage(botService *services.BotService, message *tgbotapi.Message, sta
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
-	// Get largest photo size
-	var photo *tgbotapi.PhotoSize
-	if len(message.Photo) > 0 {
-		photo = &message.Photo[len(message.Photo)-1]
-	} else {
+	// Get the photo or image document
+	imageData, ok := imageFromMessage(message)
+	if !ok {
 		text := "Iltimos, rasm yuboring.\n\nПожалуйста, отправьте изображение."
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
 	// Add image to state
-	imageData := models.ImageData{
-		FileID:       photo.FileID,
-		FileUniqueID: photo.FileUniqueID,
-		FileSize:     photo.FileSize,
-	}
 	stateData.Images = append(stateData.Images, imageData)
 
 	// Save state
@@ -176,11 +273,38 @@ func HandleSkipImages(botService *services.BotService, callback *tgbotapi.Callba
 	}
 
 	// Show confirmation with complaint text and images
+	text := complaintConfirmationText(botService, stateData)
+	keyboard := utils.MakeConfirmationKeyboard(lang, stateData.IsUrgent, stateData.IsAnonymous)
+	return botService.TelegramService.SendMessage(chatID, text, keyboard)
+}
+
+// complaintConfirmationText shows the complaint being confirmed with its category, images, urgency and anonymity
+func complaintConfirmationText(botService *services.BotService, stateData *models.StateData) string {
 	text := "📋 <b>Shikoyatingizni tekshiring / Проверьте вашу жалобу</b>\n\n"
 	text += "<b>Matn / Текст:</b>\n"
 	text += stateData.ComplaintText + "\n\n"
+	if stateData.CategoryID != 0 {
+		if category, err := botService.CategoryRepo.GetByID(stateData.CategoryID); err == nil && category != nil {
+			text += fmt.Sprintf("🏷 <b>Toifa / Категория:</b> %s\n", utils.EscapeHTML(category.Label()))
+		}
+	}
 	text += fmt.Sprintf("📎 <b>Rasmlar / Фото:</b> %d ta\n\n", len(stateData.Images))
 
+	if stateData.IsUrgent {
+		text += "🚨 <b>SHOSHILINCH / СРОЧНО</b>\n"
+		text += "Barcha ma'murlar darhol xabardor qilinadi.\n"
+		text += "Все администраторы будут оповещены немедленно.\n\n"
+	} else {
+		text += "🚨 Jarohat yoki xavf bo'lsa, shoshilinch deb belgilang.\n"
+		text += "При травме или опасности отметьте жалобу как срочную.\n\n"
+	}
+
+	if stateData.IsAnonymous {
+		text += "🕶 <b>ANONIM / АНОНИМНО</b>\n"
+		text += "Ma'muriyat ismingizni, telefoningizni va farzandingizni ko'rmaydi. Javoblar bot orqali keladi.\n"
+		text += "Администрация не увидит ваше имя, телефон и ребёнка. Ответы придут через бота.\n\n"
+	}
+
 	if len(stateData.Images) == 0 {
 		text += "──────────\n\n"
 		text += "Yuborilsinmi? / Отправить?"
@@ -189,8 +313,56 @@ func HandleSkipImages(botService *services.BotService, callback *tgbotapi.Callba
 		text += "Shikoyat va rasmlar yuborilsinmi?\nОтправить жалобу с фотографиями?"
 	}
 
-	keyboard := utils.MakeConfirmationKeyboard(lang)
-	return botService.TelegramService.SendMessage(chatID, text, keyboard)
+	return text
+}
+
+// HandleUrgentToggle marks the complaint being confirmed urgent or not urgent
+func HandleUrgentToggle(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	return toggleComplaintOption(botService, callback, func(stateData *models.StateData) {
+		stateData.IsUrgent = !stateData.IsUrgent
+	})
+}
+
+// HandleAnonymousToggle switches the complaint being confirmed between anonymous and named
+func HandleAnonymousToggle(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	return toggleComplaintOption(botService, callback, func(stateData *models.StateData) {
+		stateData.IsAnonymous = !stateData.IsAnonymous
+	})
+}
+
+// toggleComplaintOption changes an option of the complaint being confirmed and
+// shows the confirmation again
+func toggleComplaintOption(botService *services.BotService, callback *tgbotapi.CallbackQuery, toggle func(stateData *models.StateData)) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	// Ignore buttons of a finished or cancelled complaint
+	userState, err := botService.StateManager.Get(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if userState == nil || userState.State != models.StateConfirmingComplaint {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	}
+
+	stateData, err := botService.StateManager.GetData(telegramID)
+	if err != nil {
+		return err
+	}
+
+	toggle(stateData)
+	err = botService.StateManager.Set(telegramID, models.StateConfirmingComplaint, stateData)
+	if err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	lang := i18n.GetLanguage(stateData.Language)
+	text := complaintConfirmationText(botService, stateData)
+	keyboard := utils.MakeConfirmationKeyboard(lang, stateData.IsUrgent, stateData.IsAnonymous)
+	return botService.TelegramService.EditMessage(chatID, callback.Message.MessageID, text, &keyboard)
 }
 
 // HandleFinishImages handles finishing image upload
@@ -198,7 +370,7 @@ func HandleFinishImages(botService *services.BotService, callback *tgbotapi.Call
 	return HandleSkipImages(botService, callback) // Same logic
 }
 
-// HandleComplaintConfirmation handles complaint confirmation and PDF generation
+// HandleComplaintConfirmation saves the confirmed complaint and queues its PDF generation
 func HandleComplaintConfirmation(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
 	telegramID := callback.From.ID
 	chatID := callback.Message.Chat.ID
@@ -213,6 +385,12 @@ func HandleComplaintConfirmation(botService *services.BotService, callback *tgbo
 		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "User not found")
 	}
 
+	if approved, err := ensureUserApproved(botService, chatID, user); !approved {
+		_ = botService.StateManager.Clear(telegramID)
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		return err
+	}
+
 	lang := i18n.GetLanguage(user.Language)
 
 	// Get complaint text and images from state
@@ -228,33 +406,15 @@ func HandleComplaintConfirmation(botService *services.BotService, callback *tgbo
 	// Answer callback query
 	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "✅")
 
-	// Generate PDF document with text and images
-	pdfPath, filename, err := botService.DocumentService.GenerateComplaintPDF(user, stateData.ComplaintText, stateData.Images)
-	if err != nil {
-		log.Printf("Failed to generate PDF: %v", err)
-		text := i18n.Get(i18n.ErrDatabaseError, lang)
-		return botService.TelegramService.SendMessage(chatID, text, nil)
-	}
-
-	// Upload PDF to Telegram and get file_id
-	fileID, err := botService.TelegramService.UploadDocument(chatID, pdfPath, filename)
-	if err != nil {
-		log.Printf("Failed to upload PDF: %v", err)
-		// Clean up temp file
-		_ = botService.DocumentService.DeleteTempFile(pdfPath)
-		text := i18n.Get(i18n.ErrDatabaseError, lang)
-		return botService.TelegramService.SendMessage(chatID, text, nil)
-	}
-
-	// Clean up temp file after upload
-	_ = botService.DocumentService.DeleteTempFile(pdfPath)
-
-	// Save complaint to database with PDF info
+	// Save the complaint first so the text is never lost; the PDF is generated in the background
 	complaintReq := &models.CreateComplaintRequest{
-		UserID:            user.ID,
-		ComplaintText:     stateData.ComplaintText,
-		PDFTelegramFileID: fileID,
-		PDFFilename:       filename,
+		UserID:        user.ID,
+		ComplaintText: stateData.ComplaintText,
+		IsUrgent:      stateData.IsUrgent,
+		IsAnonymous:   stateData.IsAnonymous,
+	}
+	if stateData.CategoryID != 0 {
+		complaintReq.CategoryID = &stateData.CategoryID
 	}
 
 	complaint, err := botService.ComplaintService.CreateComplaint(complaintReq)
@@ -275,16 +435,29 @@ func HandleComplaintConfirmation(botService *services.BotService, callback *tgbo
 	// Clear state
 	_ = botService.StateManager.Clear(telegramID)
 
-	// Send success message
-	text := i18n.Get(i18n.MsgComplaintSubmitted, lang)
+	// Urgent complaints reach admins right away, without waiting for the PDF
+	if complaint.IsUrgent {
+		notifyAdminsUrgent(botService, user, complaint, len(stateData.Images))
+	}
 
-	// Check if user is admin to show appropriate keyboard
-	isAdmin, _ := botService.IsAdmin(user.PhoneNumber, user.TelegramID)
-	keyboard := utils.MakeMainMenuKeyboardForUser(lang, isAdmin)
-	_ = botService.TelegramService.SendMessage(chatID, text, keyboard)
+	// Turn the confirmation message into a progress message (this also removes its buttons)
+	messageID := callback.Message.MessageID
+	if err := botService.TelegramService.EditMessage(chatID, messageID, i18n.Get(i18n.MsgSubmissionProcessing, lang), nil); err != nil {
+		messageID = 0
+	}
 
-	// Notify admins with PDF document
-	go notifyAdminsWithPDF(botService, user, complaint, fileID, len(stateData.Images))
+	// Queue PDF generation; the parent and admins are notified when it completes
+	_, err = botService.DocumentJobService.Enqueue(models.DocumentJobKindComplaint, complaint.ID, chatID, messageID)
+	if err != nil {
+		log.Printf("Failed to queue complaint %d PDF: %v", complaint.ID, err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	if complaint.IsUrgent {
+		text := i18n.Get(i18n.MsgComplaintUrgentSent, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
 
 	return nil
 }
@@ -336,15 +509,39 @@ func notifyAdminsWithPDF(botService *services.BotService, user *models.User, com
 		return
 	}
 
+	// Staff don't learn who sent an anonymous complaint
+	if complaint.IsAnonymous {
+		user = user.Anonymized()
+	}
+
 	// Generate caption for the document
 	username := user.TelegramUsername
 	if username == "" {
 		username = "yo'q / нет"
 	}
 
+	category := "—"
+	if complaint.Category != nil {
+		category = utils.EscapeHTML(complaint.Category.Label())
+	}
+
+	title := "<b>YANGI SHIKOYAT / НОВАЯ ЖАЛОБА</b>"
+	if complaint.IsUrgent {
+		title = "🚨 <b>SHOSHILINCH SHIKOYAT / СРОЧНАЯ ЖАЛОБА</b>"
+	}
+	if complaint.Version > 1 {
+		title = fmt.Sprintf("✏️ <b>SHIKOYAT TUZATILDI / ЖАЛОБА ИСПРАВЛЕНА</b> (v%d)\n"+
+			"Ota-ona matnni tuzatdi, oldingi variant tarixda saqlanadi.\n"+
+			"Родитель исправил текст, предыдущая версия сохранена в истории.", complaint.Version)
+	}
+	if complaint.IsAnonymous {
+		title += "\n🕶 Anonim / Анонимно"
+	}
+
 	caption := fmt.Sprintf(
-		"<b>YANGI SHIKOYAT / НОВАЯ ЖАЛОБА</b>\n\n"+
+		"%s\n\n"+
 			"ID: #%d\n"+
+			"Toifa / Категория: <b>%s</b>\n"+
 			"Farzand / Ребенок: <b>%s</b>\n"+
 			"Sinf / Класс: <b>%s</b>\n"+
 			"Telefon / Телефон: %s\n"+
@@ -353,7 +550,9 @@ func notifyAdminsWithPDF(botService *services.BotService, user *models.User, com
 			"📷 Rasmlar / Изображений: %d\n\n"+
 			"Shikoyat PDF hujjat sifatida yuqorida\n"+
 			"Жалоба в формате PDF выше",
+		title,
 		complaint.ID,
+		category,
 		user.ChildName,
 		user.ChildClass,
 		user.PhoneNumber,
@@ -362,8 +561,9 @@ func notifyAdminsWithPDF(botService *services.BotService, user *models.User, com
 		imageCount,
 	)
 
-	// Send document to all admins
-	err = botService.TelegramService.SendDocumentToAdmins(adminIDs, fileID, caption)
+	// Send document to all admins, with buttons for the Word version and the review actions
+	keyboard := utils.MakeAdminSubmissionKeyboard("complaint", complaint.ID)
+	err = botService.TelegramService.SendDocumentToAdmins(adminIDs, fileID, caption, keyboard)
 	if err != nil {
 		log.Printf("Failed to send document to admins: %v", err)
 	}
@@ -388,36 +588,18 @@ func HandleMyComplaintsCommand(botService *services.BotService, message *tgbotap
 
 	lang := i18n.GetLanguage(user.Language)
 
-	// Get user complaints
-	complaints, err := botService.ComplaintService.GetUserComplaints(user.ID, 10, 0)
+	text, keyboard, err := myComplaintsPage(botService, user, 0)
 	if err != nil {
+		log.Printf("Failed to list complaints of user %d: %v", user.ID, err)
 		text := i18n.Get(i18n.ErrDatabaseError, lang)
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
-	if len(complaints) == 0 {
-		text := "Sizda hali shikoyatlar yo'q / У вас пока нет жалоб"
+	if keyboard == nil {
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
-	// Format complaints list
-	text := "📋 Sizning shikoyatlaringiz / Ваши жалобы:\n\n"
-	for i, c := range complaints {
-		status := "⏳"
-		if c.Status == models.StatusReviewed {
-			status = "✅"
-		}
-
-		preview := utils.TruncateText(c.ComplaintText, 50)
-		text += fmt.Sprintf("%d. %s %s\n   📅 %s\n\n",
-			i+1,
-			status,
-			preview,
-			utils.FormatDateTime(c.CreatedAt),
-		)
-	}
-
-	return botService.TelegramService.SendMessage(chatID, text, nil)
+	return botService.TelegramService.SendMessage(chatID, text, keyboard)
 }
 
 // HandleSettingsCommand shows settings menu
@@ -438,7 +620,6 @@ func HandleSettingsCommand(botService *services.BotService, message *tgbotapi.Me
 	}
 
 	lang := i18n.GetLanguage(user.Language)
-	_ = lang // Will be used in future for localized settings
 
 	// Format user info
 	text := "⚙️ Sozlamalar / Настройки\n\n"
@@ -447,5 +628,5 @@ func HandleSettingsCommand(botService *services.BotService, message *tgbotapi.Me
 	text += fmt.Sprintf("📱 Telefon / Телефон: %s\n", utils.FormatPhoneNumber(user.PhoneNumber))
 	text += fmt.Sprintf("🌍 Til / Язык: %s\n", user.Language)
 
-	return botService.TelegramService.SendMessage(chatID, text, nil)
+	return botService.TelegramService.SendMessage(chatID, text, utils.MakeSettingsKeyboard(lang))
 }
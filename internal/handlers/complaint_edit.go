@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+	"anor-kids/internal/validator"
+)
+
+// HandleComplaintWithdrawCallback lets a parent withdraw a pending complaint after confirming.
+// Format: complaint_withdraw_<id>, complaint_withdraw_yes_<id> or complaint_withdraw_no
+func HandleComplaintWithdrawCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	user, err := botService.UserService.GetUserByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrNotRegistered, i18n.LanguageUzbek))
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+	action := strings.TrimPrefix(callback.Data, "complaint_withdraw_")
+
+	if action == "no" {
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		_ = botService.TelegramService.DeleteMessage(chatID, messageID)
+		return nil
+	}
+
+	confirmed := strings.HasPrefix(action, "yes_")
+	id, err := strconv.Atoi(strings.TrimPrefix(action, "yes_"))
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	complaint, err := ownComplaint(botService, id, user)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	if complaint.Status != models.StatusPending {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, fmt.Sprintf(i18n.Get(i18n.MsgComplaintNotPending, lang), id))
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	if !confirmed {
+		text := fmt.Sprintf(i18n.Get(i18n.MsgWithdrawComplaintConfirm, lang), id)
+		return botService.TelegramService.SendMessage(chatID, text, utils.MakeWithdrawComplaintKeyboard(lang, id))
+	}
+
+	// The complaint may have been reviewed since the confirmation was shown
+	withdrawn, err := botService.ComplaintService.WithdrawComplaint(id, user.ID)
+	if err != nil {
+		log.Printf("Failed to withdraw complaint %d: %v", id, err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.EditMessage(chatID, messageID, text, nil)
+	}
+
+	if !withdrawn {
+		text := fmt.Sprintf(i18n.Get(i18n.MsgComplaintNotPending, lang), id)
+		return botService.TelegramService.EditMessage(chatID, messageID, text, nil)
+	}
+
+	log.Printf("Complaint #%d withdrawn by its author", id)
+	notifyAdminsComplaintWithdrawn(botService, user, complaint)
+
+	text := fmt.Sprintf(i18n.Get(i18n.MsgComplaintWithdrawn, lang), id)
+	return botService.TelegramService.EditMessage(chatID, messageID, text, nil)
+}
+
+// HandleComplaintEditCallback asks a parent for the corrected text of a pending complaint.
+// Format: complaint_edit_<id>
+func HandleComplaintEditCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrNotRegistered, i18n.LanguageUzbek))
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	id, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "complaint_edit_"))
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	complaint, err := ownComplaint(botService, id, user)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	if complaint.Status != models.StatusPending {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, fmt.Sprintf(i18n.Get(i18n.MsgComplaintNotPending, lang), id))
+	}
+
+	// Admins are notified of a correction with its PDF, so the previous one must be done
+	if complaint.PDFTelegramFileID == "" {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, fmt.Sprintf(i18n.Get(i18n.MsgComplaintEditPending, lang), id))
+	}
+
+	stateData := &models.StateData{
+		Language:    user.Language,
+		ComplaintID: complaint.ID,
+	}
+	if err := botService.StateManager.Set(telegramID, models.StateAwaitingComplaintEdit, stateData); err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	// The stored text is HTML-escaped; unescape it so truncation can't split an entity
+	current := utils.EscapeHTML(utils.TruncateText(html.UnescapeString(complaint.ComplaintText), 1000))
+	text := fmt.Sprintf(i18n.Get(i18n.MsgRequestComplaintEdit, lang), complaint.ID, current)
+	return botService.TelegramService.SendMessage(chatID, text, nil)
+}
+
+// HandleComplaintEditInput replaces the text of a complaint with the parent's correction
+// and queues its new PDF; admins are notified when the PDF is ready
+func HandleComplaintEditInput(botService *services.BotService, message *tgbotapi.Message, stateData *models.StateData) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+	lang := i18n.GetLanguage(stateData.Language)
+
+	if message.Text == "/cancel" {
+		_ = botService.StateManager.Clear(telegramID)
+		text := i18n.Get(i18n.MsgComplaintEditCancelled, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	complaintText, err := validator.ValidateComplaintText(message.Text)
+	if err != nil {
+		text := i18n.Get(i18n.ErrInvalidComplaint, lang) + "\n\n" + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	_ = botService.StateManager.Clear(telegramID)
+
+	if user == nil {
+		text := i18n.Get(i18n.ErrNotRegistered, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	revised, err := botService.ComplaintService.ReviseComplaint(stateData.ComplaintID, user.ID, complaintText)
+	if err != nil {
+		log.Printf("Failed to revise complaint %d: %v", stateData.ComplaintID, err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	if !revised {
+		text := fmt.Sprintf(i18n.Get(i18n.MsgComplaintNotPending, lang), stateData.ComplaintID)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	log.Printf("Complaint #%d corrected by its author", stateData.ComplaintID)
+
+	text := i18n.Get(i18n.MsgSubmissionProcessing, lang)
+	_ = botService.TelegramService.SendMessage(chatID, text, nil)
+
+	// Queue the new PDF; the parent and admins are notified when it completes
+	_, err = botService.DocumentJobService.Enqueue(models.DocumentJobKindComplaint, stateData.ComplaintID, chatID, 0)
+	if err != nil {
+		log.Printf("Failed to queue complaint %d PDF: %v", stateData.ComplaintID, err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	return nil
+}
+
+// ownComplaint gets a complaint of the user, nil if it doesn't exist or belongs to someone else
+func ownComplaint(botService *services.BotService, id int, user *models.User) (*models.Complaint, error) {
+	complaint, err := botService.ComplaintService.GetComplaintByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if complaint == nil || complaint.UserID != user.ID {
+		return nil, nil
+	}
+
+	return complaint, nil
+}
+
+// notifyAdminsComplaintWithdrawn tells admins that a parent withdrew a complaint
+func notifyAdminsComplaintWithdrawn(botService *services.BotService, user *models.User, complaint *models.Complaint) {
+	adminIDs, err := botService.GetAdminTelegramIDs()
+	if err != nil {
+		log.Printf("Failed to get admin IDs: %v", err)
+		return
+	}
+
+	if len(adminIDs) == 0 {
+		return
+	}
+
+	// Staff don't learn who sent an anonymous complaint
+	if complaint.IsAnonymous {
+		user = user.Anonymized()
+	}
+
+	message := fmt.Sprintf(
+		"↩️ <b>SHIKOYAT QAYTARIB OLINDI / ЖАЛОБА ОТОЗВАНА</b>\n\n"+
+			"ID: #%d\n"+
+			"Farzand / Ребенок: <b>%s</b>\n"+
+			"Sinf / Класс: <b>%s</b>\n"+
+			"Sana / Дата: %s\n\n"+
+			"Ota-ona shikoyatni ko'rib chiqilishidan oldin qaytarib oldi.\n"+
+			"Родитель отозвал жалобу до её рассмотрения.",
+		complaint.ID,
+		utils.EscapeHTML(user.ChildName),
+		utils.EscapeHTML(user.ChildClass),
+		utils.FormatDateTime(complaint.CreatedAt),
+	)
+
+	if err := botService.TelegramService.NotifyAdmins(adminIDs, message); err != nil {
+		log.Printf("Failed to notify admins: %v", err)
+	}
+}
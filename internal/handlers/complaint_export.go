@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// exportStatusAll selects complaints of every status
+const exportStatusAll = "all"
+
+// showComplaintExportPeriods starts the complaint archive wizard: period, then status, then class,
+// then category if there are any
+func showComplaintExportPeriods(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	text := "🗂 <b>Shikoyatlar arxivi / Архив жалоб</b>\n\n" +
+		"PDF, rasmlar va manifest.csv bilan ZIP / ZIP с PDF, изображениями и manifest.csv\n\n" +
+		"Davrni tanlang / Выберите период:\n\n" +
+		"Boshqa davr uchun / Для другого периода:\n" +
+		"<code>/export_complaints 2025-09-01 2025-09-30</code>"
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	keyboard := makeExportPeriodKeyboard("export_p_")
+	return botService.TelegramService.EditMessage(callback.Message.Chat.ID, callback.Message.MessageID, text, &keyboard)
+}
+
+// HandleExportComplaintsCommand handles /export_complaints <from> <to> - export wizard with a custom period
+func HandleExportComplaintsCommand(botService *services.BotService, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", message.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		text := "❌ Bu buyruq faqat ma'murlar uchun / Эта команда только для администраторов"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		text := "Foydalanish / Использование:\n<code>/export_complaints 2025-09-01 2025-09-30</code>"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	from, errFrom := utils.ParseDate(args[0], time.Local)
+	to, errTo := utils.ParseDate(args[1], time.Local)
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		text := "❌ Noto'g'ri sana / Неверная дата\n\n" +
+			"Format: <code>2025-09-01</code> yoki / или <code>01.09.2025</code>"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	period := utils.CustomPeriod(from, to)
+	text := formatExportStep(period, "", "", "") + "\nHolatni tanlang / Выберите статус:"
+	return botService.TelegramService.SendMessage(chatID, text, makeExportStatusKeyboard(period))
+}
+
+// HandleExportWizardCallback handles the export menu and wizard steps
+// Format: export_z (complaint archive), export_t_<table>_<format>[_<period>] (tables),
+// export_p_<period>, export_s_<period>_<status>, export_c_<period>_<status>_<classID>,
+// export_k_<period>_<status>_<classID>_<categoryID> (archive steps)
+func HandleExportWizardCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	isAdmin, err := botService.IsAdmin("", callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	step, rest, _ := strings.Cut(strings.TrimPrefix(callback.Data, "export_"), "_")
+	fields := strings.Split(rest, "_")
+
+	switch step {
+	case "z":
+		return showComplaintExportPeriods(botService, callback)
+	case "t":
+		return handleTableExportCallback(botService, callback, fields)
+	}
+
+	if _, _, ok := utils.PeriodRange(fields[0], time.Now()); !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+	period := fields[0]
+
+	if len(fields) > 1 && !isExportStatus(fields[1]) {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	switch {
+	case step == "p" && len(fields) == 1:
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		text := formatExportStep(period, "", "", "") + "\nHolatni tanlang / Выберите статус:"
+		keyboard := makeExportStatusKeyboard(period)
+		return botService.TelegramService.EditMessage(chatID, messageID, text, &keyboard)
+
+	case step == "s" && len(fields) == 2:
+		classes, err := botService.ClassRepo.GetAll()
+		if err != nil {
+			return err
+		}
+
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		text := formatExportStep(period, fields[1], "", "") + "\nGuruhni tanlang / Выберите группу:"
+		keyboard := makeExportClassKeyboard(period, fields[1], classes)
+		return botService.TelegramService.EditMessage(chatID, messageID, text, &keyboard)
+
+	case step == "c" && len(fields) == 3:
+		className, ok, err := exportClassName(botService, fields[2])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Guruh topilmadi / Группа не найдена")
+		}
+
+		categories, err := botService.CategoryRepo.GetAll()
+		if err != nil {
+			return err
+		}
+
+		if len(categories) == 0 {
+			return startComplaintExport(botService, callback, period, fields[1], fields[2], "0")
+		}
+
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		text := formatExportStep(period, fields[1], className, "") + "\nToifani tanlang / Выберите категорию:"
+		keyboard := makeExportCategoryKeyboard(period, fields[1], fields[2], categories)
+		return botService.TelegramService.EditMessage(chatID, messageID, text, &keyboard)
+
+	case step == "k" && len(fields) == 4:
+		return startComplaintExport(botService, callback, period, fields[1], fields[2], fields[3])
+	}
+
+	return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+}
+
+// exportClassName returns the name of the class chosen in the export wizard, ""
+// for all classes. ok is false if the class does not exist.
+func exportClassName(botService *services.BotService, classID string) (name string, ok bool, err error) {
+	id, err := strconv.Atoi(classID)
+	if err != nil {
+		return "", false, nil
+	}
+	if id == 0 {
+		return "", true, nil
+	}
+
+	classes, err := botService.ClassRepo.GetAll()
+	if err != nil {
+		return "", false, err
+	}
+	for _, class := range classes {
+		if class.ID == id {
+			return class.ClassName, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// startComplaintExport starts the background export and reports its result in the wizard message
+func startComplaintExport(botService *services.BotService, callback *tgbotapi.CallbackQuery, period, status, classID, categoryID string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	from, to, _ := utils.PeriodRange(period, time.Now())
+	filter := models.ExportFilter{From: from, To: to}
+
+	if status != exportStatusAll {
+		filter.Status = status
+	}
+
+	className, ok, err := exportClassName(botService, classID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Guruh topilmadi / Группа не найдена")
+	}
+	filter.ChildClass = className
+
+	categoryLabel := ""
+	if id, err := strconv.Atoi(categoryID); err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	} else if id != 0 {
+		category, err := botService.CategoryRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Toifa topilmadi / Категория не найдена")
+		}
+		filter.CategoryID = category.ID
+		categoryLabel = category.Label()
+	}
+
+	summary := formatExportStep(period, status, className, categoryLabel)
+
+	err = botService.ComplaintExportService.StartExport(chatID, filter, func(result *services.ComplaintExportResult, err error) {
+		text := summary + "\n" + formatComplaintExportResult(result, err)
+		if editErr := botService.TelegramService.EditMessage(chatID, messageID, text, nil); editErr != nil {
+			_ = botService.TelegramService.SendMessage(chatID, text, nil)
+		}
+	})
+	if errors.Is(err, services.ErrExportRunning) {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "⏳ Eksport allaqachon bajarilmoqda / Экспорт уже выполняется")
+	}
+	if err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	text := summary + "\n⏳ Arxiv tayyorlanmoqda, bu bir necha daqiqa olishi mumkin...\n" +
+		"Архив готовится, это может занять несколько минут..."
+	return botService.TelegramService.EditMessage(chatID, messageID, text, nil)
+}
+
+// formatComplaintExportResult formats the outcome of a background export
+func formatComplaintExportResult(result *services.ComplaintExportResult, err error) string {
+	if err != nil {
+		log.Printf("Complaint export failed: %v", err)
+		return "❌ Eksport amalga oshmadi / Экспорт не удался"
+	}
+
+	if result.Complaints == 0 {
+		return "📭 Tanlangan filtr bo'yicha shikoyatlar yo'q / Нет жалоб по выбранным фильтрам"
+	}
+
+	text := "✅ <b>Eksport tayyor / Экспорт готов</b>\n"
+	text += fmt.Sprintf("📋 Shikoyatlar / Жалоб: %d\n", result.Complaints)
+	text += fmt.Sprintf("🗂 Arxivlar / Архивов: %d\n", result.Parts)
+	if result.MissingFiles > 0 {
+		text += fmt.Sprintf("\n⚠️ Yuklab bo'lmagan fayllar / Не удалось загрузить файлов: %d\n", result.MissingFiles)
+		text += "Batafsil / Подробнее: manifest.csv"
+	}
+	return text
+}
+
+// formatExportStep formats the filters chosen so far in the export wizard
+func formatExportStep(period, status, className, category string) string {
+	from, to, _ := utils.PeriodRange(period, time.Now())
+
+	text := "📥 <b>Shikoyatlarni eksport qilish / Экспорт жалоб</b>\n\n"
+	text += fmt.Sprintf("📅 Davr / Период: %s\n", utils.FormatPeriod(from, to))
+
+	if status != "" {
+		text += fmt.Sprintf("📊 Holat / Статус: %s\n", exportStatusLabel(status))
+	}
+
+	if className != "" {
+		text += fmt.Sprintf("👥 Guruh / Группа: %s\n", className)
+	}
+
+	if category != "" {
+		text += fmt.Sprintf("🏷 Toifa / Категория: %s\n", utils.EscapeHTML(category))
+	}
+
+	return text
+}
+
+// isExportStatus reports whether status is a valid status filter
+func isExportStatus(status string) bool {
+	switch status {
+	case exportStatusAll, models.StatusPending, models.StatusReviewed, models.StatusArchived, models.StatusWithdrawn:
+		return true
+	}
+	return false
+}
+
+// exportStatusLabel returns the display name of a complaint status filter
+func exportStatusLabel(status string) string {
+	switch status {
+	case models.StatusPending:
+		return "⏳ Kutilmoqda / Ожидание"
+	case models.StatusReviewed:
+		return "✅ Ko'rib chiqildi / Рассмотрено"
+	case models.StatusArchived:
+		return "📦 Arxivlangan / Архивировано"
+	case models.StatusWithdrawn:
+		return "↩️ Qaytarib olingan / Отозвано"
+	default:
+		return "Hammasi / Все"
+	}
+}
+
+// makeExportPeriodKeyboard creates a period picker; each button's data is prefix + period code
+func makeExportPeriodKeyboard(prefix string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("7 kun / 7 дней", prefix+utils.PeriodLast7Days),
+			tgbotapi.NewInlineKeyboardButtonData("30 kun / 30 дней", prefix+utils.PeriodLast30Days),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Shu oy / Этот месяц", prefix+utils.PeriodThisMonth),
+			tgbotapi.NewInlineKeyboardButtonData("O'tgan oy / Прошлый месяц", prefix+utils.PeriodLastMonth),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Barcha vaqt / За всё время", prefix+utils.PeriodAll),
+		),
+	)
+}
+
+// makeExportStatusKeyboard creates the status step of the export wizard
+func makeExportStatusKeyboard(period string) tgbotapi.InlineKeyboardMarkup {
+	prefix := "export_s_" + period + "_"
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(exportStatusLabel(exportStatusAll), prefix+exportStatusAll),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(exportStatusLabel(models.StatusPending), prefix+models.StatusPending),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(exportStatusLabel(models.StatusReviewed), prefix+models.StatusReviewed),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(exportStatusLabel(models.StatusArchived), prefix+models.StatusArchived),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(exportStatusLabel(models.StatusWithdrawn), prefix+models.StatusWithdrawn),
+		),
+	)
+}
+
+// makeExportClassKeyboard creates the class step of the export wizard. Classes are
+// referenced by ID, names may not fit in the 64-byte callback data.
+func makeExportClassKeyboard(period, status string, classes []*models.Class) tgbotapi.InlineKeyboardMarkup {
+	prefix := "export_c_" + period + "_" + status + "_"
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Barcha guruhlar / Все группы", prefix+"0"),
+		),
+	}
+
+	// Create buttons in rows of 3
+	var row []tgbotapi.InlineKeyboardButton
+	for _, class := range classes {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(class.ClassName, prefix+strconv.Itoa(class.ID)))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// makeExportCategoryKeyboard creates the category step of the export wizard
+func makeExportCategoryKeyboard(period, status, classID string, categories []*models.Category) tgbotapi.InlineKeyboardMarkup {
+	prefix := "export_k_" + period + "_" + status + "_" + classID + "_"
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Barcha toifalar / Все категории", prefix+"0"),
+		),
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, category := range categories {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(category.NameUz, prefix+strconv.Itoa(category.ID)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// HandleDocumentJobResult finishes a submission once its PDF job completed (jobErr == nil)
+// or failed for good: the parent's progress message is updated and admins are notified
+func HandleDocumentJobResult(botService *services.BotService, job *models.DocumentJob, jobErr error) {
+	switch job.Kind {
+	case models.DocumentJobKindComplaint:
+		complaint, err := botService.ComplaintService.GetComplaintByID(job.ItemID)
+		if err != nil || complaint == nil {
+			log.Printf("Failed to load complaint %d for document job %d: %v", job.ItemID, job.ID, err)
+			return
+		}
+
+		user, err := botService.UserService.GetUserByID(complaint.UserID)
+		if err != nil || user == nil {
+			log.Printf("Failed to load user for complaint %d: %v", complaint.ID, err)
+			return
+		}
+
+		images, _ := botService.ComplaintService.GetComplaintImages(complaint.ID)
+
+		if jobErr != nil {
+			updateProgressMessage(botService, job, user, i18n.MsgDocumentFailed)
+			author := user
+			if complaint.IsAnonymous {
+				author = user.Anonymized()
+			}
+			title := "YANGI SHIKOYAT / НОВАЯ ЖАЛОБА"
+			if complaint.Version > 1 {
+				title = "SHIKOYAT TUZATILDI / ЖАЛОБА ИСПРАВЛЕНА"
+			}
+			notifyAdminsDocumentFailed(botService, author, title, complaint.ID, complaint.ComplaintText, len(images))
+			return
+		}
+
+		// A corrected complaint gets a new PDF too
+		result := i18n.MsgComplaintSubmitted
+		if complaint.Version > 1 {
+			result = i18n.MsgComplaintEdited
+		}
+		updateProgressMessage(botService, job, user, result)
+		notifyAdminsWithPDF(botService, user, complaint, complaint.PDFTelegramFileID, len(images))
+
+	case models.DocumentJobKindProposal:
+		proposal, err := botService.ProposalService.GetProposalByID(job.ItemID)
+		if err != nil || proposal == nil {
+			log.Printf("Failed to load proposal %d for document job %d: %v", job.ItemID, job.ID, err)
+			return
+		}
+
+		user, err := botService.UserService.GetUserByID(proposal.UserID)
+		if err != nil || user == nil {
+			log.Printf("Failed to load user for proposal %d: %v", proposal.ID, err)
+			return
+		}
+
+		images, _ := botService.ProposalService.GetProposalImages(proposal.ID)
+
+		if jobErr != nil {
+			updateProgressMessage(botService, job, user, i18n.MsgDocumentFailed)
+			notifyAdminsDocumentFailed(botService, user, "YANGI TAKLIF / НОВОЕ ПРЕДЛОЖЕНИЕ", proposal.ID, proposal.ProposalText, len(images))
+			return
+		}
+
+		updateProgressMessage(botService, job, user, i18n.MsgProposalSubmitted)
+		notifyAdminsWithProposalPDF(botService, user, proposal, proposal.PDFTelegramFileID, len(images))
+
+	default:
+		log.Printf("Unknown document job kind: %s", job.Kind)
+	}
+}
+
+// updateProgressMessage replaces the parent's progress message with the final status,
+// or sends it as a new message if the progress message can't be edited
+func updateProgressMessage(botService *services.BotService, job *models.DocumentJob, user *models.User, key string) {
+	lang := i18n.GetLanguage(user.Language)
+	text := i18n.Get(key, lang)
+
+	if job.MessageID != 0 {
+		if err := botService.TelegramService.EditMessage(job.ChatID, job.MessageID, text, nil); err == nil {
+			return
+		}
+	}
+
+	isAdmin, _ := botService.IsAdmin(user.PhoneNumber, user.TelegramID)
+	keyboard := utils.MakeMainMenuKeyboardForUser(lang, isAdmin)
+
+	if err := botService.TelegramService.SendMessage(job.ChatID, text, keyboard); err != nil {
+		log.Printf("Failed to send document job %d result: %v", job.ID, err)
+	}
+}
+
+// notifyAdminsDocumentFailed sends a submission to admins as text when its PDF could not be generated
+func notifyAdminsDocumentFailed(botService *services.BotService, user *models.User, title string, itemID int, itemText string, imageCount int) {
+	adminIDs, err := botService.GetAdminTelegramIDs()
+	if err != nil {
+		log.Printf("Failed to get admin IDs: %v", err)
+		return
+	}
+
+	if len(adminIDs) == 0 {
+		log.Println("No admins configured")
+		return
+	}
+
+	message := fmt.Sprintf(
+		"<b>%s</b>\n\n"+
+			"⚠️ PDF yaratib bo'lmadi / Не удалось создать PDF\n\n"+
+			"ID: #%d\n"+
+			"Farzand / Ребенок: <b>%s</b>\n"+
+			"Sinf / Класс: <b>%s</b>\n"+
+			"Telefon / Телефон: %s\n"+
+			"📷 Rasmlar / Изображений: %d\n\n"+
+			"%s",
+		title,
+		itemID,
+		user.ChildName,
+		user.ChildClass,
+		user.PhoneNumber,
+		imageCount,
+		// The stored text is HTML-escaped; unescape it so truncation can't split an entity
+		utils.EscapeHTML(utils.TruncateText(html.UnescapeString(itemText), 3000)),
+	)
+
+	if err := botService.TelegramService.NotifyAdmins(adminIDs, message); err != nil {
+		log.Printf("Failed to notify admins: %v", err)
+	}
+}
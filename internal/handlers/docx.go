@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+)
+
+// HandleDocxDownloadCallback renders a complaint or proposal as a Word document and sends it to the admin
+// Format: docx_complaint_123 or docx_proposal_123
+func HandleDocxDownloadCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	kind, idPart, ok := strings.Cut(strings.TrimPrefix(callback.Data, "docx_"), "_")
+	if !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	var (
+		userID int
+		images []models.ImageData
+		render func(user *models.User) (filePath, filename string, err error)
+	)
+
+	switch kind {
+	case "complaint":
+		complaint, err := botService.ComplaintService.GetComplaintByID(id)
+		if err != nil {
+			return err
+		}
+		if complaint == nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+		}
+
+		stored, err := botService.ComplaintService.GetComplaintImages(complaint.ID)
+		if err != nil {
+			return err
+		}
+		for _, img := range stored {
+			images = append(images, models.ImageData{
+				FileID:       img.TelegramFileID,
+				FileUniqueID: img.FileUniqueID,
+				FileSize:     img.FileSize,
+				MimeType:     img.MimeType,
+			})
+		}
+
+		userID = complaint.UserID
+		render = func(user *models.User) (string, string, error) {
+			return botService.DocumentService.GenerateComplaintDOCX(user, complaint, images)
+		}
+
+	case "proposal":
+		proposal, err := botService.ProposalService.GetProposalByID(id)
+		if err != nil {
+			return err
+		}
+		if proposal == nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Taklif topilmadi / Предложение не найдено")
+		}
+
+		stored, err := botService.ProposalService.GetProposalImages(proposal.ID)
+		if err != nil {
+			return err
+		}
+		for _, img := range stored {
+			images = append(images, models.ImageData{
+				FileID:       img.TelegramFileID,
+				FileUniqueID: img.FileUniqueID,
+				FileSize:     img.FileSize,
+				MimeType:     img.MimeType,
+			})
+		}
+
+		userID = proposal.UserID
+		render = func(user *models.User) (string, string, error) {
+			return botService.DocumentService.GenerateProposalDOCX(user, proposal, images)
+		}
+
+	default:
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	user, err := botService.UserService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Foydalanuvchi topilmadi / Пользователь не найден")
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "⏳ Word hujjat tayyorlanmoqda / Готовится документ Word")
+
+	docxPath, filename, err := render(user)
+	if err != nil {
+		log.Printf("Failed to generate DOCX for %s %d: %v", kind, id, err)
+		text := "❌ Word hujjatni yaratib bo'lmadi / Не удалось создать документ Word"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	// Clean up temp file after upload
+	defer func() { _ = botService.DocumentService.DeleteTempFile(docxPath) }()
+
+	if _, err := botService.TelegramService.UploadDocument(chatID, docxPath, filename); err != nil {
+		log.Printf("Failed to upload DOCX for %s %d: %v", kind, id, err)
+		text := "❌ Word hujjatni yuborib bo'lmadi / Не удалось отправить документ Word"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// exportTableNames are the display names of the exportable tables
+var exportTableNames = map[string]string{
+	services.ExportTableUsers:      "👥 Foydalanuvchilar / Пользователи",
+	services.ExportTableComplaints: "📋 Shikoyatlar / Жалобы",
+	services.ExportTableProposals:  "💡 Takliflar / Предложения",
+}
+
+// HandleAdminExportCallback shows the export menu: the complaint archive and the CSV/XLSX tables
+func HandleAdminExportCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	text := "📥 <b>Eksport / Экспорт</b>\n\n" +
+		"🗂 Arxiv - shikoyatlar PDF va rasmlari bilan (tekshiruvlar uchun)\n" +
+		"Архив - жалобы с PDF и изображениями (для проверок)\n\n" +
+		"CSV / XLSX - jadval / таблица"
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	return botService.TelegramService.SendMessage(chatID, text, makeExportMenuKeyboard())
+}
+
+// handleTableExportCallback exports a table as CSV or XLSX. Without a period it asks for one first.
+// Format: export_t_<table>_<format>[_<period>]
+func handleTableExportCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery, fields []string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	if len(fields) < 2 || len(fields) > 3 {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	table := fields[0]
+	tableName, ok := exportTableNames[table]
+	if !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	format, ok := services.ParseExportFormat(fields[1])
+	if !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	title := fmt.Sprintf("%s · %s\n\n", tableName, strings.ToUpper(string(format)))
+
+	if len(fields) == 2 {
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		text := title + "Davrni tanlang / Выберите период:"
+		keyboard := makeExportPeriodKeyboard(fmt.Sprintf("export_t_%s_%s_", table, format))
+		return botService.TelegramService.EditMessage(chatID, messageID, text, &keyboard)
+	}
+
+	from, to, ok := utils.PeriodRange(fields[2], time.Now())
+	if !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "⏳")
+
+	filePath, filename, err := botService.ExportService.ExportFile(table, format, models.ExportFilter{From: from, To: to})
+	if err != nil {
+		log.Printf("Failed to export %s: %v", table, err)
+		return botService.TelegramService.SendMessage(chatID, "❌ Eksport amalga oshmadi / Экспорт не удался", nil)
+	}
+
+	// Clean up temp file after upload
+	defer func() { _ = botService.DocumentService.DeleteTempFile(filePath) }()
+
+	if _, err := botService.TelegramService.UploadDocument(chatID, filePath, filename); err != nil {
+		log.Printf("Failed to upload %s export: %v", table, err)
+		return botService.TelegramService.SendMessage(chatID, "❌ Faylni yuborib bo'lmadi / Не удалось отправить файл", nil)
+	}
+
+	text := title + fmt.Sprintf("📅 Davr / Период: %s\n✅ Tayyor / Готово", utils.FormatPeriod(from, to))
+	return botService.TelegramService.EditMessage(chatID, messageID, text, nil)
+}
+
+// makeExportMenuKeyboard creates the export menu keyboard
+func makeExportMenuKeyboard() tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗂 Shikoyatlar arxivi (ZIP) / Архив жалоб (ZIP)", "export_z"),
+		),
+	}
+
+	for _, table := range []string{services.ExportTableUsers, services.ExportTableComplaints, services.ExportTableProposals} {
+		for _, format := range []services.ExportFormat{services.ExportFormatCSV, services.ExportFormatXLSX} {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf("%s · %s", exportTableNames[table], strings.ToUpper(string(format))),
+					fmt.Sprintf("export_t_%s_%s", table, format),
+				),
+			))
+		}
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
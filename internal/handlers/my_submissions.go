@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+const mySubmissionsPerPage = 10
+
+// Most recent replies shown under an opened complaint
+const myComplaintRepliesShown = 5
+
+// myComplaintsPage formats a page of the user's complaints, newest first. The
+// keyboard is nil if there are none; a page past the end shows the last one.
+func myComplaintsPage(botService *services.BotService, user *models.User, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	total, err := botService.ComplaintService.CountUserComplaints(user.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if total == 0 {
+		return "Sizda hali shikoyatlar yo'q / У вас пока нет жалоб", nil, nil
+	}
+
+	totalPages := (total + mySubmissionsPerPage - 1) / mySubmissionsPerPage
+	page = min(max(page, 0), totalPages-1)
+
+	complaints, err := botService.ComplaintService.GetUserComplaints(user.ID, mySubmissionsPerPage, page*mySubmissionsPerPage)
+	if err != nil {
+		return "", nil, err
+	}
+
+	text := "📋 Sizning shikoyatlaringiz / Ваши жалобы:\n\n"
+	for i, c := range complaints {
+		edited := ""
+		if c.Version > 1 {
+			edited = " ✏️"
+		}
+
+		text += fmt.Sprintf("%d. %s #%d%s %s\n   📅 %s\n\n",
+			page*mySubmissionsPerPage+i+1,
+			utils.StatusIcon(c.Status),
+			c.ID,
+			edited,
+			mySubmissionPreview(c.ComplaintText, 50),
+			utils.FormatDateTime(c.CreatedAt),
+		)
+	}
+
+	text += "👇 Batafsil ko'rish uchun tanlang / Выберите, чтобы открыть"
+	if totalPages > 1 {
+		text += fmt.Sprintf("\n📄 %d/%d", page+1, totalPages)
+	}
+
+	keyboard := utils.MakeMyComplaintsKeyboard(complaints, page, totalPages)
+	return text, &keyboard, nil
+}
+
+// myProposalsPage formats a page of the user's proposals, newest first. The
+// keyboard is nil if there are none; a page past the end shows the last one.
+func myProposalsPage(botService *services.BotService, user *models.User, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	total, err := botService.ProposalService.CountUserProposals(user.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if total == 0 {
+		return "Sizda hali takliflar yo'q / У вас пока нет предложений", nil, nil
+	}
+
+	totalPages := (total + mySubmissionsPerPage - 1) / mySubmissionsPerPage
+	page = min(max(page, 0), totalPages-1)
+
+	proposals, err := botService.ProposalService.GetUserProposals(user.ID, mySubmissionsPerPage, page*mySubmissionsPerPage)
+	if err != nil {
+		return "", nil, err
+	}
+
+	text := "📋 Sizning takliflaringiz / Ваши предложения:\n\n"
+	for i, p := range proposals {
+		text += fmt.Sprintf("%d. %s #%d %s\n   📅 %s\n\n",
+			page*mySubmissionsPerPage+i+1,
+			utils.StatusIcon(p.Status),
+			p.ID,
+			mySubmissionPreview(p.ProposalText, 50),
+			utils.FormatDateTime(p.CreatedAt),
+		)
+	}
+
+	text += "👇 Batafsil ko'rish uchun tanlang / Выберите, чтобы открыть"
+	if totalPages > 1 {
+		text += fmt.Sprintf("\n📄 %d/%d", page+1, totalPages)
+	}
+
+	keyboard := utils.MakeMyProposalsKeyboard(proposals, page, totalPages)
+	return text, &keyboard, nil
+}
+
+// HandleMySubmissionsPageCallback shows another page of the parent's complaints or proposals.
+// Format: my_complaints_page_<page> or my_proposals_page_<page>
+func HandleMySubmissionsPageCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	user, err := botService.UserService.GetUserByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrNotRegistered, i18n.LanguageUzbek))
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	complaints := strings.HasPrefix(callback.Data, "my_complaints_page_")
+	pagePart := strings.TrimPrefix(strings.TrimPrefix(callback.Data, "my_complaints_page_"), "my_proposals_page_")
+	page, err := strconv.Atoi(pagePart)
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid page")
+	}
+
+	var text string
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	if complaints {
+		text, keyboard, err = myComplaintsPage(botService, user, page)
+	} else {
+		text, keyboard, err = myProposalsPage(botService, user, page)
+	}
+	if err != nil {
+		log.Printf("Failed to list submissions of user %d: %v", user.ID, err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrDatabaseError, lang))
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	return botService.TelegramService.EditMessage(chatID, messageID, text, keyboard)
+}
+
+// HandleMyComplaintCallback opens one of the parent's complaints: its images,
+// its PDF, then the text with its status history and the staff's replies.
+// Format: my_complaint_<id>_<page>
+func HandleMyComplaintCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrNotRegistered, i18n.LanguageUzbek))
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	id, page, ok := parseMySubmissionData(callback.Data, "my_complaint_")
+	if !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	complaint, err := ownComplaint(botService, id, user)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	timeline, err := botService.ComplaintService.GetComplaintTimeline(id)
+	if err != nil || timeline == nil {
+		log.Printf("Failed to get timeline of complaint %d: %v", id, err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrDatabaseError, lang))
+	}
+
+	versions, err := botService.ComplaintService.GetComplaintVersions(id)
+	if err != nil {
+		log.Printf("Failed to get versions of complaint %d: %v", id, err)
+	}
+
+	replies, err := botService.ComplaintService.GetComplaintReplies(id)
+	if err != nil {
+		log.Printf("Failed to get replies to complaint %d: %v", id, err)
+	}
+
+	rating, err := botService.ComplaintService.GetComplaintRating(id)
+	if err != nil {
+		log.Printf("Failed to get rating of complaint %d: %v", id, err)
+	}
+
+	images, err := botService.ComplaintService.GetComplaintImages(id)
+	if err != nil {
+		log.Printf("Failed to get images of complaint %d: %v", id, err)
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	var imageFiles []imageFile
+	for _, image := range images {
+		imageFiles = append(imageFiles, imageFile{fileID: image.TelegramFileID, isDocument: image.MimeType != ""})
+	}
+	sendMySubmissionFiles(botService, chatID, imageFiles, complaint.PDFTelegramFileID, complaint.PDFFilename)
+
+	text := fmt.Sprintf("📋 <b>Shikoyat #%d / Жалоба #%d</b>\n", complaint.ID, complaint.ID)
+	if complaint.Category != nil {
+		text += fmt.Sprintf("🏷 %s\n", utils.EscapeHTML(complaint.Category.Label()))
+	}
+	if complaint.IsUrgent {
+		text += "🚨 Shoshilinch / Срочная\n"
+	}
+	if complaint.IsAnonymous {
+		text += "🕶 Anonim / Анонимная\n"
+	}
+	text += "\n" + mySubmissionBody(complaint.ComplaintText, timeline)
+
+	// Status history, in the order things happened
+	events := []mySubmissionEvent{{at: complaint.CreatedAt, text: "📝 Yuborildi / Отправлена"}}
+	for _, version := range versions {
+		line := fmt.Sprintf("✏️ Tuzatildi / Исправлена (v%d)", version.Version+1)
+		events = append(events, mySubmissionEvent{at: version.ReplacedAt, text: line})
+	}
+	events = append(events, mySubmissionTimelineEvents(timeline)...)
+	if rating != nil && rating.Rating != nil && rating.RatedAt != nil {
+		line := "⭐ Bahoingiz / Ваша оценка: " + strings.Repeat("⭐", *rating.Rating)
+		events = append(events, mySubmissionEvent{at: *rating.RatedAt, text: line})
+	}
+	text += "\n\n" + formatMySubmissionEvents(events)
+
+	if complaint.Status == models.StatusArchived {
+		text += "📦 Arxivlangan / В архиве\n"
+	}
+
+	if len(replies) > 0 {
+		text += "\n💬 <b>Javoblar / Ответы:</b>\n"
+		if len(replies) > myComplaintRepliesShown {
+			text += fmt.Sprintf("<i>(+%d oldingi / предыдущих)</i>\n", len(replies)-myComplaintRepliesShown)
+			replies = replies[len(replies)-myComplaintRepliesShown:]
+		}
+		for _, reply := range replies {
+			name := reply.AdminName
+			if name == "" {
+				name = i18n.Get(i18n.MsgComplaintStaffFallback, lang)
+			}
+			// The stored reply is HTML-escaped; unescape it so truncation can't split an entity
+			replyText := utils.EscapeHTML(utils.TruncateText(html.UnescapeString(reply.ReplyText), 300))
+			text += fmt.Sprintf("\n<b>%s</b>, %s:\n%s\n", utils.EscapeHTML(name), utils.FormatDateTime(reply.CreatedAt), replyText)
+		}
+	}
+
+	keyboard := utils.MakeMyComplaintKeyboard(lang, complaint, page)
+	return botService.TelegramService.SendMessage(chatID, strings.TrimSpace(text), keyboard)
+}
+
+// HandleMyProposalCallback opens one of the parent's proposals: its images,
+// its PDF, then the text with its status history.
+// Format: my_proposal_<id>_<page>
+func HandleMyProposalCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrNotRegistered, i18n.LanguageUzbek))
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	id, page, ok := parseMySubmissionData(callback.Data, "my_proposal_")
+	if !ok {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	proposal, err := botService.ProposalService.GetProposalByID(id)
+	if err != nil {
+		return err
+	}
+
+	if proposal == nil || proposal.UserID != user.ID {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Taklif topilmadi / Предложение не найдено")
+	}
+
+	timeline, err := botService.ProposalService.GetProposalTimeline(id)
+	if err != nil || timeline == nil {
+		log.Printf("Failed to get timeline of proposal %d: %v", id, err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrDatabaseError, lang))
+	}
+
+	images, err := botService.ProposalService.GetProposalImages(id)
+	if err != nil {
+		log.Printf("Failed to get images of proposal %d: %v", id, err)
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	var imageFiles []imageFile
+	for _, image := range images {
+		imageFiles = append(imageFiles, imageFile{fileID: image.TelegramFileID, isDocument: image.MimeType != ""})
+	}
+	sendMySubmissionFiles(botService, chatID, imageFiles, proposal.PDFTelegramFileID, proposal.PDFFilename)
+
+	text := fmt.Sprintf("💡 <b>Taklif #%d / Предложение #%d</b>\n\n", proposal.ID, proposal.ID)
+	text += mySubmissionBody(proposal.ProposalText, timeline)
+
+	events := []mySubmissionEvent{{at: proposal.CreatedAt, text: "📝 Yuborildi / Отправлено"}}
+	events = append(events, mySubmissionTimelineEvents(timeline)...)
+	text += "\n\n" + formatMySubmissionEvents(events)
+
+	if proposal.Status == models.ProposalStatusArchived {
+		text += "📦 Arxivlangan / В архиве\n"
+	}
+
+	keyboard := utils.MakeMyProposalKeyboard(lang, page)
+	return botService.TelegramService.SendMessage(chatID, strings.TrimSpace(text), keyboard)
+}
+
+// imageFile is an image attached to a complaint or proposal. Images the parent
+// sent as files have a MIME type and go back as documents, photos have none.
+type imageFile struct {
+	fileID     string
+	isDocument bool
+}
+
+// mySubmissionEvent is one line of the status history of a complaint or proposal
+type mySubmissionEvent struct {
+	at   time.Time
+	text string
+}
+
+// sendMySubmissionFiles sends the images of a complaint or proposal as albums,
+// then its PDF if it's ready. Failures are logged, the text still follows.
+func sendMySubmissionFiles(botService *services.BotService, chatID int64, images []imageFile, pdfFileID, pdfFilename string) {
+	var photos, documents []string
+	for _, image := range images {
+		if image.isDocument {
+			documents = append(documents, image.fileID)
+		} else {
+			photos = append(photos, image.fileID)
+		}
+	}
+
+	if err := botService.TelegramService.SendMediaGroupByFileID(chatID, photos, false); err != nil {
+		log.Printf("Failed to send images: %v", err)
+	}
+
+	if err := botService.TelegramService.SendMediaGroupByFileID(chatID, documents, true); err != nil {
+		log.Printf("Failed to send image files: %v", err)
+	}
+
+	if pdfFileID == "" {
+		return
+	}
+
+	if err := botService.TelegramService.SendDocumentByFileID(chatID, pdfFileID, pdfFilename, nil); err != nil {
+		log.Printf("Failed to send PDF: %v", err)
+	}
+}
+
+// mySubmissionBody formats the text of a complaint or proposal, or says it was
+// deleted after the retention period
+func mySubmissionBody(text string, timeline *models.SubmissionTimeline) string {
+	if timeline.PurgedAt != nil || text == "" {
+		return "🗑 <i>Matn saqlash muddati tugagani uchun o'chirilgan / Текст удалён по истечении срока хранения</i>"
+	}
+
+	// The stored text is HTML-escaped; unescape it so truncation can't split an entity
+	return utils.EscapeHTML(utils.TruncateText(html.UnescapeString(text), 1000))
+}
+
+// mySubmissionPreview shortens the text of a complaint or proposal for the list
+func mySubmissionPreview(text string, maxLen int) string {
+	if text == "" {
+		return "🗑"
+	}
+
+	return utils.EscapeHTML(utils.TruncateText(html.UnescapeString(text), maxLen))
+}
+
+// mySubmissionTimelineEvents lists when a complaint or proposal was taken in
+// hand, reviewed, withdrawn and purged
+func mySubmissionTimelineEvents(timeline *models.SubmissionTimeline) []mySubmissionEvent {
+	var events []mySubmissionEvent
+
+	// Reviewing right away also sets the first response, it's shown once
+	if timeline.FirstResponseAt != nil && (timeline.ReviewedAt == nil || !timeline.FirstResponseAt.Equal(*timeline.ReviewedAt)) {
+		events = append(events, mySubmissionEvent{at: *timeline.FirstResponseAt, text: "👀 Ko'rib chiqishga olindi / Взято в работу"})
+	}
+
+	if timeline.ReviewedAt != nil {
+		events = append(events, mySubmissionEvent{at: *timeline.ReviewedAt, text: "✅ Ko'rib chiqildi / Рассмотрено"})
+	}
+
+	if timeline.WithdrawnAt != nil {
+		events = append(events, mySubmissionEvent{at: *timeline.WithdrawnAt, text: "↩️ Qaytarib olindi / Отозвано"})
+	}
+
+	if timeline.PurgedAt != nil {
+		events = append(events, mySubmissionEvent{at: *timeline.PurgedAt, text: "🗑 Matn o'chirildi / Текст удалён"})
+	}
+
+	return events
+}
+
+// formatMySubmissionEvents formats the status history, oldest first
+func formatMySubmissionEvents(events []mySubmissionEvent) string {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at.Before(events[j].at)
+	})
+
+	text := "📜 <b>Holat tarixi / История статуса:</b>\n"
+	for _, event := range events {
+		text += fmt.Sprintf("%s — %s\n", utils.FormatDateTime(event.at), event.text)
+	}
+
+	return text
+}
+
+// parseMySubmissionData parses <prefix><id>_<page>
+func parseMySubmissionData(data, prefix string) (id, page int, ok bool) {
+	idPart, pagePart, found := strings.Cut(strings.TrimPrefix(data, prefix), "_")
+	if !found {
+		return 0, 0, false
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	page, err = strconv.Atoi(pagePart)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return id, page, true
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+	"anor-kids/internal/validator"
+)
+
+// ownContactPhone returns the phone number of a contact shared through the
+// contact button. Typed numbers and contacts of other people are rejected:
+// Telegram sets Contact.UserID only for contacts that belong to a Telegram user,
+// and it matches From.ID only when the sender shared their own number.
+func ownContactPhone(message *tgbotapi.Message) (string, bool) {
+	if message.Contact == nil || message.From == nil {
+		return "", false
+	}
+
+	if message.Contact.UserID != message.From.ID {
+		return "", false
+	}
+
+	return message.Contact.PhoneNumber, true
+}
+
+// HandleChangePhoneCallback starts the phone number change flow from settings
+func HandleChangePhoneCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "User not found")
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	err = botService.StateManager.Set(telegramID, models.StateAwaitingNewPhone, &models.StateData{Language: user.Language})
+	if err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	text := i18n.Get(i18n.MsgRequestNewPhone, lang)
+	return botService.TelegramService.SendMessage(chatID, text, utils.MakePhoneChangeKeyboard(lang))
+}
+
+// HandleNewPhoneNumber handles the new phone number in the phone change flow
+func HandleNewPhoneNumber(botService *services.BotService, message *tgbotapi.Message, stateData *models.StateData) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+	lang := i18n.GetLanguage(stateData.Language)
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		_ = botService.StateManager.Clear(telegramID)
+		text := i18n.Get(i18n.ErrNotRegistered, lang)
+		return botService.TelegramService.SendMessage(chatID, text, utils.RemoveKeyboard())
+	}
+
+	isAdmin, _ := botService.IsAdmin(user.PhoneNumber, telegramID)
+	menuKeyboard := utils.MakeMainMenuKeyboardForUser(lang, isAdmin)
+
+	// Cancel button (check both languages)
+	if message.Text == i18n.Get(i18n.BtnCancel, i18n.LanguageUzbek) || message.Text == i18n.Get(i18n.BtnCancel, i18n.LanguageRussian) {
+		_ = botService.StateManager.Clear(telegramID)
+		text := i18n.Get(i18n.MsgPhoneChangeCancelled, lang)
+		return botService.TelegramService.SendMessage(chatID, text, menuKeyboard)
+	}
+
+	phoneNumber, ok := ownContactPhone(message)
+	if !ok {
+		text := i18n.Get(i18n.ErrPhoneNotOwn, lang)
+		return botService.TelegramService.SendMessage(chatID, text, utils.MakePhoneChangeKeyboard(lang))
+	}
+
+	validPhone, err := validator.ValidateUzbekPhone(phoneNumber)
+	if err != nil {
+		text := i18n.Get(i18n.ErrInvalidPhone, lang)
+		return botService.TelegramService.SendMessage(chatID, text, utils.MakePhoneChangeKeyboard(lang))
+	}
+
+	err = botService.UserService.ChangePhoneNumber(telegramID, validPhone)
+	if errors.Is(err, services.ErrPhoneNumberTaken) {
+		_ = botService.StateManager.Clear(telegramID)
+		text := i18n.Get(i18n.ErrPhoneTaken, lang)
+		return botService.TelegramService.SendMessage(chatID, text, menuKeyboard)
+	}
+
+	if err != nil {
+		_ = botService.StateManager.Clear(telegramID)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, menuKeyboard)
+	}
+
+	_ = botService.StateManager.Clear(telegramID)
+
+	// The new number may belong to an admin
+	_ = botService.AdminRepo.UpdateTelegramID(validPhone, telegramID)
+	isAdmin, _ = botService.IsAdmin(validPhone, telegramID)
+
+	text := fmt.Sprintf(i18n.Get(i18n.MsgPhoneChanged, lang), validPhone)
+	return botService.TelegramService.SendMessage(chatID, text, utils.MakeMainMenuKeyboardForUser(lang, isAdmin))
+}
+
+// restoreAccount moves an existing registration to the sender's new telegram
+// account after they proved ownership of its phone number
+func restoreAccount(botService *services.BotService, message *tgbotapi.Message, existing *models.User, lang i18n.Language) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+
+	err := botService.UserService.TransferAccount(existing.ID, telegramID, message.From.UserName)
+	if err != nil {
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	_ = botService.StateManager.Clear(telegramID)
+	_ = botService.AdminRepo.UpdateTelegramID(existing.PhoneNumber, telegramID)
+
+	isAdmin, _ := botService.IsAdmin(existing.PhoneNumber, telegramID)
+
+	text := fmt.Sprintf(i18n.Get(i18n.MsgAccountRestored, lang), existing.ChildName, existing.ChildClass)
+	return botService.TelegramService.SendMessage(chatID, text, utils.MakeMainMenuKeyboardForUser(lang, isAdmin))
+}
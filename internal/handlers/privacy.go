@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// HandleExportDataCallback sends the user a ZIP with everything stored about them
+func HandleExportDataCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "User not found")
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	_ = botService.TelegramService.SendMessage(chatID, i18n.Get(i18n.MsgExportPreparing, lang), nil)
+
+	zipPath, filename, err := botService.PrivacyService.ExportUserData(user)
+	if err != nil {
+		log.Printf("Failed to export user data: %v", err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	// Clean up temp file after upload
+	defer func() { _ = botService.DocumentService.DeleteTempFile(zipPath) }()
+
+	_, err = botService.TelegramService.UploadDocument(chatID, zipPath, filename)
+	if err != nil {
+		log.Printf("Failed to upload user data export: %v", err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	return botService.TelegramService.SendMessage(chatID, i18n.Get(i18n.MsgExportReady, lang), nil)
+}
+
+// HandleDeleteAccountCallback asks for the first account deletion confirmation
+func HandleDeleteAccountCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "User not found")
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	text := i18n.Get(i18n.MsgDeleteAccountConfirm, lang)
+	return botService.TelegramService.SendMessage(chatID, text, utils.MakeDeleteAccountKeyboard(lang, false))
+}
+
+// HandleDeleteAccountConfirmCallback handles the first confirmation and asks for the final one
+func HandleDeleteAccountConfirmCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "User not found")
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	// The final button only works while in this state
+	err = botService.StateManager.Set(telegramID, models.StateConfirmingAccountDeletion, &models.StateData{Language: user.Language})
+	if err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	text := i18n.Get(i18n.MsgDeleteAccountFinal, lang)
+	keyboard := utils.MakeDeleteAccountKeyboard(lang, true)
+	return botService.TelegramService.EditMessage(chatID, callback.Message.MessageID, text, &keyboard)
+}
+
+// HandleDeleteAccountFinalCallback deletes the account after the second confirmation
+func HandleDeleteAccountFinalCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "User not found")
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+
+	state, err := botService.StateManager.GetState(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if state != models.StateConfirmingAccountDeletion {
+		_ = botService.TelegramService.DeleteMessage(chatID, callback.Message.MessageID)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.MsgDeleteAccountCancelled, lang))
+	}
+
+	err = botService.PrivacyService.DeleteAccount(user)
+	if err != nil {
+		log.Printf("Failed to delete account %d: %v", user.ID, err)
+		_ = botService.StateManager.Clear(telegramID)
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	log.Printf("User %d deleted their account", user.ID)
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "✅")
+	_ = botService.TelegramService.DeleteMessage(chatID, callback.Message.MessageID)
+
+	text := i18n.Get(i18n.MsgAccountDeleted, lang)
+	return botService.TelegramService.SendMessage(chatID, text, utils.RemoveKeyboard())
+}
+
+// HandleDeleteAccountCancelCallback cancels account deletion
+func HandleDeleteAccountCancelCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	lang := i18n.LanguageUzbek
+	if user != nil {
+		lang = i18n.GetLanguage(user.Language)
+		_ = botService.StateManager.Clear(telegramID)
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	text := i18n.Get(i18n.MsgDeleteAccountCancelled, lang)
+	return botService.TelegramService.EditMessage(chatID, callback.Message.MessageID, text, nil)
+}
@@ -29,6 +29,11 @@ func HandleProposalCommand(botService *services.BotService, message *tgbotapi.Me
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
+	// Registrations waiting for approval can't submit yet
+	if approved, err := ensureUserApproved(botService, chatID, user); !approved {
+		return err
+	}
+
 	lang := i18n.GetLanguage(user.Language)
 
 	// Set state to awaiting proposal
@@ -93,21 +98,14 @@ func HandleProposalImage(botService *services.BotService, message *tgbotapi.Mess
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
-	// Get largest photo size
-	var photo *tgbotapi.PhotoSize
-	if len(message.Photo) > 0 {
-		photo = &message.Photo[len(message.Photo)-1]
-	} else {
+	// Get the photo or image document
+	imageData, ok := imageFromMessage(message)
+	if !ok {
 		text := "Iltimos, rasm yuboring.\n\nПожалуйста, отправьте изображение."
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
 	// Add image to state
-	imageData := models.ImageData{
-		FileID:       photo.FileID,
-		FileUniqueID: photo.FileUniqueID,
-		FileSize:     photo.FileSize,
-	}
 	stateData.Images = append(stateData.Images, imageData)
 
 	// Save state
@@ -198,7 +196,7 @@ func HandleFinishProposalImages(botService *services.BotService, callback *tgbot
 	return HandleSkipProposalImages(botService, callback) // Same logic
 }
 
-// HandleProposalConfirmation handles proposal confirmation and PDF generation
+// HandleProposalConfirmation saves the confirmed proposal and queues its PDF generation
 func HandleProposalConfirmation(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
 	telegramID := callback.From.ID
 	chatID := callback.Message.Chat.ID
@@ -213,6 +211,12 @@ func HandleProposalConfirmation(botService *services.BotService, callback *tgbot
 		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "User not found")
 	}
 
+	if approved, err := ensureUserApproved(botService, chatID, user); !approved {
+		_ = botService.StateManager.Clear(telegramID)
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		return err
+	}
+
 	lang := i18n.GetLanguage(user.Language)
 
 	// Get proposal text and images from state
@@ -228,33 +232,10 @@ func HandleProposalConfirmation(botService *services.BotService, callback *tgbot
 	// Answer callback query
 	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "✅")
 
-	// Generate PDF document with text and images
-	pdfPath, filename, err := botService.DocumentService.GenerateProposalPDF(user, stateData.ProposalText, stateData.Images)
-	if err != nil {
-		log.Printf("Failed to generate PDF: %v", err)
-		text := i18n.Get(i18n.ErrDatabaseError, lang)
-		return botService.TelegramService.SendMessage(chatID, text, nil)
-	}
-
-	// Upload PDF to Telegram and get file_id
-	fileID, err := botService.TelegramService.UploadDocument(chatID, pdfPath, filename)
-	if err != nil {
-		log.Printf("Failed to upload PDF: %v", err)
-		// Clean up temp file
-		_ = botService.DocumentService.DeleteTempFile(pdfPath)
-		text := i18n.Get(i18n.ErrDatabaseError, lang)
-		return botService.TelegramService.SendMessage(chatID, text, nil)
-	}
-
-	// Clean up temp file after upload
-	_ = botService.DocumentService.DeleteTempFile(pdfPath)
-
-	// Save proposal to database with PDF info
+	// Save the proposal first so the text is never lost; the PDF is generated in the background
 	proposalReq := &models.CreateProposalRequest{
-		UserID:            user.ID,
-		ProposalText:      stateData.ProposalText,
-		PDFTelegramFileID: fileID,
-		PDFFilename:       filename,
+		UserID:       user.ID,
+		ProposalText: stateData.ProposalText,
 	}
 
 	proposal, err := botService.ProposalService.CreateProposal(proposalReq)
@@ -275,16 +256,19 @@ func HandleProposalConfirmation(botService *services.BotService, callback *tgbot
 	// Clear state
 	_ = botService.StateManager.Clear(telegramID)
 
-	// Send success message
-	text := i18n.Get(i18n.MsgProposalSubmitted, lang)
-
-	// Check if user is admin to show appropriate keyboard
-	isAdmin, _ := botService.IsAdmin(user.PhoneNumber, user.TelegramID)
-	keyboard := utils.MakeMainMenuKeyboardForUser(lang, isAdmin)
-	_ = botService.TelegramService.SendMessage(chatID, text, keyboard)
+	// Turn the confirmation message into a progress message (this also removes its buttons)
+	messageID := callback.Message.MessageID
+	if err := botService.TelegramService.EditMessage(chatID, messageID, i18n.Get(i18n.MsgSubmissionProcessing, lang), nil); err != nil {
+		messageID = 0
+	}
 
-	// Notify admins with PDF document
-	go notifyAdminsWithProposalPDF(botService, user, proposal, fileID, len(stateData.Images))
+	// Queue PDF generation; the parent and admins are notified when it completes
+	_, err = botService.DocumentJobService.Enqueue(models.DocumentJobKindProposal, proposal.ID, chatID, messageID)
+	if err != nil {
+		log.Printf("Failed to queue proposal %d PDF: %v", proposal.ID, err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
 
 	return nil
 }
@@ -362,8 +346,9 @@ func notifyAdminsWithProposalPDF(botService *services.BotService, user *models.U
 		imageCount,
 	)
 
-	// Send document to all admins
-	err = botService.TelegramService.SendDocumentToAdmins(adminIDs, fileID, caption)
+	// Send document to all admins, with buttons for the Word version and the review actions
+	keyboard := utils.MakeAdminSubmissionKeyboard("proposal", proposal.ID)
+	err = botService.TelegramService.SendDocumentToAdmins(adminIDs, fileID, caption, keyboard)
 	if err != nil {
 		log.Printf("Failed to send document to admins: %v", err)
 	}
@@ -388,34 +373,16 @@ func HandleMyProposalsCommand(botService *services.BotService, message *tgbotapi
 
 	lang := i18n.GetLanguage(user.Language)
 
-	// Get user proposals
-	proposals, err := botService.ProposalService.GetUserProposals(user.ID, 10, 0)
+	text, keyboard, err := myProposalsPage(botService, user, 0)
 	if err != nil {
+		log.Printf("Failed to list proposals of user %d: %v", user.ID, err)
 		text := i18n.Get(i18n.ErrDatabaseError, lang)
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
-	if len(proposals) == 0 {
-		text := "Sizda hali takliflar yo'q / У вас пока нет предложений"
+	if keyboard == nil {
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
-	// Format proposals list
-	text := "📋 Sizning takliflaringiz / Ваши предложения:\n\n"
-	for i, p := range proposals {
-		status := "⏳"
-		if p.Status == models.ProposalStatusReviewed {
-			status = "✅"
-		}
-
-		preview := utils.TruncateText(p.ProposalText, 50)
-		text += fmt.Sprintf("%d. %s %s\n   📅 %s\n\n",
-			i+1,
-			status,
-			preview,
-			utils.FormatDateTime(p.CreatedAt),
-		)
-	}
-
-	return botService.TelegramService.SendMessage(chatID, text, nil)
+	return botService.TelegramService.SendMessage(chatID, text, keyboard)
 }
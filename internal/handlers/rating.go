@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+	"anor-kids/internal/validator"
+)
+
+// requestComplaintRating asks the author of a reviewed complaint to rate how it
+// was resolved. The rating is attributed to the staff member who reviewed it.
+func requestComplaintRating(botService *services.BotService, complaint *models.Complaint, from *tgbotapi.User) {
+	created, err := botService.ComplaintService.RequestRating(complaint.ID, from.ID, staffName(from))
+	if err != nil {
+		log.Printf("Failed to request rating of complaint %d: %v", complaint.ID, err)
+		return
+	}
+
+	if !created {
+		return
+	}
+
+	user, err := botService.UserRepo.GetByID(complaint.UserID)
+	if err != nil {
+		log.Printf("Failed to get author of complaint %d: %v", complaint.ID, err)
+		return
+	}
+
+	if user == nil {
+		return
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+	text := fmt.Sprintf(i18n.Get(i18n.MsgRateComplaint, lang), complaint.ID)
+	if err := botService.TelegramService.SendMessage(user.TelegramID, text, utils.MakeRatingKeyboard(complaint.ID)); err != nil {
+		log.Printf("Failed to ask rating of complaint %d: %v", complaint.ID, err)
+	}
+}
+
+// HandleRatingCallback stores a parent's rating of a reviewed complaint and offers to add a comment.
+// Format: rate_<id>_<rating> or rate_skip_<id>
+func HandleRatingCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	user, err := botService.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrNotRegistered, i18n.LanguageUzbek))
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+	action := strings.TrimPrefix(callback.Data, "rate_")
+
+	if strings.HasPrefix(action, "skip_") {
+		if state, _ := botService.StateManager.Get(telegramID); state != nil && state.State == models.StateAwaitingRatingComment {
+			_ = botService.StateManager.Clear(telegramID)
+		}
+
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+		text := fmt.Sprintf(i18n.Get(i18n.MsgRatingThanks, lang), "")
+		return botService.TelegramService.EditMessage(chatID, messageID, strings.TrimSpace(text), nil)
+	}
+
+	idPart, ratingPart, _ := strings.Cut(action, "_")
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	rating, err := strconv.Atoi(ratingPart)
+	if err != nil || rating < models.MinRating || rating > models.MaxRating {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid rating")
+	}
+
+	complaint, err := ownComplaint(botService, id, user)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	rated, err := botService.ComplaintService.RateComplaint(id, rating)
+	if err != nil {
+		log.Printf("Failed to rate complaint %d: %v", id, err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, i18n.Get(i18n.ErrDatabaseError, lang))
+	}
+
+	if !rated {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	log.Printf("Complaint #%d rated %d by its author", id, rating)
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	stars := strings.Repeat("⭐", rating)
+
+	// Don't interrupt a complaint or proposal the parent is in the middle of
+	state, err := botService.StateManager.Get(telegramID)
+	if err != nil || (state != nil && state.State != models.StateRegistered && state.State != models.StateAwaitingRatingComment) {
+		text := fmt.Sprintf(i18n.Get(i18n.MsgRatingThanks, lang), stars)
+		return botService.TelegramService.EditMessage(chatID, messageID, text, nil)
+	}
+
+	stateData := &models.StateData{
+		Language:    user.Language,
+		ComplaintID: id,
+	}
+	if err := botService.StateManager.Set(telegramID, models.StateAwaitingRatingComment, stateData); err != nil {
+		return err
+	}
+
+	keyboard := utils.MakeRatingCommentKeyboard(lang, id)
+	text := fmt.Sprintf(i18n.Get(i18n.MsgRatingSaved, lang), stars)
+	return botService.TelegramService.EditMessage(chatID, messageID, text, &keyboard)
+}
+
+// HandleRatingCommentInput stores the parent's comment on their rating. A main
+// menu button ends the comment and is handled as usual.
+func HandleRatingCommentInput(botService *services.BotService, message *tgbotapi.Message, stateData *models.StateData) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+	lang := i18n.GetLanguage(stateData.Language)
+
+	if message.Text == "/cancel" {
+		_ = botService.StateManager.Clear(telegramID)
+		text := fmt.Sprintf(i18n.Get(i18n.MsgRatingThanks, lang), "")
+		return botService.TelegramService.SendMessage(chatID, strings.TrimSpace(text), nil)
+	}
+
+	if isMainMenuButton(message.Text) {
+		_ = botService.StateManager.Clear(telegramID)
+
+		user, err := botService.UserService.GetUserByTelegramID(telegramID)
+		if err != nil {
+			return err
+		}
+		return HandleRegisteredUserMessage(botService, message, user)
+	}
+
+	comment, err := validator.ValidateRatingComment(message.Text)
+	if err != nil {
+		text := "❌ " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	_ = botService.StateManager.Clear(telegramID)
+
+	if _, err := botService.ComplaintService.CommentRating(stateData.ComplaintID, comment); err != nil {
+		log.Printf("Failed to comment rating of complaint %d: %v", stateData.ComplaintID, err)
+		text := i18n.Get(i18n.ErrDatabaseError, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	text := i18n.Get(i18n.MsgRatingCommentSaved, lang)
+	return botService.TelegramService.SendMessage(chatID, text, nil)
+}
+
+// isMainMenuButton checks if the text is one of the parent's main menu buttons in either language
+func isMainMenuButton(text string) bool {
+	buttons := []string{
+		i18n.BtnSubmitComplaint,
+		i18n.BtnSubmitProposal,
+		i18n.BtnMyComplaints,
+		i18n.BtnMyProposals,
+		i18n.BtnSettings,
+		i18n.BtnAdminPanel,
+	}
+
+	for _, button := range buttons {
+		if text == i18n.Get(button, i18n.LanguageUzbek) || text == i18n.Get(button, i18n.LanguageRussian) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"anor-kids/internal/i18n"
@@ -51,19 +53,39 @@ func HandlePhoneNumber(botService *services.BotService, message *tgbotapi.Messag
 	chatID := message.Chat.ID
 	lang := i18n.GetLanguage(stateData.Language)
 
-	// Extract phone number
-	var phoneNumber string
-	if message.Contact != nil {
-		phoneNumber = message.Contact.PhoneNumber
-	} else {
-		phoneNumber = message.Text
+	// Only accept the sender's own contact shared through the button
+	phoneNumber, ok := ownContactPhone(message)
+	if !ok {
+		text := i18n.Get(i18n.ErrPhoneNotOwn, lang)
+		return botService.TelegramService.SendMessage(chatID, text, utils.MakePhoneKeyboard(lang))
 	}
 
 	// Validate phone number
 	validPhone, err := validator.ValidateUzbekPhone(phoneNumber)
 	if err != nil {
 		text := i18n.Get(i18n.ErrInvalidPhone, lang)
-		return botService.TelegramService.SendMessage(chatID, text, nil)
+		return botService.TelegramService.SendMessage(chatID, text, utils.MakePhoneKeyboard(lang))
+	}
+
+	// The phone is already registered from another telegram account
+	// (e.g. the parent reinstalled Telegram with a new account)
+	existing, err := botService.UserService.GetUserByPhoneNumber(validPhone)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.TelegramID != telegramID {
+		return restoreAccount(botService, message, existing, lang)
+	}
+
+	// Parents pre-registered from a class roster skip the child and class questions
+	entry, err := botService.RosterService.Lookup(validPhone)
+	if err != nil {
+		return err
+	}
+
+	if entry != nil {
+		return registerFromRoster(botService, message, entry, stateData.Language, lang)
 	}
 
 	// Update state with phone number
@@ -172,36 +194,7 @@ func HandleClassSelection(botService *services.BotService, callback *tgbotapi.Ca
 		Language:         stateData.Language,
 	}
 
-	user, err := botService.UserService.CreateUser(userReq)
-	if err != nil {
-		text := i18n.Get(i18n.ErrDatabaseError, lang)
-		return botService.TelegramService.SendMessage(chatID, text, nil)
-	}
-
-	// Update state to registered
-	err = botService.StateManager.Clear(telegramID)
-	if err != nil {
-		return err
-	}
-
-	// Send registration complete message
-	text := i18n.Get(i18n.MsgRegistrationComplete, lang)
-	text = fmt.Sprintf(text, user.ChildName, user.ChildClass, user.PhoneNumber)
-	text = utils.EscapeMarkdown(text)
-
-	// Link admin telegram ID if this user is an admin
-	// This links the admin's telegram_id to their admin record for faster future checks
-	_ = botService.AdminRepo.UpdateTelegramID(user.PhoneNumber, user.TelegramID)
-
-	// Check if user is admin to show appropriate keyboard
-	isAdmin, _ := botService.IsAdmin(user.PhoneNumber, user.TelegramID)
-	keyboard := utils.MakeMainMenuKeyboardForUser(lang, isAdmin)
-
-	return botService.TelegramService.SendMessage(
-		chatID,
-		text,
-		keyboard,
-	)
+	return finishRegistration(botService, chatID, userReq, lang)
 }
 
 // HandleChildClass handles child class input and completes registration
@@ -238,34 +231,88 @@ func HandleChildClass(botService *services.BotService, message *tgbotapi.Message
 		Language:         stateData.Language,
 	}
 
+	return finishRegistration(botService, chatID, userReq, lang)
+}
+
+// registerFromRoster registers a parent with the child and class from their
+// roster entry. An admin prepared the roster, so there is nothing to approve.
+func registerFromRoster(botService *services.BotService, message *tgbotapi.Message, entry *models.RosterEntry, language string, lang i18n.Language) error {
+	chatID := message.Chat.ID
+
+	text := fmt.Sprintf(i18n.Get(i18n.MsgPhoneReceived, lang), entry.PhoneNumber)
+	_ = botService.TelegramService.SendMessage(chatID, text, utils.RemoveKeyboard())
+
+	userReq := &models.CreateUserRequest{
+		TelegramID:       message.From.ID,
+		TelegramUsername: message.From.UserName,
+		PhoneNumber:      entry.PhoneNumber,
+		ChildName:        entry.ChildName,
+		ChildClass:       entry.ChildClass,
+		Language:         language,
+		ApprovalStatus:   models.ApprovalApproved,
+	}
+
+	err := finishRegistration(botService, chatID, userReq, lang)
+
+	// The entry is used up once the account exists
+	if user, _ := botService.UserService.GetUserByTelegramID(message.From.ID); user != nil {
+		if claimErr := botService.RosterService.Claim(entry); claimErr != nil {
+			log.Printf("Failed to remove claimed roster entry %d: %v", entry.ID, claimErr)
+		}
+	}
+
+	return err
+}
+
+// finishRegistration creates the user and either completes registration or,
+// when approval is required, queues it for the class teacher and admins
+func finishRegistration(botService *services.BotService, chatID int64, userReq *models.CreateUserRequest, lang i18n.Language) error {
+	// Link admin telegram ID if this user is an admin
+	// This links the admin's telegram_id to their admin record for faster future checks
+	_ = botService.AdminRepo.UpdateTelegramID(userReq.PhoneNumber, userReq.TelegramID)
+
+	// Admins and pre-approved parents never wait for approval
+	isAdmin, _ := botService.IsAdmin(userReq.PhoneNumber, userReq.TelegramID)
+	needsApproval := botService.Config.Registration.RequireApproval && !isAdmin &&
+		userReq.ApprovalStatus != models.ApprovalApproved
+	if needsApproval {
+		userReq.ApprovalStatus = models.ApprovalPending
+	}
+
 	user, err := botService.UserService.CreateUser(userReq)
+	if errors.Is(err, services.ErrPhoneNumberTaken) {
+		_ = botService.StateManager.Clear(userReq.TelegramID)
+		text := i18n.Get(i18n.ErrPhoneTaken, lang)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
 	if err != nil {
 		text := i18n.Get(i18n.ErrDatabaseError, lang)
 		return botService.TelegramService.SendMessage(chatID, text, nil)
 	}
 
 	// Update state to registered
-	err = botService.StateManager.Clear(telegramID)
+	err = botService.StateManager.Clear(user.TelegramID)
 	if err != nil {
 		return err
 	}
 
-	// Send registration complete message
-	text := i18n.Get(i18n.MsgRegistrationComplete, lang)
+	messageKey := i18n.MsgRegistrationComplete
+	if needsApproval {
+		messageKey = i18n.MsgRegistrationPending
+	}
+
+	// Send registration complete (or pending) message
+	text := i18n.Get(messageKey, lang)
 	text = fmt.Sprintf(text, user.ChildName, user.ChildClass, user.PhoneNumber)
 	text = utils.EscapeMarkdown(text)
 
-	// Link admin telegram ID if this user is an admin
-	// This links the admin's telegram_id to their admin record for faster future checks
-	_ = botService.AdminRepo.UpdateTelegramID(user.PhoneNumber, user.TelegramID)
-
-	// Check if user is admin to show appropriate keyboard
-	isAdmin, _ := botService.IsAdmin(user.PhoneNumber, user.TelegramID)
 	keyboard := utils.MakeMainMenuKeyboardForUser(lang, isAdmin)
+	err = botService.TelegramService.SendMessage(chatID, text, keyboard)
+
+	if needsApproval {
+		go notifyRegistrationReviewers(botService, user)
+	}
 
-	return botService.TelegramService.SendMessage(
-		chatID,
-		text,
-		keyboard,
-	)
+	return err
 }
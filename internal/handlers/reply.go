@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+	"anor-kids/internal/validator"
+)
+
+// HandleComplaintReplyCallback asks an admin for a reply to the author of a complaint.
+// Format: reply_complaint_<id>
+func HandleComplaintReplyCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "reply_complaint_"))
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	complaint, err := botService.ComplaintService.GetComplaintByID(id)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	stateData := &models.StateData{ComplaintID: complaint.ID}
+	if err := botService.StateManager.Set(telegramID, models.StateAwaitingComplaintReply, stateData); err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	text := fmt.Sprintf("💬 <b>Shikoyat #%d uchun javob / Ответ на жалобу #%d</b>\n\n", complaint.ID, complaint.ID)
+	text += "Ota-onaga yuboriladigan javobni yozing.\n"
+	text += "Напишите ответ, который получит родитель.\n\n"
+	if complaint.IsAnonymous {
+		text += "🕶 Shikoyat anonim: javob bot orqali yetkaziladi, muallif oshkor qilinmaydi.\n"
+		text += "🕶 Жалоба анонимная: ответ будет передан через бота, автор не раскрывается.\n\n"
+	}
+	text += "Yoki /cancel bekor qilish uchun"
+	return botService.TelegramService.SendMessage(chatID, text, nil)
+}
+
+// HandleComplaintReplyInput relays an admin's reply to the author of a complaint
+// through the bot, so the admin never learns who the parent is
+func HandleComplaintReplyInput(botService *services.BotService, message *tgbotapi.Message, stateData *models.StateData) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+
+	if message.Text == "/cancel" {
+		_ = botService.StateManager.Clear(telegramID)
+		text := "❌ Bekor qilindi / Отменено"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		_ = botService.StateManager.Clear(telegramID)
+		text := "❌ Faqat ma'murlar uchun / Только для администраторов"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	replyText, err := validator.ValidateReplyText(message.Text)
+	if err != nil {
+		text := "❌ " + err.Error() + "\n\nYoki /cancel bekor qilish uchun"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	_ = botService.StateManager.Clear(telegramID)
+
+	complaint, err := botService.ComplaintService.GetComplaintByID(stateData.ComplaintID)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		text := "❌ Shikoyat topilmadi / Жалоба не найдена"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	user, err := botService.UserRepo.GetByID(complaint.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		text := "❌ Ota-ona o'z akkauntini o'chirgan / Родитель удалил свой аккаунт"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	name := staffName(message.From)
+	if _, err := botService.ComplaintService.CreateComplaintReply(complaint.ID, telegramID, name, replyText); err != nil {
+		log.Printf("Failed to save reply to complaint %d: %v", complaint.ID, err)
+		text := "❌ Xatolik / Ошибка"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+	if name == "" {
+		name = i18n.Get(i18n.MsgComplaintStaffFallback, lang)
+	}
+
+	// The text was escaped by the validator
+	text := fmt.Sprintf(i18n.Get(i18n.MsgComplaintReply, lang), complaint.ID, replyText, utils.EscapeHTML(name))
+	if err := botService.TelegramService.SendMessage(user.TelegramID, text, nil); err != nil {
+		log.Printf("Failed to relay reply to complaint %d: %v", complaint.ID, err)
+		text := "⚠️ Javob saqlandi, lekin yetkazib bo'lmadi / Ответ сохранён, но не доставлен"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	log.Printf("Reply to complaint #%d relayed by %d", complaint.ID, telegramID)
+
+	text = fmt.Sprintf("✅ Javob yuborildi / Ответ отправлен (#%d)", complaint.ID)
+	return botService.TelegramService.SendMessage(chatID, text, nil)
+}
+
+// HandleRevealCommand handles /reveal <id> <reason>: shows a super-admin who
+// sent an anonymous complaint. Every reveal is recorded in the audit log and
+// reported to the other super-admins.
+func HandleRevealCommand(botService *services.BotService, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	isSuperAdmin, err := botService.IsSuperAdmin(message.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isSuperAdmin {
+		text := "❌ Bu buyruq faqat bosh ma'murlar uchun / Эта команда только для главных администраторов"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	idPart, reason, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	reason = strings.TrimSpace(reason)
+
+	id, err := strconv.Atoi(strings.TrimPrefix(idPart, "#"))
+	if err != nil || reason == "" {
+		text := "🔓 <b>Anonim muallifni aniqlash / Раскрытие анонимного автора</b>\n\n" +
+			"<code>/reveal 123 sabab</code>\n" +
+			"<code>/reveal 123 причина</code>\n\n" +
+			"Sabab majburiy va jurnalga yoziladi.\n" +
+			"Причина обязательна и записывается в журнал."
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	complaint, err := botService.ComplaintService.GetComplaintByID(id)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		text := "❌ Shikoyat topilmadi / Жалоба не найдена"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	if !complaint.IsAnonymous {
+		text := "ℹ️ Bu shikoyat anonim emas / Эта жалоба не анонимная"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	user, err := botService.ComplaintService.RevealAuthor(complaint, message.From.ID, reason)
+	if err != nil {
+		log.Printf("Failed to reveal author of complaint %d: %v", id, err)
+		text := "❌ Xatolik / Ошибка"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	log.Printf("🔓 Author of anonymous complaint #%d revealed by %d", id, message.From.ID)
+	notifySuperAdminsReveal(botService, complaint.ID, message.From, reason)
+
+	if user == nil {
+		text := "ℹ️ Ota-ona o'z akkauntini o'chirgan / Родитель удалил свой аккаунт"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	username := user.TelegramUsername
+	if username == "" {
+		username = "yo'q / нет"
+	}
+
+	text := fmt.Sprintf(
+		"🔓 <b>Shikoyat #%d muallifi / Автор жалобы #%d</b>\n\n"+
+			"Farzand / Ребенок: <b>%s</b>\n"+
+			"Sinf / Класс: <b>%s</b>\n"+
+			"Telefon / Телефон: %s\n"+
+			"Username: @%s\n"+
+			"Telegram ID: <code>%d</code>\n\n"+
+			"Bu ko'rish jurnalga yozildi / Этот просмотр записан в журнал",
+		complaint.ID,
+		complaint.ID,
+		utils.EscapeHTML(user.ChildName),
+		utils.EscapeHTML(user.ChildClass),
+		utils.FormatPhoneNumber(user.PhoneNumber),
+		utils.EscapeHTML(username),
+		user.TelegramID,
+	)
+	return botService.TelegramService.SendMessage(chatID, text, nil)
+}
+
+// notifySuperAdminsReveal tells the other super-admins that an anonymous author was revealed
+func notifySuperAdminsReveal(botService *services.BotService, complaintID int, from *tgbotapi.User, reason string) {
+	superAdminIDs, err := botService.GetSuperAdminTelegramIDs()
+	if err != nil {
+		log.Printf("Failed to get super-admin IDs: %v", err)
+		return
+	}
+
+	var others []int64
+	for _, id := range superAdminIDs {
+		if id != from.ID {
+			others = append(others, id)
+		}
+	}
+
+	if len(others) == 0 {
+		return
+	}
+
+	text := fmt.Sprintf(
+		"🔓 <b>Anonim muallif aniqlandi / Анонимный автор раскрыт</b>\n\n"+
+			"Shikoyat / Жалоба: #%d\n"+
+			"Kim / Кто: %s (ID %d)\n"+
+			"Sabab / Причина: %s",
+		complaintID,
+		utils.EscapeHTML(staffName(from)),
+		from.ID,
+		utils.EscapeHTML(reason),
+	)
+	_ = botService.TelegramService.NotifyAdmins(others, text)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+)
+
+// reportHours are the send hours offered to admins
+var reportHours = []int{7, services.DefaultReportHour, 9, 13, 18}
+
+// HandleAdminReportsCallback shows the admin's report subscription
+func HandleAdminReportsCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	admin, err := botService.AdminRepo.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if admin == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	sub, err := botService.ReportService.Subscription(admin.ID)
+	if err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	keyboard := makeReportSettingsKeyboard(sub)
+	return botService.TelegramService.SendMessage(callback.Message.Chat.ID, formatReportSettings(botService, sub), keyboard)
+}
+
+// HandleReportSettingsCallback changes the admin's report subscription or sends a report now.
+// Format: report_f_<weekly|monthly|off>, report_h_<hour>, report_now
+func HandleReportSettingsCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	admin, err := botService.AdminRepo.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if admin == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	sub, err := botService.ReportService.Subscription(admin.ID)
+	if err != nil {
+		return err
+	}
+
+	frequency, hour := models.ReportWeekly, services.DefaultReportHour
+	if sub != nil {
+		frequency, hour = sub.Frequency, sub.SendHour
+	}
+
+	action, value, _ := strings.Cut(strings.TrimPrefix(callback.Data, "report_"), "_")
+
+	switch action {
+	case "now":
+		_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "⏳")
+		if err := botService.ReportService.Send(chatID, frequency, time.Now()); err != nil {
+			log.Printf("Failed to send %s report: %v", frequency, err)
+			return botService.TelegramService.SendMessage(chatID, "❌ Hisobotni tayyorlab bo'lmadi / Не удалось подготовить отчёт", nil)
+		}
+		return nil
+
+	case "f":
+		if value == "off" {
+			err = botService.ReportService.Unsubscribe(admin.ID)
+			break
+		}
+		if !services.IsReportFrequency(value) {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+		}
+		err = botService.ReportService.Subscribe(admin.ID, value, hour)
+
+	case "h":
+		hour, convErr := strconv.Atoi(value)
+		if convErr != nil || !slices.Contains(reportHours, hour) {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+		}
+		err = botService.ReportService.Subscribe(admin.ID, frequency, hour)
+
+	default:
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	if err != nil {
+		log.Printf("Failed to update report subscription: %v", err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Xatolik / Ошибка")
+	}
+
+	if sub, err = botService.ReportService.Subscription(admin.ID); err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "✅")
+	keyboard := makeReportSettingsKeyboard(sub)
+	return botService.TelegramService.EditMessage(chatID, messageID, formatReportSettings(botService, sub), &keyboard)
+}
+
+// formatReportSettings describes the reports and the admin's current schedule
+func formatReportSettings(botService *services.BotService, sub *models.ReportSubscription) string {
+	days := botService.ReportService.StaleAfterDays()
+
+	text := "📬 <b>Hisobotlar / Отчёты</b>\n\n"
+	text += fmt.Sprintf("PDF hisobot: murojaatlar holat va guruhlar bo'yicha, %d kundan ortiq hal qilinmaganlar, "+
+		"yangi ota-onalar va e'lonlar qamrovi.\n", days)
+	text += fmt.Sprintf("PDF-отчёт: обращения по статусам и группам, нерешённые старше %d дней, "+
+		"новые родители и охват объявлений.\n\n", days)
+
+	switch {
+	case sub == nil:
+		text += "🔕 Obuna yo'q / Подписки нет"
+	case sub.Frequency == models.ReportMonthly:
+		text += fmt.Sprintf("✅ Har oyning 1-kuni %02d:00 da, o'tgan oy uchun\n"+
+			"✅ 1-го числа каждого месяца в %02d:00, за прошлый месяц", sub.SendHour, sub.SendHour)
+	default:
+		text += fmt.Sprintf("✅ Har dushanba %02d:00 da, o'tgan hafta uchun\n"+
+			"✅ Каждый понедельник в %02d:00, за прошлую неделю", sub.SendHour, sub.SendHour)
+	}
+
+	if sub != nil && sub.TelegramID == nil {
+		text += "\n\n⚠️ Telegram hisobingiz bog'lanmagan / Ваш Telegram не привязан"
+	}
+
+	return text
+}
+
+// makeReportSettingsKeyboard creates the report schedule keyboard; the current choice is ticked
+func makeReportSettingsKeyboard(sub *models.ReportSubscription) tgbotapi.InlineKeyboardMarkup {
+	tick := func(selected bool, label string) string {
+		if selected {
+			return "✅ " + label
+		}
+		return label
+	}
+
+	frequency, hour := "", 0
+	if sub != nil {
+		frequency, hour = sub.Frequency, sub.SendHour
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tick(frequency == models.ReportWeekly, "Haftalik / Еженедельно"), "report_f_"+models.ReportWeekly),
+			tgbotapi.NewInlineKeyboardButtonData(tick(frequency == models.ReportMonthly, "Oylik / Ежемесячно"), "report_f_"+models.ReportMonthly),
+		),
+	}
+
+	if sub != nil {
+		var hours []tgbotapi.InlineKeyboardButton
+		for _, h := range reportHours {
+			hours = append(hours, tgbotapi.NewInlineKeyboardButtonData(tick(h == hour, fmt.Sprintf("%02d:00", h)), fmt.Sprintf("report_h_%d", h)))
+		}
+		rows = append(rows, hours)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔕 Obunani bekor qilish / Отписаться", "report_f_off"),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📄 Hozir olish / Получить сейчас", "report_now"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
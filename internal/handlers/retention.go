@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// formatRetentionReport formats a retention run report for super-admins
+func formatRetentionReport(botService *services.BotService, report *models.RetentionReport) string {
+	cfg := botService.RetentionService.Config()
+
+	var text string
+	if report.DryRun {
+		text = "🧪 <b>SAQLASH SIYOSATI: SINOV / ПОЛИТИКА ХРАНЕНИЯ: ПРОБНЫЙ ЗАПУСК</b>\n\n"
+		text += "Hech narsa o'zgartirilmadi. Quyidagilar keyingi ishga tushirishda bajariladi.\n"
+		text += "Ничего не изменено. Следующее будет выполнено при следующем запуске.\n\n"
+	} else {
+		text = "🧹 <b>SAQLASH SIYOSATI / ПОЛИТИКА ХРАНЕНИЯ</b>\n\n"
+	}
+
+	if cfg.ArchiveAfterDays > 0 {
+		text += fmt.Sprintf("📦 Arxivlash / Архивация (%d kun / дн.):\n", cfg.ArchiveAfterDays)
+		text += fmt.Sprintf("   Shikoyatlar / Жалобы: %d\n", report.ArchivedComplaints)
+		text += fmt.Sprintf("   Takliflar / Предложения: %d\n", report.ArchivedProposals)
+	}
+
+	if cfg.PurgeAfterMonths > 0 {
+		text += fmt.Sprintf("🗑 Matn va rasmlarni o'chirish / Удаление текста и изображений (%d oy / мес.):\n", cfg.PurgeAfterMonths)
+		text += fmt.Sprintf("   Shikoyatlar / Жалобы: %d\n", report.PurgedComplaints)
+		text += fmt.Sprintf("   Takliflar / Предложения: %d\n", report.PurgedProposals)
+	}
+
+	if cfg.InactiveUserMonths > 0 {
+		text += fmt.Sprintf("👤 Faol bo'lmagan foydalanuvchilar / Неактивные пользователи (%d oy / мес.): %d\n", cfg.InactiveUserMonths, report.DeletedUsers)
+	}
+
+	text += fmt.Sprintf("\n🕐 %s", utils.FormatDateTime(report.RunAt))
+
+	return text
+}
+
+// SendRetentionReport sends a retention run report to all linked super-admins
+func SendRetentionReport(botService *services.BotService, report *models.RetentionReport) {
+	superAdminIDs, err := botService.GetSuperAdminTelegramIDs()
+	if err != nil {
+		log.Printf("Failed to get super-admin IDs: %v", err)
+		return
+	}
+
+	if len(superAdminIDs) == 0 {
+		log.Println("No linked super-admins to send retention report to")
+		return
+	}
+
+	_ = botService.TelegramService.NotifyAdmins(superAdminIDs, formatRetentionReport(botService, report))
+}
+
+// HandleRetentionReportCommand handles /retention_report command - shows a dry run on demand
+func HandleRetentionReportCommand(botService *services.BotService, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	isSuperAdmin, err := botService.IsSuperAdmin(message.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isSuperAdmin {
+		text := "❌ Bu buyruq faqat bosh ma'murlar uchun / Эта команда только для главных администраторов"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	if !botService.RetentionService.Enabled() {
+		text := "ℹ️ Saqlash siyosati sozlanmagan / Политика хранения не настроена\n\n"
+		text += "RETENTION_ARCHIVE_DAYS, RETENTION_PURGE_MONTHS, RETENTION_INACTIVE_USER_MONTHS"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	report, err := botService.RetentionService.DryRun()
+	if err != nil {
+		text := "❌ Xatolik / Ошибка: " + err.Error()
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	return botService.TelegramService.SendMessage(chatID, formatRetentionReport(botService, report), nil)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+)
+
+// HandleReviewActionCallback takes a complaint or proposal in hand or marks it reviewed.
+// Admins can act on any item, teachers on the items of their classes.
+// Format: review_ack_<kind>_<id> or review_done_<kind>_<id>
+func HandleReviewActionCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(strings.TrimPrefix(callback.Data, "review_"), "_")
+	if len(parts) != 3 || (parts[0] != "ack" && parts[0] != "done") {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+	action, kind := parts[0], parts[1]
+
+	id, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	var userID int
+	var status string
+	var complaint *models.Complaint
+
+	switch kind {
+	case models.DocumentJobKindComplaint:
+		complaint, err = botService.ComplaintService.GetComplaintByID(id)
+		if err != nil {
+			return err
+		}
+		if complaint == nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+		}
+		userID, status = complaint.UserID, complaint.Status
+
+	case models.DocumentJobKindProposal:
+		proposal, err := botService.ProposalService.GetProposalByID(id)
+		if err != nil {
+			return err
+		}
+		if proposal == nil {
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Taklif topilmadi / Предложение не найдено")
+		}
+		userID, status = proposal.UserID, proposal.Status
+
+	default:
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid data")
+	}
+
+	allowed, err := canActOnSubmission(botService, callback.From.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat guruh tarbiyachisi yoki ma'murlar uchun / Только для воспитателя группы или администраторов")
+	}
+
+	if status == models.StatusWithdrawn {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "↩️ Ota-ona shikoyatni qaytarib olgan / Родитель отозвал жалобу")
+	}
+
+	if status != models.StatusPending {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "ℹ️ Allaqachon ko'rib chiqilgan / Уже рассмотрено")
+	}
+
+	// Taking an urgent complaint in hand also stops its alerts
+	if complaint != nil {
+		if _, err := acknowledgeUrgentComplaint(botService, complaint, callback.From); err != nil {
+			log.Printf("Failed to acknowledge urgent complaint %d: %v", id, err)
+		}
+	}
+
+	if action == "ack" {
+		if _, err := botService.SLAService.Acknowledge(kind, id, time.Now()); err != nil {
+			log.Printf("Failed to acknowledge %s %d: %v", kind, id, err)
+			return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Xatolik / Ошибка")
+		}
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, fmt.Sprintf("👀 #%d qabul qilindi / #%d принято", id, id))
+	}
+
+	updated := true
+	if kind == models.DocumentJobKindComplaint {
+		updated, err = botService.ComplaintService.UpdateComplaintStatus(id, complaint.Version, models.StatusReviewed)
+	} else {
+		err = botService.ProposalService.UpdateProposalStatus(id, models.ProposalStatusReviewed)
+	}
+	if err != nil {
+		log.Printf("Failed to mark %s %d reviewed: %v", kind, id, err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Xatolik / Ошибка")
+	}
+
+	// The parent may have withdrawn or corrected the complaint since it was read
+	if !updated {
+		return answerComplaintChanged(botService, callback, id)
+	}
+
+	log.Printf("%s #%d marked reviewed by %d", kind, id, callback.From.ID)
+
+	// Ask the parent how well the complaint was resolved
+	if complaint != nil {
+		requestComplaintRating(botService, complaint, callback.From)
+	}
+	return botService.TelegramService.AnswerCallbackQuery(callback.ID, fmt.Sprintf("✅ #%d ko'rib chiqildi / #%d рассмотрено", id, id))
+}
+
+// answerComplaintChanged tells the staff member why a complaint they tried to
+// mark reviewed was left as is
+func answerComplaintChanged(botService *services.BotService, callback *tgbotapi.CallbackQuery, id int) error {
+	complaint, err := botService.ComplaintService.GetComplaintByID(id)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case complaint == nil:
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	case complaint.Status == models.StatusWithdrawn:
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "↩️ Ota-ona shikoyatni qaytarib olgan / Родитель отозвал жалобу")
+	case complaint.Status == models.StatusPending:
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "✏️ Ota-ona matnni tuzatdi, qaytadan ko'ring / Родитель исправил текст, посмотрите снова")
+	default:
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "ℹ️ Allaqachon ko'rib chiqilgan / Уже рассмотрено")
+	}
+}
+
+// canActOnSubmission checks if the telegram user may act on a parent's
+// submission: admins always, teachers for their own classes
+func canActOnSubmission(botService *services.BotService, telegramID int64, userID int) (bool, error) {
+	user, err := botService.UserRepo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	if user == nil {
+		// The parent has been deleted, only admins know the item
+		return botService.IsAdmin("", telegramID)
+	}
+
+	return botService.CanReviewRegistration(telegramID, user)
+}
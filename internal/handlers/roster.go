@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// rosterReportMaxErrors is how many invalid rows the import report lists
+const rosterReportMaxErrors = 20
+
+// HandleAdminImportRosterCallback asks an admin for a class roster file
+func HandleAdminImportRosterCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	telegramID := callback.From.ID
+	chatID := callback.Message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat ma'murlar uchun / Только для администраторов")
+	}
+
+	if err := botService.StateManager.Set(telegramID, models.StateAwaitingRosterFile, &models.StateData{}); err != nil {
+		return err
+	}
+
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+
+	text := "📥 <b>Ro'yxatni import qilish / Импорт списка</b>\n\n" +
+		"CSV yoki XLSX fayl yuboring. Ustunlar: telefon, bola ismi, guruh.\n" +
+		"Отправьте файл CSV или XLSX. Столбцы: телефон, имя ребёнка, группа.\n\n" +
+		"<code>Telefon;Bola;Guruh\n" +
+		"+998901234567;Aliyeva Malika;9A\n" +
+		";;10B</code>\n\n" +
+		"• Yo'q guruhlar yaratiladi / Недостающие группы будут созданы\n" +
+		"• Faqat guruh yozilgan qator guruhni yaratadi / Строка только с группой создаёт группу\n" +
+		"• Ro'yxatdagi ota-ona telefonini yuborganda bola va guruh avtomatik to'ldiriladi\n" +
+		"• Когда родитель из списка отправит свой номер, ребёнок и группа заполнятся автоматически"
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Bekor qilish / Отменить", "roster_cancel"),
+		),
+	)
+
+	return botService.TelegramService.SendMessage(chatID, text, keyboard)
+}
+
+// HandleRosterCancelCallback leaves the roster import
+func HandleRosterCancelCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	_ = botService.StateManager.Clear(callback.From.ID)
+	_ = botService.TelegramService.AnswerCallbackQuery(callback.ID, "")
+	return botService.TelegramService.EditMessage(callback.Message.Chat.ID, callback.Message.MessageID, "❌ Bekor qilindi / Отменено", nil)
+}
+
+// HandleRosterFileInput imports the roster file sent by an admin and replies with the report
+func HandleRosterFileInput(botService *services.BotService, message *tgbotapi.Message) error {
+	telegramID := message.From.ID
+	chatID := message.Chat.ID
+
+	isAdmin, err := botService.IsAdmin("", telegramID)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		_ = botService.StateManager.Clear(telegramID)
+		return botService.TelegramService.SendMessage(chatID, "❌ Faqat ma'murlar uchun / Только для администраторов", nil)
+	}
+
+	doc := message.Document
+	if doc == nil {
+		text := "📎 CSV yoki XLSX faylni hujjat sifatida yuboring.\n" +
+			"📎 Отправьте файл CSV или XLSX как документ."
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	if doc.FileSize > services.RosterMaxFileSize {
+		text := fmt.Sprintf("❌ Fayl juda katta (maksimal %d MB) / Файл слишком большой (максимум %d MB)",
+			services.RosterMaxFileSize>>20, services.RosterMaxFileSize>>20)
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	// No FileUniqueID, so the roster with parents' phones is not kept in the download cache
+	data, err := botService.DownloadManager.Fetch(models.ImageData{FileID: doc.FileID, FileSize: doc.FileSize})
+	if err != nil {
+		log.Printf("Failed to download roster: %v", err)
+		return botService.TelegramService.SendMessage(chatID, "❌ Faylni yuklab bo'lmadi / Не удалось загрузить файл", nil)
+	}
+
+	report, err := botService.RosterService.Import(data)
+	if errors.Is(err, services.ErrRosterUnreadable) || errors.Is(err, services.ErrRosterTooLarge) {
+		log.Printf("Rejected roster %q: %v", doc.FileName, err)
+		text := "❌ Faylni o'qib bo'lmadi. CSV yoki XLSX jadval yuboring, guruh ustuni bo'lishi kerak (5000 qatorgacha).\n" +
+			"❌ Не удалось прочитать файл. Отправьте таблицу CSV или XLSX со столбцом группы (до 5000 строк)."
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+	if err != nil {
+		log.Printf("Failed to import roster: %v", err)
+		return botService.TelegramService.SendMessage(chatID, "❌ Xatolik / Ошибка", nil)
+	}
+
+	_ = botService.StateManager.Clear(telegramID)
+
+	pending, err := botService.RosterService.Pending()
+	if err != nil {
+		log.Printf("Failed to count roster entries: %v", err)
+	}
+
+	return botService.TelegramService.SendMessage(chatID, formatRosterReport(report, pending), nil)
+}
+
+// formatRosterReport formats the outcome of a roster import
+func formatRosterReport(report *services.RosterImportReport, pending int) string {
+	var b strings.Builder
+
+	b.WriteString("✅ <b>Import yakunlandi / Импорт завершён</b>\n\n")
+
+	fmt.Fprintf(&b, "📚 Yangi guruhlar / Новые группы: %d", len(report.ClassesCreated))
+	if len(report.ClassesCreated) > 0 {
+		fmt.Fprintf(&b, " (%s)", utils.EscapeHTML(strings.Join(report.ClassesCreated, ", ")))
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "👤 Qo'shildi / Добавлено: %d\n", report.Added)
+	fmt.Fprintf(&b, "🔄 Yangilandi / Обновлено: %d\n", report.Updated)
+	fmt.Fprintf(&b, "☑️ Allaqachon ro'yxatdan o'tgan / Уже зарегистрированы: %d\n", report.Registered)
+	fmt.Fprintf(&b, "⏳ Ro'yxatdan o'tishi kutilmoqda / Ожидают регистрации: %d\n", pending)
+
+	if len(report.InactiveClasses) > 0 {
+		fmt.Fprintf(&b, "\n⚠️ Faol emas guruhlar, ularni yoqmaguncha ota-onalar ro'yxatdan o'ta olmaydi / "+
+			"Неактивные группы, родители не смогут зарегистрироваться, пока их не включить: %s\n",
+			utils.EscapeHTML(strings.Join(report.InactiveClasses, ", ")))
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(&b, "\n❌ <b>Xato qatorlar / Ошибочные строки: %d</b>\n", len(report.Errors))
+		for i, rowErr := range report.Errors {
+			if i == rosterReportMaxErrors {
+				rest := len(report.Errors) - rosterReportMaxErrors
+				fmt.Fprintf(&b, "... va yana %d / и ещё %d\n", rest, rest)
+				break
+			}
+			fmt.Fprintf(&b, "%d: %s\n", rowErr.Row, utils.EscapeHTML(rowErr.Reason))
+		}
+	}
+
+	return b.String()
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"strings"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"anor-kids/internal/i18n"
 	"anor-kids/internal/models"
@@ -24,12 +26,16 @@ func RouteByState(botService *services.BotService, message *tgbotapi.Message, st
 	case models.StateAwaitingChildClass:
 		return HandleChildClass(botService, message, stateData)
 
+	case models.StateAwaitingComplaintCategory:
+		// Waiting for the category (handled by callback), show the choice again
+		return HandleComplaintCommand(botService, message)
+
 	case models.StateAwaitingComplaint:
 		return HandleComplaintText(botService, message, stateData)
 
 	case models.StateAwaitingImages:
-		// Handle photo uploads
-		if message.Photo != nil && len(message.Photo) > 0 {
+		// Handle photo and image document uploads
+		if _, ok := imageFromMessage(message); ok {
 			return HandleImage(botService, message, stateData)
 		}
 		// If not a photo, remind user
@@ -47,8 +53,8 @@ func RouteByState(botService *services.BotService, message *tgbotapi.Message, st
 		return HandleProposalText(botService, message, stateData)
 
 	case models.StateAwaitingProposalImages:
-		// Handle photo uploads for proposal
-		if message.Photo != nil && len(message.Photo) > 0 {
+		// Handle photo and image document uploads for proposal
+		if _, ok := imageFromMessage(message); ok {
 			return HandleProposalImage(botService, message, stateData)
 		}
 		// If not a photo, remind user
@@ -62,12 +68,30 @@ func RouteByState(botService *services.BotService, message *tgbotapi.Message, st
 		// Waiting for confirmation (handled by callback)
 		return nil
 
+	case models.StateAwaitingNewPhone:
+		return HandleNewPhoneNumber(botService, message, stateData)
+
 	case models.StateAwaitingAdminPhone:
 		return HandleAdminLinkPhone(botService, message)
 
 	case models.StateAwaitingClassName:
 		return HandleClassNameInput(botService, message)
 
+	case models.StateAwaitingCategoryName:
+		return HandleCategoryNameInput(botService, message, stateData)
+
+	case models.StateAwaitingComplaintReply:
+		return HandleComplaintReplyInput(botService, message, stateData)
+
+	case models.StateAwaitingComplaintEdit:
+		return HandleComplaintEditInput(botService, message, stateData)
+
+	case models.StateAwaitingRatingComment:
+		return HandleRatingCommentInput(botService, message, stateData)
+
+	case models.StateAwaitingRosterFile:
+		return HandleRosterFileInput(botService, message)
+
 	case models.StateAwaitingAnnouncementTitle:
 		return HandleAnnouncementTitle(botService, message, stateData)
 
@@ -86,7 +110,12 @@ func RouteByState(botService *services.BotService, message *tgbotapi.Message, st
 		text := "📸 Iltimos, rasm yuboring.\n\n📸 Пожалуйста, отправьте изображение."
 		return botService.TelegramService.SendMessage(message.Chat.ID, text, nil)
 
-	case models.StateRegistered:
+	case models.StateRegistered, models.StateConfirmingAccountDeletion:
+		// Any other message cancels a pending account deletion
+		if state == models.StateConfirmingAccountDeletion {
+			_ = botService.StateManager.Clear(message.From.ID)
+		}
+
 		// User is registered, get user data
 		user, err := botService.UserService.GetUserByTelegramID(message.From.ID)
 		if err != nil {
@@ -195,6 +224,37 @@ func HandleCallbackQuery(botService *services.BotService, callback *tgbotapi.Cal
 		return HandleClassSelection(botService, callback)
 	}
 
+	// Settings callbacks
+	if data == "settings_change_phone" {
+		return HandleChangePhoneCallback(botService, callback)
+	}
+
+	if data == "settings_export_data" {
+		return HandleExportDataCallback(botService, callback)
+	}
+
+	if data == "settings_delete_account" {
+		return HandleDeleteAccountCallback(botService, callback)
+	}
+
+	// Account deletion confirmation callbacks
+	if data == "delete_account_confirm" {
+		return HandleDeleteAccountConfirmCallback(botService, callback)
+	}
+
+	if data == "delete_account_final" {
+		return HandleDeleteAccountFinalCallback(botService, callback)
+	}
+
+	if data == "delete_account_cancel" {
+		return HandleDeleteAccountCancelCallback(botService, callback)
+	}
+
+	// Complaint category selection (complaint_cat_<id>)
+	if strings.HasPrefix(data, "complaint_cat_") {
+		return HandleComplaintCategorySelection(botService, callback)
+	}
+
 	// Complaint confirmation
 	if data == "confirm_complaint" {
 		return HandleComplaintConfirmation(botService, callback)
@@ -246,28 +306,142 @@ func HandleCallbackQuery(botService *services.BotService, callback *tgbotapi.Cal
 		return HandleAdminUsersCallback(botService, callback)
 	}
 
-	if data == "admin_complaints" {
+	if data == "admin_pending_users" {
+		return HandleAdminPendingUsersCallback(botService, callback)
+	}
+
+	// Registration review callbacks (reg_approve_<id>, reg_reject_<id>)
+	if strings.HasPrefix(data, "reg_approve_") || strings.HasPrefix(data, "reg_reject_") {
+		return HandleRegistrationReviewCallback(botService, callback)
+	}
+
+	// Word version of a complaint or proposal (docx_complaint_<id>, docx_proposal_<id>)
+	if strings.HasPrefix(data, "docx_") {
+		return HandleDocxDownloadCallback(botService, callback)
+	}
+
+	// Urgent complaint marking and acknowledgement (urgent_toggle, urgent_ack_<id>)
+	if data == "urgent_toggle" {
+		return HandleUrgentToggle(botService, callback)
+	}
+
+	if strings.HasPrefix(data, "urgent_ack_") {
+		return HandleUrgentAckCallback(botService, callback)
+	}
+
+	// Anonymous complaint toggle
+	if data == "anonymous_toggle" {
+		return HandleAnonymousToggle(botService, callback)
+	}
+
+	// Parent's own complaints and proposals: paging (my_complaints_page_<page>,
+	// my_proposals_page_<page>) and opening one (my_complaint_<id>_<page>, my_proposal_<id>_<page>)
+	if strings.HasPrefix(data, "my_complaints_page_") || strings.HasPrefix(data, "my_proposals_page_") {
+		return HandleMySubmissionsPageCallback(botService, callback)
+	}
+
+	if strings.HasPrefix(data, "my_complaint_") {
+		return HandleMyComplaintCallback(botService, callback)
+	}
+
+	if strings.HasPrefix(data, "my_proposal_") {
+		return HandleMyProposalCallback(botService, callback)
+	}
+
+	// Parent withdrawing or correcting a pending complaint
+	// (complaint_withdraw_<id>, complaint_withdraw_yes_<id>, complaint_withdraw_no, complaint_edit_<id>)
+	if strings.HasPrefix(data, "complaint_withdraw_") {
+		return HandleComplaintWithdrawCallback(botService, callback)
+	}
+
+	if strings.HasPrefix(data, "complaint_edit_") {
+		return HandleComplaintEditCallback(botService, callback)
+	}
+
+	// Parent rating a reviewed complaint (rate_<id>_<rating>, rate_skip_<id>)
+	if strings.HasPrefix(data, "rate_") {
+		return HandleRatingCallback(botService, callback)
+	}
+
+	// Staff reply relayed to the author of a complaint (reply_complaint_<id>)
+	if strings.HasPrefix(data, "reply_complaint_") {
+		return HandleComplaintReplyCallback(botService, callback)
+	}
+
+	// Review actions on a complaint or proposal (review_ack_<kind>_<id>, review_done_<kind>_<id>)
+	if strings.HasPrefix(data, "review_") {
+		return HandleReviewActionCallback(botService, callback)
+	}
+
+	// Complaint assignment (assign_take_<id>, assign_pick_<id>, assign_to_<id>_<adminID>)
+	if strings.HasPrefix(data, "assign_") {
+		return HandleAssignCallback(botService, callback)
+	}
+
+	// Complaints list, all or of one category (admin_complaints_<categoryID>)
+	if data == "admin_complaints" || strings.HasPrefix(data, "admin_complaints_") {
 		return HandleAdminComplaintsCallback(botService, callback)
 	}
 
+	if data == "admin_my_assigned" {
+		return HandleMyAssignedCallback(botService, callback)
+	}
+
 	if data == "admin_proposals" {
 		return HandleAdminProposalsCallback(botService, callback)
 	}
 
-	if data == "admin_stats" {
+	// Statistics, all or of one complaint category (admin_stats_<categoryID>)
+	if data == "admin_stats" || strings.HasPrefix(data, "admin_stats_") {
 		return HandleAdminStatsCallback(botService, callback)
 	}
 
+	if data == "admin_export" {
+		return HandleAdminExportCallback(botService, callback)
+	}
+
+	// Scheduled report settings (report_f_..., report_h_..., report_now)
+	if data == "admin_reports" {
+		return HandleAdminReportsCallback(botService, callback)
+	}
+
+	if strings.HasPrefix(data, "report_") {
+		return HandleReportSettingsCallback(botService, callback)
+	}
+
+	// Complaint export wizard (export_p_..., export_s_..., export_c_...)
+	if strings.HasPrefix(data, "export_") {
+		return HandleExportWizardCallback(botService, callback)
+	}
+
 	// Admin manage classes callback
 	if data == "admin_manage_classes" {
 		return HandleAdminManageClassesCallback(botService, callback)
 	}
 
+	// Complaint category management (category_toggle_<id>, category_rename_<id>, category_create)
+	if data == "admin_manage_categories" {
+		return HandleAdminManageCategoriesCallback(botService, callback)
+	}
+
+	if strings.HasPrefix(data, "category_") {
+		return HandleCategoryActionCallback(botService, callback)
+	}
+
 	// Admin create class callback
 	if data == "admin_create_class" {
 		return HandleAdminCreateClassCallback(botService, callback)
 	}
 
+	// Class roster import
+	if data == "admin_import_roster" {
+		return HandleAdminImportRosterCallback(botService, callback)
+	}
+
+	if data == "roster_cancel" {
+		return HandleRosterCancelCallback(botService, callback)
+	}
+
 	// Admin back button
 	if data == "admin_back" {
 		return HandleAdminBackCallback(botService, callback)
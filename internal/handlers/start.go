@@ -96,7 +96,8 @@ func HandleHelp(botService *services.BotService, message *tgbotapi.Message) erro
 		helpText += "<b>Buyruqlar:</b>\n"
 		helpText += "/start - Botni ishga tushirish\n"
 		helpText += "/help - Yordam\n"
-		helpText += "/complaint - Shikoyat yuborish\n\n"
+		helpText += "/complaint - Shikoyat yuborish\n"
+		helpText += "/verify &lt;kod&gt; - Hujjat haqiqiyligini tekshirish (yoki PDF faylni /verify izohi bilan yuboring)\n\n"
 		helpText += "<b>Qo'llab-quvvatlash:</b>\n"
 		helpText += "Muammolar yuzaga kelsa, maktab ma'muriyatiga murojaat qiling."
 	} else {
@@ -104,7 +105,8 @@ func HandleHelp(botService *services.BotService, message *tgbotapi.Message) erro
 		helpText += "<b>Команды:</b>\n"
 		helpText += "/start - Запустить бота\n"
 		helpText += "/help - Помощь\n"
-		helpText += "/complaint - Подать жалобу\n\n"
+		helpText += "/complaint - Подать жалобу\n"
+		helpText += "/verify &lt;код&gt; - Проверить подлинность документа (или отправьте PDF с подписью /verify)\n\n"
 		helpText += "<b>Поддержка:</b>\n"
 		helpText += "Если возникли проблемы, обратитесь к администрации школы."
 	}
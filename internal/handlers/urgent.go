@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/i18n"
+	"anor-kids/internal/models"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// notifyAdminsUrgent alerts all admins about a new urgent complaint as soon as
+// it is saved; its PDF follows when ready. The SLA checker repeats the alert
+// until someone acknowledges it.
+func notifyAdminsUrgent(botService *services.BotService, user *models.User, complaint *models.Complaint, imageCount int) {
+	adminIDs, err := botService.GetAdminTelegramIDs()
+	if err != nil {
+		log.Printf("Failed to get admin IDs: %v", err)
+		return
+	}
+
+	if len(adminIDs) == 0 {
+		log.Println("No admins configured")
+		return
+	}
+
+	// Record the alert first, so the checker doesn't repeat it right away
+	if err := botService.SLAService.MarkUrgentAlerted(complaint.ID, time.Now()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	category := "—"
+	if complaint.Category != nil {
+		category = utils.EscapeHTML(complaint.Category.Label())
+	}
+
+	// Staff don't learn who sent an anonymous complaint
+	if complaint.IsAnonymous {
+		user = user.Anonymized()
+	}
+
+	text := fmt.Sprintf(
+		"🚨🚨🚨 <b>SHOSHILINCH SHIKOYAT / СРОЧНАЯ ЖАЛОБА</b>\n\n"+
+			"ID: #%d\n"+
+			"Toifa / Категория: <b>%s</b>\n"+
+			"Farzand / Ребенок: <b>%s</b>\n"+
+			"Sinf / Класс: <b>%s</b>\n"+
+			"Telefon / Телефон: %s\n"+
+			"Sana / Дата: %s\n"+
+			"📷 Rasmlar / Изображений: %d\n\n"+
+			"%s\n\n"+
+			"PDF keyinroq yuboriladi / PDF будет отправлен следом\n\n"+
+			"Qabul qilinmaguncha har %d daqiqada eslatiladi.\n"+
+			"Напоминание каждые %d минут, пока жалобу не примут.",
+		complaint.ID,
+		category,
+		user.ChildName,
+		user.ChildClass,
+		user.PhoneNumber,
+		utils.FormatDateTime(complaint.CreatedAt),
+		imageCount,
+		// The stored text is HTML-escaped; unescape it so truncation can't split an entity
+		utils.EscapeHTML(utils.TruncateText(html.UnescapeString(complaint.ComplaintText), 3000)),
+		int(services.UrgentAlertInterval.Minutes()),
+		int(services.UrgentAlertInterval.Minutes()),
+	)
+
+	keyboard := utils.MakeUrgentAckKeyboard(complaint.ID)
+	for _, adminID := range adminIDs {
+		if err := botService.TelegramService.SendMessage(adminID, text, keyboard); err != nil {
+			log.Printf("Failed to send urgent alert to %d: %v", adminID, err)
+		}
+	}
+
+	log.Printf("🚨 Urgent complaint #%d sent to %d admins", complaint.ID, len(adminIDs))
+}
+
+// HandleUrgentAckCallback acknowledges an urgent complaint, which stops the
+// alerts and tells the parent. Format: urgent_ack_<id>
+func HandleUrgentAckCallback(botService *services.BotService, callback *tgbotapi.CallbackQuery) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "urgent_ack_"))
+	if err != nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "Invalid ID")
+	}
+
+	complaint, err := botService.ComplaintService.GetComplaintByID(id)
+	if err != nil {
+		return err
+	}
+
+	if complaint == nil {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Shikoyat topilmadi / Жалоба не найдена")
+	}
+
+	allowed, err := canActOnSubmission(botService, callback.From.ID, complaint.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Faqat guruh tarbiyachisi yoki ma'murlar uchun / Только для воспитателя группы или администраторов")
+	}
+
+	acknowledged, err := acknowledgeUrgentComplaint(botService, complaint, callback.From)
+	if err != nil {
+		log.Printf("Failed to acknowledge urgent complaint %d: %v", id, err)
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "❌ Xatolik / Ошибка")
+	}
+
+	if !acknowledged {
+		return botService.TelegramService.AnswerCallbackQuery(callback.ID, "ℹ️ Allaqachon qabul qilingan / Уже принято")
+	}
+
+	// Keep the alert, with who took it in place of the button
+	text := utils.EscapeHTML(callback.Message.Text) + "\n\n✅ Qabul qildi / Принял: " + utils.EscapeHTML(staffName(callback.From))
+	_ = botService.TelegramService.EditMessage(callback.Message.Chat.ID, callback.Message.MessageID, text, nil)
+
+	return botService.TelegramService.AnswerCallbackQuery(callback.ID, fmt.Sprintf("🚨 #%d qabul qilindi / #%d принято", id, id))
+}
+
+// acknowledgeUrgentComplaint stops the alerts about an urgent complaint and
+// tells the parent who took it. Returns false if the complaint is not urgent
+// or was acknowledged before; other complaints are left alone.
+func acknowledgeUrgentComplaint(botService *services.BotService, complaint *models.Complaint, from *tgbotapi.User) (bool, error) {
+	if !complaint.IsUrgent {
+		return false, nil
+	}
+
+	acknowledged, err := botService.SLAService.AcknowledgeUrgent(complaint.ID, from.ID, time.Now())
+	if err != nil || !acknowledged {
+		return false, err
+	}
+
+	log.Printf("🚨 Urgent complaint #%d acknowledged by %d", complaint.ID, from.ID)
+
+	user, err := botService.UserRepo.GetByID(complaint.UserID)
+	if err != nil {
+		log.Printf("Failed to get complaint author: %v", err)
+		return true, nil
+	}
+
+	if user == nil {
+		return true, nil
+	}
+
+	lang := i18n.GetLanguage(user.Language)
+	name := staffName(from)
+	if name == "" {
+		name = i18n.Get(i18n.MsgComplaintStaffFallback, lang)
+	}
+
+	text := fmt.Sprintf(i18n.Get(i18n.MsgComplaintUrgentAcknowledged, lang), complaint.ID, utils.EscapeHTML(name))
+	if err := botService.TelegramService.SendMessage(user.TelegramID, text, nil); err != nil {
+		log.Printf("Failed to notify parent about urgent acknowledgement: %v", err)
+	}
+
+	return true, nil
+}
+
+// staffName returns the first name of a staff member's Telegram profile; the
+// parent never sees more
+func staffName(from *tgbotapi.User) string {
+	return strings.TrimSpace(from.FirstName)
+}
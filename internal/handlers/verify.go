@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"anor-kids/internal/models"
+	"anor-kids/internal/registry"
+	"anor-kids/internal/services"
+	"anor-kids/internal/utils"
+)
+
+// HandleVerifyCommand checks a verification code from a PDF footer against the document registry.
+// Also reached through the QR code deep link (/start verify_<code>). Open to everyone, so
+// officials outside the bot can check documents.
+func HandleVerifyCommand(botService *services.BotService, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	code := registry.NormalizeCode(message.CommandArguments())
+	if code == "" {
+		text := "🔍 <b>Hujjatni tekshirish / Проверка документа</b>\n\n" +
+			"PDF pastidagi kodni yuboring: <code>/verify XXXX-XXXX-XXXX</code>\n" +
+			"yoki PDF faylning o'zini /verify izohi bilan yuboring.\n\n" +
+			"Отправьте код из нижней части PDF: <code>/verify XXXX-XXXX-XXXX</code>\n" +
+			"или сам PDF-файл с подписью /verify."
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	result, err := botService.RegistryService.VerifyCode(code)
+	if err != nil {
+		log.Printf("Failed to verify code %s: %v", code, err)
+		return botService.TelegramService.SendMessage(chatID, "❌ Xatolik / Ошибка", nil)
+	}
+
+	return botService.TelegramService.SendMessage(chatID, formatVerification(result, false), nil)
+}
+
+// HandleVerifyDocumentMessage checks a PDF sent with the /verify caption: the file is
+// authentic only if its SHA-256 is in the registry, so any edit is detected
+func HandleVerifyDocumentMessage(botService *services.BotService, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	doc := message.Document
+
+	data, err := botService.DownloadManager.Fetch(models.ImageData{
+		FileID:       doc.FileID,
+		FileUniqueID: doc.FileUniqueID,
+		FileSize:     doc.FileSize,
+	})
+	if err != nil {
+		log.Printf("Failed to download document for verification: %v", err)
+		text := "❌ Faylni yuklab bo'lmadi / Не удалось загрузить файл"
+		return botService.TelegramService.SendMessage(chatID, text, nil)
+	}
+
+	result, err := botService.RegistryService.VerifyDocument(data)
+	if err != nil {
+		log.Printf("Failed to verify document: %v", err)
+		return botService.TelegramService.SendMessage(chatID, "❌ Xatolik / Ошибка", nil)
+	}
+
+	return botService.TelegramService.SendMessage(chatID, formatVerification(result, true), nil)
+}
+
+// isVerifyCaption reports whether a document caption is the /verify command
+func isVerifyCaption(caption string) bool {
+	fields := strings.Fields(caption)
+	if len(fields) == 0 {
+		return false
+	}
+	return fields[0] == "/verify" || strings.HasPrefix(fields[0], "/verify@")
+}
+
+// formatVerification formats a registry check. fileChecked says whether the
+// document itself was hashed or only its code was looked up.
+func formatVerification(result *services.Verification, fileChecked bool) string {
+	entry := result.Entry
+
+	var text string
+	switch {
+	case entry == nil && fileChecked:
+		text = "❌ <b>Fayl reestrda yo'q yoki o'zgartirilgan / Файл не найден в реестре или был изменён</b>\n"
+	case entry == nil:
+		text = "❌ <b>Bu kod bilan hujjat topilmadi / Документ с таким кодом не найден</b>\n"
+	case !result.Authentic():
+		text = "⚠️ <b>Hujjatni tasdiqlab bo'lmaydi / Документ не может быть подтверждён</b>\n"
+	case fileChecked:
+		text = "✅ <b>Fayl asl nusxa bilan bir xil / Файл совпадает с оригиналом</b>\n"
+	default:
+		text = "✅ <b>Hujjat reestrda bor / Документ зарегистрирован</b>\n"
+	}
+
+	if entry != nil {
+		kind := "Shikoyat / Жалоба"
+		if entry.Kind == models.DocumentJobKindProposal {
+			kind = "Taklif / Предложение"
+		}
+
+		text += fmt.Sprintf(
+			"\nKod / Код: <code>%s</code>\n"+
+				"Hujjat / Документ: %s #%d\n"+
+				"Yaratilgan / Создан: %s\n"+
+				"SHA-256: <code>%s</code>\n",
+			entry.Code,
+			kind,
+			entry.ItemID,
+			utils.FormatDateTime(entry.CreatedAt),
+			entry.DocumentHash,
+		)
+	}
+
+	if result.ChainIntact {
+		text += fmt.Sprintf("\n🔗 Reestr zanjiri butun / Цепочка реестра не нарушена (%d)", result.ChainLength)
+	} else {
+		text += fmt.Sprintf("\n⚠️ Reestr zanjiri #%d yozuvdan buzilgan / Цепочка реестра нарушена с записи #%d",
+			result.BrokenEntryID, result.BrokenEntryID)
+	}
+
+	if entry != nil && !fileChecked {
+		text += "\n\nFaylning o'zini tekshirish uchun PDF ni /verify izohi bilan yuboring yoki SHA-256 ni solishtiring.\n" +
+			"Чтобы проверить сам файл, отправьте PDF с подписью /verify или сравните SHA-256."
+	}
+
+	return text
+}
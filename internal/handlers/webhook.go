@@ -4,11 +4,19 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"log"
 	"anor-kids/internal/i18n"
+	"anor-kids/internal/registry"
 	"anor-kids/internal/services"
 )
 
 // HandleUpdate is the main update handler that routes all Telegram updates
 func HandleUpdate(botService *services.BotService, update tgbotapi.Update) {
+	// Record activity for the retention policy
+	if sender := update.SentFrom(); sender != nil && !sender.IsBot {
+		if err := botService.UserRepo.TouchLastActive(sender.ID); err != nil {
+			log.Printf("Failed to record user activity: %v", err)
+		}
+	}
+
 	// Handle callback queries (inline button clicks)
 	if update.CallbackQuery != nil {
 		if err := HandleCallbackQuery(botService, update.CallbackQuery); err != nil {
@@ -41,6 +49,11 @@ func HandleMessage(botService *services.BotService, message *tgbotapi.Message) e
 
 	telegramID := message.From.ID
 
+	// A document sent with the /verify caption is checked against the registry
+	if message.Document != nil && isVerifyCaption(message.Caption) {
+		return HandleVerifyDocumentMessage(botService, message)
+	}
+
 	// Handle commands first
 	if message.IsCommand() {
 		return HandleCommand(botService, message)
@@ -97,6 +110,10 @@ func HandleMessage(botService *services.BotService, message *tgbotapi.Message) e
 func HandleCommand(botService *services.BotService, message *tgbotapi.Message) error {
 	switch message.Command() {
 	case "start":
+		// QR code on a PDF: t.me/<bot>?start=verify_<code>
+		if registry.IsStartParameter(message.CommandArguments()) {
+			return HandleVerifyCommand(botService, message)
+		}
 		return HandleStart(botService, message)
 	case "help":
 		return HandleHelp(botService, message)
@@ -114,6 +131,16 @@ func HandleCommand(botService *services.BotService, message *tgbotapi.Message) e
 		return HandleDeleteClassCommand(botService, message)
 	case "toggle_class":
 		return HandleToggleClassCommand(botService, message)
+	case "set_teacher":
+		return HandleSetTeacherCommand(botService, message)
+	case "verify":
+		return HandleVerifyCommand(botService, message)
+	case "retention_report":
+		return HandleRetentionReportCommand(botService, message)
+	case "export_complaints":
+		return HandleExportComplaintsCommand(botService, message)
+	case "reveal":
+		return HandleRevealCommand(botService, message)
 	default:
 		// Unknown command
 		return HandleStart(botService, message)
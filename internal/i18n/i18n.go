@@ -28,14 +28,50 @@ const (
 	MsgChildNameReceived      = "child_name_received"
 	MsgRequestChildClass      = "request_child_class"
 	MsgRegistrationComplete   = "registration_complete"
+	MsgAccountRestored        = "account_restored"
+
+	// Phone change
+	MsgRequestNewPhone        = "request_new_phone"
+	MsgPhoneChanged           = "phone_changed"
+	MsgPhoneChangeCancelled   = "phone_change_cancelled"
+
+	// Data export and account deletion
+	MsgExportPreparing        = "export_preparing"
+	MsgExportReady            = "export_ready"
+	MsgDeleteAccountConfirm   = "delete_account_confirm"
+	MsgDeleteAccountFinal     = "delete_account_final"
+	MsgAccountDeleted         = "account_deleted"
+	MsgDeleteAccountCancelled = "delete_account_cancelled"
+
+	// Registration approval
+	MsgRegistrationPending    = "registration_pending"
+	MsgRegistrationApproved   = "registration_approved"
+	MsgRegistrationRejected   = "registration_rejected"
 
 	// Complaint flow
 	MsgMainMenu               = "main_menu"
+	MsgChooseComplaintCategory = "choose_complaint_category"
 	MsgRequestComplaint       = "request_complaint"
 	MsgComplaintReceived      = "complaint_received"
 	MsgConfirmComplaint       = "confirm_complaint"
 	MsgComplaintSubmitted     = "complaint_submitted"
 	MsgComplaintCancelled     = "complaint_cancelled"
+	MsgComplaintAssigned      = "complaint_assigned"
+	MsgComplaintStaffFallback = "complaint_staff_fallback"
+	MsgComplaintUrgentSent    = "complaint_urgent_sent"
+	MsgComplaintUrgentAcknowledged = "complaint_urgent_acknowledged"
+	MsgComplaintReply         = "complaint_reply"
+	MsgWithdrawComplaintConfirm = "withdraw_complaint_confirm"
+	MsgComplaintWithdrawn     = "complaint_withdrawn"
+	MsgComplaintNotPending    = "complaint_not_pending"
+	MsgRequestComplaintEdit   = "request_complaint_edit"
+	MsgComplaintEditPending   = "complaint_edit_pending"
+	MsgComplaintEdited        = "complaint_edited"
+	MsgComplaintEditCancelled = "complaint_edit_cancelled"
+	MsgRateComplaint          = "rate_complaint"
+	MsgRatingSaved            = "rating_saved"
+	MsgRatingThanks           = "rating_thanks"
+	MsgRatingCommentSaved     = "rating_comment_saved"
 
 	// Proposal flow
 	MsgSubmitProposal         = "submit_proposal"
@@ -46,12 +82,17 @@ const (
 	MsgProposalSubmitted      = "proposal_submitted"
 	MsgProposalCancelled      = "proposal_cancelled"
 
+	// Document generation
+	MsgSubmissionProcessing   = "submission_processing"
+	MsgDocumentFailed         = "document_failed"
+
 	// Admin messages
 	MsgAdminPanel             = "admin_panel"
 	MsgUserList               = "user_list"
 	MsgComplaintList          = "complaint_list"
 	MsgStats                  = "stats"
 	MsgNewComplaint           = "new_complaint"
+	MsgAdminOverdue           = "admin_overdue"
 
 	// Buttons
 	BtnUzbek                  = "btn_uzbek"
@@ -62,19 +103,37 @@ const (
 	BtnSubmitProposal         = "btn_submit_proposal"
 	BtnMyProposals            = "btn_my_proposals"
 	BtnSettings               = "btn_settings"
+	BtnChangePhone            = "btn_change_phone"
+	BtnExportData             = "btn_export_data"
+	BtnDeleteAccount          = "btn_delete_account"
+	BtnConfirmDelete          = "btn_confirm_delete"
+	BtnConfirmDeleteFinal     = "btn_confirm_delete_final"
 	BtnConfirm                = "btn_confirm"
 	BtnCancel                 = "btn_cancel"
+	BtnMarkUrgent             = "btn_mark_urgent"
+	BtnUnmarkUrgent           = "btn_unmark_urgent"
+	BtnSendAnonymously        = "btn_send_anonymously"
+	BtnSendWithName           = "btn_send_with_name"
+	BtnWithdrawComplaint      = "btn_withdraw_complaint"
+	BtnConfirmWithdraw        = "btn_confirm_withdraw"
+	BtnEditComplaint          = "btn_edit_complaint"
+	BtnSkipComment            = "btn_skip_comment"
 	BtnBack                   = "btn_back"
 
 	// Admin buttons
 	BtnAdminPanel             = "btn_admin_panel"
 	BtnCreateClass            = "btn_create_class"
+	BtnImportRoster           = "btn_import_roster"
 	BtnManageClasses          = "btn_manage_classes"
+	BtnManageCategories       = "btn_manage_categories"
 	BtnViewUsers              = "btn_view_users"
+	BtnPendingRegistrations   = "btn_pending_registrations"
 	BtnViewComplaints         = "btn_view_complaints"
 	BtnViewProposals          = "btn_view_proposals"
 	BtnViewStats              = "btn_view_stats"
 	BtnExport                 = "btn_export"
+	BtnReports                = "btn_reports"
+	BtnMyAssigned             = "btn_my_assigned"
 	BtnCreateAnnouncement     = "btn_create_announcement"
 	BtnManageAnnouncements    = "btn_manage_announcements"
 	BtnViewAnnouncements      = "btn_view_announcements"
@@ -92,12 +151,16 @@ const (
 
 	// Errors
 	ErrInvalidPhone           = "err_invalid_phone"
+	ErrPhoneNotOwn            = "err_phone_not_own"
+	ErrPhoneTaken             = "err_phone_taken"
 	ErrInvalidName            = "err_invalid_name"
 	ErrInvalidClass           = "err_invalid_class"
 	ErrInvalidComplaint       = "err_invalid_complaint"
 	ErrInvalidProposal        = "err_invalid_proposal"
 	ErrAlreadyRegistered      = "err_already_registered"
 	ErrNotRegistered          = "err_not_registered"
+	ErrRegistrationPending    = "err_registration_pending"
+	ErrRegistrationRejected   = "err_registration_rejected"
 	ErrDatabaseError          = "err_database_error"
 	ErrUnknownCommand         = "err_unknown_command"
 
@@ -16,7 +16,7 @@ var russian = map[string]string{
 
 	MsgLanguageSelected: "✅ Язык выбран: Русский\n\nДля продолжения пройдите регистрацию.",
 
-	MsgRequestPhone: "📱 Пожалуйста, отправьте ваш номер телефона.\n\nНомер должен начинаться с +998.\n\nПример: +998901234567\n\nИли отправьте через кнопку ниже 👇",
+	MsgRequestPhone: "📱 Пожалуйста, отправьте ваш номер телефона через кнопку ниже 👇\n\nПринимается только номер, привязанный к вашему аккаунту Telegram.",
 
 	MsgPhoneReceived: "✅ Номер телефона получен: %s",
 
@@ -32,9 +32,37 @@ var russian = map[string]string{
 		"📱 Телефон: %s\n\n" +
 		"Теперь вы можете подавать жалобы.",
 
+	MsgAccountRestored: "✅ Вы уже были зарегистрированы с этим номером телефона.\n\nВаш аккаунт перенесён на новый аккаунт Telegram.\n\nРебенок: %s\nГруппа: %s",
+
+	// Phone change
+	MsgRequestNewPhone:      "📱 Отправьте новый номер телефона через кнопку ниже 👇\n\nНомер должен быть привязан к вашему аккаунту Telegram.",
+	MsgPhoneChanged:         "✅ Номер телефона изменён: %s",
+	MsgPhoneChangeCancelled: "Изменение номера телефона отменено.",
+
+	// Data export and account deletion
+	MsgExportPreparing:        "⏳ Готовим ваши данные, подождите немного...",
+	MsgExportReady:            "📦 Все ваши данные, которые мы храним: профиль, жалобы, предложения, сведения об изображениях и PDF документы.",
+	MsgDeleteAccountConfirm:   "⚠️ Вы хотите удалить аккаунт?\n\nВаш профиль, все жалобы и предложения будут удалены безвозвратно. Перед этим можно сохранить копию через \"Скачать мои данные\".",
+	MsgDeleteAccountFinal:     "❗️ Последнее подтверждение\n\nЭто действие нельзя отменить. Действительно удалить?",
+	MsgAccountDeleted:         "✅ Ваш аккаунт и все ваши данные удалены.\n\nЧтобы зарегистрироваться заново, отправьте /start.",
+	MsgDeleteAccountCancelled: "Удаление аккаунта отменено.",
+
+	// Registration approval
+	MsgRegistrationPending: "⏳ Ваша заявка на регистрацию принята!\n\n" +
+		"👤 Ребенок: %s\n" +
+		"🎓 Группа: %s\n" +
+		"📱 Телефон: %s\n\n" +
+		"Вы сможете подавать жалобы и предложения после того, как воспитатель группы или администрация подтвердит заявку.",
+
+	MsgRegistrationApproved: "✅ Ваша регистрация подтверждена!\n\nТеперь вы можете подавать жалобы и предложения.",
+
+	MsgRegistrationRejected: "❌ Ваша заявка на регистрацию отклонена.\n\nЕсли у вас есть вопросы, обратитесь к администрации школы.",
+
 	// Complaint flow
 	MsgMainMenu: "📋 Главное меню\n\nВыберите:",
 
+	MsgChooseComplaintCategory: "🏷 О чём ваша жалоба? Выберите категорию:",
+
 	MsgRequestComplaint: "✍️ Пожалуйста, напишите вашу жалобу.\n\n" +
 		"Текст жалобы должен содержать минимум 10 символов.\n\n" +
 		"Пишите четко и понятно.",
@@ -49,6 +77,42 @@ var russian = map[string]string{
 
 	MsgComplaintCancelled: "❌ Жалоба отменена.",
 
+	MsgComplaintAssigned: "👤 Ответственный по вашей жалобе #%d: <b>%s</b>.\n\nВаше обращение уже в работе.",
+
+	MsgComplaintStaffFallback: "сотрудник администрации",
+
+	MsgComplaintUrgentSent: "🚨 Ваша жалоба отмечена как срочная и сразу отправлена всем администраторам.\n\n" +
+		"Мы сообщим вам, как только сотрудник её примет.",
+
+	MsgComplaintUrgentAcknowledged: "✅ Ваша срочная жалоба #%d принята.\n\nЕю занимается <b>%s</b>.",
+
+	MsgComplaintReply: "💬 <b>Ответ на вашу жалобу #%d</b>\n\n%s\n\n— %s",
+
+	MsgWithdrawComplaintConfirm: "↩️ Отозвать жалобу #%d?\n\nАдминистрация не будет её рассматривать. Это действие нельзя отменить.",
+
+	MsgComplaintWithdrawn: "↩️ Жалоба #%d отозвана. Администрация уведомлена.",
+
+	MsgComplaintNotPending: "ℹ️ Жалоба #%d уже рассмотрена или отозвана, её нельзя изменить.",
+
+	MsgRequestComplaintEdit: "✏️ Отправьте исправленный текст жалобы #%d.\n\n" +
+		"Текущий текст:\n%s\n\n" +
+		"Изображения не меняются. Для отмены /cancel",
+
+	MsgComplaintEditPending: "⏳ PDF-документ жалобы #%d ещё готовится. Попробуйте чуть позже.",
+
+	MsgComplaintEdited: "✅ Исправленная версия вашей жалобы отправлена!\n\n" +
+		"Администрация уведомлена об изменении. Предыдущая версия сохранена в истории.",
+
+	MsgComplaintEditCancelled: "❌ Исправление отменено.",
+
+	MsgRateComplaint: "✅ Ваша жалоба #%d рассмотрена.\n\nОцените, как было решено ваше обращение, от 1 до 5:",
+
+	MsgRatingSaved: "🙏 Спасибо! Ваша оценка: %s\n\nЕсли хотите оставить комментарий, напишите его.",
+
+	MsgRatingThanks: "🙏 Спасибо за вашу оценку! %s",
+
+	MsgRatingCommentSaved: "🙏 Спасибо за ваш комментарий!",
+
 	// Proposal flow
 	MsgSubmitProposal: "/proposal - Отправить предложение",
 	MsgMyProposals:    "/my_proposals - Мои предложения",
@@ -67,12 +131,17 @@ var russian = map[string]string{
 
 	MsgProposalCancelled: "❌ Предложение отменено.",
 
+	// Document generation
+	MsgSubmissionProcessing: "⏳ Принято и сохранено!\n\nPDF-документ готовится, это может занять некоторое время...",
+	MsgDocumentFailed:       "✅ Ваше обращение сохранено и передано администрации.\n\n⚠️ Не удалось подготовить PDF-документ, администрация рассмотрит текст отдельно.",
+
 	// Admin messages
 	MsgAdminPanel:      "👨‍💼 Панель администратора",
 	MsgUserList:        "👥 Список зарегистрированных пользователей",
 	MsgComplaintList:   "📋 Список жалоб",
 	MsgStats:           "📊 Статистика",
 	MsgNewComplaint:    "🔔 Получена новая жалоба!",
+	MsgAdminOverdue:    "⏰ Просрочено: жалоб %d, предложений %d",
 
 	// Buttons
 	BtnUzbek:           "🇺🇿 O'zbek",
@@ -83,19 +152,37 @@ var russian = map[string]string{
 	BtnSubmitProposal:  "💡 Отправить предложение",
 	BtnMyProposals:     "📝 Мои предложения",
 	BtnSettings:        "⚙️ Настройки",
+	BtnChangePhone:     "📱 Изменить номер телефона",
+	BtnExportData:      "📦 Скачать мои данные",
+	BtnDeleteAccount:   "🗑 Удалить аккаунт",
+	BtnConfirmDelete:   "Да, удалить",
+	BtnConfirmDeleteFinal: "🗑 Удалить навсегда",
 	BtnConfirm:         "✅ Подтвердить",
+	BtnMarkUrgent:      "🚨 Отметить как срочную",
+	BtnUnmarkUrgent:    "↩️ Не срочно",
+	BtnSendAnonymously: "🕶 Отправить анонимно",
+	BtnSendWithName:    "👤 Отправить с именем",
+	BtnWithdrawComplaint: "↩️ Отозвать #%d",
+	BtnConfirmWithdraw: "Да, отозвать",
+	BtnEditComplaint:   "✏️ Исправить #%d",
+	BtnSkipComment:     "⏭ Без комментария",
 	BtnCancel:          "❌ Отменить",
 	BtnBack:            "◀️ Назад",
 
 	// Admin buttons
 	BtnAdminPanel:          "👨‍💼 Панель администратора",
 	BtnCreateClass:         "➕ Создать группу",
+	BtnImportRoster:        "📥 Импорт списка",
 	BtnManageClasses:       "📚 Управление группами",
+	BtnManageCategories:    "🏷 Категории жалоб",
 	BtnViewUsers:           "👥 Пользователи",
+	BtnPendingRegistrations: "⏳ Ожидают подтверждения",
 	BtnViewComplaints:      "📋 Жалобы",
 	BtnViewProposals:       "💡 Предложения",
 	BtnViewStats:           "📊 Статистика",
 	BtnExport:              "📥 Экспорт",
+	BtnReports:             "📬 Отчёты",
+	BtnMyAssigned:          "📌 Назначенные мне",
 	BtnCreateAnnouncement:  "📢 Создать объявление",
 	BtnManageAnnouncements: "📰 Управление объявлениями",
 	BtnViewAnnouncements:   "📰 Объявления",
@@ -113,12 +200,16 @@ var russian = map[string]string{
 
 	// Errors
 	ErrInvalidPhone:      "❌ Неверный формат номера телефона!\n\nНомер должен начинаться с +998 и содержать 9 цифр.\n\nПример: +998901234567",
+	ErrPhoneNotOwn:       "❌ Пожалуйста, отправьте свой номер телефона через кнопку ниже.\n\nНомер, введённый вручную, или чужой контакт не принимаются.",
+	ErrPhoneTaken:        "❌ Этот номер телефона привязан к другому аккаунту.\n\nОбратитесь к администрации.",
 	ErrInvalidName:       "❌ Неверный формат имени!\n\nИмя должно содержать только буквы.",
 	ErrInvalidClass:      "❌ Неверный формат группы!\n\nНеобходимо указать номер группы (1-11) и букву (A-Z).\n\nПример: 9A, 11B",
 	ErrInvalidComplaint:  "❌ Текст жалобы слишком короткий!\n\nВведите минимум 10 символов.",
 	ErrInvalidProposal:   "❌ Текст предложения слишком короткий!\n\nВведите минимум 10 символов.",
 	ErrAlreadyRegistered: "❌ Вы уже зарегистрированы!",
 	ErrNotRegistered:     "❌ Вы не зарегистрированы!\n\nПожалуйста, сначала нажмите /start.",
+	ErrRegistrationPending:  "⏳ Ваша регистрация еще не подтверждена.\n\nВы сможете подавать жалобы и предложения после подтверждения.",
+	ErrRegistrationRejected: "❌ Ваша заявка на регистрацию отклонена.\n\nОбратитесь к администрации школы.",
 	ErrDatabaseError:     "❌ Произошла ошибка. Пожалуйста, попробуйте позже.",
 	ErrUnknownCommand:    "❌ Неизвестная команда. Нажмите /help.",
 
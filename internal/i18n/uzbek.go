@@ -16,7 +16,7 @@ var uzbek = map[string]string{
 
 	MsgLanguageSelected: "✅ Til tanlandi: O'zbek\n\nDavom etish uchun ro'yxatdan o'ting.",
 
-	MsgRequestPhone: "📱 Iltimos, telefon raqamingizni yuboring.\n\nTelefon raqam +998 bilan boshlanishi kerak.\n\nMisol: +998901234567\n\nYoki quyidagi tugma orqali raqamingizni yuboring 👇",
+	MsgRequestPhone: "📱 Iltimos, quyidagi tugma orqali telefon raqamingizni yuboring 👇\n\nFaqat Telegram akkauntingizga bog'langan raqam qabul qilinadi.",
 
 	MsgPhoneReceived: "✅ Telefon raqam qabul qilindi: %s",
 
@@ -32,9 +32,37 @@ var uzbek = map[string]string{
 		"📱 Telefon: %s\n\n" +
 		"Endi siz shikoyat yuborishingiz mumkin.",
 
+	MsgAccountRestored: "✅ Bu telefon raqam bilan oldin ro'yxatdan o'tgansiz.\n\nAkkauntingiz yangi Telegram akkauntga ko'chirildi.\n\nFarzand: %s\nGuruh: %s",
+
+	// Phone change
+	MsgRequestNewPhone:      "📱 Yangi telefon raqamingizni quyidagi tugma orqali yuboring 👇\n\nRaqam Telegram akkauntingizga bog'langan bo'lishi kerak.",
+	MsgPhoneChanged:         "✅ Telefon raqam o'zgartirildi: %s",
+	MsgPhoneChangeCancelled: "Telefon raqamni o'zgartirish bekor qilindi.",
+
+	// Data export and account deletion
+	MsgExportPreparing:        "⏳ Ma'lumotlaringiz tayyorlanmoqda, biroz kuting...",
+	MsgExportReady:            "📦 Bizda saqlangan barcha ma'lumotlaringiz: profil, shikoyatlar, takliflar, rasmlar haqida ma'lumot va PDF hujjatlar.",
+	MsgDeleteAccountConfirm:   "⚠️ Akkauntingizni o'chirmoqchimisiz?\n\nProfilingiz, barcha shikoyat va takliflaringiz butunlay o'chiriladi. Avval \"Ma'lumotlarimni yuklab olish\" orqali nusxa olishingiz mumkin.",
+	MsgDeleteAccountFinal:     "❗️ Oxirgi tasdiqlash\n\nBu amalni ortga qaytarib bo'lmaydi. Haqiqatan ham o'chirilsinmi?",
+	MsgAccountDeleted:         "✅ Akkauntingiz va barcha ma'lumotlaringiz o'chirildi.\n\nQaytadan ro'yxatdan o'tish uchun /start buyrug'ini yuboring.",
+	MsgDeleteAccountCancelled: "Akkauntni o'chirish bekor qilindi.",
+
+	// Registration approval
+	MsgRegistrationPending: "⏳ Ro'yxatdan o'tish arizangiz qabul qilindi!\n\n" +
+		"👤 Farzand: %s\n" +
+		"🎓 Guruh: %s\n" +
+		"📱 Telefon: %s\n\n" +
+		"Guruh tarbiyachisi yoki ma'muriyat arizangizni tasdiqlagandan so'ng shikoyat va takliflar yuborishingiz mumkin bo'ladi.",
+
+	MsgRegistrationApproved: "✅ Ro'yxatdan o'tishingiz tasdiqlandi!\n\nEndi siz shikoyat va takliflar yuborishingiz mumkin.",
+
+	MsgRegistrationRejected: "❌ Ro'yxatdan o'tish arizangiz rad etildi.\n\nSavollar bo'lsa, maktab ma'muriyatiga murojaat qiling.",
+
 	// Complaint flow
 	MsgMainMenu: "📋 Asosiy menyu\n\nTanlang:",
 
+	MsgChooseComplaintCategory: "🏷 Shikoyatingiz nima haqida? Toifani tanlang:",
+
 	MsgRequestComplaint: "✍️ Iltimos, shikoyatingizni yozib yuboring.\n\n" +
 		"Shikoyat matni kamida 10 ta belgidan iborat bo'lishi kerak.\n\n" +
 		"Aniq va tushunarli yozing.",
@@ -49,6 +77,42 @@ var uzbek = map[string]string{
 
 	MsgComplaintCancelled: "❌ Shikoyat bekor qilindi.",
 
+	MsgComplaintAssigned: "👤 Shikoyatingiz #%d bo'yicha mas'ul xodim: <b>%s</b>.\n\nMurojaatingiz ko'rib chiqilmoqda.",
+
+	MsgComplaintStaffFallback: "ma'muriyat xodimi",
+
+	MsgComplaintUrgentSent: "🚨 Shikoyatingiz shoshilinch deb belgilandi va barcha ma'murlarga darhol yuborildi.\n\n" +
+		"Xodim uni qabul qilishi bilan sizga xabar beramiz.",
+
+	MsgComplaintUrgentAcknowledged: "✅ Shoshilinch shikoyatingiz #%d qabul qilindi.\n\nU bilan <b>%s</b> shug'ullanmoqda.",
+
+	MsgComplaintReply: "💬 <b>Shikoyatingiz #%d bo'yicha javob</b>\n\n%s\n\n— %s",
+
+	MsgWithdrawComplaintConfirm: "↩️ Shikoyat #%d qaytarib olinsinmi?\n\nMa'muriyat uni ko'rib chiqmaydi. Buni ortga qaytarib bo'lmaydi.",
+
+	MsgComplaintWithdrawn: "↩️ Shikoyat #%d qaytarib olindi. Ma'muriyat xabardor qilindi.",
+
+	MsgComplaintNotPending: "ℹ️ Shikoyat #%d allaqachon ko'rib chiqilgan yoki qaytarib olingan, uni o'zgartirib bo'lmaydi.",
+
+	MsgRequestComplaintEdit: "✏️ Shikoyat #%d ning tuzatilgan matnini yuboring.\n\n" +
+		"Joriy matn:\n%s\n\n" +
+		"Rasmlar o'zgarmaydi. Bekor qilish uchun /cancel",
+
+	MsgComplaintEditPending: "⏳ Shikoyat #%d ning PDF hujjati hali tayyorlanmoqda. Birozdan keyin urinib ko'ring.",
+
+	MsgComplaintEdited: "✅ Shikoyatingizning tuzatilgan varianti yuborildi!\n\n" +
+		"Ma'muriyat o'zgarish haqida xabardor qilindi. Oldingi variant tarixda saqlanadi.",
+
+	MsgComplaintEditCancelled: "❌ Tuzatish bekor qilindi.",
+
+	MsgRateComplaint: "✅ Shikoyatingiz #%d ko'rib chiqildi.\n\nMurojaatingiz qanday hal qilinganini 1 dan 5 gacha baholang:",
+
+	MsgRatingSaved: "🙏 Rahmat! Bahoingiz: %s\n\nIzoh qoldirmoqchi bo'lsangiz, yozib yuboring.",
+
+	MsgRatingThanks: "🙏 Bahoingiz uchun rahmat! %s",
+
+	MsgRatingCommentSaved: "🙏 Izohingiz uchun rahmat!",
+
 	// Proposal flow
 	MsgSubmitProposal: "/proposal - Taklif yuborish",
 	MsgMyProposals:    "/my_proposals - Mening takliflarim",
@@ -67,12 +131,17 @@ var uzbek = map[string]string{
 
 	MsgProposalCancelled: "❌ Taklif bekor qilindi.",
 
+	// Document generation
+	MsgSubmissionProcessing: "⏳ Qabul qilindi va saqlandi!\n\nPDF hujjat tayyorlanmoqda, bu biroz vaqt olishi mumkin...",
+	MsgDocumentFailed:       "✅ Murojaatingiz saqlandi va ma'muriyatga yetkazildi.\n\n⚠️ PDF hujjatni tayyorlab bo'lmadi, ma'muriyat matnni alohida ko'rib chiqadi.",
+
 	// Admin messages
 	MsgAdminPanel:      "👨‍💼 Ma'muriyat paneli",
 	MsgUserList:        "👥 Ro'yxatdan o'tgan foydalanuvchilar ro'yxati",
 	MsgComplaintList:   "📋 Shikoyatlar ro'yxati",
 	MsgStats:           "📊 Statistika",
 	MsgNewComplaint:    "🔔 Yangi shikoyat keldi!",
+	MsgAdminOverdue:    "⏰ Muddati o'tgan: %d ta shikoyat, %d ta taklif",
 
 	// Buttons
 	BtnUzbek:           "🇺🇿 O'zbek",
@@ -83,19 +152,37 @@ var uzbek = map[string]string{
 	BtnSubmitProposal:  "💡 Taklif yuborish",
 	BtnMyProposals:     "📝 Mening takliflarim",
 	BtnSettings:        "⚙️ Sozlamalar",
+	BtnChangePhone:     "📱 Telefon raqamni o'zgartirish",
+	BtnExportData:      "📦 Ma'lumotlarimni yuklab olish",
+	BtnDeleteAccount:   "🗑 Akkauntni o'chirish",
+	BtnConfirmDelete:   "Ha, o'chirish",
+	BtnConfirmDeleteFinal: "🗑 Butunlay o'chirish",
 	BtnConfirm:         "✅ Tasdiqlash",
+	BtnMarkUrgent:      "🚨 Shoshilinch deb belgilash",
+	BtnUnmarkUrgent:    "↩️ Shoshilinch emas",
+	BtnSendAnonymously: "🕶 Anonim yuborish",
+	BtnSendWithName:    "👤 Ism bilan yuborish",
+	BtnWithdrawComplaint: "↩️ #%d qaytarib olish",
+	BtnConfirmWithdraw: "Ha, qaytarib olish",
+	BtnEditComplaint:   "✏️ #%d tuzatish",
+	BtnSkipComment:     "⏭ Izohsiz",
 	BtnCancel:          "❌ Bekor qilish",
 	BtnBack:            "◀️ Orqaga",
 
 	// Admin buttons
 	BtnAdminPanel:          "👨‍💼 Ma'muriyat paneli",
 	BtnCreateClass:         "➕ Guruh yaratish",
+	BtnImportRoster:        "📥 Ro'yxatni import qilish",
 	BtnManageClasses:       "📚 Guruhlarni boshqarish",
+	BtnManageCategories:    "🏷 Shikoyat toifalari",
 	BtnViewUsers:           "👥 Foydalanuvchilar",
+	BtnPendingRegistrations: "⏳ Tasdiqlash kutayotganlar",
 	BtnViewComplaints:      "📋 Shikoyatlar",
 	BtnViewProposals:       "💡 Takliflar",
 	BtnViewStats:           "📊 Statistika",
 	BtnExport:              "📥 Eksport",
+	BtnReports:             "📬 Hisobotlar",
+	BtnMyAssigned:          "📌 Menga tayinlanganlar",
 	BtnCreateAnnouncement:  "📢 E'lon yaratish",
 	BtnManageAnnouncements: "📰 E'lonlarni boshqarish",
 	BtnViewAnnouncements:   "📰 E'lonlar",
@@ -113,12 +200,16 @@ var uzbek = map[string]string{
 
 	// Errors
 	ErrInvalidPhone:      "❌ Noto'g'ri telefon raqam formati!\n\nTelefon raqam +998 bilan boshlanishi va 9 ta raqamdan iborat bo'lishi kerak.\n\nMisol: +998901234567",
+	ErrPhoneNotOwn:       "❌ Iltimos, o'z telefon raqamingizni quyidagi tugma orqali yuboring.\n\nQo'lda yozilgan yoki boshqa odamning kontakti qabul qilinmaydi.",
+	ErrPhoneTaken:        "❌ Bu telefon raqam boshqa akkauntga bog'langan.\n\nMa'muriyatga murojaat qiling.",
 	ErrInvalidName:       "❌ Noto'g'ri ism formati!\n\nIsm faqat harflardan iborat bo'lishi kerak.",
 	ErrInvalidClass:      "❌ Noto'g'ri guruh formati!\n\nGuruh raqami (1-11) va harfi (A-Z) ko'rsatilishi kerak.\n\nMisol: 9A, 11B",
 	ErrInvalidComplaint:  "❌ Shikoyat matni juda qisqa!\n\nKamida 10 ta belgi kiriting.",
 	ErrInvalidProposal:   "❌ Taklif matni juda qisqa!\n\nKamida 10 ta belgi kiriting.",
 	ErrAlreadyRegistered: "❌ Siz allaqachon ro'yxatdan o'tgansiz!",
 	ErrNotRegistered:     "❌ Siz ro'yxatdan o'tmagansiz!\n\nIltimos, avval /start buyrug'ini bosing.",
+	ErrRegistrationPending:  "⏳ Ro'yxatdan o'tishingiz hali tasdiqlanmagan.\n\nTasdiqlangandan so'ng shikoyat va takliflar yuborishingiz mumkin bo'ladi.",
+	ErrRegistrationRejected: "❌ Ro'yxatdan o'tish arizangiz rad etilgan.\n\nMaktab ma'muriyatiga murojaat qiling.",
 	ErrDatabaseError:     "❌ Xatolik yuz berdi. Iltimos, keyinroq urinib ko'ring.",
 	ErrUnknownCommand:    "❌ Noma'lum buyruq. /help ni bosing.",
 
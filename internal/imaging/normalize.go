@@ -0,0 +1,119 @@
+// Package imaging converts user-uploaded images into a form that can be embedded in PDFs.
+//
+// Parents send photos as Telegram documents in whatever format their phone
+// produces. gofpdf only embeds JPEG, PNG and GIF, and ignores EXIF orientation,
+// so every image is decoded, rotated upright, scaled down and re-encoded as JPEG.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// Registered decoders
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxDimension is the default cap for the longer image side in pixels
+const MaxDimension = 2000
+
+// MaxPixels caps width*height of an image to decode. A small, highly compressed
+// file can otherwise decode to gigabytes of pixels.
+const MaxPixels = 50_000_000
+
+// jpegQuality is the quality of re-encoded images
+const jpegQuality = 85
+
+// ErrUnsupportedFormat is returned for images that have no pure-Go decoder (e.g. HEIC)
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// ErrTooLarge is returned for images with more than MaxPixels pixels
+var ErrTooLarge = errors.New("image too large")
+
+// DetectFormat returns the image format detected from the data ("jpeg", "png",
+// "webp", "heic", ...), or "unknown"
+func DetectFormat(data []byte) string {
+	if _, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		return format
+	}
+
+	// ISO base media file (HEIF family): size, "ftyp", major brand
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		switch string(data[8:12]) {
+		case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1":
+			return "heic"
+		case "avif", "avis":
+			return "avif"
+		}
+	}
+
+	return "unknown"
+}
+
+// Normalize decodes an image in any supported format and re-encodes it as JPEG
+// with EXIF orientation applied and the longer side capped at maxDimension pixels.
+// Transparent areas become white. Images above MaxPixels are rejected before
+// they are decoded.
+func Normalize(data []byte, maxDimension int) ([]byte, error) {
+	// Unreadable headers are left to Decode, which reports the format
+	if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil && config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, config.Width, config.Height)
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if detected := DetectFormat(data); detected != "unknown" {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, detected)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	var img image.Image = flatten(src, maxDimension)
+
+	if format == "jpeg" || format == "tiff" {
+		img = Orient(img, ReadOrientation(data))
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// flatten draws the image onto a white background, scaled down to fit maxDimension
+func flatten(src image.Image, maxDimension int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if maxDimension > 0 && (width > maxDimension || height > maxDimension) {
+		if width >= height {
+			height = max(1, height*maxDimension/width)
+			width = maxDimension
+		} else {
+			width = max(1, width*maxDimension/height)
+			height = maxDimension
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	}
+
+	return dst
+}
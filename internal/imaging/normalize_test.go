@@ -0,0 +1,210 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+// testImage returns a w×h image with a red top-left pixel
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{B: 255, A: 255})
+		}
+	}
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	return img
+}
+
+// jpegWithOrientation encodes an image as JPEG with an EXIF APP1 segment
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	encoded := buf.Bytes()
+
+	// Big-endian TIFF header with a single IFD0 entry: orientation (SHORT, count 1)
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], exifOrientationTag)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, encoded[:2]...)
+	out = append(out, segment...)
+	return append(out, encoded[2:]...)
+}
+
+func decodeJPEG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.Decode() error = %v", err)
+	}
+	if format != "jpeg" {
+		t.Fatalf("output format = %s, want jpeg", format)
+	}
+	return img
+}
+
+func TestNormalizeFormats(t *testing.T) {
+	var pngData, bmpData bytes.Buffer
+	_ = png.Encode(&pngData, testImage(40, 30))
+	_ = bmp.Encode(&bmpData, testImage(40, 30))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"png", pngData.Bytes()},
+		{"bmp", bmpData.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat(tt.data); got != tt.name {
+				t.Errorf("DetectFormat() = %s, want %s", got, tt.name)
+			}
+
+			out, err := Normalize(tt.data, MaxDimension)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+
+			if b := decodeJPEG(t, out).Bounds(); b.Dx() != 40 || b.Dy() != 30 {
+				t.Errorf("output size = %dx%d, want 40x30", b.Dx(), b.Dy())
+			}
+		})
+	}
+}
+
+func TestNormalizeCapsResolution(t *testing.T) {
+	var data bytes.Buffer
+	_ = png.Encode(&data, testImage(400, 100))
+
+	out, err := Normalize(data.Bytes(), 200)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	if b := decodeJPEG(t, out).Bounds(); b.Dx() != 200 || b.Dy() != 50 {
+		t.Errorf("output size = %dx%d, want 200x50", b.Dx(), b.Dy())
+	}
+}
+
+func TestNormalizeAppliesOrientation(t *testing.T) {
+	// Red 10×10 block in the top-left corner, large enough to survive JPEG compression
+	src := testImage(40, 20)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			src.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	data := jpegWithOrientation(t, src, 6)
+
+	if got := ReadOrientation(data); got != 6 {
+		t.Fatalf("ReadOrientation() = %d, want 6", got)
+	}
+
+	out, err := Normalize(data, MaxDimension)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	img := decodeJPEG(t, out)
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Errorf("output size = %dx%d, want 20x40", b.Dx(), b.Dy())
+	}
+
+	// Rotated 90° clockwise: the red corner moves to the top right
+	if r, _, _, _ := img.At(15, 4).RGBA(); r < 0x8000 {
+		t.Error("top-right pixel is not red after rotation")
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := testImage(3, 2)
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		redX, redY  int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+
+	for _, tt := range tests {
+		img := Orient(src, tt.orientation)
+		if b := img.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("Orient(%d) size = %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if r, _, _, _ := img.At(tt.redX, tt.redY).RGBA(); r != 0xFFFF {
+			t.Errorf("Orient(%d) red pixel not at (%d, %d)", tt.orientation, tt.redX, tt.redY)
+		}
+	}
+}
+
+func TestNormalizeUnsupported(t *testing.T) {
+	heic := append([]byte{0, 0, 0, 24}, []byte("ftypheic\x00\x00\x00\x00mif1heic")...)
+
+	if got := DetectFormat(heic); got != "heic" {
+		t.Errorf("DetectFormat() = %s, want heic", got)
+	}
+
+	if _, err := Normalize(heic, MaxDimension); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Normalize(heic) error = %v, want ErrUnsupportedFormat", err)
+	}
+
+	if _, err := Normalize([]byte("not an image"), MaxDimension); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Normalize(garbage) error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestNormalizeRejectsHugeImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(2, 2)); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	// Claim 10000x10000 in the IHDR chunk, which follows the 8-byte signature;
+	// the pixel data is never reached
+	data := buf.Bytes()
+	ihdr := data[8:]
+	binary.BigEndian.PutUint32(ihdr[8:], 10000)
+	binary.BigEndian.PutUint32(ihdr[12:], 10000)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+
+	if _, err := Normalize(data, MaxDimension); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Normalize(10000x10000) error = %v, want ErrTooLarge", err)
+	}
+}
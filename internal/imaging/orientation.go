@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientationTag is the TIFF tag holding the EXIF orientation (1-8)
+const exifOrientationTag = 0x0112
+
+// ReadOrientation returns the EXIF orientation of JPEG or TIFF data, 1 (upright)
+// if there is none
+func ReadOrientation(data []byte) int {
+	// TIFF files carry the tag in their own IFD0
+	if orientation, ok := tiffOrientation(data); ok {
+		return orientation
+	}
+
+	// JPEG: look for the APP1 Exif segment before the image data
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 1
+		}
+
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan / end of image
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		segment := pos + 4
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+
+		if marker == 0xE1 && end-segment > 6 && string(data[segment:segment+6]) == "Exif\x00\x00" {
+			if orientation, ok := tiffOrientation(data[segment+6 : end]); ok {
+				return orientation
+			}
+			return 1
+		}
+
+		pos = end
+	}
+
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from TIFF-structured data
+func tiffOrientation(data []byte) (int, bool) {
+	if len(data) < 8 {
+		return 0, false
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	if order.Uint16(data[2:]) != 42 {
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(data[4:]))
+	if ifd+2 > len(data) {
+		return 0, false
+	}
+
+	count := int(order.Uint16(data[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(data) {
+			return 0, false
+		}
+
+		if order.Uint16(data[entry:]) == exifOrientationTag {
+			orientation := int(order.Uint16(data[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 0, false
+			}
+			return orientation, true
+		}
+	}
+
+	return 0, false
+}
+
+// Orient transforms an image so that it displays upright for the given EXIF orientation
+func Orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 { // 90° rotations swap width and height
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -2,6 +2,10 @@ package models
 
 import "time"
 
+// DefaultAdminName is the name of admins created from ADMIN_PHONES until they
+// use the bot and their Telegram first name is known
+const DefaultAdminName = "Admin"
+
 // Admin represents an admin user
 type Admin struct {
 	ID          int       `json:"id" db:"id"`
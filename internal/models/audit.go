@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Audited actions
+const (
+	AuditRevealAnonymous = "reveal_anonymous" // a super-admin revealed the author of an anonymous complaint
+)
+
+// AuditEntry records a sensitive action taken by a staff member
+type AuditEntry struct {
+	ID              int       `json:"id" db:"id"`
+	ActorTelegramID int64     `json:"actor_telegram_id" db:"actor_telegram_id"`
+	Action          string    `json:"action" db:"action"`
+	ItemKind        string    `json:"item_kind" db:"item_kind"` // DocumentJobKindComplaint or DocumentJobKindProposal
+	ItemID          int       `json:"item_id" db:"item_id"`
+	Reason          string    `json:"reason" db:"reason"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Category is a complaint category parents choose from
+type Category struct {
+	ID        int       `json:"id" db:"id"`
+	NameUz    string    `json:"name_uz" db:"name_uz"`
+	NameRu    string    `json:"name_ru" db:"name_ru"`
+	SortOrder int       `json:"sort_order,omitempty" db:"sort_order"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at"`
+}
+
+// Name returns the category name in a language ("uz" or "ru")
+func (c *Category) Name(lang string) string {
+	if lang == "ru" {
+		return c.NameRu
+	}
+	return c.NameUz
+}
+
+// Label returns the bilingual name shown to admins and in documents, "" for
+// a nil category
+func (c *Category) Label() string {
+	if c == nil {
+		return ""
+	}
+	return c.NameUz + " / " + c.NameRu
+}
@@ -4,10 +4,12 @@ import "time"
 
 // Class represents a school class
 type Class struct {
-	ID        int       `json:"id" db:"id"`
-	ClassName string    `json:"class_name" db:"class_name"`
-	IsActive  bool      `json:"is_active" db:"is_active"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID                int       `json:"id" db:"id"`
+	ClassName         string    `json:"class_name" db:"class_name"`
+	IsActive          bool      `json:"is_active" db:"is_active"`
+	TeacherPhone      string    `json:"teacher_phone,omitempty" db:"teacher_phone"`
+	TeacherTelegramID *int64    `json:"teacher_telegram_id,omitempty" db:"teacher_telegram_id"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 }
 
 // CreateClassRequest is the request to create a new class
@@ -11,6 +11,10 @@ type Complaint struct {
 	PDFFilename        string    `json:"pdf_filename" db:"pdf_filename"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 	Status             string    `json:"status" db:"status"`
+	Category           *Category `json:"category,omitempty" db:"-"` // ID and names only, nil if none
+	IsUrgent           bool      `json:"is_urgent" db:"is_urgent"`
+	IsAnonymous        bool      `json:"is_anonymous" db:"is_anonymous"`
+	Version            int       `json:"version" db:"version"` // 1 for the original text, incremented on each correction
 }
 
 // ComplaintImage represents an image attached to a complaint
@@ -39,6 +43,23 @@ type ComplaintWithUser struct {
 	PhoneNumber        string    `json:"phone_number" db:"phone_number"`
 	ChildName          string    `json:"child_name" db:"child_name"`
 	ChildClass         string    `json:"child_class" db:"child_class"`
+	Category           *Category `json:"category,omitempty" db:"-"` // ID and names only, nil if none
+	IsUrgent           bool      `json:"is_urgent" db:"is_urgent"`
+	IsAnonymous        bool      `json:"is_anonymous" db:"is_anonymous"`
+}
+
+// HideAuthor removes the author's identity from an anonymous complaint shown to
+// staff. The user ID goes too, since it would match the user list.
+func (c *ComplaintWithUser) HideAuthor() {
+	if !c.IsAnonymous {
+		return
+	}
+	c.UserID = 0
+	c.UserTelegramID = 0
+	c.TelegramUsername = ""
+	c.PhoneNumber = AnonymousPlaceholder
+	c.ChildName = AnonymousName
+	c.ChildClass = AnonymousPlaceholder
 }
 
 // ComplaintWithImages represents a complaint with its associated images
@@ -50,15 +71,41 @@ type ComplaintWithImages struct {
 // CreateComplaintRequest is the request to create a new complaint
 type CreateComplaintRequest struct {
 	UserID            int      `json:"user_id" validate:"required"`
+	CategoryID        *int     `json:"category_id"`
+	IsUrgent          bool     `json:"is_urgent"`
+	IsAnonymous       bool     `json:"is_anonymous"`
 	ComplaintText     string   `json:"complaint_text" validate:"required,min=10,max=5000"`
 	ImageFileIDs      []string `json:"image_file_ids"` // Array of Telegram file IDs for images
 	PDFTelegramFileID string   `json:"pdf_telegram_file_id" validate:"required"`
 	PDFFilename       string   `json:"pdf_filename" validate:"required"`
 }
 
+// ComplaintReply is a staff reply relayed to the author of a complaint
+type ComplaintReply struct {
+	ID              int       `json:"id" db:"id"`
+	ComplaintID     int       `json:"complaint_id" db:"complaint_id"`
+	AdminTelegramID int64     `json:"admin_telegram_id" db:"admin_telegram_id"`
+	AdminName       string    `json:"admin_name" db:"admin_name"` // First name shown to the parent, "" if unknown
+	ReplyText       string    `json:"reply_text" db:"reply_text"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// ComplaintVersion is an earlier text of a complaint the parent corrected, with its PDF
+type ComplaintVersion struct {
+	ID                int       `json:"id" db:"id"`
+	ComplaintID       int       `json:"complaint_id" db:"complaint_id"`
+	Version           int       `json:"version" db:"version"`
+	ComplaintText     string    `json:"complaint_text" db:"complaint_text"`
+	PDFTelegramFileID string    `json:"pdf_telegram_file_id" db:"pdf_telegram_file_id"`
+	PDFFilename       string    `json:"pdf_filename" db:"pdf_filename"`
+	SubmittedAt       time.Time `json:"submitted_at" db:"submitted_at"`
+	ReplacedAt        time.Time `json:"replaced_at" db:"replaced_at"`
+}
+
 // ComplaintStatus constants
 const (
-	StatusPending  = "pending"
-	StatusReviewed = "reviewed"
-	StatusArchived = "archived"
+	StatusPending   = "pending"
+	StatusReviewed  = "reviewed"
+	StatusArchived  = "archived"
+	StatusWithdrawn = "withdrawn" // withdrawn by the parent before review
 )
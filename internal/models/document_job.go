@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Document job kinds
+const (
+	DocumentJobKindComplaint = "complaint"
+	DocumentJobKindProposal  = "proposal"
+)
+
+// Document job statuses
+const (
+	DocumentJobQueued  = "queued"
+	DocumentJobRunning = "running"
+	DocumentJobDone    = "done"
+	DocumentJobFailed  = "failed"
+)
+
+// DocumentJob is a queued PDF generation for a saved complaint or proposal
+type DocumentJob struct {
+	ID            int       `json:"id" db:"id"`
+	Kind          string    `json:"kind" db:"kind"`
+	ItemID        int       `json:"item_id" db:"item_id"`
+	ChatID        int64     `json:"chat_id" db:"chat_id"`
+	MessageID     int       `json:"message_id" db:"message_id"`
+	Status        string    `json:"status" db:"status"`
+	Attempts      int       `json:"attempts" db:"attempts"`
+	LastError     string    `json:"last_error" db:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
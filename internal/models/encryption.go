@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Data key purposes
+const (
+	DataKeyPurposeData  = "data"  // Encrypts field values
+	DataKeyPurposeIndex = "index" // Keys the phone number blind index
+)
+
+// DataKey represents an encryption key wrapped with the master key
+type DataKey struct {
+	ID         int       `json:"id" db:"id"`
+	Purpose    string    `json:"purpose" db:"purpose"`
+	WrappedKey string    `json:"-" db:"wrapped_key"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
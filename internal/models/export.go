@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ExportFilter selects the rows included in an export: complaints and proposals
+// by submission date, users by registration date. Zero values don't restrict the export.
+type ExportFilter struct {
+	From       time.Time // Inclusive
+	To         time.Time // Exclusive
+	Status     string    // Complaint/proposal status or user approval status
+	ChildClass string
+	CategoryID int // Complaint category, ignored for proposals and users
+}
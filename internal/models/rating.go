@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ComplaintRating is a parent's rating of how their reviewed complaint was handled
+type ComplaintRating struct {
+	ID              int        `json:"id" db:"id"`
+	ComplaintID     int        `json:"complaint_id" db:"complaint_id"`
+	StaffTelegramID int64      `json:"staff_telegram_id" db:"staff_telegram_id"` // who marked the complaint reviewed
+	StaffName       string     `json:"staff_name" db:"staff_name"`               // "" if unknown
+	Rating          *int       `json:"rating" db:"rating"`                       // 1 to 5, nil until the parent answers
+	Comment         string     `json:"comment" db:"comment"`
+	RequestedAt     time.Time  `json:"requested_at" db:"requested_at"`
+	RatedAt         *time.Time `json:"rated_at" db:"rated_at"`
+}
+
+// Rating bounds
+const (
+	MinRating = 1
+	MaxRating = 5
+)
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RegistryEntry records the SHA-256 of a generated complaint or proposal PDF.
+// Entries form a hash chain: each one includes the hash of the entry before it.
+type RegistryEntry struct {
+	ID           int       `json:"id" db:"id"`
+	Code         string    `json:"code" db:"code"` // Verification code printed in the PDF footer
+	Kind         string    `json:"kind" db:"kind"` // DocumentJobKindComplaint or DocumentJobKindProposal
+	ItemID       int       `json:"item_id" db:"item_id"`
+	DocumentHash string    `json:"document_hash" db:"document_hash"`
+	PrevHash     string    `json:"prev_hash" db:"prev_hash"`
+	EntryHash    string    `json:"entry_hash" db:"entry_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Report frequencies: weekly reports are sent on Monday for the week before,
+// monthly reports on the 1st for the month before
+const (
+	ReportWeekly  = "weekly"
+	ReportMonthly = "monthly"
+)
+
+// ReportSubscription is an admin's subscription to scheduled reports
+type ReportSubscription struct {
+	ID         int
+	AdminID    int
+	TelegramID *int64 // nil until the admin has linked their Telegram account
+	Frequency  string
+	SendHour   int // local hour of day the report is sent at
+	LastSentAt *time.Time
+	UpdatedAt  time.Time
+}
+
+// AnnouncementReach is how many parents an announcement was delivered to
+type AnnouncementReach struct {
+	ID        int
+	Title     string
+	CreatedAt time.Time
+	Delivered int
+	Failed    int
+}
+
+// AdminReport is the content of a scheduled report for the period [From, To)
+type AdminReport struct {
+	Frequency        string
+	From             time.Time
+	To               time.Time
+	Complaints       int
+	Proposals        int
+	Classes          []ClassStats
+	Categories       []CategoryStats
+	Statuses         []StatusStats
+	MedianReview     *float64      // hours, nil if nothing of the period was reviewed
+	StaleAfterDays   int           // pending items older than this are unresolved
+	Unresolved       []*Submission // pending at report time, oldest first
+	Registrations    int           // parents registered in the period
+	PendingApprovals int           // registrations waiting for approval at report time
+	Announcements    []AnnouncementReach
+	GeneratedAt      time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RetentionReport summarizes what a retention run changed (or would change, for a dry run)
+type RetentionReport struct {
+	DryRun             bool      `json:"dry_run"`
+	ArchivedComplaints int       `json:"archived_complaints"`
+	ArchivedProposals  int       `json:"archived_proposals"`
+	PurgedComplaints   int       `json:"purged_complaints"`
+	PurgedProposals    int       `json:"purged_proposals"`
+	DeletedUsers       int       `json:"deleted_users"`
+	RunAt              time.Time `json:"run_at"`
+}
+
+// Total returns the number of affected rows
+func (r *RetentionReport) Total() int {
+	return r.ArchivedComplaints + r.ArchivedProposals + r.PurgedComplaints + r.PurgedProposals + r.DeletedUsers
+}
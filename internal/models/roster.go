@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RosterEntry is a parent pre-registered by an admin from an imported roster.
+// The entry is claimed when the phone registers with the bot.
+type RosterEntry struct {
+	ID          int       `json:"id" db:"id"`
+	PhoneNumber string    `json:"phone_number" db:"phone_number"`
+	ChildName   string    `json:"child_name" db:"child_name"`
+	ChildClass  string    `json:"child_class" db:"child_class"`
+	ImportedAt  time.Time `json:"imported_at" db:"imported_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Escalation levels of an overdue complaint or proposal: the last group of
+// people reminded about it
+const (
+	EscalationNone     = 0
+	EscalationStaff    = 1 // the assigned admin, or the teacher of the parent's class
+	EscalationAdmins   = 2 // all admins
+	EscalationDirector = 3 // the director
+)
+
+// SLAItem is a pending complaint or proposal as tracked against its review deadline
+type SLAItem struct {
+	ID                 int
+	Kind               string // DocumentJobKindComplaint or DocumentJobKindProposal
+	ChildClass         string // "" if the parent has been deleted or the complaint is anonymous
+	TeacherTelegramID  *int64 // nil if the class has no linked teacher or the complaint is anonymous
+	AssigneeTelegramID *int64 // nil if the complaint is not assigned; proposals never are
+	CreatedAt          time.Time
+	FirstResponseAt    *time.Time
+	EscalationLevel    int
+}
+
+// UrgentItem is an urgent complaint no staff member has acknowledged yet
+type UrgentItem struct {
+	ID         int
+	ChildClass string    // "" if the parent has been deleted or the complaint is anonymous
+	Category   *Category // ID and names only, nil if none
+	CreatedAt  time.Time
+	AlertCount int // alerts sent so far
+}
+
+// OverdueCounts are the pending complaints and proposals past their deadline
+type OverdueCounts struct {
+	Complaints int `json:"complaints"`
+	Proposals  int `json:"proposals"`
+}
+
+// Total returns the number of overdue items
+func (c OverdueCounts) Total() int {
+	return c.Complaints + c.Proposals
+}
@@ -28,7 +28,11 @@ type StateData struct {
 	ChildName          string      `json:"child_name,omitempty"`
 	ChildClass         string      `json:"child_class,omitempty"`
 	Language           string      `json:"language,omitempty"`
+	CategoryID         int         `json:"category_id,omitempty"`    // Complaint category, or the category being renamed
 	ComplaintText      string      `json:"complaint_text,omitempty"`
+	IsUrgent           bool        `json:"is_urgent,omitempty"`      // Complaint marked urgent by the parent
+	IsAnonymous        bool        `json:"is_anonymous,omitempty"`   // Complaint sent without the parent's identity
+	ComplaintID        int         `json:"complaint_id,omitempty"`   // Complaint a staff member is replying to, or the parent is correcting or rating
 	ProposalText       string      `json:"proposal_text,omitempty"`
 	AnnouncementTitle  string      `json:"announcement_title,omitempty"`
 	AnnouncementText   string      `json:"announcement_text,omitempty"`
@@ -44,6 +48,7 @@ const (
 	StateAwaitingChildName          = "awaiting_child_name"
 	StateAwaitingChildClass         = "awaiting_child_class"
 	StateRegistered                 = "registered"
+	StateAwaitingComplaintCategory  = "awaiting_complaint_category"
 	StateAwaitingComplaint          = "awaiting_complaint"
 	StateAwaitingImages             = "awaiting_images"          // State for collecting complaint images
 	StateConfirmingComplaint        = "confirming_complaint"
@@ -51,8 +56,15 @@ const (
 	StateAwaitingProposalImages     = "awaiting_proposal_images" // State for collecting proposal images
 	StateConfirmingProposal         = "confirming_proposal"
 	StateAwaitingAdminPhone         = "awaiting_admin_phone"
+	StateAwaitingNewPhone           = "awaiting_new_phone"
+	StateConfirmingAccountDeletion  = "confirming_account_deletion"
 	StateAwaitingClassName          = "awaiting_class_name"
+	StateAwaitingCategoryName       = "awaiting_category_name"
 	StateAwaitingAnnouncementTitle  = "awaiting_announcement_title"
 	StateAwaitingAnnouncementText   = "awaiting_announcement_text"
 	StateAwaitingAnnouncementImage  = "awaiting_announcement_image"
+	StateAwaitingRosterFile         = "awaiting_roster_file"
+	StateAwaitingComplaintReply     = "awaiting_complaint_reply"
+	StateAwaitingComplaintEdit      = "awaiting_complaint_edit"
+	StateAwaitingRatingComment      = "awaiting_rating_comment"
 )
@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+// Submission is a complaint or proposal as counted in statistics
+type Submission struct {
+	ID         int
+	Kind       string // DocumentJobKindComplaint or DocumentJobKindProposal
+	UserID     int
+	ChildClass string    // "" if the parent has been deleted
+	Category   *Category // complaint category (ID and names only), nil if none; proposals have none
+	Status     string
+	CreatedAt  time.Time
+	ReviewedAt *time.Time
+}
+
+// RatedComplaint is a parent's rating of a reviewed complaint as counted in statistics
+type RatedComplaint struct {
+	ComplaintID     int
+	Category        *Category // ID and names only, nil if none
+	StaffTelegramID int64
+	StaffName       string // "" if unknown
+	Rating          int
+	RatedAt         time.Time
+}
+
+// WeeklyStats are the submissions of one week, Monday to Sunday
+type WeeklyStats struct {
+	WeekStart         time.Time `json:"week_start"`
+	Complaints        int       `json:"complaints"`
+	Proposals         int       `json:"proposals"`
+	ActiveParents     int       `json:"active_parents"`      // parents who submitted anything that week
+	MedianReviewHours *float64  `json:"median_review_hours"` // of the week's submissions reviewed so far, nil if none
+}
+
+// ClassStats are the submissions of one class
+type ClassStats struct {
+	Class      string `json:"class"`
+	Complaints int    `json:"complaints"`
+	Proposals  int    `json:"proposals"`
+}
+
+// CategoryStats are the complaints of one category; Category is nil for
+// complaints without one
+type CategoryStats struct {
+	Category   *Category `json:"category"`
+	Complaints int       `json:"complaints"`
+}
+
+// StatusStats are the submissions currently in one status
+type StatusStats struct {
+	Status     string `json:"status"`
+	Complaints int    `json:"complaints"`
+	Proposals  int    `json:"proposals"`
+}
+
+// StaffSatisfaction is the average rating of the complaints one staff member reviewed
+type StaffSatisfaction struct {
+	StaffTelegramID int64   `json:"staff_telegram_id"`
+	StaffName       string  `json:"staff_name"` // latest known first name, "" if unknown
+	Ratings         int     `json:"ratings"`
+	Average         float64 `json:"average"`
+}
+
+// CategorySatisfaction is the average rating of the complaints of one category;
+// Category is nil for complaints without one
+type CategorySatisfaction struct {
+	Category *Category `json:"category"`
+	Ratings  int       `json:"ratings"`
+	Average  float64   `json:"average"`
+}
+
+// MonthlySatisfaction is the average rating given in one calendar month
+type MonthlySatisfaction struct {
+	Month   time.Time `json:"month"` // first day of the month
+	Ratings int       `json:"ratings"`
+	Average float64   `json:"average"`
+}
+
+// Satisfaction are the parents' ratings of reviewed complaints, from 1 to 5
+type Satisfaction struct {
+	Ratings    int                    `json:"ratings"`
+	Average    *float64               `json:"average"` // nil if there are no ratings
+	Staff      []StaffSatisfaction    `json:"staff"`
+	Categories []CategorySatisfaction `json:"categories"`
+	Months     []MonthlySatisfaction  `json:"months"`
+}
+
+// StatsDashboard holds the statistics of submissions created in [From, To)
+type StatsDashboard struct {
+	From              time.Time       `json:"from"`
+	To                time.Time       `json:"to"`
+	Weeks             []WeeklyStats   `json:"weeks"`
+	CategoryID        *int            `json:"category_id,omitempty"` // only complaints of this category are counted
+	Classes           []ClassStats    `json:"classes"`
+	Categories        []CategoryStats `json:"categories"`
+	Statuses          []StatusStats   `json:"statuses"`
+	Complaints        int             `json:"complaints"`
+	Proposals         int             `json:"proposals"`
+	ActiveParents     int             `json:"active_parents"`
+	MedianReviewHours *float64        `json:"median_review_hours"`
+	Satisfaction      Satisfaction    `json:"satisfaction"` // ratings given in the period
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SubmissionTimeline are the moments a complaint or proposal changed, as shown
+// to its author; each is nil until it happened
+type SubmissionTimeline struct {
+	FirstResponseAt *time.Time // taken in hand, or reviewed right away
+	ReviewedAt      *time.Time
+	WithdrawnAt     *time.Time // complaints only
+	PurgedAt        *time.Time // text, images and PDF deleted after the retention period
+}
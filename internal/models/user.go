@@ -11,9 +11,37 @@ type User struct {
 	ChildName        string    `json:"child_name" db:"child_name"`
 	ChildClass       string    `json:"child_class" db:"child_class"`
 	Language         string    `json:"language" db:"language"`
+	ApprovalStatus   string    `json:"approval_status" db:"approval_status"`
 	RegisteredAt     time.Time `json:"registered_at" db:"registered_at"`
 }
 
+// IsApproved reports whether the user may submit complaints and proposals
+func (u *User) IsApproved() bool {
+	return u.ApprovalStatus == "" || u.ApprovalStatus == ApprovalApproved
+}
+
+// Shown to staff in place of the author of an anonymous complaint
+const (
+	AnonymousName        = "Anonim / Аноним"
+	AnonymousPlaceholder = "—"
+)
+
+// Anonymized returns a copy of the user without name, phone or child, for
+// showing an anonymous complaint to staff; TelegramID and Language are kept
+// so replies can still be relayed to the parent
+func (u *User) Anonymized() *User {
+	return &User{
+		ID:             u.ID,
+		TelegramID:     u.TelegramID,
+		PhoneNumber:    AnonymousPlaceholder,
+		ChildName:      AnonymousName,
+		ChildClass:     AnonymousPlaceholder,
+		Language:       u.Language,
+		ApprovalStatus: u.ApprovalStatus,
+		RegisteredAt:   u.RegisteredAt,
+	}
+}
+
 // CreateUserRequest is the request to create a new user
 type CreateUserRequest struct {
 	TelegramID       int64  `json:"telegram_id" validate:"required"`
@@ -22,6 +50,7 @@ type CreateUserRequest struct {
 	ChildName        string `json:"child_name" validate:"required,min=2,max=255"`
 	ChildClass       string `json:"child_class" validate:"required"`
 	Language         string `json:"language" validate:"required,oneof=uz ru"`
+	ApprovalStatus   string `json:"approval_status" validate:"omitempty,oneof=pending approved"`
 }
 
 // UpdateUserRequest is the request to update user data
@@ -30,3 +59,21 @@ type UpdateUserRequest struct {
 	ChildClass string `json:"child_class,omitempty"`
 	Language   string `json:"language,omitempty" validate:"omitempty,oneof=uz ru"`
 }
+
+// Registration approval statuses
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// UserDataExport is everything stored about a user, as included in their data export
+type UserDataExport struct {
+	ExportedAt time.Time             `json:"exported_at"`
+	User       *User                 `json:"user"`
+	Complaints []ComplaintWithImages `json:"complaints"`
+	Proposals  []ProposalWithImages  `json:"proposals"`
+	Replies    []ComplaintReply      `json:"replies"`  // Staff replies to the complaints
+	Versions   []ComplaintVersion    `json:"versions"` // Earlier texts of corrected complaints
+	Ratings    []ComplaintRating     `json:"ratings"`
+}
@@ -0,0 +1,117 @@
+package pdftext
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// variationSelector16 requests emoji presentation. Twemoji file names drop it
+// for most sequences but keep it inside some ZWJ sequences.
+const variationSelector16 = 0xFE0F
+
+// emojiSet holds Twemoji PNGs keyed by their code point sequence, e.g.
+// "1f92e" or "1f468-200d-1f469-200d-1f467"
+type emojiSet struct {
+	images map[string][]byte
+	starts map[rune]bool // First code point of every sequence
+	maxLen int           // Longest sequence in code points
+}
+
+// loadEmoji reads a zip of Twemoji PNGs named "<code points>.png"
+func loadEmoji(path string) (*emojiSet, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open emoji images: %w", err)
+	}
+	defer archive.Close()
+
+	set := &emojiSet{
+		images: make(map[string][]byte, len(archive.File)),
+		starts: make(map[rune]bool),
+	}
+
+	for _, file := range archive.File {
+		key, ok := strings.CutSuffix(file.Name, ".png")
+		if !ok {
+			continue
+		}
+
+		runes, err := parseEmojiKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid emoji image name %s: %w", file.Name, err)
+		}
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read emoji image %s: %w", file.Name, err)
+		}
+
+		set.images[key] = data
+		set.starts[runes[0]] = true
+		set.maxLen = max(set.maxLen, len(runes))
+	}
+
+	return set, nil
+}
+
+// match returns the image key and length in runes of the longest emoji
+// sequence at the start of runes, or n == 0 if there is none
+func (s *emojiSet) match(runes []rune) (key string, n int) {
+	if len(runes) == 0 || !s.starts[runes[0]] {
+		return "", 0
+	}
+
+	// Text may carry VS16s the file name drops, so look past maxLen
+	for n = min(len(runes), 2*s.maxLen); n > 0; n-- {
+		if key = emojiKey(runes[:n], true); s.images[key] != nil {
+			return key, n
+		}
+		if key = emojiKey(runes[:n], false); s.images[key] != nil {
+			return key, n
+		}
+	}
+
+	return "", 0
+}
+
+// emojiKey formats code points the way Twemoji names its files
+func emojiKey(runes []rune, keepVS16 bool) string {
+	var b strings.Builder
+	for _, r := range runes {
+		if r == variationSelector16 && !keepVS16 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(strconv.FormatInt(int64(r), 16))
+	}
+	return b.String()
+}
+
+// parseEmojiKey parses a Twemoji file name back into code points
+func parseEmojiKey(key string) ([]rune, error) {
+	parts := strings.Split(key, "-")
+	runes := make([]rune, 0, len(parts))
+	for _, part := range parts {
+		cp, err := strconv.ParseUint(part, 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, rune(cp))
+	}
+	return runes, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,166 @@
+// Package pdftext writes user text into gofpdf documents without losing characters.
+//
+// DejaVu Sans covers Latin, Cyrillic and most symbols but no emoji, and gofpdf
+// can only draw characters up to U+FFFF. Text is therefore split into runs:
+// characters DejaVu has are written with it, other BMP characters with GNU
+// Unifont, and emoji are drawn as small Twemoji images. Images and characters
+// no font has are wrapped in ActualText, so copying or extracting text from
+// the PDF returns the original input.
+package pdftext
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Family is the font family of the primary font, registered in "" and "B" styles
+const Family = "DejaVu"
+
+// Files expected in the fonts directory
+const (
+	regularFile  = "DejaVuSans.ttf"
+	boldFile     = "DejaVuSans-Bold.ttf"
+	fallbackFile = "unifont.ttf"
+	emojiFile    = "twemoji-72x72.zip"
+)
+
+const (
+	fallbackFamily  = "Unifont"
+	replacementChar = "\uFFFD"
+)
+
+// Fonts holds the font data and emoji images shared by all documents.
+// It is safe for concurrent use.
+type Fonts struct {
+	regular     []byte
+	bold        []byte
+	fallback    []byte
+	regularSet  *charset
+	boldSet     *charset
+	fallbackSet *charset
+	emoji       *emojiSet
+}
+
+// Load loads the fonts and emoji images from dir
+func Load(dir string) (*Fonts, error) {
+	f := &Fonts{}
+
+	for _, font := range []struct {
+		file string
+		data *[]byte
+		set  **charset
+	}{
+		{regularFile, &f.regular, &f.regularSet},
+		{boldFile, &f.bold, &f.boldSet},
+		{fallbackFile, &f.fallback, &f.fallbackSet},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, font.file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read font: %w", err)
+		}
+
+		set, err := newCharset(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", font.file, err)
+		}
+
+		*font.data = data
+		*font.set = set
+	}
+
+	emoji, err := loadEmoji(filepath.Join(dir, emojiFile))
+	if err != nil {
+		return nil, err
+	}
+	f.emoji = emoji
+
+	return f, nil
+}
+
+// Register adds the primary font to a document. The fallback font is added
+// on first use, so documents that don't need it don't embed it.
+func (f *Fonts) Register(pdf *gofpdf.Fpdf) {
+	pdf.AddUTF8FontFromBytes(Family, "", f.regular)
+	pdf.AddUTF8FontFromBytes(Family, "B", f.bold)
+}
+
+// Write writes text from the current position like gofpdf's Write, wrapping
+// at the right margin and breaking pages as needed. style is the primary font
+// style ("" or "B") and the size is that of the current font. The primary font
+// is selected afterwards.
+func (f *Fonts) Write(pdf *gofpdf.Fpdf, lineHeight float64, style, text string) {
+	size, _ := pdf.GetFontSize()
+
+	primary := f.regularSet
+	if strings.Contains(style, "B") {
+		primary = f.boldSet
+	}
+
+	for _, run := range f.split(text, primary) {
+		switch run.kind {
+		case runPrimary:
+			pdf.SetFont(Family, style, size)
+			writeText(pdf, lineHeight, run.text)
+
+		case runFallback:
+			f.setFallbackFont(pdf, size)
+			writeText(pdf, lineHeight, run.text)
+
+		case runEmoji:
+			f.writeEmoji(pdf, lineHeight, run)
+
+		case runMissing:
+			f.setFallbackFont(pdf, size)
+			width := pdf.GetStringWidth(replacementChar)
+			reserve(pdf, width, lineHeight)
+			pdf.RawWriteStr(beginActualText(run.text))
+			pdf.CellFormat(width, lineHeight, replacementChar, "", 0, "L", false, 0, "")
+			pdf.RawWriteStr("EMC")
+		}
+	}
+
+	pdf.SetFont(Family, style, size)
+}
+
+// setFallbackFont selects the fallback font, adding it to the document if needed
+func (f *Fonts) setFallbackFont(pdf *gofpdf.Fpdf, size float64) {
+	pdf.AddUTF8FontFromBytes(fallbackFamily, "", f.fallback)
+	pdf.SetFont(fallbackFamily, "", size)
+}
+
+// writeEmoji draws an emoji image one em wide, vertically centred on the line
+// and offset by the cell margin like text
+func (f *Fonts) writeEmoji(pdf *gofpdf.Fpdf, lineHeight float64, run run) {
+	name := "emoji-" + run.key
+	options := gofpdf.ImageOptions{ImageType: "PNG"}
+	if pdf.GetImageInfo(name) == nil {
+		pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(f.emoji.images[run.key]))
+	}
+
+	_, em := pdf.GetFontSize()
+	reserve(pdf, em, lineHeight)
+
+	x, y := pdf.GetXY()
+	pdf.RawWriteStr(beginActualText(run.text))
+	pdf.ImageOptions(name, x+pdf.GetCellMargin(), y+(lineHeight-em)/2, em, em, false, options, 0, "")
+	pdf.RawWriteStr("EMC")
+	pdf.SetX(x + em)
+}
+
+// beginActualText starts a marked-content span whose text is replaced by text
+// when copied or extracted
+func beginActualText(text string) string {
+	var b strings.Builder
+	b.WriteString("/Span <</ActualText <FEFF")
+	for _, unit := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">>> BDC")
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package pdftext
+
+import (
+	"github.com/jung-kurt/gofpdf"
+)
+
+// writeText writes text in the current font from the current position,
+// breaking lines between words and, for words wider than a line, between
+// characters.
+//
+// gofpdf's Write can't be used: it breaks the line before every zero-width
+// glyph (combining marks), and drops a lone space.
+func writeText(pdf *gofpdf.Fpdf, lineHeight float64, text string) {
+	for _, token := range tokenize(text) {
+		width := pdf.GetStringWidth(token)
+
+		switch {
+		case token == "\n":
+			pdf.Ln(lineHeight)
+
+		case token[0] == ' ':
+			// A line break replaces spaces that don't fit
+			if width > remaining(pdf) {
+				pdf.Ln(lineHeight)
+				continue
+			}
+			cell(pdf, lineHeight, width, token)
+
+		case width > lineWidth(pdf):
+			breakWord(pdf, lineHeight, token)
+
+		default:
+			cell(pdf, lineHeight, width, token)
+		}
+	}
+}
+
+// tokenize splits text into words, runs of spaces and newlines
+func tokenize(text string) []string {
+	var tokens []string
+	start := 0
+	for i, r := range text {
+		if i == start {
+			continue
+		}
+		prev := text[i-1]
+		if r == '\n' || prev == '\n' || (r == ' ') != (prev == ' ') {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// breakWord writes a word wider than a line, filling each line with as many
+// characters as fit
+func breakWord(pdf *gofpdf.Fpdf, lineHeight float64, word string) {
+	if pdf.GetX() > leftMargin(pdf) {
+		pdf.Ln(lineHeight)
+	}
+
+	start, width := 0, 0.0
+	for i, r := range word {
+		w := pdf.GetStringWidth(string(r))
+		if i > start && width+w > remaining(pdf) {
+			cell(pdf, lineHeight, width, word[start:i])
+			pdf.Ln(lineHeight)
+			start, width = i, 0
+		}
+		width += w
+	}
+	cell(pdf, lineHeight, width, word[start:])
+}
+
+// cell writes text that fits on the current line, moving to the next line or
+// page first if needed
+func cell(pdf *gofpdf.Fpdf, lineHeight, width float64, text string) {
+	reserve(pdf, width, lineHeight)
+	pdf.CellFormat(width, lineHeight, text, "", 0, "L", false, 0, "")
+}
+
+// reserve moves to the next line if width doesn't fit on the current one and
+// to the next page if the line doesn't fit on the page. Drawing that follows
+// then can't be split by an automatic page break.
+func reserve(pdf *gofpdf.Fpdf, width, lineHeight float64) {
+	if width > remaining(pdf) && pdf.GetX() > leftMargin(pdf) {
+		pdf.Ln(lineHeight)
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	if auto, bottom := pdf.GetAutoPageBreak(); auto && pdf.GetY()+lineHeight > pageHeight-bottom {
+		pdf.AddPage()
+	}
+}
+
+// remaining returns the width left on the current line. Cells draw their text
+// one cell margin to the right of the current position.
+func remaining(pdf *gofpdf.Fpdf) float64 {
+	pageWidth, _ := pdf.GetPageSize()
+	_, _, right, _ := pdf.GetMargins()
+	return pageWidth - right - pdf.GetCellMargin() - pdf.GetX()
+}
+
+// lineWidth returns the width of a whole line
+func lineWidth(pdf *gofpdf.Fpdf) float64 {
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	return pageWidth - left - right - pdf.GetCellMargin()
+}
+
+func leftMargin(pdf *gofpdf.Fpdf) float64 {
+	left, _, _, _ := pdf.GetMargins()
+	return left
+}
@@ -0,0 +1,329 @@
+package pdftext
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var loadFonts = sync.OnceValues(func() (*Fonts, error) {
+	return Load("../../fonts")
+})
+
+func testFonts(t *testing.T) *Fonts {
+	t.Helper()
+
+	fonts, err := loadFonts()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return fonts
+}
+
+// render writes text into a new uncompressed A4 document
+func render(t *testing.T, fonts *Fonts, text string) []byte {
+	t.Helper()
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	fonts.Register(pdf)
+	pdf.AddPage()
+	pdf.SetFont(Family, "", 11)
+	fonts.Write(pdf, 6, "", text)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// pageContents returns the content stream of every page of an uncompressed gofpdf document
+func pageContents(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+
+	var pages [][]byte
+	for _, m := range regexp.MustCompile(`/Contents (\d+) 0 R`).FindAllSubmatch(data, -1) {
+		obj := regexp.MustCompile(`\n` + string(m[1]) + ` 0 obj\n<</Length (\d+)>>\nstream\n`)
+		loc := obj.FindSubmatchIndex(data)
+		if loc == nil {
+			t.Fatalf("content stream %s not found", m[1])
+		}
+		length, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		pages = append(pages, data[loc[1]:loc[1]+length])
+	}
+	return pages
+}
+
+// extractText returns the text of a document the way a PDF reader copies it:
+// strings shown with Tj, marked content replaced by its ActualText, and a
+// newline wherever the vertical position changes
+func extractText(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var out strings.Builder
+	for i, content := range pageContents(t, data) {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+
+		var (
+			operands []string
+			lastY    = -1.0
+			actual   string // ActualText waiting for its position
+			hidden   int    // Depth of marked content covered by ActualText
+		)
+
+		moveTo := func(y float64) {
+			if lastY >= 0 && (y < lastY-5 || y > lastY+5) {
+				out.WriteString("\n")
+			}
+			lastY = y
+			if actual != "" {
+				out.WriteString(actual)
+				actual = ""
+			}
+		}
+
+		for _, token := range contentTokens(content) {
+			switch token {
+			case "BDC":
+				if len(operands) >= 3 && operands[len(operands)-3] == "/ActualText" {
+					actual = decodeUTF16(operands[len(operands)-2])
+					hidden++
+				}
+			case "EMC":
+				if hidden > 0 {
+					hidden--
+					out.WriteString(actual)
+					actual = ""
+				}
+			case "Td":
+				y, _ := strconv.ParseFloat(operands[len(operands)-1], 64)
+				moveTo(y)
+			case "cm":
+				y, _ := strconv.ParseFloat(operands[len(operands)-1], 64)
+				if hidden > 0 {
+					moveTo(y)
+				}
+			case "Tj":
+				if hidden == 0 {
+					out.WriteString(decodeUTF16(operands[len(operands)-1]))
+				}
+			default:
+				operands = append(operands, token)
+				continue
+			}
+			operands = operands[:0]
+		}
+	}
+
+	return out.String()
+}
+
+// contentTokens splits a content stream into operands and operators. Strings are
+// returned decoded, without their delimiters.
+func contentTokens(content []byte) []string {
+	var tokens []string
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\n' || c == '\r' || c == '\t':
+			i++
+
+		case c == '(':
+			var s []byte
+			depth := 1
+			for i++; i < len(content); i++ {
+				c := content[i]
+				if c == '\\' {
+					i++
+					switch content[i] {
+					case 'n':
+						s = append(s, '\n')
+					case 'r':
+						s = append(s, '\r')
+					default:
+						s = append(s, content[i])
+					}
+					continue
+				}
+				if c == '(' {
+					depth++
+				}
+				if c == ')' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+				s = append(s, c)
+			}
+			tokens = append(tokens, string(s))
+			i++
+
+		case c == '<' && i+1 < len(content) && content[i+1] == '<', c == '>' && i+1 < len(content) && content[i+1] == '>':
+			tokens = append(tokens, string(content[i:i+2]))
+			i += 2
+
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			var s []byte
+			for j := i + 1; j+1 < i+end; j += 2 {
+				v, _ := strconv.ParseUint(string(content[j:j+2]), 16, 8)
+				s = append(s, byte(v))
+			}
+			tokens = append(tokens, string(s))
+			i += end + 1
+
+		default:
+			j := i + 1
+			for j < len(content) && !bytes.ContainsRune([]byte(" \n\r\t()<>[]/"), rune(content[j])) {
+				j++
+			}
+			tokens = append(tokens, string(content[i:j]))
+			i = j
+		}
+	}
+	return tokens
+}
+
+// decodeUTF16 decodes a UTF-16BE string, with or without a byte order mark
+func decodeUTF16(s string) string {
+	s = strings.TrimPrefix(s, "\xFE\xFF")
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// withoutSpace removes whitespace, which line wrapping adds and drops
+func withoutSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func TestWriteGolden(t *testing.T) {
+	fonts := testFonts(t)
+
+	inputs, err := filepath.Glob("testdata/*.txt")
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no inputs in testdata: %v", err)
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".txt")
+		t.Run(name, func(t *testing.T) {
+			text, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := extractText(t, render(t, fonts, string(text)))
+
+			// Nothing may be lost or changed, whatever the layout
+			if withoutSpace(got) != withoutSpace(string(text)) {
+				t.Errorf("extracted text differs from input\ngot:  %q\nwant: %q", got, text)
+			}
+
+			golden := strings.TrimSuffix(input, ".txt") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("extracted text differs from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestWriteAcrossPages(t *testing.T) {
+	fonts := testFonts(t)
+
+	text := strings.Repeat("Ovqat sovuq edi 🤮 孩子 \U0001D538 ", 400)
+	data := render(t, fonts, text)
+
+	pages := pageContents(t, data)
+	if len(pages) < 2 {
+		t.Fatalf("got %d pages, want several", len(pages))
+	}
+
+	// Every marked-content span must start and end on the same page
+	for i, page := range pages {
+		if begins, ends := bytes.Count(page, []byte("BDC")), bytes.Count(page, []byte("EMC")); begins != ends {
+			t.Errorf("page %d has %d BDC and %d EMC", i+1, begins, ends)
+		}
+	}
+
+	if got := extractText(t, data); withoutSpace(got) != withoutSpace(text) {
+		t.Errorf("extracted text differs from input")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	fonts := testFonts(t)
+
+	tests := []struct {
+		name string
+		text string
+		want []run
+	}{
+		{"latin and cyrillic", "Salom, Привет", []run{{kind: runPrimary, text: "Salom, Привет"}}},
+		{"uzbek apostrophe", "bogʻcha", []run{{kind: runPrimary, text: "bogʻcha"}}},
+		{"emoji", "ovqat 🤮", []run{
+			{kind: runPrimary, text: "ovqat "},
+			{kind: runEmoji, text: "🤮", key: "1f92e"},
+		}},
+		{"skin tone", "👍🏽", []run{{kind: runEmoji, text: "👍🏽", key: "1f44d-1f3fd"}}},
+		{"zwj sequence", "👨‍👩‍👧", []run{{kind: runEmoji, text: "👨‍👩‍👧", key: "1f468-200d-1f469-200d-1f467"}}},
+		{"flag", "🇺🇿", []run{{kind: runEmoji, text: "🇺🇿", key: "1f1fa-1f1ff"}}},
+		{"keycap", "1️⃣", []run{{kind: runEmoji, text: "1️⃣", key: "31-20e3"}}},
+		{"text heart", "❤", []run{{kind: runPrimary, text: "❤"}}},
+		{"emoji heart", "❤️", []run{{kind: runEmoji, text: "❤️", key: "2764"}}},
+		{"emoji missing from primary", "⭐", []run{{kind: runEmoji, text: "⭐", key: "2b50"}}},
+		{"digits stay text", "2024", []run{{kind: runPrimary, text: "2024"}}},
+		{"cjk", "ok 中文", []run{
+			{kind: runPrimary, text: "ok "},
+			{kind: runFallback, text: "中文"},
+		}},
+		{"outside bmp", "𝔸", []run{{kind: runMissing, text: "𝔸"}}},
+		{"tabs and carriage returns", "a\tb\r\nc", []run{{kind: runPrimary, text: "a b\nc"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fonts.split(tt.text, fonts.regularSet)
+			if len(got) != len(tt.want) {
+				t.Fatalf("split(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("split(%q)[%d] = %+v, want %+v", tt.text, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package pdftext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// charset is the set of BMP characters a font has glyphs for. gofpdf's UTF-8
+// fonts address glyphs by UTF-16 code unit, so nothing above U+FFFF can be
+// drawn as text.
+type charset [0x10000 / 64]uint64
+
+// newCharset reads the characters covered by a TrueType font
+func newCharset(data []byte) (*charset, error) {
+	font, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	var buf sfnt.Buffer
+	set := new(charset)
+	for r := rune(0x20); r <= 0xFFFF; r++ {
+		if index, err := font.GlyphIndex(&buf, r); err == nil && index != 0 {
+			set[r/64] |= 1 << (r % 64)
+		}
+	}
+
+	return set, nil
+}
+
+// has reports whether the font has a glyph for r
+func (c *charset) has(r rune) bool {
+	return r >= 0 && r <= 0xFFFF && c[r/64]&(1<<(r%64)) != 0
+}
+
+// runKind says how a run of text is drawn
+type runKind int
+
+const (
+	runPrimary  runKind = iota // Text in the primary font
+	runFallback                // Text in the fallback font
+	runEmoji                   // Emoji image
+	runMissing                 // Replacement character, no font has the glyph
+)
+
+// run is a piece of text drawn the same way
+type run struct {
+	kind runKind
+	text string // Original characters
+	key  string // Emoji image key for runEmoji
+}
+
+// split splits text into runs. primary is the charset of the primary font
+// style in use.
+func (f *Fonts) split(text string, primary *charset) []run {
+	var runs []run
+	add := func(kind runKind, text, key string) {
+		// Merge neighbouring text of the same kind so it is written in one go
+		if n := len(runs); n > 0 && runs[n-1].kind == kind && (kind == runPrimary || kind == runFallback) {
+			runs[n-1].text += text
+			return
+		}
+		runs = append(runs, run{kind: kind, text: text, key: key})
+	}
+
+	runes := []rune(normalizeSpace(text))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		// Single BMP characters the primary font has (e.g. ❤ or ©) stay text;
+		// sequences, VS16 and astral emoji become images
+		if key, n := f.emoji.match(runes[i:]); n > 1 || (n == 1 && (r > 0xFFFF || !primary.has(r))) {
+			add(runEmoji, string(runes[i:i+n]), key)
+			i += n
+			continue
+		}
+
+		switch {
+		case r == '\n' || primary.has(r):
+			add(runPrimary, string(r), "")
+		case f.fallbackSet.has(r) && !unicode.IsControl(r):
+			add(runFallback, string(r), "")
+		default:
+			add(runMissing, string(r), "")
+		}
+		i++
+	}
+
+	return runs
+}
+
+// normalizeSpace turns tabs and carriage returns into what gofpdf can write
+func normalizeSpace(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.NewReplacer("\r", "\n", "\t", " ").Replace(text)
+}
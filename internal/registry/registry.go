@@ -0,0 +1,145 @@
+// Package registry implements the hash chain behind the tamper-evident document registry.
+//
+// Every generated complaint or proposal PDF gets a random verification code,
+// printed with a QR code in its footer, and a registry entry holding the
+// SHA-256 of the finished file. Each entry's hash covers its own fields and the
+// hash of the entry before it, so editing, removing or reordering any entry
+// breaks every hash after it. A PDF is authentic if its SHA-256 matches the
+// entry for its code and the chain verifies.
+package registry
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"anor-kids/internal/models"
+)
+
+// GenesisHash is the previous hash of the first entry
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// codeAlphabet leaves out characters that are easily misread (0/O, 1/I/L)
+const codeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// Verification codes are codeGroups groups of codeGroupLen characters, e.g. "7KQX-M2PA-9RTD"
+const (
+	codeGroups   = 3
+	codeGroupLen = 4
+)
+
+// startPrefix marks a verification code in a t.me deep link start parameter
+const startPrefix = "verify_"
+
+// NewCode generates a random verification code
+func NewCode() (string, error) {
+	random := make([]byte, codeGroups*codeGroupLen)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	var b strings.Builder
+	for i, v := range random {
+		if i > 0 && i%codeGroupLen == 0 {
+			b.WriteByte('-')
+		}
+		// 256 isn't a multiple of the alphabet size; the bias is irrelevant for lookups
+		b.WriteByte(codeAlphabet[int(v)%len(codeAlphabet)])
+	}
+
+	return b.String(), nil
+}
+
+// NormalizeCode turns user input into the canonical code form: upper case with
+// dashes between groups. It returns "" if the input can't be a code.
+func NormalizeCode(input string) string {
+	input = strings.TrimPrefix(strings.TrimSpace(input), startPrefix)
+
+	var chars []byte
+	for _, r := range strings.ToUpper(input) {
+		if r == '-' || r == ' ' {
+			continue
+		}
+		if !strings.ContainsRune(codeAlphabet, r) {
+			return ""
+		}
+		chars = append(chars, byte(r))
+	}
+
+	if len(chars) != codeGroups*codeGroupLen {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, c := range chars {
+		if i > 0 && i%codeGroupLen == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// StartParameter returns the t.me deep link parameter that opens /verify for code
+func StartParameter(code string) string {
+	return startPrefix + code
+}
+
+// IsStartParameter reports whether a /start parameter carries a verification code
+func IsStartParameter(param string) bool {
+	return strings.HasPrefix(param, startPrefix)
+}
+
+// DocumentHash returns the hex SHA-256 of a document
+func DocumentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// EntryHash computes the hash of an entry from its fields and PrevHash.
+// CreatedAt is hashed with second precision, as stored.
+func EntryHash(entry *models.RegistryEntry) string {
+	fields := []string{
+		entry.PrevHash,
+		entry.Code,
+		entry.Kind,
+		strconv.Itoa(entry.ItemID),
+		entry.DocumentHash,
+		strconv.FormatInt(entry.CreatedAt.Unix(), 10),
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewEntry creates the entry that follows prevHash, with its hash filled in
+func NewEntry(prevHash, code, kind string, itemID int, documentHash string, now time.Time) *models.RegistryEntry {
+	entry := &models.RegistryEntry{
+		Code:         code,
+		Kind:         kind,
+		ItemID:       itemID,
+		DocumentHash: documentHash,
+		PrevHash:     prevHash,
+		CreatedAt:    now.UTC().Truncate(time.Second),
+	}
+	entry.EntryHash = EntryHash(entry)
+	return entry
+}
+
+// VerifyChain checks entries in chain order, starting from the first entry. It
+// returns the index of the first entry whose hash doesn't match its fields or
+// whose PrevHash doesn't match the entry before it, or -1 if the chain is intact.
+func VerifyChain(entries []*models.RegistryEntry) int {
+	prev := GenesisHash
+	for i, entry := range entries {
+		if entry.PrevHash != prev || EntryHash(entry) != entry.EntryHash {
+			return i
+		}
+		prev = entry.EntryHash
+	}
+	return -1
+}
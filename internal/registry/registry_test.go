@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"anor-kids/internal/models"
+)
+
+// testChain builds a valid chain of n entries
+func testChain(n int) []*models.RegistryEntry {
+	now := time.Date(2025, 10, 28, 9, 30, 0, 0, time.UTC)
+
+	var entries []*models.RegistryEntry
+	prev := GenesisHash
+	for i := 0; i < n; i++ {
+		entry := NewEntry(prev, fmt.Sprintf("AAAA-BBBB-%04d", i+2), models.DocumentJobKindComplaint, i+1,
+			DocumentHash([]byte(fmt.Sprintf("document %d", i))), now.Add(time.Duration(i)*time.Minute))
+		entry.ID = i + 1
+		entries = append(entries, entry)
+		prev = entry.EntryHash
+	}
+	return entries
+}
+
+func TestNewCode(t *testing.T) {
+	format := regexp.MustCompile(`^[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{4}-[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{4}-[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{4}$`)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := NewCode()
+		if err != nil {
+			t.Fatalf("NewCode() error = %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Errorf("NewCode() = %q, want XXXX-XXXX-XXXX", code)
+		}
+		if seen[code] {
+			t.Errorf("NewCode() returned %q twice", code)
+		}
+		seen[code] = true
+
+		if got := NormalizeCode(code); got != code {
+			t.Errorf("NormalizeCode(%q) = %q, want it unchanged", code, got)
+		}
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"7KQX-M2PA-9RTD", "7KQX-M2PA-9RTD"},
+		{"7kqx-m2pa-9rtd", "7KQX-M2PA-9RTD"},
+		{" 7KQXM2PA9RTD ", "7KQX-M2PA-9RTD"},
+		{"7KQX M2PA 9RTD", "7KQX-M2PA-9RTD"},
+		{"verify_7KQX-M2PA-9RTD", "7KQX-M2PA-9RTD"},
+		{"7KQX-M2PA", ""},
+		{"7KQX-M2PA-9RTD-2", ""},
+		{"7KQX-M2PA-9RT0", ""}, // 0 is not in the alphabet
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeCode(tt.input); got != tt.want {
+			t.Errorf("NormalizeCode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestStartParameter(t *testing.T) {
+	param := StartParameter("7KQX-M2PA-9RTD")
+
+	// Telegram allows up to 64 characters A-Z, a-z, 0-9, _ and - in start parameters
+	if !regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`).MatchString(param) {
+		t.Errorf("StartParameter() = %q, not a valid start parameter", param)
+	}
+	if !IsStartParameter(param) {
+		t.Errorf("IsStartParameter(%q) = false", param)
+	}
+	if got := NormalizeCode(param); got != "7KQX-M2PA-9RTD" {
+		t.Errorf("NormalizeCode(%q) = %q", param, got)
+	}
+}
+
+func TestVerifyChainIntact(t *testing.T) {
+	if got := VerifyChain(nil); got != -1 {
+		t.Errorf("VerifyChain(empty) = %d, want -1", got)
+	}
+
+	if got := VerifyChain(testChain(5)); got != -1 {
+		t.Errorf("VerifyChain() = %d, want -1", got)
+	}
+}
+
+func TestVerifyChainDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(entries []*models.RegistryEntry) []*models.RegistryEntry
+		want   int
+	}{
+		{"document hash edited", func(e []*models.RegistryEntry) []*models.RegistryEntry {
+			e[2].DocumentHash = DocumentHash([]byte("edited"))
+			return e
+		}, 2},
+		{"item edited", func(e []*models.RegistryEntry) []*models.RegistryEntry {
+			e[1].ItemID = 99
+			return e
+		}, 1},
+		{"date edited", func(e []*models.RegistryEntry) []*models.RegistryEntry {
+			e[3].CreatedAt = e[3].CreatedAt.Add(time.Hour)
+			return e
+		}, 3},
+		{"entry rehashed after edit", func(e []*models.RegistryEntry) []*models.RegistryEntry {
+			// Recomputing the edited entry's hash breaks the link from the next one
+			e[2].Code = "ZZZZ-ZZZZ-ZZZZ"
+			e[2].EntryHash = EntryHash(e[2])
+			return e
+		}, 3},
+		{"entry removed", func(e []*models.RegistryEntry) []*models.RegistryEntry {
+			return append(e[:1], e[2:]...)
+		}, 1},
+		{"entries swapped", func(e []*models.RegistryEntry) []*models.RegistryEntry {
+			e[3], e[4] = e[4], e[3]
+			return e
+		}, 3},
+		{"first entry removed", func(e []*models.RegistryEntry) []*models.RegistryEntry {
+			return e[1:]
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyChain(tt.tamper(testChain(5))); got != tt.want {
+				t.Errorf("VerifyChain() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryHashIgnoresSubseconds(t *testing.T) {
+	entry := testChain(1)[0]
+	stored := *entry
+	stored.CreatedAt = entry.CreatedAt.Add(500 * time.Millisecond).In(time.FixedZone("UZT", 5*3600))
+
+	if EntryHash(&stored) != entry.EntryHash {
+		t.Error("EntryHash() changed with sub-second precision or time zone")
+	}
+}
@@ -4,57 +4,70 @@ import (
 	"database/sql"
 	"fmt"
 
+	"anor-kids/internal/encryption"
 	"anor-kids/internal/models"
 )
 
+// adminColumns is the column list shared by all admin queries, in scanAdmin order
+const adminColumns = `id, phone_number, telegram_id, name, added_at`
+
 type AdminRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *encryption.Cipher
 }
 
-func NewAdminRepository(db *sql.DB) *AdminRepository {
-	return &AdminRepository{db: db}
+func NewAdminRepository(db *sql.DB, cipher *encryption.Cipher) *AdminRepository {
+	return &AdminRepository{db: db, cipher: cipher}
 }
 
-// Create creates a new admin
-func (r *AdminRepository) Create(phoneNumber, name string) (*models.Admin, error) {
-	query := `
-		INSERT INTO admins (phone_number, name)
-		VALUES ($1, $2)
-		RETURNING id, phone_number, telegram_id, name, added_at
-	`
-
+// scanAdmin scans a row selected with adminColumns and decrypts the phone number
+func (r *AdminRepository) scanAdmin(row rowScanner) (*models.Admin, error) {
 	var admin models.Admin
-	err := r.db.QueryRow(query, phoneNumber, name).Scan(
+	err := row.Scan(
 		&admin.ID,
 		&admin.PhoneNumber,
 		&admin.TelegramID,
 		&admin.Name,
 		&admin.AddedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
 
+	admin.PhoneNumber, err = r.cipher.Decrypt(admin.PhoneNumber)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create admin: %w", err)
+		return nil, err
 	}
 
 	return &admin, nil
 }
 
+// Create creates a new admin
+func (r *AdminRepository) Create(phoneNumber, name string) (*models.Admin, error) {
+	encryptedPhone, err := r.cipher.Encrypt(phoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt phone number: %w", err)
+	}
+
+	query := `
+		INSERT INTO admins (phone_number, phone_hash, name)
+		VALUES ($1, $2, $3)
+		RETURNING ` + adminColumns
+
+	admin, err := r.scanAdmin(r.db.QueryRow(query, encryptedPhone, r.cipher.BlindIndex(phoneNumber), name))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin: %w", err)
+	}
+
+	return admin, nil
+}
+
 // GetByID gets admin by ID
 func (r *AdminRepository) GetByID(id int) (*models.Admin, error) {
-	query := `
-		SELECT id, phone_number, telegram_id, name, added_at
-		FROM admins
-		WHERE id = $1
-	`
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE id = $1`
 
-	var admin models.Admin
-	err := r.db.QueryRow(query, id).Scan(
-		&admin.ID,
-		&admin.PhoneNumber,
-		&admin.TelegramID,
-		&admin.Name,
-		&admin.AddedAt,
-	)
+	admin, err := r.scanAdmin(r.db.QueryRow(query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -64,25 +77,14 @@ func (r *AdminRepository) GetByID(id int) (*models.Admin, error) {
 		return nil, fmt.Errorf("failed to get admin: %w", err)
 	}
 
-	return &admin, nil
+	return admin, nil
 }
 
-// GetByPhoneNumber gets admin by phone number (indexed, fast query)
+// GetByPhoneNumber gets admin by phone number (blind index, fast query)
 func (r *AdminRepository) GetByPhoneNumber(phoneNumber string) (*models.Admin, error) {
-	query := `
-		SELECT id, phone_number, telegram_id, name, added_at
-		FROM admins
-		WHERE phone_number = $1
-	`
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE phone_hash = $1`
 
-	var admin models.Admin
-	err := r.db.QueryRow(query, phoneNumber).Scan(
-		&admin.ID,
-		&admin.PhoneNumber,
-		&admin.TelegramID,
-		&admin.Name,
-		&admin.AddedAt,
-	)
+	admin, err := r.scanAdmin(r.db.QueryRow(query, r.cipher.BlindIndex(phoneNumber)))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -92,25 +94,14 @@ func (r *AdminRepository) GetByPhoneNumber(phoneNumber string) (*models.Admin, e
 		return nil, fmt.Errorf("failed to get admin: %w", err)
 	}
 
-	return &admin, nil
+	return admin, nil
 }
 
 // GetByTelegramID gets admin by telegram ID (indexed, fast query)
 func (r *AdminRepository) GetByTelegramID(telegramID int64) (*models.Admin, error) {
-	query := `
-		SELECT id, phone_number, telegram_id, name, added_at
-		FROM admins
-		WHERE telegram_id = $1
-	`
+	query := `SELECT ` + adminColumns + ` FROM admins WHERE telegram_id = $1`
 
-	var admin models.Admin
-	err := r.db.QueryRow(query, telegramID).Scan(
-		&admin.ID,
-		&admin.PhoneNumber,
-		&admin.TelegramID,
-		&admin.Name,
-		&admin.AddedAt,
-	)
+	admin, err := r.scanAdmin(r.db.QueryRow(query, telegramID))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -120,13 +111,13 @@ func (r *AdminRepository) GetByTelegramID(telegramID int64) (*models.Admin, erro
 		return nil, fmt.Errorf("failed to get admin: %w", err)
 	}
 
-	return &admin, nil
+	return admin, nil
 }
 
 // GetAll gets all admins
 func (r *AdminRepository) GetAll() ([]*models.Admin, error) {
 	query := `
-		SELECT id, phone_number, telegram_id, name, added_at
+		SELECT ` + adminColumns + `
 		FROM admins
 		ORDER BY added_at ASC
 	`
@@ -139,18 +130,11 @@ func (r *AdminRepository) GetAll() ([]*models.Admin, error) {
 
 	var admins []*models.Admin
 	for rows.Next() {
-		var admin models.Admin
-		err := rows.Scan(
-			&admin.ID,
-			&admin.PhoneNumber,
-			&admin.TelegramID,
-			&admin.Name,
-			&admin.AddedAt,
-		)
+		admin, err := r.scanAdmin(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan admin: %w", err)
 		}
-		admins = append(admins, &admin)
+		admins = append(admins, admin)
 	}
 
 	return admins, nil
@@ -158,14 +142,24 @@ func (r *AdminRepository) GetAll() ([]*models.Admin, error) {
 
 // UpdateTelegramID updates admin telegram ID
 func (r *AdminRepository) UpdateTelegramID(phoneNumber string, telegramID int64) error {
-	query := `UPDATE admins SET telegram_id = $1 WHERE phone_number = $2`
-	_, err := r.db.Exec(query, telegramID, phoneNumber)
+	query := `UPDATE admins SET telegram_id = $1 WHERE phone_hash = $2`
+	_, err := r.db.Exec(query, telegramID, r.cipher.BlindIndex(phoneNumber))
 	if err != nil {
 		return fmt.Errorf("failed to update admin telegram ID: %w", err)
 	}
 	return nil
 }
 
+// UpdateName updates the admin's display name
+func (r *AdminRepository) UpdateName(id int, name string) error {
+	query := `UPDATE admins SET name = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, name, id)
+	if err != nil {
+		return fmt.Errorf("failed to update admin name: %w", err)
+	}
+	return nil
+}
+
 // IsAdmin checks if phone number or telegram ID is an admin
 // Note: Empty phone numbers are ignored to avoid false matches
 func (r *AdminRepository) IsAdmin(phoneNumber string, telegramID int64) (bool, error) {
@@ -178,19 +172,19 @@ func (r *AdminRepository) IsAdmin(phoneNumber string, telegramID int64) (bool, e
 		query = `
 			SELECT EXISTS(
 				SELECT 1 FROM admins
-				WHERE phone_number = $1 OR telegram_id = $2
+				WHERE phone_hash = $1 OR telegram_id = $2
 			)
 		`
-		args = []any{phoneNumber, telegramID}
+		args = []any{r.cipher.BlindIndex(phoneNumber), telegramID}
 	} else if phoneNumber != "" {
 		// Only phone number provided
 		query = `
 			SELECT EXISTS(
 				SELECT 1 FROM admins
-				WHERE phone_number = $1
+				WHERE phone_hash = $1
 			)
 		`
-		args = []any{phoneNumber}
+		args = []any{r.cipher.BlindIndex(phoneNumber)}
 	} else if telegramID != 0 {
 		// Only telegram_id provided
 		query = `
@@ -226,8 +220,8 @@ func (r *AdminRepository) Count() (int, error) {
 
 // Delete deletes an admin by phone number
 func (r *AdminRepository) Delete(phoneNumber string) error {
-	query := `DELETE FROM admins WHERE phone_number = $1`
-	_, err := r.db.Exec(query, phoneNumber)
+	query := `DELETE FROM admins WHERE phone_hash = $1`
+	_, err := r.db.Exec(query, r.cipher.BlindIndex(phoneNumber))
 	if err != nil {
 		return fmt.Errorf("failed to delete admin: %w", err)
 	}
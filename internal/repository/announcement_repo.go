@@ -5,15 +5,17 @@ import (
 	"fmt"
 	"time"
 
+	"anor-kids/internal/encryption"
 	"anor-kids/internal/models"
 )
 
 type AnnouncementRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *encryption.Cipher
 }
 
-func NewAnnouncementRepository(db *sql.DB) *AnnouncementRepository {
-	return &AnnouncementRepository{db: db}
+func NewAnnouncementRepository(db *sql.DB, cipher *encryption.Cipher) *AnnouncementRepository {
+	return &AnnouncementRepository{db: db, cipher: cipher}
 }
 
 // Create creates a new announcement
@@ -208,6 +210,9 @@ func (r *AnnouncementRepository) GetAllWithAdmin(limit, offset int) ([]*models.A
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan announcement with admin: %w", err)
 		}
+		if announcement.AdminPhone, err = r.cipher.Decrypt(announcement.AdminPhone); err != nil {
+			return nil, fmt.Errorf("failed to decrypt admin phone: %w", err)
+		}
 		announcements = append(announcements, &announcement)
 	}
 
@@ -283,3 +288,40 @@ func (r *AnnouncementRepository) CountByAdminID(adminID int) (int, error) {
 	}
 	return count, nil
 }
+
+// RecordDelivery saves how many parents a broadcast reached and missed
+func (r *AnnouncementRepository) RecordDelivery(id, delivered, failed int) error {
+	query := `UPDATE announcements SET delivered_count = $1, failed_count = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, delivered, failed, id)
+	if err != nil {
+		return fmt.Errorf("failed to record announcement delivery: %w", err)
+	}
+	return nil
+}
+
+// GetReach gets the delivery counts of the announcements created in [from, to), oldest first
+func (r *AnnouncementRepository) GetReach(from, to time.Time) ([]models.AnnouncementReach, error) {
+	query := `
+		SELECT id, title, created_at, delivered_count, failed_count
+		FROM announcements
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, from.UTC().Format(sqlTimeLayout), to.UTC().Format(sqlTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcement reach: %w", err)
+	}
+	defer rows.Close()
+
+	var reach []models.AnnouncementReach
+	for rows.Next() {
+		var item models.AnnouncementReach
+		if err := rows.Scan(&item.ID, &item.Title, &item.CreatedAt, &item.Delivered, &item.Failed); err != nil {
+			return nil, fmt.Errorf("failed to scan announcement reach: %w", err)
+		}
+		reach = append(reach, item)
+	}
+
+	return reach, rows.Err()
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anor-kids/internal/models"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create records an audited action
+func (r *AuditRepository) Create(entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor_telegram_id, action, item_kind, item_id, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(query, entry.ActorTelegramID, entry.Action, entry.ItemKind, entry.ItemID, entry.Reason).
+		Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetByItem gets the audited actions on a complaint or proposal, oldest first
+func (r *AuditRepository) GetByItem(kind string, id int) ([]*models.AuditEntry, error) {
+	query := `
+		SELECT id, actor_telegram_id, action, item_kind, item_id, reason, created_at
+		FROM audit_log
+		WHERE item_kind = $1 AND item_id = $2
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, kind, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorTelegramID,
+			&entry.Action,
+			&entry.ItemKind,
+			&entry.ItemID,
+			&entry.Reason,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anor-kids/internal/models"
+)
+
+// categoryColumns is the column list shared by all category queries, in scanCategory order
+const categoryColumns = `id, name_uz, name_ru, sort_order, is_active, created_at`
+
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// scanCategory scans a row selected with categoryColumns
+func scanCategory(row rowScanner) (*models.Category, error) {
+	var category models.Category
+	err := row.Scan(
+		&category.ID,
+		&category.NameUz,
+		&category.NameRu,
+		&category.SortOrder,
+		&category.IsActive,
+		&category.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// scanCategories scans all rows selected with categoryColumns
+func scanCategories(rows *sql.Rows) ([]*models.Category, error) {
+	var categories []*models.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+// categoryRef builds the category of a complaint from LEFT JOINed columns,
+// nil if the complaint has none
+func categoryRef(id sql.NullInt64, nameUz, nameRu sql.NullString) *models.Category {
+	if !id.Valid {
+		return nil
+	}
+	return &models.Category{ID: int(id.Int64), NameUz: nameUz.String, NameRu: nameRu.String}
+}
+
+// Create creates a new active category at the end of the list
+func (r *CategoryRepository) Create(nameUz, nameRu string) (*models.Category, error) {
+	query := `
+		INSERT INTO complaint_categories (name_uz, name_ru, sort_order, is_active)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(sort_order), 0) + 10 FROM complaint_categories), 1)
+		RETURNING ` + categoryColumns
+
+	category, err := scanCategory(r.db.QueryRow(query, nameUz, nameRu))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return category, nil
+}
+
+// GetAll gets all categories in display order
+func (r *CategoryRepository) GetAll() ([]*models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM complaint_categories
+		ORDER BY sort_order ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCategories(rows)
+}
+
+// GetActive gets the categories parents can choose, in display order
+func (r *CategoryRepository) GetActive() ([]*models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM complaint_categories
+		WHERE is_active = 1
+		ORDER BY sort_order ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active categories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCategories(rows)
+}
+
+// GetByID gets category by ID
+func (r *CategoryRepository) GetByID(id int) (*models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM complaint_categories
+		WHERE id = $1
+	`
+
+	category, err := scanCategory(r.db.QueryRow(query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return category, nil
+}
+
+// Rename changes the names of a category; complaints keep it
+func (r *CategoryRepository) Rename(id int, nameUz, nameRu string) error {
+	query := `UPDATE complaint_categories SET name_uz = $1, name_ru = $2 WHERE id = $3`
+	result, err := r.db.Exec(query, nameUz, nameRu, id)
+	if err != nil {
+		return fmt.Errorf("failed to rename category: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	return nil
+}
+
+// ToggleActive toggles whether parents can choose a category
+func (r *CategoryRepository) ToggleActive(id int) error {
+	query := `
+		UPDATE complaint_categories
+		SET is_active = CASE WHEN is_active = 1 THEN 0 ELSE 1 END
+		WHERE id = $1
+	`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to toggle category status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	return nil
+}
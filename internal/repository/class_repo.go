@@ -7,6 +7,9 @@ import (
 	"anor-kids/internal/models"
 )
 
+// classColumns is the column list shared by all class queries, in scanClass order
+const classColumns = `id, class_name, is_active, COALESCE(teacher_phone, ''), teacher_telegram_id, created_at`
+
 type ClassRepository struct {
 	db *sql.DB
 }
@@ -15,33 +18,57 @@ func NewClassRepository(db *sql.DB) *ClassRepository {
 	return &ClassRepository{db: db}
 }
 
-// Create creates a new class
-func (r *ClassRepository) Create(className string) (*models.Class, error) {
-	query := `
-		INSERT INTO classes (class_name, is_active)
-		VALUES ($1, 1)
-		RETURNING id, class_name, is_active, created_at
-	`
-
+// scanClass scans a row selected with classColumns
+func scanClass(row rowScanner) (*models.Class, error) {
 	var class models.Class
-	err := r.db.QueryRow(query, className).Scan(
+	err := row.Scan(
 		&class.ID,
 		&class.ClassName,
 		&class.IsActive,
+		&class.TeacherPhone,
+		&class.TeacherTelegramID,
 		&class.CreatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// scanClasses scans all rows selected with classColumns
+func scanClasses(rows *sql.Rows) ([]*models.Class, error) {
+	var classes []*models.Class
+	for rows.Next() {
+		class, err := scanClass(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan class: %w", err)
+		}
+		classes = append(classes, class)
+	}
+
+	return classes, rows.Err()
+}
+
+// Create creates a new class
+func (r *ClassRepository) Create(className string) (*models.Class, error) {
+	query := `
+		INSERT INTO classes (class_name, is_active)
+		VALUES ($1, 1)
+		RETURNING ` + classColumns
+
+	class, err := scanClass(r.db.QueryRow(query, className))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create class: %w", err)
 	}
 
-	return &class, nil
+	return class, nil
 }
 
 // GetAll gets all classes
 func (r *ClassRepository) GetAll() ([]*models.Class, error) {
 	query := `
-		SELECT id, class_name, is_active, created_at
+		SELECT ` + classColumns + `
 		FROM classes
 		ORDER BY class_name ASC
 	`
@@ -52,28 +79,13 @@ func (r *ClassRepository) GetAll() ([]*models.Class, error) {
 	}
 	defer rows.Close()
 
-	var classes []*models.Class
-	for rows.Next() {
-		var class models.Class
-		err := rows.Scan(
-			&class.ID,
-			&class.ClassName,
-			&class.IsActive,
-			&class.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan class: %w", err)
-		}
-		classes = append(classes, &class)
-	}
-
-	return classes, nil
+	return scanClasses(rows)
 }
 
 // GetActive gets all active classes
 func (r *ClassRepository) GetActive() ([]*models.Class, error) {
 	query := `
-		SELECT id, class_name, is_active, created_at
+		SELECT ` + classColumns + `
 		FROM classes
 		WHERE is_active = 1
 		ORDER BY class_name ASC
@@ -85,39 +97,18 @@ func (r *ClassRepository) GetActive() ([]*models.Class, error) {
 	}
 	defer rows.Close()
 
-	var classes []*models.Class
-	for rows.Next() {
-		var class models.Class
-		err := rows.Scan(
-			&class.ID,
-			&class.ClassName,
-			&class.IsActive,
-			&class.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan class: %w", err)
-		}
-		classes = append(classes, &class)
-	}
-
-	return classes, nil
+	return scanClasses(rows)
 }
 
 // GetByName gets class by name
 func (r *ClassRepository) GetByName(className string) (*models.Class, error) {
 	query := `
-		SELECT id, class_name, is_active, created_at
+		SELECT ` + classColumns + `
 		FROM classes
 		WHERE class_name = $1
 	`
 
-	var class models.Class
-	err := r.db.QueryRow(query, className).Scan(
-		&class.ID,
-		&class.ClassName,
-		&class.IsActive,
-		&class.CreatedAt,
-	)
+	class, err := scanClass(r.db.QueryRow(query, className))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -127,7 +118,7 @@ func (r *ClassRepository) GetByName(className string) (*models.Class, error) {
 		return nil, fmt.Errorf("failed to get class: %w", err)
 	}
 
-	return &class, nil
+	return class, nil
 }
 
 // Delete deletes a class by name
@@ -174,6 +165,48 @@ func (r *ClassRepository) ToggleActive(className string) error {
 	return nil
 }
 
+// SetTeacher assigns a teacher phone to a class. The teacher's telegram ID is
+// reset and linked again once the teacher verifies the phone.
+func (r *ClassRepository) SetTeacher(className, teacherPhone string, teacherTelegramID *int64) error {
+	query := `
+		UPDATE classes
+		SET teacher_phone = NULLIF($1, ''), teacher_telegram_id = $2
+		WHERE class_name = $3
+	`
+	result, err := r.db.Exec(query, teacherPhone, teacherTelegramID, className)
+	if err != nil {
+		return fmt.Errorf("failed to set class teacher: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("class not found")
+	}
+
+	return nil
+}
+
+// LinkTeacherTelegramID links a telegram ID to every class taught by the phone.
+// Returns the number of classes linked.
+func (r *ClassRepository) LinkTeacherTelegramID(teacherPhone string, telegramID int64) (int, error) {
+	query := `UPDATE classes SET teacher_telegram_id = $1 WHERE teacher_phone = $2`
+	result, err := r.db.Exec(query, telegramID, teacherPhone)
+	if err != nil {
+		return 0, fmt.Errorf("failed to link teacher telegram ID: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rows), nil
+}
+
 // Count counts total classes
 func (r *ClassRepository) Count() (int, error) {
 	var count int
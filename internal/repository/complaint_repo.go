@@ -4,33 +4,49 @@ import (
 	"database/sql"
 	"fmt"
 
+	"anor-kids/internal/encryption"
 	"anor-kids/internal/models"
 )
 
+// complaintColumns is the column list shared by complaint queries, in
+// scanComplaint order; select them FROM complaintTables
+const complaintColumns = `c.id, c.user_id, c.complaint_text, c.pdf_telegram_file_id, c.pdf_filename, c.created_at, c.status,
+	c.is_urgent, c.is_anonymous, c.version, k.id, k.name_uz, k.name_ru`
+
+// complaintTables are complaints (c) with their category (k)
+const complaintTables = `complaints c LEFT JOIN complaint_categories k ON k.id = c.category_id`
+
+// complaintWithUserColumns is the column list of v_complaints_with_user queries,
+// in scanComplaintsWithUser order
+const complaintWithUserColumns = `id, user_id, complaint_text, pdf_telegram_file_id, pdf_filename, created_at, status,
+	user_telegram_id, telegram_username, phone_number, child_name, child_class,
+	category_id, category_name_uz, category_name_ru, is_urgent, is_anonymous`
+
 type ComplaintRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *encryption.Cipher
 }
 
-func NewComplaintRepository(db *sql.DB) *ComplaintRepository {
-	return &ComplaintRepository{db: db}
+func NewComplaintRepository(db *sql.DB, cipher *encryption.Cipher) *ComplaintRepository {
+	return &ComplaintRepository{db: db, cipher: cipher}
 }
 
-// Create creates a new complaint with PDF file
-func (r *ComplaintRepository) Create(req *models.CreateComplaintRequest) (*models.Complaint, error) {
-	query := `
-		INSERT INTO complaints (user_id, complaint_text, pdf_telegram_file_id, pdf_filename)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, user_id, complaint_text, pdf_telegram_file_id, pdf_filename, created_at, status
-	`
+// decrypt decrypts the encrypted fields of a complaint
+func (r *ComplaintRepository) decrypt(complaint *models.Complaint) error {
+	text, err := r.cipher.Decrypt(complaint.ComplaintText)
+	if err != nil {
+		return err
+	}
+	complaint.ComplaintText = text
+	return nil
+}
 
+// scanComplaint scans and decrypts a row selected with complaintColumns
+func (r *ComplaintRepository) scanComplaint(row rowScanner) (*models.Complaint, error) {
 	var complaint models.Complaint
-	err := r.db.QueryRow(
-		query,
-		req.UserID,
-		req.ComplaintText,
-		req.PDFTelegramFileID,
-		req.PDFFilename,
-	).Scan(
+	var categoryID sql.NullInt64
+	var categoryUz, categoryRu sql.NullString
+	err := row.Scan(
 		&complaint.ID,
 		&complaint.UserID,
 		&complaint.ComplaintText,
@@ -38,33 +54,96 @@ func (r *ComplaintRepository) Create(req *models.CreateComplaintRequest) (*model
 		&complaint.PDFFilename,
 		&complaint.CreatedAt,
 		&complaint.Status,
+		&complaint.IsUrgent,
+		&complaint.IsAnonymous,
+		&complaint.Version,
+		&categoryID,
+		&categoryUz,
+		&categoryRu,
 	)
+	if err != nil {
+		return nil, err
+	}
+	complaint.Category = categoryRef(categoryID, categoryUz, categoryRu)
+
+	if err := r.decrypt(&complaint); err != nil {
+		return nil, fmt.Errorf("failed to decrypt complaint: %w", err)
+	}
+
+	return &complaint, nil
+}
+
+// scanComplaints scans and decrypts all rows selected with complaintColumns
+func (r *ComplaintRepository) scanComplaints(rows *sql.Rows) ([]*models.Complaint, error) {
+	var complaints []*models.Complaint
+	for rows.Next() {
+		complaint, err := r.scanComplaint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan complaint: %w", err)
+		}
+		complaints = append(complaints, complaint)
+	}
+
+	return complaints, rows.Err()
+}
+
+// decryptWithUser decrypts the encrypted fields of a complaint and its author
+func (r *ComplaintRepository) decryptWithUser(complaint *models.ComplaintWithUser) error {
+	var err error
+	if complaint.ComplaintText, err = r.cipher.Decrypt(complaint.ComplaintText); err != nil {
+		return err
+	}
+	if complaint.PhoneNumber, err = r.cipher.Decrypt(complaint.PhoneNumber); err != nil {
+		return err
+	}
+	if complaint.ChildName, err = r.cipher.Decrypt(complaint.ChildName); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Create creates a new complaint with PDF file
+func (r *ComplaintRepository) Create(req *models.CreateComplaintRequest) (*models.Complaint, error) {
+	encryptedText, err := r.cipher.Encrypt(req.ComplaintText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt complaint text: %w", err)
+	}
+
+	query := `
+		INSERT INTO complaints (user_id, category_id, is_urgent, is_anonymous, complaint_text, pdf_telegram_file_id, pdf_filename)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id int
+	err = r.db.QueryRow(
+		query,
+		req.UserID,
+		req.CategoryID,
+		req.IsUrgent,
+		req.IsAnonymous,
+		encryptedText,
+		req.PDFTelegramFileID,
+		req.PDFFilename,
+	).Scan(&id)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create complaint: %w", err)
 	}
 
-	return &complaint, nil
+	// Read back with the category names
+	return r.GetByID(id)
 }
 
 // GetByID gets complaint by ID
 func (r *ComplaintRepository) GetByID(id int) (*models.Complaint, error) {
 	query := `
-		SELECT id, user_id, complaint_text, pdf_telegram_file_id, pdf_filename, created_at, status
-		FROM complaints
-		WHERE id = $1
+		SELECT ` + complaintColumns + `
+		FROM ` + complaintTables + `
+		WHERE c.id = $1
 	`
 
-	var complaint models.Complaint
-	err := r.db.QueryRow(query, id).Scan(
-		&complaint.ID,
-		&complaint.UserID,
-		&complaint.ComplaintText,
-		&complaint.PDFTelegramFileID,
-		&complaint.PDFFilename,
-		&complaint.CreatedAt,
-		&complaint.Status,
-	)
+	complaint, err := r.scanComplaint(r.db.QueryRow(query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -74,16 +153,16 @@ func (r *ComplaintRepository) GetByID(id int) (*models.Complaint, error) {
 		return nil, fmt.Errorf("failed to get complaint: %w", err)
 	}
 
-	return &complaint, nil
+	return complaint, nil
 }
 
 // GetByUserID gets complaints by user ID (indexed, fast query)
 func (r *ComplaintRepository) GetByUserID(userID int, limit, offset int) ([]*models.Complaint, error) {
 	query := `
-		SELECT id, user_id, complaint_text, pdf_telegram_file_id, pdf_filename, created_at, status
-		FROM complaints
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		SELECT ` + complaintColumns + `
+		FROM ` + complaintTables + `
+		WHERE c.user_id = $1
+		ORDER BY c.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
@@ -93,33 +172,15 @@ func (r *ComplaintRepository) GetByUserID(userID int, limit, offset int) ([]*mod
 	}
 	defer rows.Close()
 
-	var complaints []*models.Complaint
-	for rows.Next() {
-		var complaint models.Complaint
-		err := rows.Scan(
-			&complaint.ID,
-			&complaint.UserID,
-			&complaint.ComplaintText,
-			&complaint.PDFTelegramFileID,
-			&complaint.PDFFilename,
-			&complaint.CreatedAt,
-			&complaint.Status,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan complaint: %w", err)
-		}
-		complaints = append(complaints, &complaint)
-	}
-
-	return complaints, nil
+	return r.scanComplaints(rows)
 }
 
 // GetAll gets all complaints with pagination (for admin)
 func (r *ComplaintRepository) GetAll(limit, offset int) ([]*models.Complaint, error) {
 	query := `
-		SELECT id, user_id, complaint_text, pdf_telegram_file_id, pdf_filename, created_at, status
-		FROM complaints
-		ORDER BY created_at DESC
+		SELECT ` + complaintColumns + `
+		FROM ` + complaintTables + `
+		ORDER BY c.created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
@@ -129,45 +190,53 @@ func (r *ComplaintRepository) GetAll(limit, offset int) ([]*models.Complaint, er
 	}
 	defer rows.Close()
 
-	var complaints []*models.Complaint
-	for rows.Next() {
-		var complaint models.Complaint
-		err := rows.Scan(
-			&complaint.ID,
-			&complaint.UserID,
-			&complaint.ComplaintText,
-			&complaint.PDFTelegramFileID,
-			&complaint.PDFFilename,
-			&complaint.CreatedAt,
-			&complaint.Status,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan complaint: %w", err)
-		}
-		complaints = append(complaints, &complaint)
-	}
-
-	return complaints, nil
+	return r.scanComplaints(rows)
 }
 
-// GetAllWithUser gets all complaints with user info using view (optimized for admin)
-func (r *ComplaintRepository) GetAllWithUser(limit, offset int) ([]*models.ComplaintWithUser, error) {
+// GetAllWithUser gets all complaints with user info using view (optimized for admin).
+// categoryID 0 selects every category.
+func (r *ComplaintRepository) GetAllWithUser(categoryID, limit, offset int) ([]*models.ComplaintWithUser, error) {
 	query := `
-		SELECT id, user_id, complaint_text, pdf_telegram_file_id, pdf_filename, created_at, status,
-		       user_telegram_id, telegram_username, phone_number, child_name, child_class
+		SELECT ` + complaintWithUserColumns + `
 		FROM v_complaints_with_user
-		LIMIT $1 OFFSET $2
+		WHERE $1 = 0 OR category_id = $1
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.Query(query, categoryID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get complaints with user: %w", err)
 	}
 	defer rows.Close()
 
+	return r.scanComplaintsWithUser(rows)
+}
+
+// GetForExport gets complaints with user info matching an export filter, oldest first
+func (r *ComplaintRepository) GetForExport(filter models.ExportFilter, limit, offset int) ([]*models.ComplaintWithUser, error) {
+	where, args := exportWhere(filter, "created_at", "status", "category_id", "is_anonymous")
+	query, args := exportPage(`
+		SELECT `+complaintWithUserColumns+`
+		FROM v_complaints_with_user
+		`+where, "created_at ASC, id ASC", args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaints for export: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanComplaintsWithUser(rows)
+}
+
+// scanComplaintsWithUser scans and decrypts rows selected from v_complaints_with_user.
+// These rows are only shown to staff, so anonymous complaints come back without their author.
+func (r *ComplaintRepository) scanComplaintsWithUser(rows *sql.Rows) ([]*models.ComplaintWithUser, error) {
 	var complaints []*models.ComplaintWithUser
 	for rows.Next() {
 		var complaint models.ComplaintWithUser
+		var categoryID sql.NullInt64
+		var categoryUz, categoryRu sql.NullString
 		err := rows.Scan(
 			&complaint.ID,
 			&complaint.UserID,
@@ -181,23 +250,33 @@ func (r *ComplaintRepository) GetAllWithUser(limit, offset int) ([]*models.Compl
 			&complaint.PhoneNumber,
 			&complaint.ChildName,
 			&complaint.ChildClass,
+			&categoryID,
+			&categoryUz,
+			&categoryRu,
+			&complaint.IsUrgent,
+			&complaint.IsAnonymous,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan complaint with user: %w", err)
 		}
+		complaint.Category = categoryRef(categoryID, categoryUz, categoryRu)
+		if err := r.decryptWithUser(&complaint); err != nil {
+			return nil, fmt.Errorf("failed to decrypt complaint: %w", err)
+		}
+		complaint.HideAuthor()
 		complaints = append(complaints, &complaint)
 	}
 
-	return complaints, nil
+	return complaints, rows.Err()
 }
 
 // GetByStatus gets complaints by status (indexed, fast query)
 func (r *ComplaintRepository) GetByStatus(status string, limit, offset int) ([]*models.Complaint, error) {
 	query := `
-		SELECT id, user_id, complaint_text, pdf_telegram_file_id, pdf_filename, created_at, status
-		FROM complaints
-		WHERE status = $1
-		ORDER BY created_at DESC
+		SELECT ` + complaintColumns + `
+		FROM ` + complaintTables + `
+		WHERE c.status = $1
+		ORDER BY c.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
@@ -207,33 +286,132 @@ func (r *ComplaintRepository) GetByStatus(status string, limit, offset int) ([]*
 	}
 	defer rows.Close()
 
-	var complaints []*models.Complaint
-	for rows.Next() {
-		var complaint models.Complaint
-		err := rows.Scan(
-			&complaint.ID,
-			&complaint.UserID,
-			&complaint.ComplaintText,
-			&complaint.PDFTelegramFileID,
-			&complaint.PDFFilename,
-			&complaint.CreatedAt,
-			&complaint.Status,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan complaint: %w", err)
-		}
-		complaints = append(complaints, &complaint)
+	return r.scanComplaints(rows)
+}
+
+// UpdateStatus updates the status of a pending complaint whose text is still
+// the given version. Returns false if the parent withdrew or corrected it, or
+// it was already handled, since it was read.
+func (r *ComplaintRepository) UpdateStatus(id, version int, status string) (bool, error) {
+	query := `
+		UPDATE complaints
+		SET status = $1,
+		    reviewed_at = CASE WHEN $1 = 'reviewed' THEN CURRENT_TIMESTAMP ELSE reviewed_at END,
+		    first_response_at = CASE WHEN $1 = 'reviewed' THEN COALESCE(first_response_at, CURRENT_TIMESTAMP) ELSE first_response_at END
+		WHERE id = $2 AND version = $3 AND status = $4
+	`
+	result, err := r.db.Exec(query, status, id, version, models.StatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to update complaint status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
-	return complaints, nil
+	return rows > 0, nil
 }
 
-// UpdateStatus updates complaint status
-func (r *ComplaintRepository) UpdateStatus(id int, status string) error {
-	query := `UPDATE complaints SET status = $1 WHERE id = $2`
-	_, err := r.db.Exec(query, status, id)
+// Assign assigns a complaint to an admin; taking a complaint in hand counts as
+// its first response. Returns false if the complaint does not exist or is
+// already assigned to the admin.
+func (r *ComplaintRepository) Assign(id, adminID int) (bool, error) {
+	query := `
+		UPDATE complaints
+		SET assigned_admin_id = $1,
+		    assigned_at = CURRENT_TIMESTAMP,
+		    first_response_at = COALESCE(first_response_at, CURRENT_TIMESTAMP)
+		WHERE id = $2 AND (assigned_admin_id IS NULL OR assigned_admin_id != $1)
+	`
+	result, err := r.db.Exec(query, adminID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign complaint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// GetAssigneeID gets the admin a complaint is assigned to, nil if none
+func (r *ComplaintRepository) GetAssigneeID(id int) (*int, error) {
+	var adminID *int
+	err := r.db.QueryRow(`SELECT assigned_admin_id FROM complaints WHERE id = $1`, id).Scan(&adminID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint assignee: %w", err)
+	}
+
+	return adminID, nil
+}
+
+// GetTimeline gets when a complaint was taken in hand, reviewed, withdrawn and purged,
+// nil if it doesn't exist
+func (r *ComplaintRepository) GetTimeline(id int) (*models.SubmissionTimeline, error) {
+	query := `SELECT first_response_at, reviewed_at, withdrawn_at, purged_at FROM complaints WHERE id = $1`
+
+	var timeline models.SubmissionTimeline
+	err := r.db.QueryRow(query, id).Scan(
+		&timeline.FirstResponseAt,
+		&timeline.ReviewedAt,
+		&timeline.WithdrawnAt,
+		&timeline.PurgedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint timeline: %w", err)
+	}
+
+	return &timeline, nil
+}
+
+// GetAssignedWithUser gets the pending complaints assigned to an admin with user info, oldest first
+func (r *ComplaintRepository) GetAssignedWithUser(adminID int, limit, offset int) ([]*models.ComplaintWithUser, error) {
+	query := `
+		SELECT ` + complaintWithUserColumns + `
+		FROM v_complaints_with_user
+		WHERE id IN (SELECT id FROM complaints WHERE assigned_admin_id = $1 AND status = $2)
+		ORDER BY created_at ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(query, adminID, models.StatusPending, limit, offset)
 	if err != nil {
-		return fmt.Errorf("failed to update complaint status: %w", err)
+		return nil, fmt.Errorf("failed to get assigned complaints: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanComplaintsWithUser(rows)
+}
+
+// CountAssigned counts the pending complaints assigned to an admin
+func (r *ComplaintRepository) CountAssigned(adminID int) (int, error) {
+	query := `SELECT COUNT(*) FROM complaints WHERE assigned_admin_id = $1 AND status = $2`
+
+	var count int
+	err := r.db.QueryRow(query, adminID, models.StatusPending).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count assigned complaints: %w", err)
+	}
+	return count, nil
+}
+
+// UpdatePDF sets the generated PDF of a complaint
+func (r *ComplaintRepository) UpdatePDF(id int, fileID, filename string) error {
+	query := `UPDATE complaints SET pdf_telegram_file_id = $1, pdf_filename = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, fileID, filename, id)
+	if err != nil {
+		return fmt.Errorf("failed to update complaint PDF: %w", err)
 	}
 	return nil
 }
@@ -248,6 +426,18 @@ func (r *ComplaintRepository) Count() (int, error) {
 	return count, nil
 }
 
+// CountByCategory counts the complaints of a category in a status; status ""
+// counts every status
+func (r *ComplaintRepository) CountByCategory(categoryID int, status string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM complaints WHERE category_id = $1 AND ($2 = '' OR status = $2)`
+	err := r.db.QueryRow(query, categoryID, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count complaints by category: %w", err)
+	}
+	return count, nil
+}
+
 // CountByStatus counts complaints by status
 func (r *ComplaintRepository) CountByStatus(status string) (int, error) {
 	var count int
@@ -319,3 +509,183 @@ func (r *ComplaintRepository) GetComplaintImages(complaintID int) ([]models.Comp
 
 	return images, nil
 }
+
+// CreateReply stores a staff reply to a complaint
+func (r *ComplaintRepository) CreateReply(complaintID int, adminTelegramID int64, adminName, text string) (*models.ComplaintReply, error) {
+	encryptedText, err := r.cipher.Encrypt(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt reply text: %w", err)
+	}
+
+	query := `
+		INSERT INTO complaint_replies (complaint_id, admin_telegram_id, admin_name, reply_text)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	reply := models.ComplaintReply{
+		ComplaintID:     complaintID,
+		AdminTelegramID: adminTelegramID,
+		AdminName:       adminName,
+		ReplyText:       text,
+	}
+	err = r.db.QueryRow(query, complaintID, adminTelegramID, adminName, encryptedText).Scan(&reply.ID, &reply.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create complaint reply: %w", err)
+	}
+
+	return &reply, nil
+}
+
+// GetReplies gets the staff replies to a complaint, oldest first
+func (r *ComplaintRepository) GetReplies(complaintID int) ([]models.ComplaintReply, error) {
+	query := `
+		SELECT id, complaint_id, admin_telegram_id, admin_name, reply_text, created_at
+		FROM complaint_replies
+		WHERE complaint_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, complaintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint replies: %w", err)
+	}
+	defer rows.Close()
+
+	var replies []models.ComplaintReply
+	for rows.Next() {
+		var reply models.ComplaintReply
+		err := rows.Scan(
+			&reply.ID,
+			&reply.ComplaintID,
+			&reply.AdminTelegramID,
+			&reply.AdminName,
+			&reply.ReplyText,
+			&reply.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan complaint reply: %w", err)
+		}
+		if reply.ReplyText, err = r.cipher.Decrypt(reply.ReplyText); err != nil {
+			return nil, fmt.Errorf("failed to decrypt complaint reply: %w", err)
+		}
+		replies = append(replies, reply)
+	}
+
+	return replies, rows.Err()
+}
+
+// Withdraw marks a pending complaint of a user withdrawn. Returns false if the
+// complaint is not the user's or is no longer pending.
+func (r *ComplaintRepository) Withdraw(id, userID int) (bool, error) {
+	query := `
+		UPDATE complaints
+		SET status = $1, withdrawn_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND user_id = $3 AND status = $4
+	`
+	result, err := r.db.Exec(query, models.StatusWithdrawn, id, userID, models.StatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to withdraw complaint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// Revise replaces the text of a pending complaint of a user with a corrected
+// version. The current text and PDF are kept in complaint_versions and the PDF
+// is cleared until it is regenerated. Returns false if the complaint is not the
+// user's or is no longer pending.
+func (r *ComplaintRepository) Revise(id, userID int, text string) (bool, error) {
+	encryptedText, err := r.cipher.Encrypt(text)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt complaint text: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The stored text is copied as is, encrypted or not
+	archive := `
+		INSERT INTO complaint_versions (complaint_id, version, complaint_text, pdf_telegram_file_id, pdf_filename, submitted_at)
+		SELECT id, version, complaint_text, pdf_telegram_file_id, pdf_filename, COALESCE(edited_at, created_at)
+		FROM complaints
+		WHERE id = $1 AND user_id = $2 AND status = $3
+	`
+	result, err := tx.Exec(archive, id, userID, models.StatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to archive complaint version: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return false, nil
+	}
+
+	update := `
+		UPDATE complaints
+		SET complaint_text = $1, version = version + 1, edited_at = CURRENT_TIMESTAMP,
+		    pdf_telegram_file_id = '', pdf_filename = ''
+		WHERE id = $2
+	`
+	if _, err := tx.Exec(update, encryptedText, id); err != nil {
+		return false, fmt.Errorf("failed to update complaint text: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetVersions gets the earlier versions of a corrected complaint, oldest first
+func (r *ComplaintRepository) GetVersions(complaintID int) ([]models.ComplaintVersion, error) {
+	query := `
+		SELECT id, complaint_id, version, complaint_text, pdf_telegram_file_id, pdf_filename, submitted_at, replaced_at
+		FROM complaint_versions
+		WHERE complaint_id = $1
+		ORDER BY version ASC
+	`
+
+	rows, err := r.db.Query(query, complaintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.ComplaintVersion
+	for rows.Next() {
+		var version models.ComplaintVersion
+		err := rows.Scan(
+			&version.ID,
+			&version.ComplaintID,
+			&version.Version,
+			&version.ComplaintText,
+			&version.PDFTelegramFileID,
+			&version.PDFFilename,
+			&version.SubmittedAt,
+			&version.ReplacedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan complaint version: %w", err)
+		}
+		if version.ComplaintText, err = r.cipher.Decrypt(version.ComplaintText); err != nil {
+			return nil, fmt.Errorf("failed to decrypt complaint version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
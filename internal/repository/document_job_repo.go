@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anor-kids/internal/models"
+)
+
+// documentJobColumns is the column list shared by all document job queries, in scanDocumentJob order
+const documentJobColumns = `id, kind, item_id, chat_id, message_id, status, attempts, last_error, next_attempt_at, created_at, updated_at`
+
+type DocumentJobRepository struct {
+	db *sql.DB
+}
+
+func NewDocumentJobRepository(db *sql.DB) *DocumentJobRepository {
+	return &DocumentJobRepository{db: db}
+}
+
+// scanDocumentJob scans a row selected with documentJobColumns
+func scanDocumentJob(row rowScanner) (*models.DocumentJob, error) {
+	var job models.DocumentJob
+	err := row.Scan(
+		&job.ID,
+		&job.Kind,
+		&job.ItemID,
+		&job.ChatID,
+		&job.MessageID,
+		&job.Status,
+		&job.Attempts,
+		&job.LastError,
+		&job.NextAttemptAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Create queues a new document job
+func (r *DocumentJobRepository) Create(kind string, itemID int, chatID int64, messageID int) (*models.DocumentJob, error) {
+	query := `
+		INSERT INTO document_jobs (kind, item_id, chat_id, message_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + documentJobColumns
+
+	job, err := scanDocumentJob(r.db.QueryRow(query, kind, itemID, chatID, messageID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ClaimNext marks the oldest due queued job as running and returns it, nil if there is none
+func (r *DocumentJobRepository) ClaimNext() (*models.DocumentJob, error) {
+	query := `
+		UPDATE document_jobs
+		SET status = 'running', attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM document_jobs
+			WHERE status = 'queued' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY id ASC
+			LIMIT 1
+		)
+		RETURNING ` + documentJobColumns
+
+	job, err := scanDocumentJob(r.db.QueryRow(query))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim document job: %w", err)
+	}
+
+	return job, nil
+}
+
+// MarkDone marks a job as completed
+func (r *DocumentJobRepository) MarkDone(id int) error {
+	query := `UPDATE document_jobs SET status = 'done', last_error = '', updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to mark document job done: %w", err)
+	}
+	return nil
+}
+
+// Retry puts a job back in the queue. $3 is an SQLite datetime modifier such as '+2 minutes'.
+func (r *DocumentJobRepository) Retry(id int, lastError, delayModifier string) error {
+	query := `
+		UPDATE document_jobs
+		SET status = 'queued', last_error = $1, next_attempt_at = datetime('now', $2), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+	if _, err := r.db.Exec(query, lastError, delayModifier, id); err != nil {
+		return fmt.Errorf("failed to requeue document job: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed marks a job as permanently failed
+func (r *DocumentJobRepository) MarkFailed(id int, lastError string) error {
+	query := `UPDATE document_jobs SET status = 'failed', last_error = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := r.db.Exec(query, lastError, id); err != nil {
+		return fmt.Errorf("failed to mark document job failed: %w", err)
+	}
+	return nil
+}
+
+// RequeueRunning puts jobs interrupted by a restart back in the queue
+func (r *DocumentJobRepository) RequeueRunning() (int, error) {
+	result, err := r.db.Exec(`UPDATE document_jobs SET status = 'queued', updated_at = CURRENT_TIMESTAMP WHERE status = 'running'`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue running document jobs: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rows), nil
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"anor-kids/internal/encryption"
+	"anor-kids/internal/models"
+)
+
+// encryptedTable describes a table with encrypted columns
+type encryptedTable struct {
+	name      string
+	columns   []string
+	hashField string // column whose blind index is kept in phone_hash, "" if none
+}
+
+var encryptedTables = []encryptedTable{
+	{name: "users", columns: []string{"phone_number", "child_name"}, hashField: "phone_number"},
+	{name: "admins", columns: []string{"phone_number"}, hashField: "phone_number"},
+	{name: "complaints", columns: []string{"complaint_text"}},
+	{name: "complaint_replies", columns: []string{"reply_text"}},
+	{name: "complaint_versions", columns: []string{"complaint_text"}},
+	{name: "complaint_ratings", columns: []string{"comment"}},
+	{name: "proposals", columns: []string{"proposal_text"}},
+	{name: "roster_entries", columns: []string{"phone_number", "child_name"}, hashField: "phone_number"},
+}
+
+type EncryptionRepository struct {
+	db *sql.DB
+}
+
+func NewEncryptionRepository(db *sql.DB) *EncryptionRepository {
+	return &EncryptionRepository{db: db}
+}
+
+// GetKeys gets all wrapped keys, oldest first
+func (r *EncryptionRepository) GetKeys() ([]*models.DataKey, error) {
+	query := `
+		SELECT id, purpose, wrapped_key, active, created_at
+		FROM data_keys
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.DataKey
+	for rows.Next() {
+		var key models.DataKey
+		err := rows.Scan(
+			&key.ID,
+			&key.Purpose,
+			&key.WrappedKey,
+			&key.Active,
+			&key.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data key: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, nil
+}
+
+// CreateKey stores a new wrapped key
+func (r *EncryptionRepository) CreateKey(purpose, wrappedKey string, active bool) (*models.DataKey, error) {
+	query := `
+		INSERT INTO data_keys (purpose, wrapped_key, active)
+		VALUES ($1, $2, $3)
+		RETURNING id, purpose, wrapped_key, active, created_at
+	`
+
+	var key models.DataKey
+	err := r.db.QueryRow(query, purpose, wrappedKey, active).Scan(
+		&key.ID,
+		&key.Purpose,
+		&key.WrappedKey,
+		&key.Active,
+		&key.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// ActivateKey makes a data key the one used for new values
+func (r *EncryptionRepository) ActivateKey(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE data_keys SET active = 0 WHERE purpose = 'data'`); err != nil {
+		return fmt.Errorf("failed to deactivate data keys: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE data_keys SET active = 1 WHERE id = $1 AND purpose = 'data'`, id); err != nil {
+		return fmt.Errorf("failed to activate data key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateWrappedKeys replaces wrapped keys by ID (used when the master key changes)
+func (r *EncryptionRepository) UpdateWrappedKeys(wrapped map[int]string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for id, wrappedKey := range wrapped {
+		if _, err := tx.Exec(`UPDATE data_keys SET wrapped_key = $1 WHERE id = $2`, wrappedKey, id); err != nil {
+			return fmt.Errorf("failed to update data key %d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReencryptStale encrypts plaintext values, re-encrypts values encrypted with
+// an inactive data key and fills missing or outdated blind indexes.
+// Returns the number of updated rows.
+func (r *EncryptionRepository) ReencryptStale(cipher *encryption.Cipher) (int, error) {
+	total := 0
+	for _, t := range encryptedTables {
+		updated, err := r.reencryptTable(cipher, t)
+		if err != nil {
+			return total, err
+		}
+		total += updated
+	}
+	return total, nil
+}
+
+// staleRow is a row whose encrypted columns or blind index need updating
+type staleRow struct {
+	id     int
+	values []string
+	hash   sql.NullString
+}
+
+// reencryptTable re-encrypts the stale rows of one table
+func (r *EncryptionRepository) reencryptTable(cipher *encryption.Cipher, t encryptedTable) (int, error) {
+	stale, err := r.getStaleRows(cipher, t)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	assignments := make([]string, 0, len(t.columns)+1)
+	for i, column := range t.columns {
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, i+1))
+	}
+	if t.hashField != "" {
+		assignments = append(assignments, fmt.Sprintf("phone_hash = $%d", len(t.columns)+1))
+	}
+	query := `UPDATE ` + t.name + ` SET ` + strings.Join(assignments, ", ") + fmt.Sprintf(` WHERE id = $%d`, len(assignments)+1)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, row := range stale {
+		args := make([]any, 0, len(assignments)+1)
+		for _, value := range row.values {
+			args = append(args, value)
+		}
+		if t.hashField != "" {
+			args = append(args, row.hash)
+		}
+		args = append(args, row.id)
+
+		if _, err := tx.Exec(query, args...); err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt %s %d: %w", t.name, row.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(stale), nil
+}
+
+// getStaleRows reads all rows of a table and returns the ones that need
+// updating, with the new values already computed. All rows are read before
+// any update because the database allows a single open connection.
+func (r *EncryptionRepository) getStaleRows(cipher *encryption.Cipher, t encryptedTable) ([]staleRow, error) {
+	columns := append([]string{"id"}, t.columns...)
+	if t.hashField != "" {
+		columns = append(columns, "phone_hash")
+	}
+
+	rows, err := r.db.Query(`SELECT ` + strings.Join(columns, ", ") + ` FROM ` + t.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	var stale []staleRow
+	for rows.Next() {
+		row := staleRow{values: make([]string, len(t.columns))}
+		dest := []any{&row.id}
+		for i := range row.values {
+			dest = append(dest, &row.values[i])
+		}
+		if t.hashField != "" {
+			dest = append(dest, &row.hash)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", t.name, err)
+		}
+
+		changed := false
+		for i, value := range row.values {
+			plaintext, err := cipher.Decrypt(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s.%s of row %d: %w", t.name, t.columns[i], row.id, err)
+			}
+
+			if t.columns[i] == t.hashField {
+				hash := cipher.BlindIndex(plaintext)
+				if !row.hash.Valid || row.hash.String != hash {
+					row.hash = sql.NullString{String: hash, Valid: true}
+					changed = true
+				}
+			}
+
+			if cipher.NeedsReencryption(value) {
+				if row.values[i], err = cipher.Encrypt(plaintext); err != nil {
+					return nil, fmt.Errorf("failed to encrypt %s.%s of row %d: %w", t.name, t.columns[i], row.id, err)
+				}
+				changed = true
+			}
+		}
+
+		if changed {
+			stale = append(stale, row)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", t.name, err)
+	}
+
+	return stale, nil
+}
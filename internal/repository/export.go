@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"anor-kids/internal/models"
+)
+
+// sqlTimeLayout is the format of timestamps stored by CURRENT_TIMESTAMP
+const sqlTimeLayout = "2006-01-02 15:04:05"
+
+// exportWhere builds the WHERE clause of an export query. dateColumn is compared
+// with the filter's period, statusColumn with its status and categoryColumn with
+// its category; tables without categories pass "". Rows whose anonymousColumn is
+// set never match a class, so the filter can't reveal the class of an anonymous
+// parent; tables without anonymous rows pass "". Placeholders start at $1; the
+// returned args are in placeholder order.
+func exportWhere(filter models.ExportFilter, dateColumn, statusColumn, categoryColumn, anonymousColumn string) (string, []any) {
+	var conditions []string
+	var args []any
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	// Timestamps are stored in UTC as "YYYY-MM-DD HH:MM:SS"
+	if !filter.From.IsZero() {
+		addCondition(dateColumn+" >= $%d", filter.From.UTC().Format(sqlTimeLayout))
+	}
+	if !filter.To.IsZero() {
+		addCondition(dateColumn+" < $%d", filter.To.UTC().Format(sqlTimeLayout))
+	}
+	if filter.Status != "" {
+		addCondition(statusColumn+" = $%d", filter.Status)
+	}
+	if filter.ChildClass != "" {
+		condition := "child_class = $%d"
+		if anonymousColumn != "" {
+			condition += " AND " + anonymousColumn + " = 0"
+		}
+		addCondition(condition, filter.ChildClass)
+	}
+	if filter.CategoryID != 0 && categoryColumn != "" {
+		addCondition(categoryColumn+" = $%d", filter.CategoryID)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// exportPage appends ORDER BY and paging to an export query built with exportWhere
+func exportPage(query, orderBy string, args []any, limit, offset int) (string, []any) {
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, len(args)+1, len(args)+2)
+	return query, append(args, limit, offset)
+}
@@ -4,19 +4,51 @@ import (
 	"database/sql"
 	"fmt"
 
+	"anor-kids/internal/encryption"
 	"anor-kids/internal/models"
 )
 
 type ProposalRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *encryption.Cipher
 }
 
-func NewProposalRepository(db *sql.DB) *ProposalRepository {
-	return &ProposalRepository{db: db}
+func NewProposalRepository(db *sql.DB, cipher *encryption.Cipher) *ProposalRepository {
+	return &ProposalRepository{db: db, cipher: cipher}
+}
+
+// decrypt decrypts the encrypted fields of a proposal
+func (r *ProposalRepository) decrypt(proposal *models.Proposal) error {
+	text, err := r.cipher.Decrypt(proposal.ProposalText)
+	if err != nil {
+		return err
+	}
+	proposal.ProposalText = text
+	return nil
+}
+
+// decryptWithUser decrypts the encrypted fields of a proposal and its author
+func (r *ProposalRepository) decryptWithUser(proposal *models.ProposalWithUser) error {
+	var err error
+	if proposal.ProposalText, err = r.cipher.Decrypt(proposal.ProposalText); err != nil {
+		return err
+	}
+	if proposal.PhoneNumber, err = r.cipher.Decrypt(proposal.PhoneNumber); err != nil {
+		return err
+	}
+	if proposal.ChildName, err = r.cipher.Decrypt(proposal.ChildName); err != nil {
+		return err
+	}
+	return nil
 }
 
 // Create creates a new proposal with PDF file
 func (r *ProposalRepository) Create(req *models.CreateProposalRequest) (*models.Proposal, error) {
+	encryptedText, err := r.cipher.Encrypt(req.ProposalText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt proposal text: %w", err)
+	}
+
 	query := `
 		INSERT INTO proposals (user_id, proposal_text, pdf_telegram_file_id, pdf_filename)
 		VALUES ($1, $2, $3, $4)
@@ -24,10 +56,10 @@ func (r *ProposalRepository) Create(req *models.CreateProposalRequest) (*models.
 	`
 
 	var proposal models.Proposal
-	err := r.db.QueryRow(
+	err = r.db.QueryRow(
 		query,
 		req.UserID,
-		req.ProposalText,
+		encryptedText,
 		req.PDFTelegramFileID,
 		req.PDFFilename,
 	).Scan(
@@ -44,6 +76,10 @@ func (r *ProposalRepository) Create(req *models.CreateProposalRequest) (*models.
 		return nil, fmt.Errorf("failed to create proposal: %w", err)
 	}
 
+	if err := r.decrypt(&proposal); err != nil {
+		return nil, fmt.Errorf("failed to decrypt proposal: %w", err)
+	}
+
 	return &proposal, nil
 }
 
@@ -74,6 +110,10 @@ func (r *ProposalRepository) GetByID(id int) (*models.Proposal, error) {
 		return nil, fmt.Errorf("failed to get proposal: %w", err)
 	}
 
+	if err := r.decrypt(&proposal); err != nil {
+		return nil, fmt.Errorf("failed to decrypt proposal: %w", err)
+	}
+
 	return &proposal, nil
 }
 
@@ -108,6 +148,9 @@ func (r *ProposalRepository) GetByUserID(userID int, limit, offset int) ([]*mode
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan proposal: %w", err)
 		}
+		if err := r.decrypt(&proposal); err != nil {
+			return nil, fmt.Errorf("failed to decrypt proposal: %w", err)
+		}
 		proposals = append(proposals, &proposal)
 	}
 
@@ -144,6 +187,9 @@ func (r *ProposalRepository) GetAll(limit, offset int) ([]*models.Proposal, erro
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan proposal: %w", err)
 		}
+		if err := r.decrypt(&proposal); err != nil {
+			return nil, fmt.Errorf("failed to decrypt proposal: %w", err)
+		}
 		proposals = append(proposals, &proposal)
 	}
 
@@ -165,6 +211,29 @@ func (r *ProposalRepository) GetAllWithUser(limit, offset int) ([]*models.Propos
 	}
 	defer rows.Close()
 
+	return r.scanProposalsWithUser(rows)
+}
+
+// GetForExport gets proposals with user info matching an export filter, oldest first
+func (r *ProposalRepository) GetForExport(filter models.ExportFilter, limit, offset int) ([]*models.ProposalWithUser, error) {
+	where, args := exportWhere(filter, "created_at", "status", "", "")
+	query, args := exportPage(`
+		SELECT id, user_id, proposal_text, pdf_telegram_file_id, pdf_filename, created_at, status,
+		       user_telegram_id, telegram_username, phone_number, child_name, child_class
+		FROM v_proposals_with_user
+		`+where, "created_at ASC, id ASC", args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposals for export: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanProposalsWithUser(rows)
+}
+
+// scanProposalsWithUser scans and decrypts rows selected from v_proposals_with_user
+func (r *ProposalRepository) scanProposalsWithUser(rows *sql.Rows) ([]*models.ProposalWithUser, error) {
 	var proposals []*models.ProposalWithUser
 	for rows.Next() {
 		var proposal models.ProposalWithUser
@@ -185,10 +254,13 @@ func (r *ProposalRepository) GetAllWithUser(limit, offset int) ([]*models.Propos
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan proposal with user: %w", err)
 		}
+		if err := r.decryptWithUser(&proposal); err != nil {
+			return nil, fmt.Errorf("failed to decrypt proposal: %w", err)
+		}
 		proposals = append(proposals, &proposal)
 	}
 
-	return proposals, nil
+	return proposals, rows.Err()
 }
 
 // GetByStatus gets proposals by status (indexed, fast query)
@@ -222,6 +294,9 @@ func (r *ProposalRepository) GetByStatus(status string, limit, offset int) ([]*m
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan proposal: %w", err)
 		}
+		if err := r.decrypt(&proposal); err != nil {
+			return nil, fmt.Errorf("failed to decrypt proposal: %w", err)
+		}
 		proposals = append(proposals, &proposal)
 	}
 
@@ -230,7 +305,13 @@ func (r *ProposalRepository) GetByStatus(status string, limit, offset int) ([]*m
 
 // UpdateStatus updates proposal status
 func (r *ProposalRepository) UpdateStatus(id int, status string) error {
-	query := `UPDATE proposals SET status = $1 WHERE id = $2`
+	query := `
+		UPDATE proposals
+		SET status = $1,
+		    reviewed_at = CASE WHEN $1 = 'reviewed' THEN CURRENT_TIMESTAMP ELSE reviewed_at END,
+		    first_response_at = CASE WHEN $1 = 'reviewed' THEN COALESCE(first_response_at, CURRENT_TIMESTAMP) ELSE first_response_at END
+		WHERE id = $2
+	`
 	_, err := r.db.Exec(query, status, id)
 	if err != nil {
 		return fmt.Errorf("failed to update proposal status: %w", err)
@@ -238,6 +319,38 @@ func (r *ProposalRepository) UpdateStatus(id int, status string) error {
 	return nil
 }
 
+// GetTimeline gets when a proposal was taken in hand, reviewed and purged,
+// nil if it doesn't exist
+func (r *ProposalRepository) GetTimeline(id int) (*models.SubmissionTimeline, error) {
+	query := `SELECT first_response_at, reviewed_at, purged_at FROM proposals WHERE id = $1`
+
+	var timeline models.SubmissionTimeline
+	err := r.db.QueryRow(query, id).Scan(
+		&timeline.FirstResponseAt,
+		&timeline.ReviewedAt,
+		&timeline.PurgedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposal timeline: %w", err)
+	}
+
+	return &timeline, nil
+}
+
+// UpdatePDF sets the generated PDF of a proposal
+func (r *ProposalRepository) UpdatePDF(id int, fileID, filename string) error {
+	query := `UPDATE proposals SET pdf_telegram_file_id = $1, pdf_filename = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, fileID, filename, id)
+	if err != nil {
+		return fmt.Errorf("failed to update proposal PDF: %w", err)
+	}
+	return nil
+}
+
 // Count counts total proposals
 func (r *ProposalRepository) Count() (int, error) {
 	var count int
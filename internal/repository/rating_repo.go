@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anor-kids/internal/encryption"
+	"anor-kids/internal/models"
+)
+
+type RatingRepository struct {
+	db     *sql.DB
+	cipher *encryption.Cipher
+}
+
+func NewRatingRepository(db *sql.DB, cipher *encryption.Cipher) *RatingRepository {
+	return &RatingRepository{db: db, cipher: cipher}
+}
+
+// CreateRequest records that the author of a reviewed complaint is asked for a
+// rating. Returns false if they were already asked.
+func (r *RatingRepository) CreateRequest(complaintID int, staffTelegramID int64, staffName string) (bool, error) {
+	query := `
+		INSERT INTO complaint_ratings (complaint_id, staff_telegram_id, staff_name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (complaint_id) DO NOTHING
+	`
+	result, err := r.db.Exec(query, complaintID, staffTelegramID, staffName)
+	if err != nil {
+		return false, fmt.Errorf("failed to create rating request: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// GetByComplaintID gets the rating of a complaint, nil if its author was never asked
+func (r *RatingRepository) GetByComplaintID(complaintID int) (*models.ComplaintRating, error) {
+	query := `
+		SELECT id, complaint_id, staff_telegram_id, staff_name, rating, comment, requested_at, rated_at
+		FROM complaint_ratings
+		WHERE complaint_id = $1
+	`
+
+	var rating models.ComplaintRating
+	err := r.db.QueryRow(query, complaintID).Scan(
+		&rating.ID,
+		&rating.ComplaintID,
+		&rating.StaffTelegramID,
+		&rating.StaffName,
+		&rating.Rating,
+		&rating.Comment,
+		&rating.RequestedAt,
+		&rating.RatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating: %w", err)
+	}
+
+	if rating.Comment, err = r.cipher.Decrypt(rating.Comment); err != nil {
+		return nil, fmt.Errorf("failed to decrypt rating comment: %w", err)
+	}
+
+	return &rating, nil
+}
+
+// SetRating stores the parent's rating of a complaint; a second answer replaces
+// the first. Returns false if the parent was never asked.
+func (r *RatingRepository) SetRating(complaintID, rating int) (bool, error) {
+	query := `
+		UPDATE complaint_ratings
+		SET rating = $1, rated_at = CURRENT_TIMESTAMP
+		WHERE complaint_id = $2
+	`
+	result, err := r.db.Exec(query, rating, complaintID)
+	if err != nil {
+		return false, fmt.Errorf("failed to set rating: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// SetComment stores the parent's comment on a rated complaint. Returns false
+// if the complaint has no rating yet.
+func (r *RatingRepository) SetComment(complaintID int, comment string) (bool, error) {
+	encryptedComment, err := r.cipher.Encrypt(comment)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt rating comment: %w", err)
+	}
+
+	query := `UPDATE complaint_ratings SET comment = $1 WHERE complaint_id = $2 AND rating IS NOT NULL`
+	result, err := r.db.Exec(query, encryptedComment, complaintID)
+	if err != nil {
+		return false, fmt.Errorf("failed to set rating comment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
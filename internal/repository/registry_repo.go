@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anor-kids/internal/models"
+)
+
+// registryColumns is the column list shared by all registry queries, in scanRegistryEntry order
+const registryColumns = `id, code, kind, item_id, document_hash, prev_hash, entry_hash, created_at`
+
+type RegistryRepository struct {
+	db *sql.DB
+}
+
+func NewRegistryRepository(db *sql.DB) *RegistryRepository {
+	return &RegistryRepository{db: db}
+}
+
+// scanRegistryEntry scans a row selected with registryColumns
+func scanRegistryEntry(row rowScanner) (*models.RegistryEntry, error) {
+	var entry models.RegistryEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.Code,
+		&entry.Kind,
+		&entry.ItemID,
+		&entry.DocumentHash,
+		&entry.PrevHash,
+		&entry.EntryHash,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Create appends an entry. It fails if another entry already follows entry.PrevHash.
+func (r *RegistryRepository) Create(entry *models.RegistryEntry) error {
+	query := `
+		INSERT INTO document_registry (code, kind, item_id, document_hash, prev_hash, entry_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(query, entry.Code, entry.Kind, entry.ItemID, entry.DocumentHash,
+		entry.PrevHash, entry.EntryHash, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create registry entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetLast returns the most recent entry, nil if the registry is empty
+func (r *RegistryRepository) GetLast() (*models.RegistryEntry, error) {
+	query := `SELECT ` + registryColumns + ` FROM document_registry ORDER BY id DESC LIMIT 1`
+	return r.getOne(query)
+}
+
+// GetByCode returns the entry with a verification code, nil if there is none
+func (r *RegistryRepository) GetByCode(code string) (*models.RegistryEntry, error) {
+	query := `SELECT ` + registryColumns + ` FROM document_registry WHERE code = $1`
+	return r.getOne(query, code)
+}
+
+// GetByDocumentHash returns the entry for a document's SHA-256, nil if there is none
+func (r *RegistryRepository) GetByDocumentHash(documentHash string) (*models.RegistryEntry, error) {
+	query := `SELECT ` + registryColumns + ` FROM document_registry WHERE document_hash = $1 ORDER BY id ASC LIMIT 1`
+	return r.getOne(query, documentHash)
+}
+
+// GetAll returns all entries in chain order
+func (r *RegistryRepository) GetAll() ([]*models.RegistryEntry, error) {
+	query := `SELECT ` + registryColumns + ` FROM document_registry ORDER BY id ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registry entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.RegistryEntry
+	for rows.Next() {
+		entry, err := scanRegistryEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan registry entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *RegistryRepository) getOne(query string, args ...any) (*models.RegistryEntry, error) {
+	entry, err := scanRegistryEntry(r.db.QueryRow(query, args...))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registry entry: %w", err)
+	}
+
+	return entry, nil
+}
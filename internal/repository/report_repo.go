@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"anor-kids/internal/models"
+)
+
+// reportColumns is the column list shared by all subscription queries, in
+// scanReportSubscription order; report_subscriptions is aliased s, admins a
+const reportColumns = `s.id, s.admin_id, a.telegram_id, s.frequency, s.send_hour, s.last_sent_at, s.updated_at`
+
+type ReportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// scanReportSubscription scans a row selected with reportColumns
+func scanReportSubscription(row rowScanner) (*models.ReportSubscription, error) {
+	var sub models.ReportSubscription
+	var lastSentAt sql.NullTime
+	err := row.Scan(
+		&sub.ID,
+		&sub.AdminID,
+		&sub.TelegramID,
+		&sub.Frequency,
+		&sub.SendHour,
+		&lastSentAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastSentAt.Valid {
+		sub.LastSentAt = &lastSentAt.Time
+	}
+
+	return &sub, nil
+}
+
+// Subscribe subscribes an admin to reports or changes their schedule
+func (r *ReportRepository) Subscribe(adminID int, frequency string, sendHour int) error {
+	query := `
+		INSERT INTO report_subscriptions (admin_id, frequency, send_hour)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (admin_id) DO UPDATE
+		SET frequency = excluded.frequency,
+		    send_hour = excluded.send_hour,
+		    updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := r.db.Exec(query, adminID, frequency, sendHour)
+	if err != nil {
+		return fmt.Errorf("failed to save report subscription: %w", err)
+	}
+
+	return nil
+}
+
+// Unsubscribe stops an admin's reports
+func (r *ReportRepository) Unsubscribe(adminID int) error {
+	_, err := r.db.Exec(`DELETE FROM report_subscriptions WHERE admin_id = $1`, adminID)
+	if err != nil {
+		return fmt.Errorf("failed to delete report subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetByAdminID gets an admin's subscription, nil if they are not subscribed
+func (r *ReportRepository) GetByAdminID(adminID int) (*models.ReportSubscription, error) {
+	query := `
+		SELECT ` + reportColumns + `
+		FROM report_subscriptions s
+		JOIN admins a ON a.id = s.admin_id
+		WHERE s.admin_id = $1
+	`
+
+	sub, err := scanReportSubscription(r.db.QueryRow(query, adminID))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// GetAll gets the subscriptions of admins who have linked their Telegram account
+func (r *ReportRepository) GetAll() ([]*models.ReportSubscription, error) {
+	query := `
+		SELECT ` + reportColumns + `
+		FROM report_subscriptions s
+		JOIN admins a ON a.id = s.admin_id
+		WHERE a.telegram_id IS NOT NULL
+		ORDER BY s.id
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*models.ReportSubscription
+	for rows.Next() {
+		sub, err := scanReportSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// MarkSent records when a subscription's report was last sent
+func (r *ReportRepository) MarkSent(id int, sentAt time.Time) error {
+	query := `UPDATE report_subscriptions SET last_sent_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, sentAt.UTC().Format(sqlTimeLayout), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark report sent: %w", err)
+	}
+
+	return nil
+}
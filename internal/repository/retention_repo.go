@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anor-kids/internal/encryption"
+	"anor-kids/internal/models"
+)
+
+// retentionTable describes a submission table the retention rules apply to
+type retentionTable struct {
+	kind          string // value of anonymized_stats.kind and document_jobs.kind
+	name          string
+	textColumn    string
+	imageTable    string
+	imageKey      string   // key of imageTable, relatedTables and ratingTable rows
+	relatedTables []string // other contents of an item: staff replies, earlier versions
+	ratingTable   string   // ratings, kept for statistics with their comment cleared; "" if none
+}
+
+var retentionTables = map[string]retentionTable{
+	"complaints": {kind: "complaint", name: "complaints", textColumn: "complaint_text", imageTable: "complaint_images", imageKey: "complaint_id", relatedTables: []string{"complaint_replies", "complaint_versions"}, ratingTable: "complaint_ratings"},
+	"proposals":  {kind: "proposal", name: "proposals", textColumn: "proposal_text", imageTable: "proposal_images", imageKey: "proposal_id"},
+}
+
+// Retention rule conditions. $1 is an SQLite datetime modifier such as '-30 days'.
+const (
+	archivableCondition   = `status = 'reviewed' AND COALESCE(reviewed_at, created_at) < datetime('now', $1)`
+	purgeableCondition    = `purged_at IS NULL AND created_at < datetime('now', $1)`
+	inactiveUserCondition = `COALESCE(last_active_at, registered_at) < datetime('now', $1)
+		AND phone_hash NOT IN (SELECT phone_hash FROM admins WHERE phone_hash IS NOT NULL)`
+)
+
+type RetentionRepository struct {
+	db     *sql.DB
+	cipher *encryption.Cipher
+}
+
+func NewRetentionRepository(db *sql.DB, cipher *encryption.Cipher) *RetentionRepository {
+	return &RetentionRepository{db: db, cipher: cipher}
+}
+
+// lookupTable returns the retention table by name ("complaints" or "proposals")
+func lookupTable(table string) (retentionTable, error) {
+	t, ok := retentionTables[table]
+	if !ok {
+		return retentionTable{}, fmt.Errorf("unknown retention table: %s", table)
+	}
+	return t, nil
+}
+
+// CountArchivable counts reviewed items older than the modifier that would be archived
+func (r *RetentionRepository) CountArchivable(table, modifier string) (int, error) {
+	t, err := lookupTable(table)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM ` + t.name + ` WHERE ` + archivableCondition
+	if err := r.db.QueryRow(query, modifier).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count archivable %s: %w", t.name, err)
+	}
+	return count, nil
+}
+
+// Archive archives reviewed items older than the modifier
+func (r *RetentionRepository) Archive(table, modifier string) (int, error) {
+	t, err := lookupTable(table)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `UPDATE ` + t.name + ` SET status = 'archived' WHERE ` + archivableCondition
+	result, err := r.db.Exec(query, modifier)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive %s: %w", t.name, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rows), nil
+}
+
+// CountPurgeable counts items older than the modifier whose contents would be purged
+func (r *RetentionRepository) CountPurgeable(table, modifier string) (int, error) {
+	t, err := lookupTable(table)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM ` + t.name + ` WHERE ` + purgeableCondition
+	if err := r.db.QueryRow(query, modifier).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count purgeable %s: %w", t.name, err)
+	}
+	return count, nil
+}
+
+// Purge removes text, PDF and image references of items older than the modifier.
+// The rows themselves stay, so counts by status, class and date remain available.
+func (r *RetentionRepository) Purge(table, modifier string) (int, error) {
+	t, err := lookupTable(table)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleteImages := `DELETE FROM ` + t.imageTable + ` WHERE ` + t.imageKey + ` IN (SELECT id FROM ` + t.name + ` WHERE ` + purgeableCondition + `)`
+	if _, err := tx.Exec(deleteImages, modifier); err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", t.imageTable, err)
+	}
+
+	for _, related := range t.relatedTables {
+		deleteRelated := `DELETE FROM ` + related + ` WHERE ` + t.imageKey + ` IN (SELECT id FROM ` + t.name + ` WHERE ` + purgeableCondition + `)`
+		if _, err := tx.Exec(deleteRelated, modifier); err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", related, err)
+		}
+	}
+
+	// Document jobs hold the parent's chat ID; a queued job would fail without the text
+	deleteJobs := `DELETE FROM document_jobs WHERE kind = '` + t.kind + `' AND item_id IN (SELECT id FROM ` + t.name + ` WHERE ` + purgeableCondition + `)`
+	if _, err := tx.Exec(deleteJobs, modifier); err != nil {
+		return 0, fmt.Errorf("failed to purge %s document jobs: %w", t.kind, err)
+	}
+
+	if t.ratingTable != "" {
+		clearComments := `UPDATE ` + t.ratingTable + ` SET comment = '' WHERE ` + t.imageKey + ` IN (SELECT id FROM ` + t.name + ` WHERE ` + purgeableCondition + `)`
+		if _, err := tx.Exec(clearComments, modifier); err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", t.ratingTable, err)
+		}
+	}
+
+	// The PDF filename contains the child's name, so it goes too
+	purge := `
+		UPDATE ` + t.name + `
+		SET ` + t.textColumn + ` = '', pdf_telegram_file_id = '', pdf_filename = '', purged_at = CURRENT_TIMESTAMP
+		WHERE ` + purgeableCondition
+	result, err := tx.Exec(purge, modifier)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", t.name, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(rows), nil
+}
+
+// GetInactiveUsers gets non-admin users without activity since the modifier
+func (r *RetentionRepository) GetInactiveUsers(modifier string) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + inactiveUserCondition
+
+	rows, err := r.db.Query(query, modifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inactive users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUsers(rows, r.cipher)
+}
+
+// DeleteUserKeepingStats folds a user's complaints and proposals into anonymized_stats
+// and then deletes the user with all their data
+func (r *RetentionRepository) DeleteUserKeepingStats(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, t := range retentionTables {
+		query := `
+			INSERT INTO anonymized_stats (kind, class_name, month, status, count)
+			SELECT '` + t.kind + `', u.child_class, strftime('%Y-%m', s.created_at), s.status, COUNT(*)
+			FROM ` + t.name + ` s
+			JOIN users u ON u.id = s.user_id
+			WHERE s.user_id = $1
+			GROUP BY u.child_class, strftime('%Y-%m', s.created_at), s.status
+			ON CONFLICT (kind, class_name, month, status) DO UPDATE SET count = anonymized_stats.count + excluded.count
+		`
+		if _, err := tx.Exec(query, userID); err != nil {
+			return fmt.Errorf("failed to keep anonymized %s stats: %w", t.name, err)
+		}
+	}
+
+	if err := deleteUserData(tx, userID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CountAnonymized counts submissions of a kind kept only as anonymized statistics
+func (r *RetentionRepository) CountAnonymized(kind string) (int, error) {
+	var count int
+	query := `SELECT COALESCE(SUM(count), 0) FROM anonymized_stats WHERE kind = $1`
+	if err := r.db.QueryRow(query, kind).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count anonymized stats: %w", err)
+	}
+	return count, nil
+}
+
+// CreateRun records a retention run
+func (r *RetentionRepository) CreateRun(report *models.RetentionReport) error {
+	query := `
+		INSERT INTO retention_runs (dry_run, archived_complaints, archived_proposals, purged_complaints, purged_proposals, deleted_users)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING run_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		report.DryRun,
+		report.ArchivedComplaints,
+		report.ArchivedProposals,
+		report.PurgedComplaints,
+		report.PurgedProposals,
+		report.DeletedUsers,
+	).Scan(&report.RunAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record retention run: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastRun gets the latest real or dry retention run, nil if there was none
+func (r *RetentionRepository) GetLastRun(dryRun bool) (*models.RetentionReport, error) {
+	query := `
+		SELECT dry_run, archived_complaints, archived_proposals, purged_complaints, purged_proposals, deleted_users, run_at
+		FROM retention_runs
+		WHERE dry_run = $1
+		ORDER BY run_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var report models.RetentionReport
+	err := r.db.QueryRow(query, dryRun).Scan(
+		&report.DryRun,
+		&report.ArchivedComplaints,
+		&report.ArchivedProposals,
+		&report.PurgedComplaints,
+		&report.PurgedProposals,
+		&report.DeletedUsers,
+		&report.RunAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last retention run: %w", err)
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"anor-kids/internal/encryption"
+	"anor-kids/internal/models"
+)
+
+// rosterColumns is the column list shared by all roster queries, in scanRosterEntry order
+const rosterColumns = `id, phone_number, child_name, child_class, imported_at`
+
+type RosterRepository struct {
+	db     *sql.DB
+	cipher *encryption.Cipher
+}
+
+func NewRosterRepository(db *sql.DB, cipher *encryption.Cipher) *RosterRepository {
+	return &RosterRepository{db: db, cipher: cipher}
+}
+
+// scanRosterEntry scans a row selected with rosterColumns and decrypts the PII fields
+func scanRosterEntry(row rowScanner, cipher *encryption.Cipher) (*models.RosterEntry, error) {
+	var entry models.RosterEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.PhoneNumber,
+		&entry.ChildName,
+		&entry.ChildClass,
+		&entry.ImportedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.PhoneNumber, err = cipher.Decrypt(entry.PhoneNumber); err != nil {
+		return nil, err
+	}
+	if entry.ChildName, err = cipher.Decrypt(entry.ChildName); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Upsert pre-registers a parent. A phone that is already on the roster gets the new child and class.
+func (r *RosterRepository) Upsert(phoneNumber, childName, childClass string) error {
+	encryptedPhone, err := r.cipher.Encrypt(phoneNumber)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt phone number: %w", err)
+	}
+
+	encryptedChildName, err := r.cipher.Encrypt(childName)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt child name: %w", err)
+	}
+
+	query := `
+		INSERT INTO roster_entries (phone_number, phone_hash, child_name, child_class)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (phone_hash) DO UPDATE
+		SET phone_number = excluded.phone_number,
+		    child_name = excluded.child_name,
+		    child_class = excluded.child_class,
+		    imported_at = CURRENT_TIMESTAMP
+	`
+
+	_, err = r.db.Exec(query, encryptedPhone, r.cipher.BlindIndex(phoneNumber), encryptedChildName, childClass)
+	if err != nil {
+		return fmt.Errorf("failed to save roster entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetByPhoneNumber gets the roster entry of a phone number (blind index), nil if there is none
+func (r *RosterRepository) GetByPhoneNumber(phoneNumber string) (*models.RosterEntry, error) {
+	query := `SELECT ` + rosterColumns + ` FROM roster_entries WHERE phone_hash = $1`
+
+	entry, err := scanRosterEntry(r.db.QueryRow(query, r.cipher.BlindIndex(phoneNumber)), r.cipher)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roster entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Delete removes a roster entry once it is claimed
+func (r *RosterRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM roster_entries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete roster entry: %w", err)
+	}
+
+	return nil
+}
+
+// Count returns the number of parents still expected to register
+func (r *RosterRepository) Count() (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM roster_entries`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count roster entries: %w", err)
+	}
+
+	return count, nil
+}
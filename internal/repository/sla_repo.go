@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"anor-kids/internal/models"
+)
+
+type SLARepository struct {
+	db *sql.DB
+}
+
+func NewSLARepository(db *sql.DB) *SLARepository {
+	return &SLARepository{db: db}
+}
+
+// submissionTable returns the table of a submission kind
+func submissionTable(kind string) (string, error) {
+	for _, source := range submissionSources {
+		if source.kind == kind {
+			return source.table, nil
+		}
+	}
+	return "", fmt.Errorf("unknown submission kind %q", kind)
+}
+
+// Acknowledge records the first response to a complaint or proposal; later
+// calls keep the first time. Returns false if the item does not exist.
+func (r *SLARepository) Acknowledge(kind string, id int, at time.Time) (bool, error) {
+	table, err := submissionTable(kind)
+	if err != nil {
+		return false, err
+	}
+
+	query := `UPDATE ` + table + ` SET first_response_at = COALESCE(first_response_at, $1) WHERE id = $2`
+	result, err := r.db.Exec(query, at.UTC().Format(sqlTimeLayout), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to record first response: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// GetOverdue gets the pending complaints or proposals created before the
+// deadline, oldest first, with their assignee and the teacher of the parent's class
+func (r *SLARepository) GetOverdue(kind string, deadline time.Time) ([]*models.SLAItem, error) {
+	table, err := submissionTable(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only complaints are assigned to admins or sent anonymously. The class of
+	// an anonymous complaint is not shown, nor is its teacher reminded.
+	assignee, assigneeJoin, anonymous := `NULL`, ``, `0`
+	if kind == models.DocumentJobKindComplaint {
+		assignee, assigneeJoin = `a.telegram_id`, `LEFT JOIN admins a ON a.id = s.assigned_admin_id`
+		anonymous = `s.is_anonymous`
+	}
+
+	query := `
+		SELECT s.id, CASE WHEN ` + anonymous + ` = 1 THEN '' ELSE COALESCE(u.child_class, '') END,
+		       c.teacher_telegram_id, ` + assignee + `,
+		       s.created_at, s.first_response_at, s.escalation_level
+		FROM ` + table + ` s
+		LEFT JOIN users u ON u.id = s.user_id
+		LEFT JOIN classes c ON c.class_name = u.child_class AND ` + anonymous + ` = 0
+		` + assigneeJoin + `
+		WHERE s.status = $1 AND s.created_at < $2
+		ORDER BY s.created_at
+	`
+
+	rows, err := r.db.Query(query, models.StatusPending, deadline.UTC().Format(sqlTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var items []*models.SLAItem
+	for rows.Next() {
+		item := models.SLAItem{Kind: kind}
+		var firstResponseAt sql.NullTime
+		err := rows.Scan(
+			&item.ID,
+			&item.ChildClass,
+			&item.TeacherTelegramID,
+			&item.AssigneeTelegramID,
+			&item.CreatedAt,
+			&firstResponseAt,
+			&item.EscalationLevel,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan overdue %s: %w", table, err)
+		}
+		if firstResponseAt.Valid {
+			item.FirstResponseAt = &firstResponseAt.Time
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get overdue %s: %w", table, err)
+	}
+
+	return items, nil
+}
+
+// SetEscalationLevel records the reminder sent about a complaint or proposal
+func (r *SLARepository) SetEscalationLevel(kind string, id, level int, at time.Time) error {
+	table, err := submissionTable(kind)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE ` + table + ` SET escalation_level = $1, escalated_at = $2 WHERE id = $3`
+	_, err = r.db.Exec(query, level, at.UTC().Format(sqlTimeLayout), id)
+	if err != nil {
+		return fmt.Errorf("failed to update escalation level: %w", err)
+	}
+
+	return nil
+}
+
+// CountOverdue counts the pending complaints or proposals created before the deadline
+func (r *SLARepository) CountOverdue(kind string, deadline time.Time) (int, error) {
+	table, err := submissionTable(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT COUNT(*) FROM ` + table + ` WHERE status = $1 AND created_at < $2`
+
+	var count int
+	err = r.db.QueryRow(query, models.StatusPending, deadline.UTC().Format(sqlTimeLayout)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count overdue %s: %w", table, err)
+	}
+
+	return count, nil
+}
+
+// GetUnacknowledgedUrgent gets the pending urgent complaints nobody has
+// acknowledged and whose last alert was sent before the given time, oldest first
+func (r *SLARepository) GetUnacknowledgedUrgent(alertedBefore time.Time) ([]*models.UrgentItem, error) {
+	query := `
+		SELECT c.id, CASE WHEN c.is_anonymous = 1 THEN '' ELSE COALESCE(u.child_class, '') END, k.id, k.name_uz, k.name_ru, c.created_at, c.urgent_alert_count
+		FROM complaints c
+		LEFT JOIN users u ON u.id = c.user_id
+		LEFT JOIN complaint_categories k ON k.id = c.category_id
+		WHERE c.is_urgent = 1 AND c.urgent_acknowledged_at IS NULL AND c.status = $1
+		  AND (c.urgent_alerted_at IS NULL OR c.urgent_alerted_at < $2)
+		ORDER BY c.created_at
+	`
+
+	rows, err := r.db.Query(query, models.StatusPending, alertedBefore.UTC().Format(sqlTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get urgent complaints: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.UrgentItem
+	for rows.Next() {
+		var item models.UrgentItem
+		var categoryID sql.NullInt64
+		var categoryUz, categoryRu sql.NullString
+		err := rows.Scan(
+			&item.ID,
+			&item.ChildClass,
+			&categoryID,
+			&categoryUz,
+			&categoryRu,
+			&item.CreatedAt,
+			&item.AlertCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan urgent complaint: %w", err)
+		}
+		item.Category = categoryRef(categoryID, categoryUz, categoryRu)
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get urgent complaints: %w", err)
+	}
+
+	return items, nil
+}
+
+// SetUrgentAlerted records an alert sent to admins about an urgent complaint
+func (r *SLARepository) SetUrgentAlerted(id int, at time.Time) error {
+	query := `UPDATE complaints SET urgent_alerted_at = $1, urgent_alert_count = urgent_alert_count + 1 WHERE id = $2`
+	_, err := r.db.Exec(query, at.UTC().Format(sqlTimeLayout), id)
+	if err != nil {
+		return fmt.Errorf("failed to record urgent alert: %w", err)
+	}
+
+	return nil
+}
+
+// AcknowledgeUrgent records that a staff member acknowledged an urgent
+// complaint, which also counts as its first response. Returns false if the
+// complaint is not urgent or was already acknowledged.
+func (r *SLARepository) AcknowledgeUrgent(id int, telegramID int64, at time.Time) (bool, error) {
+	query := `
+		UPDATE complaints
+		SET urgent_acknowledged_at = $1,
+		    urgent_acknowledged_by = $2,
+		    first_response_at = COALESCE(first_response_at, $1)
+		WHERE id = $3 AND is_urgent = 1 AND urgent_acknowledged_at IS NULL
+	`
+	result, err := r.db.Exec(query, at.UTC().Format(sqlTimeLayout), telegramID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to acknowledge urgent complaint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
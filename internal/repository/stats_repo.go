@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"anor-kids/internal/models"
+)
+
+// submissionSources are the tables counted as submissions, with their kind
+var submissionSources = []struct {
+	kind  string
+	table string
+}{
+	{models.DocumentJobKindComplaint, "complaints"},
+	{models.DocumentJobKindProposal, "proposals"},
+}
+
+type StatsRepository struct {
+	db *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// GetSubmissions gets the complaints and proposals created since a time, with
+// the class of their parent, empty for anonymous complaints. No texts or
+// personal data are read.
+func (r *StatsRepository) GetSubmissions(since time.Time) ([]*models.Submission, error) {
+	return r.querySubmissions(`s.created_at >= $1`, since.UTC().Format(sqlTimeLayout))
+}
+
+// GetUnresolved gets the pending complaints and proposals created before a time
+func (r *StatsRepository) GetUnresolved(before time.Time) ([]*models.Submission, error) {
+	return r.querySubmissions(`s.status = $1 AND s.created_at < $2`, models.StatusPending, before.UTC().Format(sqlTimeLayout))
+}
+
+// querySubmissions gets the complaints and proposals matching a condition on
+// the submission table, aliased s. Tables are queried one by one, since a
+// UNION would return created_at as text instead of a time.
+func (r *StatsRepository) querySubmissions(where string, args ...interface{}) ([]*models.Submission, error) {
+	var submissions []*models.Submission
+
+	for _, source := range submissionSources {
+		// Only complaints have categories or are sent anonymously; the class of an
+		// anonymous complaint is left empty, as for reminders
+		class, category, categoryJoin := `COALESCE(u.child_class, '')`, `NULL, NULL, NULL`, ``
+		if source.kind == models.DocumentJobKindComplaint {
+			class = `CASE WHEN s.is_anonymous = 1 THEN '' ELSE COALESCE(u.child_class, '') END`
+			category, categoryJoin = `k.id, k.name_uz, k.name_ru`, `LEFT JOIN complaint_categories k ON k.id = s.category_id`
+		}
+
+		query := `
+			SELECT s.id, s.user_id, ` + class + `, ` + category + `, s.status, s.created_at, s.reviewed_at
+			FROM ` + source.table + ` s
+			LEFT JOIN users u ON u.id = s.user_id
+			` + categoryJoin + `
+			WHERE ` + where + `
+			ORDER BY s.created_at
+		`
+
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s for statistics: %w", source.table, err)
+		}
+
+		for rows.Next() {
+			submission := models.Submission{Kind: source.kind}
+			var reviewedAt sql.NullTime
+			var categoryID sql.NullInt64
+			var categoryUz, categoryRu sql.NullString
+			err := rows.Scan(
+				&submission.ID,
+				&submission.UserID,
+				&submission.ChildClass,
+				&categoryID,
+				&categoryUz,
+				&categoryRu,
+				&submission.Status,
+				&submission.CreatedAt,
+				&reviewedAt,
+			)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s for statistics: %w", source.table, err)
+			}
+			submission.Category = categoryRef(categoryID, categoryUz, categoryRu)
+			if reviewedAt.Valid {
+				submission.ReviewedAt = &reviewedAt.Time
+			}
+			submissions = append(submissions, &submission)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s for statistics: %w", source.table, err)
+		}
+	}
+
+	return submissions, nil
+}
+
+// CountRegistrations counts the parents registered in [from, to)
+func (r *StatsRepository) CountRegistrations(from, to time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM users WHERE registered_at >= $1 AND registered_at < $2`
+
+	var count int
+	err := r.db.QueryRow(query, from.UTC().Format(sqlTimeLayout), to.UTC().Format(sqlTimeLayout)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count registrations: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetRatings gets the ratings parents gave since a time, with the category of
+// the rated complaint. Comments are not read.
+func (r *StatsRepository) GetRatings(since time.Time) ([]*models.RatedComplaint, error) {
+	query := `
+		SELECT r.complaint_id, k.id, k.name_uz, k.name_ru, r.staff_telegram_id, r.staff_name, r.rating, r.rated_at
+		FROM complaint_ratings r
+		JOIN complaints c ON c.id = r.complaint_id
+		LEFT JOIN complaint_categories k ON k.id = c.category_id
+		WHERE r.rating IS NOT NULL AND r.rated_at >= $1
+		ORDER BY r.rated_at
+	`
+
+	rows, err := r.db.Query(query, since.UTC().Format(sqlTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ratings for statistics: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []*models.RatedComplaint
+	for rows.Next() {
+		var rating models.RatedComplaint
+		var categoryID sql.NullInt64
+		var categoryUz, categoryRu sql.NullString
+		err := rows.Scan(
+			&rating.ComplaintID,
+			&categoryID,
+			&categoryUz,
+			&categoryRu,
+			&rating.StaffTelegramID,
+			&rating.StaffName,
+			&rating.Rating,
+			&rating.RatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rating for statistics: %w", err)
+		}
+		rating.Category = categoryRef(categoryID, categoryUz, categoryRu)
+		ratings = append(ratings, &rating)
+	}
+
+	return ratings, rows.Err()
+}
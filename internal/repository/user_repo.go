@@ -4,35 +4,32 @@ import (
 	"database/sql"
 	"fmt"
 
+	"anor-kids/internal/encryption"
 	"anor-kids/internal/models"
 )
 
+// userColumns is the column list shared by all user queries, in scanUser order
+const userColumns = `id, telegram_id, telegram_username, phone_number, child_name, child_class, language,
+		COALESCE(approval_status, 'approved'), registered_at`
+
 type UserRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *encryption.Cipher
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *sql.DB, cipher *encryption.Cipher) *UserRepository {
+	return &UserRepository{db: db, cipher: cipher}
 }
 
-// Create creates a new user
-func (r *UserRepository) Create(req *models.CreateUserRequest) (*models.User, error) {
-	query := `
-		INSERT INTO users (telegram_id, telegram_username, phone_number, child_name, child_class, language)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, telegram_id, telegram_username, phone_number, child_name, child_class, language, registered_at
-	`
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+// scanUser scans a row selected with userColumns and decrypts the PII fields
+func scanUser(row rowScanner, cipher *encryption.Cipher) (*models.User, error) {
 	var user models.User
-	err := r.db.QueryRow(
-		query,
-		req.TelegramID,
-		req.TelegramUsername,
-		req.PhoneNumber,
-		req.ChildName,
-		req.ChildClass,
-		req.Language,
-	).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.TelegramID,
 		&user.TelegramUsername,
@@ -40,35 +37,86 @@ func (r *UserRepository) Create(req *models.CreateUserRequest) (*models.User, er
 		&user.ChildName,
 		&user.ChildClass,
 		&user.Language,
+		&user.ApprovalStatus,
 		&user.RegisteredAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PhoneNumber, err = cipher.Decrypt(user.PhoneNumber); err != nil {
+		return nil, err
+	}
+	if user.ChildName, err = cipher.Decrypt(user.ChildName); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Create creates a new user
+func (r *UserRepository) Create(req *models.CreateUserRequest) (*models.User, error) {
+	approvalStatus := req.ApprovalStatus
+	if approvalStatus == "" {
+		approvalStatus = models.ApprovalApproved
+	}
+
+	encryptedPhone, err := r.cipher.Encrypt(req.PhoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt phone number: %w", err)
+	}
+
+	encryptedChildName, err := r.cipher.Encrypt(req.ChildName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt child name: %w", err)
+	}
+
+	query := `
+		INSERT INTO users (telegram_id, telegram_username, phone_number, phone_hash, child_name, child_class, language, approval_status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRow(
+		query,
+		req.TelegramID,
+		req.TelegramUsername,
+		encryptedPhone,
+		r.cipher.BlindIndex(req.PhoneNumber),
+		encryptedChildName,
+		req.ChildClass,
+		req.Language,
+		approvalStatus,
+	), r.cipher)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+// GetByID gets user by ID
+func (r *UserRepository) GetByID(id int) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRow(query, id), r.cipher)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
 }
 
 // GetByTelegramID gets user by telegram ID (indexed, fast query)
 func (r *UserRepository) GetByTelegramID(telegramID int64) (*models.User, error) {
-	query := `
-		SELECT id, telegram_id, telegram_username, phone_number, child_name, child_class, language, registered_at
-		FROM users
-		WHERE telegram_id = $1
-	`
+	query := `SELECT ` + userColumns + ` FROM users WHERE telegram_id = $1`
 
-	var user models.User
-	err := r.db.QueryRow(query, telegramID).Scan(
-		&user.ID,
-		&user.TelegramID,
-		&user.TelegramUsername,
-		&user.PhoneNumber,
-		&user.ChildName,
-		&user.ChildClass,
-		&user.Language,
-		&user.RegisteredAt,
-	)
+	user, err := scanUser(r.db.QueryRow(query, telegramID), r.cipher)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -78,28 +126,14 @@ func (r *UserRepository) GetByTelegramID(telegramID int64) (*models.User, error)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
-// GetByPhoneNumber gets user by phone number (indexed, fast query)
+// GetByPhoneNumber gets user by phone number (blind index, fast query)
 func (r *UserRepository) GetByPhoneNumber(phoneNumber string) (*models.User, error) {
-	query := `
-		SELECT id, telegram_id, telegram_username, phone_number, child_name, child_class, language, registered_at
-		FROM users
-		WHERE phone_number = $1
-	`
+	query := `SELECT ` + userColumns + ` FROM users WHERE phone_hash = $1`
 
-	var user models.User
-	err := r.db.QueryRow(query, phoneNumber).Scan(
-		&user.ID,
-		&user.TelegramID,
-		&user.TelegramUsername,
-		&user.PhoneNumber,
-		&user.ChildName,
-		&user.ChildClass,
-		&user.Language,
-		&user.RegisteredAt,
-	)
+	user, err := scanUser(r.db.QueryRow(query, r.cipher.BlindIndex(phoneNumber)), r.cipher)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -109,13 +143,13 @@ func (r *UserRepository) GetByPhoneNumber(phoneNumber string) (*models.User, err
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // GetAll gets all users with pagination
 func (r *UserRepository) GetAll(limit, offset int) ([]*models.User, error) {
 	query := `
-		SELECT id, telegram_id, telegram_username, phone_number, child_name, child_class, language, registered_at
+		SELECT ` + userColumns + `
 		FROM users
 		ORDER BY registered_at DESC
 		LIMIT $1 OFFSET $2
@@ -127,32 +161,13 @@ func (r *UserRepository) GetAll(limit, offset int) ([]*models.User, error) {
 	}
 	defer rows.Close()
 
-	var users []*models.User
-	for rows.Next() {
-		var user models.User
-		err := rows.Scan(
-			&user.ID,
-			&user.TelegramID,
-			&user.TelegramUsername,
-			&user.PhoneNumber,
-			&user.ChildName,
-			&user.ChildClass,
-			&user.Language,
-			&user.RegisteredAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan user: %w", err)
-		}
-		users = append(users, &user)
-	}
-
-	return users, nil
+	return scanUsers(rows, r.cipher)
 }
 
 // GetByClass gets users by class (indexed, fast query)
 func (r *UserRepository) GetByClass(class string) ([]*models.User, error) {
 	query := `
-		SELECT id, telegram_id, telegram_username, phone_number, child_name, child_class, language, registered_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE child_class = $1
 		ORDER BY registered_at DESC
@@ -164,26 +179,58 @@ func (r *UserRepository) GetByClass(class string) ([]*models.User, error) {
 	}
 	defer rows.Close()
 
+	return scanUsers(rows, r.cipher)
+}
+
+// GetByApprovalStatus gets users by registration approval status, oldest first
+func (r *UserRepository) GetByApprovalStatus(status string, limit, offset int) ([]*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE approval_status = $1
+		ORDER BY registered_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by approval status: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUsers(rows, r.cipher)
+}
+
+// GetForExport gets users matching an export filter, oldest registration first.
+// The filter's status is the approval status.
+func (r *UserRepository) GetForExport(filter models.ExportFilter, limit, offset int) ([]*models.User, error) {
+	where, args := exportWhere(filter, "registered_at", "COALESCE(approval_status, 'approved')", "", "")
+	query, args := exportPage(`
+		SELECT `+userColumns+`
+		FROM users
+		`+where, "registered_at ASC, id ASC", args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users for export: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUsers(rows, r.cipher)
+}
+
+// scanUsers scans all rows selected with userColumns
+func scanUsers(rows *sql.Rows, cipher *encryption.Cipher) ([]*models.User, error) {
 	var users []*models.User
 	for rows.Next() {
-		var user models.User
-		err := rows.Scan(
-			&user.ID,
-			&user.TelegramID,
-			&user.TelegramUsername,
-			&user.PhoneNumber,
-			&user.ChildName,
-			&user.ChildClass,
-			&user.Language,
-			&user.RegisteredAt,
-		)
+		user, err := scanUser(rows, cipher)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
-	return users, nil
+	return users, rows.Err()
 }
 
 // Update updates user data
@@ -196,7 +243,12 @@ func (r *UserRepository) Update(telegramID int64, req *models.UpdateUserRequest)
 		WHERE telegram_id = $4
 	`
 
-	_, err := r.db.Exec(query, req.ChildName, req.ChildClass, req.Language, telegramID)
+	encryptedChildName, err := r.cipher.Encrypt(req.ChildName)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt child name: %w", err)
+	}
+
+	_, err = r.db.Exec(query, encryptedChildName, req.ChildClass, req.Language, telegramID)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -204,6 +256,124 @@ func (r *UserRepository) Update(telegramID int64, req *models.UpdateUserRequest)
 	return nil
 }
 
+// UpdatePhoneNumber changes the phone number of a user
+func (r *UserRepository) UpdatePhoneNumber(telegramID int64, phoneNumber string) error {
+	encryptedPhone, err := r.cipher.Encrypt(phoneNumber)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt phone number: %w", err)
+	}
+
+	query := `UPDATE users SET phone_number = $1, phone_hash = $2 WHERE telegram_id = $3`
+
+	_, err = r.db.Exec(query, encryptedPhone, r.cipher.BlindIndex(phoneNumber), telegramID)
+	if err != nil {
+		return fmt.Errorf("failed to update phone number: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTelegramAccount moves a user record to another telegram account
+func (r *UserRepository) UpdateTelegramAccount(id int, telegramID int64, telegramUsername string) error {
+	query := `
+		UPDATE users
+		SET telegram_id = $1, telegram_username = $2
+		WHERE id = $3
+	`
+
+	_, err := r.db.Exec(query, telegramID, telegramUsername, id)
+	if err != nil {
+		return fmt.Errorf("failed to update telegram account: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateApprovalStatus sets the approval status of a pending registration.
+// Returns false if the registration was already reviewed by someone else.
+func (r *UserRepository) UpdateApprovalStatus(id int, status string) (bool, error) {
+	query := `
+		UPDATE users
+		SET approval_status = $1, approval_reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND approval_status = 'pending'
+	`
+
+	result, err := r.db.Exec(query, status, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update approval status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// DeleteWithData deletes a user together with their complaints, proposals and images
+func (r *UserRepository) DeleteWithData(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteUserData(tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// deleteUserData deletes a user and everything they submitted within a transaction
+func deleteUserData(tx *sql.Tx, id int) error {
+	// Delete children explicitly instead of relying on ON DELETE CASCADE,
+	// which SQLite only enforces when foreign keys are enabled on the connection
+	// Document jobs hold the parent's chat ID
+	queries := []string{
+		`DELETE FROM document_jobs WHERE kind = 'complaint' AND item_id IN (SELECT id FROM complaints WHERE user_id = $1)`,
+		`DELETE FROM document_jobs WHERE kind = 'proposal' AND item_id IN (SELECT id FROM proposals WHERE user_id = $1)`,
+		`DELETE FROM complaint_images WHERE complaint_id IN (SELECT id FROM complaints WHERE user_id = $1)`,
+		`DELETE FROM complaint_replies WHERE complaint_id IN (SELECT id FROM complaints WHERE user_id = $1)`,
+		`DELETE FROM complaint_versions WHERE complaint_id IN (SELECT id FROM complaints WHERE user_id = $1)`,
+		`DELETE FROM complaint_ratings WHERE complaint_id IN (SELECT id FROM complaints WHERE user_id = $1)`,
+		`DELETE FROM complaints WHERE user_id = $1`,
+		`DELETE FROM proposal_images WHERE proposal_id IN (SELECT id FROM proposals WHERE user_id = $1)`,
+		`DELETE FROM proposals WHERE user_id = $1`,
+		`DELETE FROM users WHERE id = $1`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(query, id); err != nil {
+			return fmt.Errorf("failed to delete user data: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// TouchLastActive records user activity, at most once per hour to limit writes
+func (r *UserRepository) TouchLastActive(telegramID int64) error {
+	query := `
+		UPDATE users
+		SET last_active_at = CURRENT_TIMESTAMP
+		WHERE telegram_id = $1
+		  AND (last_active_at IS NULL OR last_active_at < datetime('now', '-1 hour'))
+	`
+
+	_, err := r.db.Exec(query, telegramID)
+	if err != nil {
+		return fmt.Errorf("failed to update last activity: %w", err)
+	}
+
+	return nil
+}
+
 // Count counts total users
 func (r *UserRepository) Count() (int, error) {
 	var count int
@@ -214,6 +384,17 @@ func (r *UserRepository) Count() (int, error) {
 	return count, nil
 }
 
+// CountByApprovalStatus counts users by registration approval status
+func (r *UserRepository) CountByApprovalStatus(status string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM users WHERE approval_status = $1`
+	err := r.db.QueryRow(query, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users by approval status: %w", err)
+	}
+	return count, nil
+}
+
 // Exists checks if user exists by telegram ID
 func (r *UserRepository) Exists(telegramID int64) (bool, error) {
 	var exists bool
@@ -142,3 +142,8 @@ func (s *AnnouncementService) CountAdminAnnouncements(adminID int) (int, error)
 
 	return count, nil
 }
+
+// RecordDelivery saves how many parents a broadcast reached, for the admin reports
+func (s *AnnouncementService) RecordDelivery(id, delivered, failed int) error {
+	return s.repo.RecordDelivery(id, delivered, failed)
+}
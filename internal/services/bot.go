@@ -3,30 +3,47 @@ package services
 import (
 	"database/sql"
 	"fmt"
+	"path/filepath"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"anor-kids/internal/config"
+	"anor-kids/internal/models"
 	"anor-kids/internal/repository"
 	"anor-kids/internal/state"
 )
 
 // BotService is the main bot service
 type BotService struct {
-	Bot                  *tgbotapi.BotAPI
-	Config               *config.Config
-	UserRepo             *repository.UserRepository
-	ComplaintRepo        *repository.ComplaintRepository
-	ProposalRepo         *repository.ProposalRepository
-	AdminRepo            *repository.AdminRepository
-	ClassRepo            *repository.ClassRepository
-	AnnouncementRepo     *repository.AnnouncementRepository
-	StateManager         *state.Manager
-	TelegramService      *TelegramService
-	UserService          *UserService
-	ComplaintService     *ComplaintService
-	ProposalService      *ProposalService
-	DocumentService      *DocumentService
-	AnnouncementService  *AnnouncementService
+	Bot                    *tgbotapi.BotAPI
+	Config                 *config.Config
+	UserRepo               *repository.UserRepository
+	ComplaintRepo          *repository.ComplaintRepository
+	ProposalRepo           *repository.ProposalRepository
+	AdminRepo              *repository.AdminRepository
+	ClassRepo              *repository.ClassRepository
+	CategoryRepo           *repository.CategoryRepository
+	RetentionRepo          *repository.RetentionRepository
+	EncryptionRepo         *repository.EncryptionRepository
+	AnnouncementRepo       *repository.AnnouncementRepository
+	StateManager           *state.Manager
+	TelegramService        *TelegramService
+	UserService            *UserService
+	ComplaintService       *ComplaintService
+	ProposalService        *ProposalService
+	DocumentService        *DocumentService
+	DownloadManager        *DownloadManager
+	DocumentJobService     *DocumentJobService
+	RegistryService        *RegistryService
+	AnnouncementService    *AnnouncementService
+	PrivacyService         *PrivacyService
+	ComplaintExportService *ComplaintExportService
+	ExportService          *ExportService
+	RosterService          *RosterService
+	StatsService           *StatsService
+	ReportService          *ReportService
+	SLAService             *SLAService
+	RetentionService       *RetentionService
+	EncryptionService      *EncryptionService
 }
 
 // NewBotService creates a new bot service
@@ -37,13 +54,31 @@ func NewBotService(cfg *config.Config, db *sql.DB) (*BotService, error) {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
 
+	// Load encryption keys first, the repositories encrypt parent data with them
+	encryptionRepo := repository.NewEncryptionRepository(db)
+	encryptionService, err := NewEncryptionService(encryptionRepo, cfg.Encryption.MasterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption keys: %w", err)
+	}
+	cipher := encryptionService.Cipher()
+
 	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-	complaintRepo := repository.NewComplaintRepository(db)
-	proposalRepo := repository.NewProposalRepository(db)
-	adminRepo := repository.NewAdminRepository(db)
+	userRepo := repository.NewUserRepository(db, cipher)
+	complaintRepo := repository.NewComplaintRepository(db, cipher)
+	proposalRepo := repository.NewProposalRepository(db, cipher)
+	adminRepo := repository.NewAdminRepository(db, cipher)
 	classRepo := repository.NewClassRepository(db)
-	announcementRepo := repository.NewAnnouncementRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	announcementRepo := repository.NewAnnouncementRepository(db, cipher)
+	retentionRepo := repository.NewRetentionRepository(db, cipher)
+	documentJobRepo := repository.NewDocumentJobRepository(db)
+	registryRepo := repository.NewRegistryRepository(db)
+	rosterRepo := repository.NewRosterRepository(db, cipher)
+	statsRepo := repository.NewStatsRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	slaRepo := repository.NewSLARepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	ratingRepo := repository.NewRatingRepository(db, cipher)
 
 	// Initialize state manager
 	stateManager := state.NewManager(db)
@@ -51,27 +86,53 @@ func NewBotService(cfg *config.Config, db *sql.DB) (*BotService, error) {
 	// Initialize services
 	telegramService := NewTelegramService(bot)
 	userService := NewUserService(userRepo)
-	complaintService := NewComplaintService(complaintRepo, userRepo)
+	complaintService := NewComplaintService(complaintRepo, userRepo, auditRepo, ratingRepo)
 	proposalService := NewProposalService(proposalRepo, userRepo)
-	documentService := NewDocumentService("./temp_docs", bot) // temp directory for generated documents
+	downloadManager := NewDownloadManager(bot, filepath.Join("./temp_docs", "cache"))
+	registryService := NewRegistryService(registryRepo)
+	documentService := NewDocumentService("./temp_docs", downloadManager, registryService, bot.Self.UserName, cfg.Document) // temp directory for generated documents
 	announcementService := NewAnnouncementService(announcementRepo, adminRepo)
+	documentJobService := NewDocumentJobService(documentJobRepo, userService, complaintService, proposalService, documentService, telegramService)
+	retentionService := NewRetentionService(retentionRepo, stateManager, cfg.Retention)
+	privacyService := NewPrivacyService(userRepo, complaintRepo, proposalRepo, ratingRepo, stateManager, telegramService, "./temp_docs")
+	complaintExportService := NewComplaintExportService(complaintRepo, telegramService, "./temp_docs")
+	exportService := NewExportService(userRepo, complaintRepo, proposalRepo, "./temp_docs")
+	rosterService := NewRosterService(rosterRepo, classRepo, userRepo)
+	statsService := NewStatsService(statsRepo, "fonts")
+	reportService := NewReportService(reportRepo, statsRepo, userRepo, announcementRepo, documentService, telegramService, cfg.Report)
+	slaService := NewSLAService(slaRepo, adminRepo, userRepo, telegramService, cfg.SLA)
 
 	return &BotService{
-		Bot:                 bot,
-		Config:              cfg,
-		UserRepo:            userRepo,
-		ComplaintRepo:       complaintRepo,
-		ProposalRepo:        proposalRepo,
-		AdminRepo:           adminRepo,
-		ClassRepo:           classRepo,
-		AnnouncementRepo:    announcementRepo,
-		StateManager:        stateManager,
-		TelegramService:     telegramService,
-		UserService:         userService,
-		ComplaintService:    complaintService,
-		ProposalService:     proposalService,
-		DocumentService:     documentService,
-		AnnouncementService: announcementService,
+		Bot:                    bot,
+		Config:                 cfg,
+		UserRepo:               userRepo,
+		ComplaintRepo:          complaintRepo,
+		ProposalRepo:           proposalRepo,
+		AdminRepo:              adminRepo,
+		ClassRepo:              classRepo,
+		CategoryRepo:           categoryRepo,
+		RetentionRepo:          retentionRepo,
+		EncryptionRepo:         encryptionRepo,
+		AnnouncementRepo:       announcementRepo,
+		StateManager:           stateManager,
+		TelegramService:        telegramService,
+		UserService:            userService,
+		ComplaintService:       complaintService,
+		ProposalService:        proposalService,
+		DocumentService:        documentService,
+		DownloadManager:        downloadManager,
+		DocumentJobService:     documentJobService,
+		RegistryService:        registryService,
+		AnnouncementService:    announcementService,
+		PrivacyService:         privacyService,
+		ComplaintExportService: complaintExportService,
+		ExportService:          exportService,
+		RosterService:          rosterService,
+		StatsService:           statsService,
+		ReportService:          reportService,
+		SLAService:             slaService,
+		RetentionService:       retentionService,
+		EncryptionService:      encryptionService,
 	}, nil
 }
 
@@ -111,7 +172,7 @@ func (s *BotService) InitializeAdmins() error {
 
 		if admin == nil {
 			// Create admin
-			_, err = s.AdminRepo.Create(phone, "Admin")
+			_, err = s.AdminRepo.Create(phone, models.DefaultAdminName)
 			if err != nil {
 				fmt.Printf("Warning: failed to create admin %s: %v\n", phone, err)
 			}
@@ -138,6 +199,43 @@ func (s *BotService) GetAdminTelegramIDs() ([]int64, error) {
 	return ids, nil
 }
 
+// GetSuperAdminTelegramIDs gets telegram IDs of linked super-admins
+func (s *BotService) GetSuperAdminTelegramIDs() ([]int64, error) {
+	var ids []int64
+	for _, phone := range s.Config.Admin.SuperAdminPhones {
+		admin, err := s.AdminRepo.GetByPhoneNumber(phone)
+		if err != nil {
+			return nil, err
+		}
+
+		if admin != nil && admin.TelegramID != nil {
+			ids = append(ids, *admin.TelegramID)
+		}
+	}
+
+	return ids, nil
+}
+
+// IsSuperAdmin checks if the telegram user is a linked super-admin
+func (s *BotService) IsSuperAdmin(telegramID int64) (bool, error) {
+	admin, err := s.AdminRepo.GetByTelegramID(telegramID)
+	if err != nil {
+		return false, err
+	}
+
+	if admin != nil {
+		return s.Config.Admin.IsSuperAdminPhone(admin.PhoneNumber), nil
+	}
+
+	// Fall back to the registered user's phone (admin not linked yet)
+	user, err := s.UserService.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return false, err
+	}
+
+	return user != nil && s.Config.Admin.IsSuperAdminPhone(user.PhoneNumber), nil
+}
+
 // IsAdmin checks if user is admin by checking:
 // 1. Database admins table (by phone or telegram_id)
 // 2. Config admin phones (if user is registered)
@@ -181,3 +279,49 @@ func (s *BotService) IsAdmin(phoneNumber string, telegramID int64) (bool, error)
 
 	return false, nil
 }
+
+// CanReviewRegistration checks if the telegram user may approve or reject the
+// registration: admins can review any class, teachers only their own classes
+func (s *BotService) CanReviewRegistration(telegramID int64, user *models.User) (bool, error) {
+	isAdmin, err := s.IsAdmin("", telegramID)
+	if err != nil {
+		return false, err
+	}
+
+	if isAdmin {
+		return true, nil
+	}
+
+	class, err := s.ClassRepo.GetByName(user.ChildClass)
+	if err != nil {
+		return false, err
+	}
+
+	return class != nil && class.TeacherTelegramID != nil && *class.TeacherTelegramID == telegramID, nil
+}
+
+// GetRegistrationReviewerIDs gets telegram IDs of everyone who can review a
+// registration for the class: the class teacher (if linked) and all admins
+func (s *BotService) GetRegistrationReviewerIDs(className string) ([]int64, error) {
+	ids, err := s.GetAdminTelegramIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	class, err := s.ClassRepo.GetByName(className)
+	if err != nil {
+		return nil, err
+	}
+
+	if class != nil && class.TeacherTelegramID != nil {
+		teacherID := *class.TeacherTelegramID
+		for _, id := range ids {
+			if id == teacherID {
+				return ids, nil
+			}
+		}
+		ids = append(ids, teacherID)
+	}
+
+	return ids, nil
+}
@@ -9,15 +9,19 @@ import (
 
 // ComplaintService handles complaint-related business logic
 type ComplaintService struct {
-	repo     *repository.ComplaintRepository
-	userRepo *repository.UserRepository
+	repo       *repository.ComplaintRepository
+	userRepo   *repository.UserRepository
+	auditRepo  *repository.AuditRepository
+	ratingRepo *repository.RatingRepository
 }
 
 // NewComplaintService creates a new complaint service
-func NewComplaintService(repo *repository.ComplaintRepository, userRepo *repository.UserRepository) *ComplaintService {
+func NewComplaintService(repo *repository.ComplaintRepository, userRepo *repository.UserRepository, auditRepo *repository.AuditRepository, ratingRepo *repository.RatingRepository) *ComplaintService {
 	return &ComplaintService{
-		repo:     repo,
-		userRepo: userRepo,
+		repo:       repo,
+		userRepo:   userRepo,
+		auditRepo:  auditRepo,
+		ratingRepo: ratingRepo,
 	}
 }
 
@@ -62,9 +66,10 @@ func (s *ComplaintService) GetAllComplaints(limit, offset int) ([]*models.Compla
 	return complaints, nil
 }
 
-// GetAllComplaintsWithUser gets all complaints with user info
-func (s *ComplaintService) GetAllComplaintsWithUser(limit, offset int) ([]*models.ComplaintWithUser, error) {
-	complaints, err := s.repo.GetAllWithUser(limit, offset)
+// GetAllComplaintsWithUser gets all complaints with user info; categoryID 0
+// selects every category
+func (s *ComplaintService) GetAllComplaintsWithUser(categoryID, limit, offset int) ([]*models.ComplaintWithUser, error) {
+	complaints, err := s.repo.GetAllWithUser(categoryID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get complaints with user: %w", err)
 	}
@@ -82,8 +87,9 @@ func (s *ComplaintService) GetComplaintsByStatus(status string, limit, offset in
 	return complaints, nil
 }
 
-// UpdateComplaintStatus updates complaint status
-func (s *ComplaintService) UpdateComplaintStatus(id int, status string) error {
+// UpdateComplaintStatus updates the status of a pending complaint read at the
+// given version. Returns false if it changed since.
+func (s *ComplaintService) UpdateComplaintStatus(id, version int, status string) (bool, error) {
 	// Validate status
 	validStatuses := map[string]bool{
 		models.StatusPending:  true,
@@ -92,12 +98,73 @@ func (s *ComplaintService) UpdateComplaintStatus(id int, status string) error {
 	}
 
 	if !validStatuses[status] {
-		return fmt.Errorf("invalid status: %s", status)
+		return false, fmt.Errorf("invalid status: %s", status)
 	}
 
-	err := s.repo.UpdateStatus(id, status)
+	updated, err := s.repo.UpdateStatus(id, version, status)
 	if err != nil {
-		return fmt.Errorf("failed to update complaint status: %w", err)
+		return false, fmt.Errorf("failed to update complaint status: %w", err)
+	}
+
+	return updated, nil
+}
+
+// AssignComplaint assigns a complaint to an admin. Returns false if it is
+// already assigned to the admin.
+func (s *ComplaintService) AssignComplaint(id, adminID int) (bool, error) {
+	assigned, err := s.repo.Assign(id, adminID)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign complaint: %w", err)
+	}
+
+	return assigned, nil
+}
+
+// GetComplaintAssigneeID gets the admin a complaint is assigned to, nil if none
+func (s *ComplaintService) GetComplaintAssigneeID(id int) (*int, error) {
+	adminID, err := s.repo.GetAssigneeID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint assignee: %w", err)
+	}
+
+	return adminID, nil
+}
+
+// GetAssignedComplaints gets the pending complaints assigned to an admin with user info
+func (s *ComplaintService) GetAssignedComplaints(adminID int, limit, offset int) ([]*models.ComplaintWithUser, error) {
+	complaints, err := s.repo.GetAssignedWithUser(adminID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assigned complaints: %w", err)
+	}
+
+	return complaints, nil
+}
+
+// CountAssignedComplaints counts the pending complaints assigned to an admin
+func (s *ComplaintService) CountAssignedComplaints(adminID int) (int, error) {
+	count, err := s.repo.CountAssigned(adminID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count assigned complaints: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetComplaintTimeline gets when a complaint changed status, nil if it doesn't exist
+func (s *ComplaintService) GetComplaintTimeline(id int) (*models.SubmissionTimeline, error) {
+	timeline, err := s.repo.GetTimeline(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint timeline: %w", err)
+	}
+
+	return timeline, nil
+}
+
+// UpdateComplaintPDF sets the generated PDF of a complaint
+func (s *ComplaintService) UpdateComplaintPDF(id int, fileID, filename string) error {
+	err := s.repo.UpdatePDF(id, fileID, filename)
+	if err != nil {
+		return fmt.Errorf("failed to update complaint PDF: %w", err)
 	}
 
 	return nil
@@ -113,6 +180,17 @@ func (s *ComplaintService) CountComplaints() (int, error) {
 	return count, nil
 }
 
+// CountComplaintsByCategory counts the complaints of a category in a status;
+// status "" counts every status
+func (s *ComplaintService) CountComplaintsByCategory(categoryID int, status string) (int, error) {
+	count, err := s.repo.CountByCategory(categoryID, status)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count complaints by category: %w", err)
+	}
+
+	return count, nil
+}
+
 // CountComplaintsByStatus counts complaints by status
 func (s *ComplaintService) CountComplaintsByStatus(status string) (int, error) {
 	count, err := s.repo.CountByStatus(status)
@@ -152,3 +230,127 @@ func (s *ComplaintService) GetComplaintImages(complaintID int) ([]models.Complai
 
 	return images, nil
 }
+
+// CreateComplaintReply stores a staff reply to a complaint
+func (s *ComplaintService) CreateComplaintReply(complaintID int, adminTelegramID int64, adminName, text string) (*models.ComplaintReply, error) {
+	reply, err := s.repo.CreateReply(complaintID, adminTelegramID, adminName, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create complaint reply: %w", err)
+	}
+
+	return reply, nil
+}
+
+// GetComplaintReplies gets the staff replies to a complaint, oldest first
+func (s *ComplaintService) GetComplaintReplies(complaintID int) ([]models.ComplaintReply, error) {
+	replies, err := s.repo.GetReplies(complaintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint replies: %w", err)
+	}
+
+	return replies, nil
+}
+
+// WithdrawComplaint withdraws a pending complaint of a user. Returns false if
+// the complaint is not the user's or is no longer pending.
+func (s *ComplaintService) WithdrawComplaint(id, userID int) (bool, error) {
+	withdrawn, err := s.repo.Withdraw(id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to withdraw complaint: %w", err)
+	}
+
+	return withdrawn, nil
+}
+
+// ReviseComplaint replaces the text of a pending complaint of a user, keeping
+// the previous version. Returns false if the complaint is not the user's or is
+// no longer pending.
+func (s *ComplaintService) ReviseComplaint(id, userID int, text string) (bool, error) {
+	revised, err := s.repo.Revise(id, userID, text)
+	if err != nil {
+		return false, fmt.Errorf("failed to revise complaint: %w", err)
+	}
+
+	return revised, nil
+}
+
+// GetComplaintVersions gets the earlier versions of a complaint, oldest first
+func (s *ComplaintService) GetComplaintVersions(complaintID int) ([]models.ComplaintVersion, error) {
+	versions, err := s.repo.GetVersions(complaintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// RevealAuthor returns the author of an anonymous complaint. The reveal is
+// recorded in the audit log first, so no author is revealed unaudited.
+// Returns nil if the parent has been deleted.
+func (s *ComplaintService) RevealAuthor(complaint *models.Complaint, actorTelegramID int64, reason string) (*models.User, error) {
+	entry := &models.AuditEntry{
+		ActorTelegramID: actorTelegramID,
+		Action:          models.AuditRevealAnonymous,
+		ItemKind:        models.DocumentJobKindComplaint,
+		ItemID:          complaint.ID,
+		Reason:          reason,
+	}
+	if err := s.auditRepo.Create(entry); err != nil {
+		return nil, fmt.Errorf("failed to audit reveal: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(complaint.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint author: %w", err)
+	}
+
+	return user, nil
+}
+
+// RequestRating records that the author of a reviewed complaint is asked to rate
+// its handling by the staff member who reviewed it. Returns false if they were
+// already asked.
+func (s *ComplaintService) RequestRating(complaintID int, staffTelegramID int64, staffName string) (bool, error) {
+	created, err := s.ratingRepo.CreateRequest(complaintID, staffTelegramID, staffName)
+	if err != nil {
+		return false, fmt.Errorf("failed to request rating: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetComplaintRating gets the rating of a complaint, nil if its author was never asked
+func (s *ComplaintService) GetComplaintRating(complaintID int) (*models.ComplaintRating, error) {
+	rating, err := s.ratingRepo.GetByComplaintID(complaintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get complaint rating: %w", err)
+	}
+
+	return rating, nil
+}
+
+// RateComplaint stores the author's rating of a complaint, from 1 to 5.
+// Returns false if they were never asked.
+func (s *ComplaintService) RateComplaint(complaintID, rating int) (bool, error) {
+	if rating < models.MinRating || rating > models.MaxRating {
+		return false, fmt.Errorf("invalid rating: %d", rating)
+	}
+
+	rated, err := s.ratingRepo.SetRating(complaintID, rating)
+	if err != nil {
+		return false, fmt.Errorf("failed to rate complaint: %w", err)
+	}
+
+	return rated, nil
+}
+
+// CommentRating stores the author's comment on their rating. Returns false if
+// the complaint has no rating yet.
+func (s *ComplaintService) CommentRating(complaintID int, comment string) (bool, error) {
+	commented, err := s.ratingRepo.SetComment(complaintID, comment)
+	if err != nil {
+		return false, fmt.Errorf("failed to comment rating: %w", err)
+	}
+
+	return commented, nil
+}
@@ -0,0 +1,440 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+	"anor-kids/internal/utils"
+)
+
+// Bulk export archive limits. Telegram rejects bot uploads over 50 MB, so
+// parts are kept well below that to leave room for the multipart request.
+const (
+	exportPartLimit         = 45 << 20
+	exportZipEntryOverhead  = 128 // Local and central directory headers, without the name
+	exportManifestName      = "manifest.csv"
+	exportDefaultImageExt   = ".jpg"
+	exportMissingFileMarker = "-"
+)
+
+// ErrExportRunning is returned when the chat already has an export in progress
+var ErrExportRunning = errors.New("export already running")
+
+// ComplaintExportResult summarizes a finished bulk export
+type ComplaintExportResult struct {
+	Complaints   int // Complaints matching the filter
+	Parts        int // Archives sent
+	MissingFiles int // PDFs and images that could not be downloaded
+}
+
+// ComplaintExportResultFunc is called when a background export finishes (err == nil) or fails
+type ComplaintExportResultFunc func(result *ComplaintExportResult, err error)
+
+// ComplaintExportService builds ZIP archives of complaints with their PDFs,
+// original images and a manifest, for handing over to inspections
+type ComplaintExportService struct {
+	complaintRepo   *repository.ComplaintRepository
+	telegramService *TelegramService
+	tempDir         string
+
+	mu      sync.Mutex
+	running map[int64]bool // Chats with an export in progress
+}
+
+// NewComplaintExportService creates a new complaint export service
+func NewComplaintExportService(
+	complaintRepo *repository.ComplaintRepository,
+	telegramService *TelegramService,
+	tempDir string,
+) *ComplaintExportService {
+	return &ComplaintExportService{
+		complaintRepo:   complaintRepo,
+		telegramService: telegramService,
+		tempDir:         tempDir,
+		running:         make(map[int64]bool),
+	}
+}
+
+// exportFile is a downloaded file and its path inside the archive
+type exportFile struct {
+	name string
+	path string
+	size int64
+}
+
+// exportEntry is a complaint with its downloaded files
+type exportEntry struct {
+	complaint *models.ComplaintWithUser
+	pdf       string   // Archive name of the PDF, "" if missing
+	images    []string // Archive names of the images
+	files     []exportFile
+	missing   []string // Files that could not be downloaded
+	parts     []int    // Parts holding the complaint's files
+}
+
+// size returns the archive size of the complaint's files
+func (e *exportEntry) size() int64 {
+	var total int64
+	for _, f := range e.files {
+		total += f.size + exportZipEntryOverhead + int64(2*len(f.name))
+	}
+	return total
+}
+
+// StartExport builds the archives for filter in the background and sends them
+// to chatID. onDone is called once everything is sent or the export failed.
+// Only one export per chat runs at a time.
+func (s *ComplaintExportService) StartExport(chatID int64, filter models.ExportFilter, onDone ComplaintExportResultFunc) error {
+	s.mu.Lock()
+	if s.running[chatID] {
+		s.mu.Unlock()
+		return ErrExportRunning
+	}
+	s.running[chatID] = true
+	s.mu.Unlock()
+
+	go func() {
+		result, err := s.export(chatID, filter)
+
+		s.mu.Lock()
+		delete(s.running, chatID)
+		s.mu.Unlock()
+
+		onDone(result, err)
+	}()
+
+	return nil
+}
+
+// export downloads every matching complaint's files, packs them into parts and uploads them
+func (s *ComplaintExportService) export(chatID int64, filter models.ExportFilter) (*ComplaintExportResult, error) {
+	var complaints []*models.ComplaintWithUser
+	for offset := 0; ; offset += exportPageSize {
+		page, err := s.complaintRepo.GetForExport(filter, exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		complaints = append(complaints, page...)
+
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	result := &ComplaintExportResult{Complaints: len(complaints)}
+	if len(complaints) == 0 {
+		return result, nil
+	}
+
+	if err := os.MkdirAll(s.tempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	workDir, err := os.MkdirTemp(s.tempDir, fmt.Sprintf("complaints_export_%d_*", chatID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	entries := make([]*exportEntry, 0, len(complaints))
+	for _, complaint := range complaints {
+		entry, err := s.download(workDir, complaint)
+		if err != nil {
+			return nil, err
+		}
+		result.MissingFiles += len(entry.missing)
+		entries = append(entries, entry)
+	}
+
+	manifest, err := buildExportManifest(entries, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The manifest goes into every part; its part column only adds a few bytes per row
+	capacity := exportPartLimit - int64(len(manifest)) - int64(len(entries))*8 - exportZipEntryOverhead
+	parts := assignExportParts(entries, capacity)
+	result.Parts = len(parts)
+
+	if manifest, err = buildExportManifest(entries, false); err != nil {
+		return nil, err
+	}
+
+	stamp := time.Now().Format("20060102_150405")
+	for i, files := range parts {
+		filename := fmt.Sprintf("complaints_%s.zip", stamp)
+		if len(parts) > 1 {
+			filename = fmt.Sprintf("complaints_%s_part%dof%d.zip", stamp, i+1, len(parts))
+		}
+
+		archivePath := filepath.Join(workDir, filename)
+		if err := writeExportArchive(archivePath, manifest, files); err != nil {
+			return nil, err
+		}
+
+		if _, err := s.telegramService.UploadDocument(chatID, archivePath, filename); err != nil {
+			return nil, fmt.Errorf("failed to send part %d: %w", i+1, err)
+		}
+
+		_ = os.Remove(archivePath)
+	}
+
+	return result, nil
+}
+
+// download fetches a complaint's PDF and images into workDir. A file that
+// can't be downloaded is recorded as missing instead of failing the export.
+func (s *ComplaintExportService) download(workDir string, complaint *models.ComplaintWithUser) (*exportEntry, error) {
+	entry := &exportEntry{complaint: complaint}
+	dir := fmt.Sprintf("complaints/%d", complaint.ID)
+
+	add := func(fileID, name string) bool {
+		if fileID == "" {
+			entry.missing = append(entry.missing, dir+"/"+name)
+			return false
+		}
+
+		path := filepath.Join(workDir, strconv.Itoa(complaint.ID)+"_"+strconv.Itoa(len(entry.files))+"_"+filepath.Base(name))
+		if err := s.telegramService.DownloadFile(fileID, path); err != nil {
+			log.Printf("Failed to export %s of complaint %d: %v", name, complaint.ID, err)
+			_ = os.Remove(path)
+			entry.missing = append(entry.missing, dir+"/"+name)
+			return false
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			entry.missing = append(entry.missing, dir+"/"+name)
+			return false
+		}
+
+		entry.files = append(entry.files, exportFile{name: dir + "/" + name, path: path, size: info.Size()})
+		return true
+	}
+
+	pdfName := filepath.Base(complaint.PDFFilename)
+	if complaint.PDFFilename == "" {
+		pdfName = fmt.Sprintf("complaint_%d.pdf", complaint.ID)
+	}
+	if add(complaint.PDFTelegramFileID, pdfName) {
+		entry.pdf = dir + "/" + pdfName
+	}
+
+	images, err := s.complaintRepo.GetComplaintImages(complaint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, img := range images {
+		name := fmt.Sprintf("image_%d%s", i+1, imageExtension(img.MimeType))
+		if add(img.TelegramFileID, name) {
+			entry.images = append(entry.images, dir+"/"+name)
+		}
+	}
+
+	return entry, nil
+}
+
+// imageExtension returns the file extension for an image MIME type
+func imageExtension(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "image/heic":
+		return ".heic"
+	case "image/gif":
+		return ".gif"
+	default:
+		return exportDefaultImageExt
+	}
+}
+
+// assignExportParts splits the entries' files into parts of at most capacity
+// bytes. A complaint's files are kept in one part unless they don't fit in any.
+func assignExportParts(entries []*exportEntry, capacity int64) [][]exportFile {
+	var parts [][]exportFile
+	var current []exportFile
+	var used int64
+
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, current)
+			current, used = nil, 0
+		}
+	}
+
+	for _, entry := range entries {
+		if used > 0 && used+entry.size() > capacity {
+			flush()
+		}
+
+		for _, f := range entry.files {
+			size := f.size + exportZipEntryOverhead + int64(2*len(f.name))
+			if used > 0 && used+size > capacity {
+				flush()
+			}
+			current = append(current, f)
+			used += size
+
+			part := len(parts) + 1
+			if n := len(entry.parts); n == 0 || entry.parts[n-1] != part {
+				entry.parts = append(entry.parts, part)
+			}
+		}
+	}
+	flush()
+
+	// Complaints without any downloaded file still need a manifest, so there's always one part
+	if len(parts) == 0 {
+		parts = append(parts, nil)
+	}
+
+	return parts
+}
+
+// buildExportManifest writes the manifest CSV listing every complaint and where
+// its files are. estimate leaves the part column empty, for sizing parts.
+func buildExportManifest(entries []*exportEntry, estimate bool) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("\ufeff") // BOM so Excel opens the file as UTF-8
+
+	w := csv.NewWriter(&b)
+	header := []string{
+		"ID",
+		"Sana / Дата",
+		"Holat / Статус",
+		"Toifa / Категория",
+		"Bola / Ребёнок",
+		"Guruh / Группа",
+		"Telefon / Телефон",
+		"Telegram",
+		"Matn / Текст",
+		"PDF",
+		"Rasmlar / Изображения",
+		"Qism / Часть",
+		"Yuklanmagan fayllar / Незагруженные файлы",
+	}
+	if err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	for _, entry := range entries {
+		c := entry.complaint
+
+		pdf := entry.pdf
+		if pdf == "" {
+			pdf = exportMissingFileMarker
+		}
+
+		var parts []string
+		if !estimate {
+			for _, part := range entry.parts {
+				parts = append(parts, strconv.Itoa(part))
+			}
+		}
+
+		record := []string{
+			strconv.Itoa(c.ID),
+			utils.FormatDateTime(c.CreatedAt),
+			c.Status,
+			c.Category.Label(),
+			c.ChildName,
+			c.ChildClass,
+			c.PhoneNumber,
+			telegramHandle(c.TelegramUsername),
+			html.UnescapeString(c.ComplaintText), // stored HTML-escaped for Telegram
+			pdf,
+			strings.Join(entry.images, "; "),
+			strings.Join(parts, ", "),
+			strings.Join(entry.missing, "; "),
+		}
+		for i := range record {
+			record[i] = csvSafe(record[i])
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return []byte(b.String()), nil
+}
+
+// writeExportArchive writes one part: the manifest and the part's files. Files
+// are stored uncompressed, PDFs and photos are already compressed.
+func writeExportArchive(archivePath string, manifest []byte, files []exportFile) (err error) {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write archive: %w", closeErr)
+		}
+	}()
+
+	archive := zip.NewWriter(out)
+
+	w, err := archive.Create(exportManifestName)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", exportManifestName, err)
+	}
+	if _, err := w.Write(manifest); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportManifestName, err)
+	}
+
+	for _, f := range files {
+		if err := addExportFile(archive, f); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
+
+// addExportFile copies a downloaded file into the archive
+func addExportFile(archive *zip.Writer, f exportFile) error {
+	in, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.name, err)
+	}
+	defer in.Close()
+
+	w, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     f.name,
+		Method:   zip.Store,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", f.name, err)
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.name, err)
+	}
+
+	return nil
+}
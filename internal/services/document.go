@@ -2,205 +2,356 @@ package services
 
 import (
 	"fmt"
-	"io"
-	"net/http"
+	"html"
 	"os"
 	"path/filepath"
 	"time"
 
+	"anor-kids/internal/config"
+	"anor-kids/internal/imaging"
 	"anor-kids/internal/models"
+	"anor-kids/internal/registry"
 	"anor-kids/internal/utils"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/jung-kurt/gofpdf"
+	"anor-kids/pkg/docx"
 )
 
 // DocumentService handles document generation and management
 type DocumentService struct {
-	tempDir string
-	bot     *tgbotapi.BotAPI
+	tempDir     string
+	downloads   *DownloadManager
+	registry    *RegistryService
+	renderer    *PDFRenderer
+	botUsername string // For the verification deep link in the PDF QR code
 }
 
 // NewDocumentService creates a new document service
-func NewDocumentService(tempDir string, bot *tgbotapi.BotAPI) *DocumentService {
+func NewDocumentService(tempDir string, downloads *DownloadManager, registry *RegistryService, botUsername string, cfg config.DocumentConfig) *DocumentService {
 	return &DocumentService{
-		tempDir: tempDir,
-		bot:     bot,
+		tempDir:     tempDir,
+		downloads:   downloads,
+		registry:    registry,
+		renderer:    NewPDFRenderer("fonts", cfg.KindergartenName, cfg.LogoPath),
+		botUsername: botUsername,
 	}
 }
 
-// GenerateComplaintPDF generates a PDF document for a complaint with text and images
-// Returns the file path and filename
-func (s *DocumentService) GenerateComplaintPDF(user *models.User, complaintText string, images []models.ImageData) (filePath, filename string, err error) {
-	// Generate filename with .pdf extension
-	filename = utils.GeneratePDFFilename(user.ChildName, user.ChildClass)
+// submissionTemplate holds what differs between the complaint and proposal PDFs
+type submissionTemplate struct {
+	kind            string // Registry kind, models.DocumentJobKind*
+	title           string
+	textHeading     string
+	referencePrefix string
+	filename        func(childName, childClass string) string
+}
+
+var (
+	complaintTemplate = submissionTemplate{
+		kind:            models.DocumentJobKindComplaint,
+		title:           "SHIKOYAT / ЖАЛОБА",
+		textHeading:     "Shikoyat matni / Текст жалобы:",
+		referencePrefix: "SH",
+		filename:        utils.GeneratePDFFilename,
+	}
+	proposalTemplate = submissionTemplate{
+		kind:            models.DocumentJobKindProposal,
+		title:           "TAKLIF / ПРЕДЛОЖЕНИЕ",
+		textHeading:     "Taklif matni / Текст предложения:",
+		referencePrefix: "TK",
+		filename:        utils.GenerateProposalPDFFilename,
+	}
+)
 
-	// Create full path
+// GenerateComplaintPDF generates a PDF document for a saved complaint with text and images
+// and records it in the document registry. Returns the file path and filename
+func (s *DocumentService) GenerateComplaintPDF(user *models.User, complaint *models.Complaint, images []models.ImageData) (filePath, filename string, err error) {
+	var fields []PDFField
+	if complaint.Category != nil {
+		fields = append(fields, PDFField{Label: "Toifa / Категория", Value: complaint.Category.Label()})
+	}
+
+	// Anonymous complaints carry no name, phone or child, in the text or the filename
+	tmpl := complaintTemplate
+	if complaint.IsAnonymous {
+		user = user.Anonymized()
+		tmpl.filename = func(string, string) string { return utils.GenerateAnonymousPDFFilename() }
+	}
+
+	return s.generateSubmissionPDF(tmpl, user, complaint.ID, complaint.CreatedAt, complaint.ComplaintText, fields, images)
+}
+
+// GenerateProposalPDF generates a PDF document for a saved proposal with text and images
+// and records it in the document registry. Returns the file path and filename
+func (s *DocumentService) GenerateProposalPDF(user *models.User, proposal *models.Proposal, images []models.ImageData) (filePath, filename string, err error) {
+	return s.generateSubmissionPDF(proposalTemplate, user, proposal.ID, proposal.CreatedAt, proposal.ProposalText, nil, images)
+}
+
+// generateSubmissionPDF renders a complaint or proposal sent at createdAt with the
+// parent's details, the extra fields of the submission kind and attached images
+func (s *DocumentService) generateSubmissionPDF(tmpl submissionTemplate, user *models.User, itemID int, createdAt time.Time, text string, extra []PDFField, images []models.ImageData) (filePath, filename string, err error) {
+	filename = tmpl.filename(user.ChildName, user.ChildClass)
 	filePath = filepath.Join(s.tempDir, filename)
 
-	// Create PDF
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Add UTF-8 font support for Cyrillic characters
-	pdf.AddUTF8Font("DejaVu", "", filepath.Join("fonts", "DejaVuSans.ttf"))
-	pdf.AddUTF8Font("DejaVu", "B", filepath.Join("fonts", "DejaVuSans-Bold.ttf"))
-
-	// Set font
-	pdf.SetFont("DejaVu", "B", 16)
-
-	// Title
-	pdf.CellFormat(0, 10, "SHIKOYAT / ЖАЛОБА", "", 1, "C", false, 0, "")
-	pdf.Ln(5)
-
-	// User information
-	pdf.SetFont("DejaVu", "B", 12)
-	pdf.Cell(0, 8, "Ma'lumotlar / Информация:")
-	pdf.Ln(8)
-
-	pdf.SetFont("DejaVu", "", 11)
-	// Strip emojis from user data for PDF compatibility
-	pdf.Cell(0, 6, fmt.Sprintf("Farzand / Ребенок: %s", utils.StripEmojis(user.ChildName)))
-	pdf.Ln(6)
-	pdf.Cell(0, 6, fmt.Sprintf("Guruh / Группа: %s", utils.StripEmojis(user.ChildClass)))
-	pdf.Ln(6)
-	pdf.Cell(0, 6, fmt.Sprintf("Telefon / Телефон: %s", user.PhoneNumber))
-	pdf.Ln(6)
-	pdf.Cell(0, 6, fmt.Sprintf("Sana / Дата: %s", time.Now().Format("02.01.2006 15:04")))
-	pdf.Ln(10)
-
-	// Complaint text
-	pdf.SetFont("DejaVu", "B", 12)
-	pdf.Cell(0, 8, "Shikoyat matni / Текст жалобы:")
-	pdf.Ln(8)
-
-	pdf.SetFont("DejaVu", "", 11)
-	// Strip emojis from complaint text for PDF compatibility
-	pdf.MultiCell(0, 6, utils.StripEmojis(complaintText), "", "", false)
-	pdf.Ln(5)
-
-	// Add images if any
-	if len(images) > 0 {
-		pdf.Ln(5)
-		pdf.SetFont("DejaVu", "B", 12)
-		pdf.Cell(0, 8, fmt.Sprintf("Qo'shimcha rasmlar / Приложенные изображения: %d ta", len(images)))
-		pdf.Ln(8)
-
-		for i, img := range images {
-			// Download image from Telegram
-			imgPath, err := s.downloadTelegramImage(img.FileID, i)
-			if err != nil {
-				fmt.Printf("[WARN] Failed to download image %d: %v\n", i, err)
-				continue
-			}
-			defer os.Remove(imgPath) // Clean up after adding to PDF
-
-			// Add image number
-			pdf.SetFont("DejaVu", "", 10)
-			pdf.Cell(0, 5, fmt.Sprintf("Rasm %d / Изображение %d:", i+1, i+1))
-			pdf.Ln(6)
-
-			// Get current Y position
-			currentY := pdf.GetY()
-
-			// Calculate max image dimensions for A4 page
-			// A4 width = 210mm, with 15mm margins on each side = 180mm usable
-			maxWidth := 180.0
-			maxHeight := 200.0 // Leave space for margins and other content
-
-			// Check if we need a new page (if less than 60mm space remaining)
-			if currentY > 240 {
-				pdf.AddPage()
-				currentY = pdf.GetY()
-			}
-
-			// Add image to PDF with proper sizing
-			// Using ImageOptions with flow mode to automatically calculate height
-			options := gofpdf.ImageOptions{
-				ImageType: "",
-				ReadDpi:   false,
-			}
-
-			// Get image info to calculate aspect ratio
-			info := pdf.RegisterImageOptions(imgPath, options)
-			if info != nil {
-				imgWidth := float64(info.Width())
-				imgHeight := float64(info.Height())
-
-				// Calculate scaled dimensions maintaining aspect ratio
-				scaledWidth := maxWidth
-				scaledHeight := (imgHeight / imgWidth) * maxWidth
-
-				// If height is too large, scale based on height instead
-				if scaledHeight > maxHeight {
-					scaledHeight = maxHeight
-					scaledWidth = (imgWidth / imgHeight) * maxHeight
-				}
-
-				// Center the image horizontally
-				xPos := (210.0 - scaledWidth) / 2.0
-
-				// Add the image with calculated dimensions
-				pdf.Image(imgPath, xPos, currentY, scaledWidth, scaledHeight, false, "", 0, "")
-
-				// Move Y position down by image height plus spacing
-				pdf.SetY(currentY + scaledHeight + 10)
-			} else {
-				// Fallback if image info not available
-				pdf.Image(imgPath, 15, currentY, maxWidth, 0, false, "", 0, "")
-				pdf.Ln(80) // Approximate spacing
-			}
+	// The code is printed in the PDF, so it can't be derived from the PDF's hash
+	code, err := registry.NewCode()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	doc := &PDFDocument{
+		Title:            tmpl.title,
+		Reference:        utils.GenerateReferenceNumber(tmpl.referencePrefix, now),
+		GeneratedAt:      now,
+		VerificationCode: code,
+		VerificationLink: s.verificationLink(code),
+		Sections: []PDFSection{
+			{
+				Heading: "Ma'lumotlar / Информация:",
+				Fields: append([]PDFField{
+					{Label: "Farzand / Ребенок", Value: user.ChildName},
+					{Label: "Guruh / Группа", Value: user.ChildClass},
+					{Label: "Telefon / Телефон", Value: user.PhoneNumber},
+					{Label: "Sana / Дата", Value: utils.FormatDateTime(createdAt)},
+				}, extra...),
+			},
+			{
+				Heading: tmpl.textHeading,
+				Text:    text,
+			},
+		},
+	}
+
+	// Download all images concurrently (cached by FileUniqueID)
+	downloads := s.downloads.FetchAll(images)
+
+	for i, download := range downloads {
+		caption := fmt.Sprintf("Rasm %d / Изображение %d:", i+1, i+1)
+
+		imgPath, err := s.saveNormalizedImage(download, i)
+		if err != nil {
+			// Keep a placeholder so admins know an image was attached
+			fmt.Printf("[WARN] Failed to add image %d: %v\n", i, err)
+			doc.Images = append(doc.Images, PDFImage{Caption: caption})
+			continue
 		}
+		defer os.Remove(imgPath) // Clean up after rendering the PDF
+
+		doc.Images = append(doc.Images, PDFImage{Path: imgPath, Caption: caption})
 	}
 
-	// Save PDF
-	err = pdf.OutputFileAndClose(filePath)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to generate PDF: %w", err)
+	if err := s.renderer.Render(doc, filePath); err != nil {
+		return "", "", err
 	}
 
-	// Verify file was created and has content
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to verify generated file: %w", err)
+	// Register the finished file. If the upload fails afterwards the entry
+	// stays, for a PDF nobody received; the retry registers a new one.
+	if _, err := s.registry.Register(code, tmpl.kind, itemID, filePath); err != nil {
+		_ = os.Remove(filePath)
+		return "", "", fmt.Errorf("failed to register document: %w", err)
+	}
+
+	fmt.Printf("[DEBUG] PDF generated: %s (%s, verification code %s)\n", filename, doc.Reference, code)
+
+	return filePath, filename, nil
+}
+
+// verificationLink returns the t.me link that opens /verify for code in the bot
+func (s *DocumentService) verificationLink(code string) string {
+	if s.botUsername == "" {
+		return ""
 	}
+	return "https://t.me/" + s.botUsername + "?start=" + registry.StartParameter(code)
+}
+
+// reportMaxUnresolved is how many unresolved items a report lists one by one
+const reportMaxUnresolved = 40
 
-	if fileInfo.Size() == 0 {
-		return "", "", fmt.Errorf("generated file is empty")
+// GenerateReportPDF renders a scheduled admin report. Reports hold only counts
+// and classes, no parent data, and are not recorded in the document registry.
+// Returns the file path and filename
+func (s *DocumentService) GenerateReportPDF(report *models.AdminReport) (filePath, filename string, err error) {
+	title, name := "HAFTALIK HISOBOT / ЕЖЕНЕДЕЛЬНЫЙ ОТЧЁТ", "Haftalik_hisobot"
+	if report.Frequency == models.ReportMonthly {
+		title, name = "OYLIK HISOBOT / ЕЖЕМЕСЯЧНЫЙ ОТЧЁТ", "Oylik_hisobot"
 	}
 
-	fmt.Printf("[DEBUG] PDF verified: %s, size: %d bytes\n", filename, fileInfo.Size())
+	filename = fmt.Sprintf("%s_%s.pdf", name, report.From.Format("2006-01-02"))
+	filePath = filepath.Join(s.tempDir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), filename))
+
+	counts := func(complaints, proposals int) string {
+		return fmt.Sprintf("%d shikoyat / жалоб, %d taklif / предложений", complaints, proposals)
+	}
+
+	median := "—"
+	if report.MedianReview != nil {
+		median = fmt.Sprintf("%.1f soat / ч", *report.MedianReview)
+	}
+
+	summary := PDFSection{
+		Heading: "Umumiy / Общее:",
+		Fields: []PDFField{
+			{Label: "Davr / Период", Value: utils.FormatPeriod(report.From, report.To)},
+			{Label: "Murojaatlar / Обращения", Value: counts(report.Complaints, report.Proposals)},
+			{Label: "Ko'rib chiqish (mediana) / Рассмотрение (медиана)", Value: median},
+			{Label: "Yangi ota-onalar / Новые родители", Value: fmt.Sprint(report.Registrations)},
+			{Label: "Tasdiq kutmoqda / Ожидают подтверждения", Value: fmt.Sprint(report.PendingApprovals)},
+		},
+	}
+
+	statuses := PDFSection{Heading: "Holatlar bo'yicha / По статусам:"}
+	for _, status := range report.Statuses {
+		statuses.Fields = append(statuses.Fields, PDFField{Label: statusLabel(status.Status), Value: counts(status.Complaints, status.Proposals)})
+	}
+
+	classes := PDFSection{Heading: "Guruhlar bo'yicha / По группам:"}
+	for _, class := range report.Classes {
+		label := class.Class
+		if label == "" {
+			label = "Guruhsiz / Без группы"
+		}
+		classes.Fields = append(classes.Fields, PDFField{Label: label, Value: counts(class.Complaints, class.Proposals)})
+	}
+	if len(report.Classes) == 0 {
+		classes.Text = "Murojaatlar yo'q / Обращений нет"
+	}
+
+	categories := PDFSection{Heading: "Shikoyat toifalari / Категории жалоб:"}
+	for _, category := range report.Categories {
+		label := category.Category.Label()
+		if label == "" {
+			label = "Toifasiz / Без категории"
+		}
+		categories.Fields = append(categories.Fields, PDFField{Label: label, Value: fmt.Sprint(category.Complaints)})
+	}
+	if len(report.Categories) == 0 {
+		categories.Text = "Shikoyatlar yo'q / Жалоб нет"
+	}
+
+	unresolved := PDFSection{
+		Heading: fmt.Sprintf("%d kundan ortiq hal qilinmagan / Не рассмотрены более %d дней: %d",
+			report.StaleAfterDays, report.StaleAfterDays, len(report.Unresolved)),
+	}
+	for i, item := range report.Unresolved {
+		if i == reportMaxUnresolved {
+			rest := len(report.Unresolved) - reportMaxUnresolved
+			unresolved.Text = fmt.Sprintf("... va yana %d / и ещё %d", rest, rest)
+			break
+		}
+
+		label := fmt.Sprintf("Shikoyat / Жалоба №%d", item.ID)
+		if item.Kind == models.DocumentJobKindProposal {
+			label = fmt.Sprintf("Taklif / Предложение №%d", item.ID)
+		}
+		class := item.ChildClass
+		if class == "" {
+			class = "—"
+		}
+		days := int(report.GeneratedAt.Sub(item.CreatedAt).Hours() / 24)
+		value := fmt.Sprintf("%s, %s, %d kun / дн.", class, utils.FormatDate(item.CreatedAt.Local()), days)
+		unresolved.Fields = append(unresolved.Fields, PDFField{Label: label, Value: value})
+	}
+	if len(report.Unresolved) == 0 {
+		unresolved.Text = "Yo'q / Нет"
+	}
+
+	announcements := PDFSection{Heading: "E'lonlar / Объявления:"}
+	for _, item := range report.Announcements {
+		value := "—"
+		if total := item.Delivered + item.Failed; total > 0 {
+			value = fmt.Sprintf("%d / %d (%.0f%%)", item.Delivered, total, float64(item.Delivered)/float64(total)*100)
+		}
+		label := fmt.Sprintf("%s %s", utils.FormatDate(item.CreatedAt.Local()), utils.TruncateText(item.Title, 60))
+		announcements.Fields = append(announcements.Fields, PDFField{Label: label, Value: value})
+	}
+	if len(report.Announcements) == 0 {
+		announcements.Text = "E'lonlar yo'q / Объявлений нет"
+	}
+
+	doc := &PDFDocument{
+		Title:       title,
+		Reference:   utils.GenerateReferenceNumber("HS", report.GeneratedAt),
+		GeneratedAt: report.GeneratedAt,
+		Sections:    []PDFSection{summary, statuses, classes, categories, unresolved, announcements},
+	}
+
+	if err := s.renderer.Render(doc, filePath); err != nil {
+		return "", "", err
+	}
 
 	return filePath, filename, nil
 }
 
-// downloadTelegramImage downloads an image from Telegram servers
-func (s *DocumentService) downloadTelegramImage(fileID string, index int) (string, error) {
-	// Get file from Telegram
-	file, err := s.bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
-	if err != nil {
-		return "", fmt.Errorf("failed to get file: %w", err)
+// GenerateComplaintDOCX generates a Word document for a saved complaint with text and images
+// Returns the file path and filename
+func (s *DocumentService) GenerateComplaintDOCX(user *models.User, complaint *models.Complaint, images []models.ImageData) (filePath, filename string, err error) {
+	filename = utils.GenerateComplaintFilename(user.ChildName, user.ChildClass)
+	if complaint.IsAnonymous {
+		user = user.Anonymized()
+		filename = utils.GenerateAnonymousComplaintFilename()
 	}
+	filePath, err = s.generateSubmissionDOCX(docx.KindComplaint, filename, user, complaint.ID, complaint.Category.Label(), complaint.ComplaintText, complaint.CreatedAt, images)
+	return filePath, filename, err
+}
 
-	// Download file
-	fileURL := file.Link(s.bot.Token)
-	resp, err := http.Get(fileURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download file: %w", err)
+// GenerateProposalDOCX generates a Word document for a saved proposal with text and images
+// Returns the file path and filename
+func (s *DocumentService) GenerateProposalDOCX(user *models.User, proposal *models.Proposal, images []models.ImageData) (filePath, filename string, err error) {
+	filename = utils.GenerateProposalFilename(user.ChildName, user.ChildClass)
+	filePath, err = s.generateSubmissionDOCX(docx.KindProposal, filename, user, proposal.ID, "", proposal.ProposalText, proposal.CreatedAt, images)
+	return filePath, filename, err
+}
+
+// generateSubmissionDOCX renders a complaint or proposal as a Word document for paperwork;
+// category is "" for proposals and uncategorized complaints
+func (s *DocumentService) generateSubmissionDOCX(kind docx.Kind, filename string, user *models.User, id int, category, text string, submittedAt time.Time, images []models.ImageData) (string, error) {
+	filePath := filepath.Join(s.tempDir, filename)
+
+	data := &docx.Data{
+		Kind:        kind,
+		ID:          id,
+		ChildName:   user.ChildName,
+		ChildClass:  user.ChildClass,
+		PhoneNumber: user.PhoneNumber,
+		Category:    category,
+		Text:        html.UnescapeString(text), // stored HTML-escaped for Telegram
+		Date:        submittedAt,
 	}
-	defer resp.Body.Close()
 
-	// Create temp file
-	tempFile := filepath.Join(s.tempDir, fmt.Sprintf("temp_img_%d_%d.jpg", time.Now().Unix(), index))
-	out, err := os.Create(tempFile)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+	// Same images as the PDF: downloaded concurrently, then normalized to JPEG
+	for i, download := range s.downloads.FetchAll(images) {
+		image := docx.Image{}
+		err := download.Err
+		if err == nil {
+			image.Data, err = imaging.Normalize(download.Data, imaging.MaxDimension)
+		}
+		if err != nil {
+			// Leave Data nil, the document shows a placeholder
+			fmt.Printf("[WARN] Failed to prepare image %d for DOCX: %v\n", i, err)
+		}
+		data.Images = append(data.Images, image)
 	}
-	defer out.Close()
 
-	// Save file
-	_, err = io.Copy(out, resp.Body)
+	if err := docx.Generate(data, filePath); err != nil {
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+// saveNormalizedImage converts a downloaded image to JPEG (real format detected,
+// EXIF orientation applied, size capped) and saves it as a temp file
+func (s *DocumentService) saveNormalizedImage(download DownloadResult, index int) (string, error) {
+	if download.Err != nil {
+		return "", download.Err
+	}
+
+	normalized, err := imaging.Normalize(download.Data, imaging.MaxDimension)
 	if err != nil {
+		return "", err
+	}
+
+	tempFile := filepath.Join(s.tempDir, fmt.Sprintf("temp_img_%d_%d.jpg", time.Now().UnixNano(), index))
+	if err := os.WriteFile(tempFile, normalized, 0644); err != nil {
 		return "", fmt.Errorf("failed to save file: %w", err)
 	}
 
@@ -248,148 +399,3 @@ func (s *DocumentService) CleanTempDirectory(maxAge time.Duration) error {
 func (s *DocumentService) GetTempDir() string {
 	return s.tempDir
 }
-
-// GenerateProposalPDF generates a PDF document for a proposal with text and images
-// Returns the file path and filename
-func (s *DocumentService) GenerateProposalPDF(user *models.User, proposalText string, images []models.ImageData) (filePath, filename string, err error) {
-	// Generate filename with .pdf extension
-	filename = utils.GenerateProposalPDFFilename(user.ChildName, user.ChildClass)
-
-	// Create full path
-	filePath = filepath.Join(s.tempDir, filename)
-
-	// Create PDF
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Add UTF-8 font support for Cyrillic characters
-	pdf.AddUTF8Font("DejaVu", "", filepath.Join("fonts", "DejaVuSans.ttf"))
-	pdf.AddUTF8Font("DejaVu", "B", filepath.Join("fonts", "DejaVuSans-Bold.ttf"))
-
-	// Set font
-	pdf.SetFont("DejaVu", "B", 16)
-
-	// Title
-	pdf.CellFormat(0, 10, "TAKLIF / ПРЕДЛОЖЕНИЕ", "", 1, "C", false, 0, "")
-	pdf.Ln(5)
-
-	// User information
-	pdf.SetFont("DejaVu", "B", 12)
-	pdf.Cell(0, 8, "Ma'lumotlar / Информация:")
-	pdf.Ln(8)
-
-	pdf.SetFont("DejaVu", "", 11)
-	// Strip emojis from user data for PDF compatibility
-	pdf.Cell(0, 6, fmt.Sprintf("Farzand / Ребенок: %s", utils.StripEmojis(user.ChildName)))
-	pdf.Ln(6)
-	pdf.Cell(0, 6, fmt.Sprintf("Guruh / Группа: %s", utils.StripEmojis(user.ChildClass)))
-	pdf.Ln(6)
-	pdf.Cell(0, 6, fmt.Sprintf("Telefon / Телефон: %s", user.PhoneNumber))
-	pdf.Ln(6)
-	pdf.Cell(0, 6, fmt.Sprintf("Sana / Дата: %s", time.Now().Format("02.01.2006 15:04")))
-	pdf.Ln(10)
-
-	// Proposal text
-	pdf.SetFont("DejaVu", "B", 12)
-	pdf.Cell(0, 8, "Taklif matni / Текст предложения:")
-	pdf.Ln(8)
-
-	pdf.SetFont("DejaVu", "", 11)
-	// Strip emojis from proposal text for PDF compatibility
-	pdf.MultiCell(0, 6, utils.StripEmojis(proposalText), "", "", false)
-	pdf.Ln(5)
-
-	// Add images if any
-	if len(images) > 0 {
-		pdf.Ln(5)
-		pdf.SetFont("DejaVu", "B", 12)
-		pdf.Cell(0, 8, fmt.Sprintf("Qo'shimcha rasmlar / Приложенные изображения: %d ta", len(images)))
-		pdf.Ln(8)
-
-		for i, img := range images {
-			// Download image from Telegram
-			imgPath, err := s.downloadTelegramImage(img.FileID, i)
-			if err != nil {
-				fmt.Printf("[WARN] Failed to download image %d: %v\n", i, err)
-				continue
-			}
-			defer os.Remove(imgPath) // Clean up after adding to PDF
-
-			// Add image number
-			pdf.SetFont("DejaVu", "", 10)
-			pdf.Cell(0, 5, fmt.Sprintf("Rasm %d / Изображение %d:", i+1, i+1))
-			pdf.Ln(6)
-
-			// Get current Y position
-			currentY := pdf.GetY()
-
-			// Calculate max image dimensions for A4 page
-			// A4 width = 210mm, with 15mm margins on each side = 180mm usable
-			maxWidth := 180.0
-			maxHeight := 200.0 // Leave space for margins and other content
-
-			// Check if we need a new page (if less than 60mm space remaining)
-			if currentY > 240 {
-				pdf.AddPage()
-				currentY = pdf.GetY()
-			}
-
-			// Add image to PDF with proper sizing
-			// Using ImageOptions with flow mode to automatically calculate height
-			options := gofpdf.ImageOptions{
-				ImageType: "",
-				ReadDpi:   false,
-			}
-
-			// Get image info to calculate aspect ratio
-			info := pdf.RegisterImageOptions(imgPath, options)
-			if info != nil {
-				imgWidth := float64(info.Width())
-				imgHeight := float64(info.Height())
-
-				// Calculate scaled dimensions maintaining aspect ratio
-				scaledWidth := maxWidth
-				scaledHeight := (imgHeight / imgWidth) * maxWidth
-
-				// If height is too large, scale based on height instead
-				if scaledHeight > maxHeight {
-					scaledHeight = maxHeight
-					scaledWidth = (imgWidth / imgHeight) * maxHeight
-				}
-
-				// Center the image horizontally
-				xPos := (210.0 - scaledWidth) / 2.0
-
-				// Add the image with calculated dimensions
-				pdf.Image(imgPath, xPos, currentY, scaledWidth, scaledHeight, false, "", 0, "")
-
-				// Move Y position down by image height plus spacing
-				pdf.SetY(currentY + scaledHeight + 10)
-			} else {
-				// Fallback if image info not available
-				pdf.Image(imgPath, 15, currentY, maxWidth, 0, false, "", 0, "")
-				pdf.Ln(80) // Approximate spacing
-			}
-		}
-	}
-
-	// Save PDF
-	err = pdf.OutputFileAndClose(filePath)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to generate PDF: %w", err)
-	}
-
-	// Verify file was created and has content
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to verify generated file: %w", err)
-	}
-
-	if fileInfo.Size() == 0 {
-		return "", "", fmt.Errorf("generated file is empty")
-	}
-
-	fmt.Printf("[DEBUG] PDF verified: %s, size: %d bytes\n", filename, fileInfo.Size())
-
-	return filePath, filename, nil
-}
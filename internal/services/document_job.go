@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+)
+
+// Document job retry policy
+const (
+	documentJobMaxAttempts  = 5
+	documentJobPollInterval = 30 * time.Second
+)
+
+// errDocumentItemGone is returned when the complaint or proposal was deleted before its PDF was generated
+var errDocumentItemGone = errors.New("document item no longer exists")
+
+// DocumentJobResultFunc is called when a job completes (err == nil) or fails for good
+type DocumentJobResultFunc func(job *models.DocumentJob, err error)
+
+// DocumentJobService generates and uploads complaint and proposal PDFs in the background
+type DocumentJobService struct {
+	repo             *repository.DocumentJobRepository
+	userService      *UserService
+	complaintService *ComplaintService
+	proposalService  *ProposalService
+	documentService  *DocumentService
+	telegramService  *TelegramService
+	wake             chan struct{}
+}
+
+// NewDocumentJobService creates a new document job service
+func NewDocumentJobService(
+	repo *repository.DocumentJobRepository,
+	userService *UserService,
+	complaintService *ComplaintService,
+	proposalService *ProposalService,
+	documentService *DocumentService,
+	telegramService *TelegramService,
+) *DocumentJobService {
+	return &DocumentJobService{
+		repo:             repo,
+		userService:      userService,
+		complaintService: complaintService,
+		proposalService:  proposalService,
+		documentService:  documentService,
+		telegramService:  telegramService,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Enqueue queues PDF generation for a saved complaint or proposal. messageID is
+// the parent's progress message, edited when the job completes (0 for none).
+func (s *DocumentJobService) Enqueue(kind string, itemID int, chatID int64, messageID int) (*models.DocumentJob, error) {
+	job, err := s.repo.Create(kind, itemID, chatID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wake the worker without blocking if it is already awake
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Start starts the background worker. Jobs interrupted by a restart are resumed.
+func (s *DocumentJobService) Start(onResult DocumentJobResultFunc) {
+	if count, err := s.repo.RequeueRunning(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if count > 0 {
+		log.Printf("✓ %d interrupted document jobs requeued", count)
+	}
+
+	go func() {
+		ticker := time.NewTicker(documentJobPollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.processDue(onResult)
+
+			select {
+			case <-s.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// processDue processes queued jobs until none are due
+func (s *DocumentJobService) processDue(onResult DocumentJobResultFunc) {
+	for {
+		job, err := s.repo.ClaimNext()
+		if err != nil {
+			log.Printf("Failed to claim document job: %v", err)
+			return
+		}
+
+		if job == nil {
+			return
+		}
+
+		s.process(job, onResult)
+	}
+}
+
+// process runs a claimed job and records the outcome
+func (s *DocumentJobService) process(job *models.DocumentJob, onResult DocumentJobResultFunc) {
+	err := s.generate(job)
+
+	if err == nil {
+		if err := s.repo.MarkDone(job.ID); err != nil {
+			log.Printf("Failed to complete document job %d: %v", job.ID, err)
+		}
+		job.Status = models.DocumentJobDone
+		onResult(job, nil)
+		return
+	}
+
+	log.Printf("Document job %d (%s #%d) attempt %d failed: %v", job.ID, job.Kind, job.ItemID, job.Attempts, err)
+
+	if errors.Is(err, errDocumentItemGone) {
+		// Nobody left to notify
+		_ = s.repo.MarkFailed(job.ID, err.Error())
+		return
+	}
+
+	if job.Attempts < documentJobMaxAttempts {
+		// Back off: 1, 4, 9, 16 minutes
+		delay := fmt.Sprintf("+%d minutes", job.Attempts*job.Attempts)
+		if err := s.repo.Retry(job.ID, err.Error(), delay); err != nil {
+			log.Printf("Failed to requeue document job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	if err := s.repo.MarkFailed(job.ID, err.Error()); err != nil {
+		log.Printf("Failed to mark document job %d failed: %v", job.ID, err)
+	}
+	job.Status = models.DocumentJobFailed
+	onResult(job, err)
+}
+
+// generate generates the PDF for a job, uploads it to the parent's chat and saves its file ID
+func (s *DocumentJobService) generate(job *models.DocumentJob) error {
+	var (
+		userID int
+		render func(user *models.User) (filePath, filename string, err error)
+		save   func(fileID, filename string) error
+	)
+
+	switch job.Kind {
+	case models.DocumentJobKindComplaint:
+		complaint, err := s.complaintService.GetComplaintByID(job.ItemID)
+		if err != nil {
+			return err
+		}
+		if complaint == nil {
+			return errDocumentItemGone
+		}
+
+		images, err := s.complaintService.GetComplaintImages(complaint.ID)
+		if err != nil {
+			return err
+		}
+
+		imageData := make([]models.ImageData, 0, len(images))
+		for _, img := range images {
+			imageData = append(imageData, storedImageData(img.TelegramFileID, img.FileUniqueID, img.FileSize, img.MimeType))
+		}
+
+		userID = complaint.UserID
+		render = func(user *models.User) (string, string, error) {
+			return s.documentService.GenerateComplaintPDF(user, complaint, imageData)
+		}
+		save = func(fileID, filename string) error {
+			return s.complaintService.UpdateComplaintPDF(complaint.ID, fileID, filename)
+		}
+
+	case models.DocumentJobKindProposal:
+		proposal, err := s.proposalService.GetProposalByID(job.ItemID)
+		if err != nil {
+			return err
+		}
+		if proposal == nil {
+			return errDocumentItemGone
+		}
+
+		images, err := s.proposalService.GetProposalImages(proposal.ID)
+		if err != nil {
+			return err
+		}
+
+		imageData := make([]models.ImageData, 0, len(images))
+		for _, img := range images {
+			imageData = append(imageData, storedImageData(img.TelegramFileID, img.FileUniqueID, img.FileSize, img.MimeType))
+		}
+
+		userID = proposal.UserID
+		render = func(user *models.User) (string, string, error) {
+			return s.documentService.GenerateProposalPDF(user, proposal, imageData)
+		}
+		save = func(fileID, filename string) error {
+			return s.proposalService.UpdateProposalPDF(proposal.ID, fileID, filename)
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown kind %s", errDocumentItemGone, job.Kind)
+	}
+
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errDocumentItemGone
+	}
+
+	pdfPath, filename, err := render(user)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.documentService.DeleteTempFile(pdfPath) }()
+
+	// Upload PDF to the parent's chat to get a file_id
+	fileID, err := s.telegramService.UploadDocument(job.ChatID, pdfPath, filename)
+	if err != nil {
+		return err
+	}
+
+	return save(fileID, filename)
+}
+
+// storedImageData converts a saved complaint or proposal image back to ImageData
+func storedImageData(fileID, fileUniqueID string, fileSize int, mimeType string) models.ImageData {
+	return models.ImageData{
+		FileID:       fileID,
+		FileUniqueID: fileUniqueID,
+		FileSize:     fileSize,
+		MimeType:     mimeType,
+	}
+}
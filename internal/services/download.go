@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"anor-kids/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Download limits
+const (
+	downloadConcurrency = 4
+	downloadTimeout     = 30 * time.Second
+	downloadRetries     = 3
+	downloadBackoff     = time.Second
+	downloadMaxSize     = 20 << 20 // Telegram bots cannot download files larger than 20 MB
+	downloadCacheMaxAge = 7 * 24 * time.Hour
+)
+
+// ErrFileTooLarge is returned for files over the download size limit
+var ErrFileTooLarge = errors.New("file too large")
+
+// DownloadResult is the outcome of downloading one attachment
+type DownloadResult struct {
+	Data []byte
+	Err  error
+}
+
+// DownloadManager downloads Telegram files with bounded concurrency, timeouts
+// and retries, and caches them on disk by FileUniqueID
+type DownloadManager struct {
+	bot      *tgbotapi.BotAPI
+	client   *http.Client
+	cacheDir string
+	slots    chan struct{}
+	backoff  time.Duration
+}
+
+// NewDownloadManager creates a new download manager caching files in cacheDir
+func NewDownloadManager(bot *tgbotapi.BotAPI, cacheDir string) *DownloadManager {
+	return newDownloadManager(bot, cacheDir, downloadTimeout)
+}
+
+// newDownloadManager creates a download manager whose Telegram calls and
+// downloads each give up after timeout
+func newDownloadManager(bot *tgbotapi.BotAPI, cacheDir string, timeout time.Duration) *DownloadManager {
+	client := &http.Client{Timeout: timeout}
+
+	// getFile goes through the bot's HTTP client, which has no timeout and would
+	// hold a slot forever if Telegram hangs; use a copy of the bot with ours
+	api := *bot
+	api.Client = client
+
+	return &DownloadManager{
+		bot:      &api,
+		client:   client,
+		cacheDir: cacheDir,
+		slots:    make(chan struct{}, downloadConcurrency),
+		backoff:  downloadBackoff,
+	}
+}
+
+// FetchAll downloads attachments concurrently. Results are in the order of images.
+func (m *DownloadManager) FetchAll(images []models.ImageData) []DownloadResult {
+	results := make([]DownloadResult, len(images))
+
+	var wg sync.WaitGroup
+	for i, img := range images {
+		wg.Add(1)
+		go func(i int, img models.ImageData) {
+			defer wg.Done()
+			data, err := m.Fetch(img)
+			results[i] = DownloadResult{Data: data, Err: err}
+		}(i, img)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Fetch returns the contents of an attachment from the cache or downloads it
+func (m *DownloadManager) Fetch(img models.ImageData) ([]byte, error) {
+	if img.FileSize > downloadMaxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFileTooLarge, img.FileSize)
+	}
+
+	if data, ok := m.readCache(img.FileUniqueID); ok {
+		return data, nil
+	}
+
+	var data []byte
+	var err error
+	for attempt := 1; attempt <= downloadRetries; attempt++ {
+		data, err = m.download(img.FileID)
+		if err == nil || errors.Is(err, ErrFileTooLarge) {
+			break
+		}
+		if attempt < downloadRetries {
+			time.Sleep(time.Duration(attempt) * m.backoff)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.writeCache(img.FileUniqueID, data)
+
+	return data, nil
+}
+
+// download downloads a file once, waiting for a free slot first
+func (m *DownloadManager) download(fileID string) ([]byte, error) {
+	m.slots <- struct{}{}
+	defer func() { <-m.slots }()
+
+	file, err := m.bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	if file.FileSize > downloadMaxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFileTooLarge, file.FileSize)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.Link(m.bot.Token), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, downloadMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if len(data) > downloadMaxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	return data, nil
+}
+
+// cachePath returns the cache file for a FileUniqueID, "" if it can't be cached
+func (m *DownloadManager) cachePath(fileUniqueID string) string {
+	if fileUniqueID == "" || m.cacheDir == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(fileUniqueID))
+	return filepath.Join(m.cacheDir, hex.EncodeToString(sum[:]))
+}
+
+// readCache reads a cached file and marks it as recently used
+func (m *DownloadManager) readCache(fileUniqueID string) ([]byte, bool) {
+	path := m.cachePath(fileUniqueID)
+	if path == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return data, true
+}
+
+// writeCache stores a downloaded file. Errors are logged only, the cache is an optimization.
+func (m *DownloadManager) writeCache(fileUniqueID string, data []byte) {
+	path := m.cachePath(fileUniqueID)
+	if path == "" {
+		return
+	}
+
+	if err := os.MkdirAll(m.cacheDir, 0755); err != nil {
+		fmt.Printf("[WARN] Failed to create download cache: %v\n", err)
+		return
+	}
+
+	// Write to a temp file and rename, so concurrent readers never see partial files
+	tmp, err := os.CreateTemp(m.cacheDir, "partial_*")
+	if err != nil {
+		fmt.Printf("[WARN] Failed to cache download: %v\n", err)
+		return
+	}
+
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(tmp.Name())
+		fmt.Printf("[WARN] Failed to cache download: %v\n", errors.Join(writeErr, closeErr))
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		fmt.Printf("[WARN] Failed to cache download: %v\n", err)
+	}
+}
+
+// CleanCache deletes cached files that were not used for downloadCacheMaxAge
+func (m *DownloadManager) CleanCache() error {
+	files, err := os.ReadDir(m.cacheDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read download cache: %w", err)
+	}
+
+	now := time.Now()
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+
+		if now.Sub(info.ModTime()) > downloadCacheMaxAge {
+			_ = os.Remove(filepath.Join(m.cacheDir, file.Name())) // Ignore errors
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"anor-kids/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeFile is a file served by fakeTelegram
+type fakeFile struct {
+	data     []byte
+	size     int // file_size reported by getFile, 0 if unknown
+	failures int // downloads answered with 500 before the file is served
+}
+
+// fakeTelegram answers getMe, getFile and file downloads for the files it holds
+type fakeTelegram struct {
+	files map[string]*fakeFile
+	delay time.Duration // added to every download
+
+	mu        sync.Mutex
+	getFiles  int
+	downloads int
+	active    int
+	maxActive int
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/getMe"):
+		writeTelegramResult(w, tgbotapi.User{ID: 1, IsBot: true, FirstName: "test", UserName: "test_bot"})
+
+	case strings.HasSuffix(r.URL.Path, "/getFile"):
+		f.mu.Lock()
+		f.getFiles++
+		f.mu.Unlock()
+
+		fileID := r.FormValue("file_id")
+		file, ok := f.files[fileID]
+		if !ok {
+			http.Error(w, `{"ok":false,"error_code":400,"description":"file not found"}`, http.StatusBadRequest)
+			return
+		}
+		writeTelegramResult(w, tgbotapi.File{FileID: fileID, FileSize: file.size, FilePath: "documents/" + fileID})
+
+	case strings.HasPrefix(r.URL.Path, "/file/"):
+		f.mu.Lock()
+		f.downloads++
+		f.active++
+		f.maxActive = max(f.maxActive, f.active)
+		file := f.files[filepath.Base(r.URL.Path)]
+		fail := file != nil && file.failures > 0
+		if fail {
+			file.failures--
+		}
+		f.mu.Unlock()
+
+		time.Sleep(f.delay)
+
+		f.mu.Lock()
+		f.active--
+		f.mu.Unlock()
+
+		if file == nil || fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(file.data)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// calls returns how many getFile calls and downloads the fake answered
+func (f *fakeTelegram) calls() (getFiles, downloads int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.getFiles, f.downloads
+}
+
+func writeTelegramResult(w http.ResponseWriter, result any) {
+	data, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": json.RawMessage(data)})
+}
+
+// redirectTransport sends every request to the test server; file links always
+// point at api.telegram.org
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// newTestDownloadManager creates a download manager talking to a fake Telegram
+// with short timeouts and backoff
+func newTestDownloadManager(t *testing.T, telegram *fakeTelegram) *DownloadManager {
+	t.Helper()
+
+	server := httptest.NewServer(telegram)
+	t.Cleanup(server.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", server.URL+"/bot%s/%s", &http.Client{})
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+
+	target, _ := url.Parse(server.URL)
+	m := newDownloadManager(bot, t.TempDir(), 5*time.Second)
+	m.client.Transport = redirectTransport{target: target}
+	m.backoff = time.Millisecond
+	return m
+}
+
+func TestDownloadGetFileTimeout(t *testing.T) {
+	// A Telegram API that answers getMe but doesn't answer getFile until the test ends
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"test","username":"test_bot"}}`))
+			return
+		}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", server.URL+"/bot%s/%s", &http.Client{})
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+
+	m := newDownloadManager(bot, t.TempDir(), 200*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.download("file")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error from a stalled getFile")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("download did not give up on a stalled getFile")
+	}
+
+	if len(m.slots) != 0 {
+		t.Errorf("download slot not released: %d in use", len(m.slots))
+	}
+}
+
+func TestDownloadFetch(t *testing.T) {
+	content := []byte("photo")
+
+	tests := []struct {
+		name          string
+		file          *fakeFile
+		fileSize      int // ImageData.FileSize sent by Telegram with the message
+		wantErr       error
+		wantGetFiles  int
+		wantDownloads int
+	}{
+		{"success", &fakeFile{data: content}, 0, nil, 1, 1},
+		{"retry then success", &fakeFile{data: content, failures: 2}, 0, nil, 3, 3},
+		{"retries exhausted", &fakeFile{data: content, failures: 3}, 0, errors.New("status 500"), 3, 3},
+		{"too large in message", &fakeFile{data: content}, downloadMaxSize + 1, ErrFileTooLarge, 0, 0},
+		{"too large in getFile", &fakeFile{data: content, size: downloadMaxSize + 1}, 0, ErrFileTooLarge, 1, 0},
+		{"too large body", &fakeFile{data: bytes.Repeat([]byte{1}, downloadMaxSize+1)}, 0, ErrFileTooLarge, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telegram := &fakeTelegram{files: map[string]*fakeFile{"file": tt.file}}
+			m := newTestDownloadManager(t, telegram)
+
+			data, err := m.Fetch(models.ImageData{FileID: "file", FileUniqueID: "unique", FileSize: tt.fileSize})
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("Fetch() error = %v", err)
+			case tt.wantErr == nil && !bytes.Equal(data, content):
+				t.Errorf("Fetch() = %q, want %q", data, content)
+			case tt.wantErr != nil && err == nil:
+				t.Fatalf("Fetch() = %q, want error %v", data, tt.wantErr)
+			case tt.wantErr == ErrFileTooLarge && !errors.Is(err, ErrFileTooLarge):
+				t.Errorf("Fetch() error = %v, want ErrFileTooLarge", err)
+			}
+
+			if getFiles, downloads := telegram.calls(); getFiles != tt.wantGetFiles || downloads != tt.wantDownloads {
+				t.Errorf("got %d getFile calls and %d downloads, want %d and %d",
+					getFiles, downloads, tt.wantGetFiles, tt.wantDownloads)
+			}
+
+			// Only successful downloads are cached
+			_, cached := m.readCache("unique")
+			if cached != (tt.wantErr == nil) {
+				t.Errorf("cached = %v, want %v", cached, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestDownloadCacheHit(t *testing.T) {
+	telegram := &fakeTelegram{files: map[string]*fakeFile{
+		"file":  {data: []byte("photo")},
+		"other": {data: []byte("other photo")},
+	}}
+	m := newTestDownloadManager(t, telegram)
+
+	if _, err := m.Fetch(models.ImageData{FileID: "file", FileUniqueID: "unique"}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	// The same file forwarded again has a new FileID but the same FileUniqueID
+	data, err := m.Fetch(models.ImageData{FileID: "forwarded", FileUniqueID: "unique"})
+	if err != nil {
+		t.Fatalf("Fetch(cached) error = %v", err)
+	}
+	if string(data) != "photo" {
+		t.Errorf("Fetch(cached) = %q, want %q", data, "photo")
+	}
+	if getFiles, downloads := telegram.calls(); getFiles != 1 || downloads != 1 {
+		t.Errorf("cache hit reached Telegram: %d getFile calls, %d downloads", getFiles, downloads)
+	}
+
+	// A different file is a miss
+	if data, err := m.Fetch(models.ImageData{FileID: "other", FileUniqueID: "other-unique"}); err != nil || string(data) != "other photo" {
+		t.Errorf("Fetch(miss) = %q, %v", data, err)
+	}
+	if _, downloads := telegram.calls(); downloads != 2 {
+		t.Errorf("got %d downloads after a miss, want 2", downloads)
+	}
+}
+
+func TestDownloadConcurrencyLimit(t *testing.T) {
+	telegram := &fakeTelegram{files: map[string]*fakeFile{}, delay: 50 * time.Millisecond}
+	var images []models.ImageData
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		telegram.files[id] = &fakeFile{data: []byte(id)}
+		images = append(images, models.ImageData{FileID: id, FileUniqueID: id})
+	}
+	m := newTestDownloadManager(t, telegram)
+
+	for i, result := range m.FetchAll(images) {
+		if result.Err != nil || string(result.Data) != images[i].FileID {
+			t.Errorf("FetchAll()[%d] = %q, %v, want %q", i, result.Data, result.Err, images[i].FileID)
+		}
+	}
+
+	telegram.mu.Lock()
+	maxActive := telegram.maxActive
+	telegram.mu.Unlock()
+	if maxActive > downloadConcurrency {
+		t.Errorf("%d downloads ran at once, want at most %d", maxActive, downloadConcurrency)
+	}
+	if maxActive < 2 {
+		t.Errorf("downloads ran one at a time")
+	}
+}
+
+func TestDownloadCleanCache(t *testing.T) {
+	m := &DownloadManager{cacheDir: t.TempDir()}
+	m.writeCache("old", []byte("old"))
+	m.writeCache("used", []byte("used"))
+	m.writeCache("new", []byte("new"))
+
+	stale := time.Now().Add(-downloadCacheMaxAge - time.Hour)
+	for _, id := range []string{"old", "used"} {
+		if err := os.Chtimes(m.cachePath(id), stale, stale); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	// Reading a file marks it as used
+	if _, ok := m.readCache("used"); !ok {
+		t.Fatal("readCache(used) missed")
+	}
+
+	if err := m.CleanCache(); err != nil {
+		t.Fatalf("CleanCache() error = %v", err)
+	}
+
+	for id, want := range map[string]bool{"old": false, "used": true, "new": true} {
+		if _, ok := m.readCache(id); ok != want {
+			t.Errorf("cached %s = %v after cleaning, want %v", id, ok, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"fmt"
+
+	"anor-kids/internal/encryption"
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+)
+
+// EncryptionService manages the keys for field-level encryption of parent data
+type EncryptionService struct {
+	repo   *repository.EncryptionRepository
+	cipher *encryption.Cipher
+}
+
+// NewEncryptionService loads the data keys with the master key, creating the
+// first data and index keys if there are none yet. Without a master key the
+// cipher is nil and values are stored in plaintext.
+func NewEncryptionService(repo *repository.EncryptionRepository, masterKey string) (*EncryptionService, error) {
+	keys, err := repo.GetKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if masterKey == "" {
+		if len(keys) > 0 {
+			return nil, fmt.Errorf("database contains encrypted data but ENCRYPTION_KEY is not set")
+		}
+		return &EncryptionService{repo: repo}, nil
+	}
+
+	master, err := encryption.ParseKey(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cipher, err := encryption.New(master)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &EncryptionService{repo: repo, cipher: cipher}
+
+	if err := s.loadKeys(keys); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// loadKeys unwraps the stored keys into the cipher, creating missing ones
+func (s *EncryptionService) loadKeys(keys []*models.DataKey) error {
+	hasIndexKey, hasDataKey := false, false
+	for _, key := range keys {
+		switch key.Purpose {
+		case models.DataKeyPurposeIndex:
+			hasIndexKey = true
+		case models.DataKeyPurposeData:
+			hasDataKey = true
+		}
+	}
+
+	if !hasIndexKey {
+		key, err := s.createKey(models.DataKeyPurposeIndex)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	if !hasDataKey {
+		key, err := s.createKey(models.DataKeyPurposeData)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	for _, key := range keys {
+		raw, err := s.cipher.UnwrapKey(key.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("data key %d: %w", key.ID, err)
+		}
+
+		if key.Purpose == models.DataKeyPurposeIndex {
+			err = s.cipher.SetIndexKey(raw)
+		} else {
+			err = s.cipher.AddDataKey(key.ID, raw, key.Active)
+		}
+		if err != nil {
+			return fmt.Errorf("data key %d: %w", key.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// createKey generates, wraps and stores a new active key
+func (s *EncryptionService) createKey(purpose string) (*models.DataKey, error) {
+	raw, err := encryption.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := s.cipher.WrapKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateKey(purpose, wrapped, true)
+}
+
+// Cipher returns the field cipher, nil when encryption is disabled
+func (s *EncryptionService) Cipher() *encryption.Cipher {
+	return s.cipher
+}
+
+// Enabled reports whether a master key is configured
+func (s *EncryptionService) Enabled() bool {
+	return s.cipher != nil
+}
+
+// ReencryptStale brings all rows up to date with the active data key and fills
+// missing blind indexes. Returns the number of updated rows.
+func (s *EncryptionService) ReencryptStale() (int, error) {
+	return s.repo.ReencryptStale(s.cipher)
+}
+
+// RotateDataKey creates a new active data key. Existing rows stay readable
+// with the old key until ReencryptStale re-encrypts them.
+func (s *EncryptionService) RotateDataKey() (int, error) {
+	if s.cipher == nil {
+		return 0, encryption.ErrNoKey
+	}
+
+	raw, err := encryption.GenerateKey()
+	if err != nil {
+		return 0, err
+	}
+
+	wrapped, err := s.cipher.WrapKey(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := s.repo.CreateKey(models.DataKeyPurposeData, wrapped, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.ActivateKey(key.ID); err != nil {
+		return 0, err
+	}
+
+	if err := s.cipher.AddDataKey(key.ID, raw, true); err != nil {
+		return 0, err
+	}
+
+	return key.ID, nil
+}
+
+// RewrapKeys wraps all stored keys with a new master key
+func (s *EncryptionService) RewrapKeys(newMasterKey string) error {
+	if s.cipher == nil {
+		return encryption.ErrNoKey
+	}
+
+	master, err := encryption.ParseKey(newMasterKey)
+	if err != nil {
+		return err
+	}
+
+	newCipher, err := encryption.New(master)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.repo.GetKeys()
+	if err != nil {
+		return err
+	}
+
+	wrapped := make(map[int]string, len(keys))
+	for _, key := range keys {
+		raw, err := s.cipher.UnwrapKey(key.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("data key %d: %w", key.ID, err)
+		}
+		if wrapped[key.ID], err = newCipher.WrapKey(raw); err != nil {
+			return fmt.Errorf("data key %d: %w", key.ID, err)
+		}
+	}
+
+	return s.repo.UpdateWrappedKeys(wrapped)
+}
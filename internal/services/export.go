@@ -0,0 +1,313 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+	"anor-kids/internal/utils"
+	"anor-kids/pkg/xlsx"
+)
+
+// ExportFormat is the file format of a table export
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+// ContentType returns the MIME type of the format
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ParseExportFormat parses a format name, reporting whether it is supported
+func ParseExportFormat(name string) (ExportFormat, bool) {
+	switch format := ExportFormat(name); format {
+	case ExportFormatCSV, ExportFormatXLSX:
+		return format, true
+	}
+	return "", false
+}
+
+// Tables that can be exported
+const (
+	ExportTableUsers      = "users"
+	ExportTableComplaints = "complaints"
+	ExportTableProposals  = "proposals"
+)
+
+// tableWriter writes the rows of a table export; the first row is the header
+type tableWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// csvTableWriter writes CSV with a BOM so Excel opens it as UTF-8
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+func (t *csvTableWriter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, cell := range cells {
+		safe[i] = csvSafe(cell)
+	}
+	return t.w.Write(safe)
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// csvPlainValue matches phone numbers and Telegram handles, which start like formulas but are safe
+var csvPlainValue = regexp.MustCompile(`^(\+?[0-9]+|@[A-Za-z0-9_]+)$`)
+
+// csvSafe keeps spreadsheet apps from evaluating parent-written text as a formula
+func csvSafe(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) || csvPlainValue.MatchString(cell) {
+		return cell
+	}
+	return "'" + cell
+}
+
+// newTableWriter starts a table export in format on w
+func newTableWriter(w io.Writer, format ExportFormat, sheetName string) (tableWriter, error) {
+	if format == ExportFormatXLSX {
+		return xlsx.NewWriter(w, sheetName)
+	}
+
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return &csvTableWriter{w: csv.NewWriter(w)}, nil
+}
+
+// ExportService exports users, complaints and proposals as CSV or XLSX tables.
+// Rows are loaded page by page and written as they arrive, so exports of any
+// size stream without being held in memory.
+type ExportService struct {
+	userRepo      *repository.UserRepository
+	complaintRepo *repository.ComplaintRepository
+	proposalRepo  *repository.ProposalRepository
+	tempDir       string
+}
+
+// NewExportService creates a new export service
+func NewExportService(
+	userRepo *repository.UserRepository,
+	complaintRepo *repository.ComplaintRepository,
+	proposalRepo *repository.ProposalRepository,
+	tempDir string,
+) *ExportService {
+	return &ExportService{
+		userRepo:      userRepo,
+		complaintRepo: complaintRepo,
+		proposalRepo:  proposalRepo,
+		tempDir:       tempDir,
+	}
+}
+
+// ExportFile writes a table export to a temp file, for sending through Telegram.
+// Returns the file path and filename; the caller must delete the file when done.
+func (s *ExportService) ExportFile(table string, format ExportFormat, filter models.ExportFilter) (filePath, filename string, err error) {
+	if err := os.MkdirAll(s.tempDir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	filename = fmt.Sprintf("%s_%s.%s", table, time.Now().Format("20060102_150405"), format)
+
+	out, err := os.CreateTemp(s.tempDir, "export_*_"+filename)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	filePath = out.Name()
+
+	err = s.Export(out, table, format, filter)
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write export file: %w", closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(filePath)
+		return "", "", err
+	}
+
+	return filePath, filename, nil
+}
+
+// Export writes table (one of the ExportTable constants) in format to w
+func (s *ExportService) Export(w io.Writer, table string, format ExportFormat, filter models.ExportFilter) error {
+	switch table {
+	case ExportTableUsers:
+		return s.ExportUsers(w, format, filter)
+	case ExportTableComplaints:
+		return s.ExportComplaints(w, format, filter)
+	case ExportTableProposals:
+		return s.ExportProposals(w, format, filter)
+	default:
+		return fmt.Errorf("unknown export table %q", table)
+	}
+}
+
+// ExportUsers writes registered users to w. The filter's period applies to the registration date.
+func (s *ExportService) ExportUsers(w io.Writer, format ExportFormat, filter models.ExportFilter) error {
+	header := []string{
+		"ID",
+		"Telegram ID",
+		"Telegram",
+		"Telefon / Телефон",
+		"Bola / Ребёнок",
+		"Guruh / Группа",
+		"Til / Язык",
+		"Tasdiqlash / Подтверждение",
+		"Ro'yxatdan o'tgan / Зарегистрирован",
+	}
+
+	return writeTable(w, format, "Foydalanuvchilar / Пользователи", header, func(limit, offset int) ([][]string, error) {
+		users, err := s.userRepo.GetForExport(filter, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([][]string, 0, len(users))
+		for _, u := range users {
+			rows = append(rows, []string{
+				strconv.Itoa(u.ID),
+				strconv.FormatInt(u.TelegramID, 10),
+				telegramHandle(u.TelegramUsername),
+				u.PhoneNumber,
+				u.ChildName,
+				u.ChildClass,
+				u.Language,
+				u.ApprovalStatus,
+				utils.FormatDateTime(u.RegisteredAt),
+			})
+		}
+		return rows, nil
+	})
+}
+
+// submissionHeader is the header of the complaint and proposal tables
+var submissionHeader = []string{
+	"ID",
+	"Sana / Дата",
+	"Holat / Статус",
+	"Bola / Ребёнок",
+	"Guruh / Группа",
+	"Telefon / Телефон",
+	"Telegram ID",
+	"Telegram",
+	"Matn / Текст",
+	"PDF",
+}
+
+// complaintHeader is submissionHeader with the complaint category after the status
+var complaintHeader = slices.Insert(slices.Clone(submissionHeader), 3, "Toifa / Категория")
+
+// ExportComplaints writes complaints with their authors to w
+func (s *ExportService) ExportComplaints(w io.Writer, format ExportFormat, filter models.ExportFilter) error {
+	return writeTable(w, format, "Shikoyatlar / Жалобы", complaintHeader, func(limit, offset int) ([][]string, error) {
+		complaints, err := s.complaintRepo.GetForExport(filter, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([][]string, 0, len(complaints))
+		for _, c := range complaints {
+			rows = append(rows, []string{
+				strconv.Itoa(c.ID),
+				utils.FormatDateTime(c.CreatedAt),
+				c.Status,
+				c.Category.Label(),
+				c.ChildName,
+				c.ChildClass,
+				c.PhoneNumber,
+				strconv.FormatInt(c.UserTelegramID, 10),
+				telegramHandle(c.TelegramUsername),
+				html.UnescapeString(c.ComplaintText), // stored HTML-escaped for Telegram
+				c.PDFFilename,
+			})
+		}
+		return rows, nil
+	})
+}
+
+// ExportProposals writes proposals with their authors to w
+func (s *ExportService) ExportProposals(w io.Writer, format ExportFormat, filter models.ExportFilter) error {
+	return writeTable(w, format, "Takliflar / Предложения", submissionHeader, func(limit, offset int) ([][]string, error) {
+		proposals, err := s.proposalRepo.GetForExport(filter, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([][]string, 0, len(proposals))
+		for _, p := range proposals {
+			rows = append(rows, []string{
+				strconv.Itoa(p.ID),
+				utils.FormatDateTime(p.CreatedAt),
+				p.Status,
+				p.ChildName,
+				p.ChildClass,
+				p.PhoneNumber,
+				strconv.FormatInt(p.UserTelegramID, 10),
+				telegramHandle(p.TelegramUsername),
+				html.UnescapeString(p.ProposalText),
+				p.PDFFilename,
+			})
+		}
+		return rows, nil
+	})
+}
+
+// writeTable writes the header and then every page returned by load until a short page
+func writeTable(w io.Writer, format ExportFormat, sheetName string, header []string, load func(limit, offset int) ([][]string, error)) error {
+	tw, err := newTableWriter(w, format, sheetName)
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteRow(header); err != nil {
+		return err
+	}
+
+	for offset := 0; ; offset += exportPageSize {
+		rows, err := load(exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := tw.WriteRow(row); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < exportPageSize {
+			break
+		}
+	}
+
+	return tw.Close()
+}
+
+// telegramHandle formats a Telegram username as @username, "" if there is none
+func telegramHandle(username string) string {
+	if username == "" {
+		return ""
+	}
+	return "@" + username
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"sync"
+	"time"
+
+	"anor-kids/internal/pdftext"
+	"anor-kids/internal/utils"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Page layout in mm (A4 portrait)
+const (
+	pdfPageWidth    = 210.0
+	pdfMargin       = 15.0
+	pdfContentWidth = pdfPageWidth - 2*pdfMargin
+	pdfHeaderHeight = 22.0
+	pdfFooterHeight = 22.0
+	pdfMaxImageH    = 200.0
+	pdfQRSize       = 16.0
+)
+
+// qrImageName is the name the verification QR code is registered under in a document
+const qrImageName = "verification-qr"
+
+// PDFDocument describes a document rendered by PDFRenderer. New document types
+// only fill in a PDFDocument; header, footer and layout are shared.
+type PDFDocument struct {
+	Title       string // Bilingual title, e.g. "SHIKOYAT / ЖАЛОБА"
+	Reference   string // Reference number shown in the header
+	GeneratedAt time.Time
+	Sections    []PDFSection
+	Images      []PDFImage
+
+	// VerificationCode is printed in the footer with a QR code of
+	// VerificationLink, or of the code itself if there is no link
+	VerificationCode string
+	VerificationLink string
+}
+
+// PDFSection is a titled block of label/value fields and/or free text
+type PDFSection struct {
+	Heading string
+	Fields  []PDFField
+	Text    string
+}
+
+// PDFField is a single "Label: value" line
+type PDFField struct {
+	Label string
+	Value string
+}
+
+// PDFImage is a local image file attached at the end of the document.
+// An empty Path renders a placeholder note instead of the image.
+type PDFImage struct {
+	Path    string
+	Caption string
+}
+
+// PDFRenderer renders PDFDocuments with the kindergarten header and page footer
+type PDFRenderer struct {
+	fonts            func() (*pdftext.Fonts, error)
+	kindergartenName string
+	logoPath         string
+}
+
+// NewPDFRenderer creates a new PDF renderer. Fonts are loaded from fontDir on
+// first use. The logo is optional.
+func NewPDFRenderer(fontDir, kindergartenName, logoPath string) *PDFRenderer {
+	return &PDFRenderer{
+		fonts: sync.OnceValues(func() (*pdftext.Fonts, error) {
+			return pdftext.Load(fontDir)
+		}),
+		kindergartenName: kindergartenName,
+		logoPath:         logoPath,
+	}
+}
+
+// Render renders a document to filePath
+func (r *PDFRenderer) Render(doc *PDFDocument, filePath string) error {
+	fonts, err := r.fonts()
+	if err != nil {
+		return fmt.Errorf("failed to load fonts: %w", err)
+	}
+
+	if doc.GeneratedAt.IsZero() {
+		doc.GeneratedAt = time.Now()
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin+pdfHeaderHeight, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfFooterHeight+5)
+	pdf.AliasNbPages("{nb}")
+
+	// DejaVu for Latin and Cyrillic; user text falls back to other fonts and emoji images
+	fonts.Register(pdf)
+
+	if doc.VerificationCode != "" {
+		r.registerQR(pdf, doc)
+	}
+
+	pdf.SetHeaderFunc(func() { r.header(pdf, fonts, doc) })
+	pdf.SetFooterFunc(func() { r.footer(pdf, doc) })
+
+	pdf.AddPage()
+
+	// Title
+	pdf.SetFont("DejaVu", "B", 16)
+	pdf.CellFormat(0, 10, doc.Title, "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	for _, section := range doc.Sections {
+		r.section(pdf, fonts, section)
+	}
+
+	if len(doc.Images) > 0 {
+		r.images(pdf, doc.Images)
+	}
+
+	if err := pdf.OutputFileAndClose(filePath); err != nil {
+		return fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	// Verify file was created and has content
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to verify generated file: %w", err)
+	}
+
+	if fileInfo.Size() == 0 {
+		return fmt.Errorf("generated file is empty")
+	}
+
+	return nil
+}
+
+// header draws the logo, kindergarten name and reference number on every page
+func (r *PDFRenderer) header(pdf *gofpdf.Fpdf, fonts *pdftext.Fonts, doc *PDFDocument) {
+	top := pdfMargin
+	nameX := pdfMargin
+
+	if r.logoPath != "" {
+		if _, err := os.Stat(r.logoPath); err == nil {
+			pdf.ImageOptions(r.logoPath, pdfMargin, top, 0, 14, false, gofpdf.ImageOptions{}, 0, "")
+			nameX += 18
+		}
+	}
+
+	pdf.SetXY(nameX, top+2)
+	pdf.SetFont("DejaVu", "B", 12)
+	fonts.Write(pdf, 6, "B", r.kindergartenName)
+
+	if doc.Reference != "" {
+		pdf.SetXY(pdfPageWidth/2, top+2)
+		pdf.SetFont("DejaVu", "", 9)
+		pdf.CellFormat(pdfPageWidth/2-pdfMargin, 5, "№ "+doc.Reference, "", 0, "R", false, 0, "")
+	}
+
+	lineY := top + 16
+	pdf.SetDrawColor(180, 180, 180)
+	pdf.Line(pdfMargin, lineY, pdfPageWidth-pdfMargin, lineY)
+	pdf.SetXY(pdfMargin, pdfMargin+pdfHeaderHeight)
+}
+
+// footer draws the generation timestamp, "page X of Y" and the verification
+// code with its QR code on every page
+func (r *PDFRenderer) footer(pdf *gofpdf.Fpdf, doc *PDFDocument) {
+	_, pageHeight := pdf.GetPageSize()
+	top := pageHeight - pdfFooterHeight
+	textWidth := pdfContentWidth
+
+	if doc.VerificationCode != "" && pdf.GetImageInfo(qrImageName) != nil {
+		pdf.ImageOptions(qrImageName, pdfPageWidth-pdfMargin-pdfQRSize, top, pdfQRSize, pdfQRSize, false, gofpdf.ImageOptions{}, 0, "")
+		textWidth -= pdfQRSize + 4
+	}
+
+	pdf.SetXY(pdfMargin, top+1)
+	pdf.SetFont("DejaVu", "", 8)
+	pdf.SetTextColor(110, 110, 110)
+
+	generated := fmt.Sprintf("Yaratilgan / Сформировано: %s", utils.FormatDateTime(doc.GeneratedAt))
+	pdf.CellFormat(textWidth/2, 5, generated, "", 0, "L", false, 0, "")
+
+	page := fmt.Sprintf("Sahifa %d / {nb} · Страница %d из {nb}", pdf.PageNo(), pdf.PageNo())
+	pdf.CellFormat(textWidth/2, 5, page, "", 1, "R", false, 0, "")
+
+	if doc.VerificationCode != "" {
+		pdf.SetFont("DejaVu", "B", 8)
+		pdf.CellFormat(textWidth, 5, "Tekshirish kodi / Код проверки: "+doc.VerificationCode, "", 1, "L", false, 0, "")
+
+		pdf.SetFont("DejaVu", "", 7)
+		hint := "Haqiqiyligini tekshirish: QR kod yoki botga /verify " + doc.VerificationCode + "\n" +
+			"Проверка подлинности: QR-код или команда боту /verify " + doc.VerificationCode
+		pdf.MultiCell(textWidth, 3.5, hint, "", "L", false)
+	}
+
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// registerQR adds the verification QR code image to the document. The code
+// stays readable in the footer if the QR code can't be made.
+func (r *PDFRenderer) registerQR(pdf *gofpdf.Fpdf, doc *PDFDocument) {
+	content := doc.VerificationLink
+	if content == "" {
+		content = doc.VerificationCode
+	}
+
+	data, err := qrPNG(content)
+	if err != nil {
+		fmt.Printf("[WARN] Failed to create verification QR code: %v\n", err)
+		return
+	}
+
+	pdf.RegisterImageOptionsReader(qrImageName, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(data))
+}
+
+// qrPNG encodes content as a QR code PNG large enough to print sharply
+func qrPNG(content string) ([]byte, error) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err = barcode.Scale(code, 256, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	// gofpdf can't read the 16-bit PNG the barcode image would encode to
+	gray := image.NewGray(code.Bounds())
+	draw.Draw(gray, gray.Bounds(), code, code.Bounds().Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gray); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// section draws a heading followed by fields and text
+func (r *PDFRenderer) section(pdf *gofpdf.Fpdf, fonts *pdftext.Fonts, section PDFSection) {
+	if section.Heading != "" {
+		pdf.SetFont("DejaVu", "B", 12)
+		pdf.Cell(0, 8, section.Heading)
+		pdf.Ln(8)
+	}
+
+	// Parents' texts are stored HTML-escaped for Telegram; the PDF shows them as typed
+	pdf.SetFont("DejaVu", "", 11)
+	for _, field := range section.Fields {
+		fonts.Write(pdf, 6, "", fmt.Sprintf("%s: %s", field.Label, html.UnescapeString(field.Value)))
+		pdf.Ln(6)
+	}
+
+	if section.Text != "" {
+		fonts.Write(pdf, 6, "", html.UnescapeString(section.Text))
+		pdf.Ln(6)
+	}
+
+	pdf.Ln(5)
+}
+
+// images draws attached images scaled to the page width, one after another
+func (r *PDFRenderer) images(pdf *gofpdf.Fpdf, images []PDFImage) {
+	pdf.Ln(5)
+	pdf.SetFont("DejaVu", "B", 12)
+	pdf.Cell(0, 8, fmt.Sprintf("Qo'shimcha rasmlar / Приложенные изображения: %d ta", len(images)))
+	pdf.Ln(8)
+
+	_, pageHeight := pdf.GetPageSize()
+	pageBottom := pageHeight - pdfFooterHeight - 5
+
+	for _, img := range images {
+		if img.Path == "" {
+			r.imagePlaceholder(pdf, img.Caption, pageBottom)
+			continue
+		}
+
+		info := pdf.RegisterImageOptions(img.Path, gofpdf.ImageOptions{})
+		if info == nil || pdf.Err() {
+			fmt.Printf("[WARN] Failed to add image %s: %v\n", img.Path, pdf.Error())
+			pdf.ClearError()
+			r.imagePlaceholder(pdf, img.Caption, pageBottom)
+			continue
+		}
+
+		// Calculate scaled dimensions maintaining aspect ratio
+		scaledWidth := pdfContentWidth
+		scaledHeight := (info.Height() / info.Width()) * pdfContentWidth
+		if scaledHeight > pdfMaxImageH {
+			scaledHeight = pdfMaxImageH
+			scaledWidth = (info.Width() / info.Height()) * pdfMaxImageH
+		}
+
+		// Keep the caption on the same page as its image
+		if pdf.GetY()+6+scaledHeight > pageBottom {
+			pdf.AddPage()
+		}
+
+		pdf.SetFont("DejaVu", "", 10)
+		pdf.Cell(0, 5, img.Caption)
+		pdf.Ln(6)
+
+		currentY := pdf.GetY()
+		xPos := (pdfPageWidth - scaledWidth) / 2.0
+		pdf.ImageOptions(img.Path, xPos, currentY, scaledWidth, scaledHeight, false, gofpdf.ImageOptions{}, 0, "")
+		pdf.SetY(currentY + scaledHeight + 10)
+	}
+}
+
+// imagePlaceholder draws a note in place of an image that could not be converted
+func (r *PDFRenderer) imagePlaceholder(pdf *gofpdf.Fpdf, caption string, pageBottom float64) {
+	if pdf.GetY()+6+20 > pageBottom {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("DejaVu", "", 10)
+	pdf.Cell(0, 5, caption)
+	pdf.Ln(6)
+
+	note := "Rasmni qo'shib bo'lmadi (format qo'llab-quvvatlanmaydi yoki fayl yuklab olinmadi). Asl fayl Telegramda saqlangan.\n" +
+		"Не удалось добавить изображение (формат не поддерживается или файл не загружен). Исходный файл сохранён в Telegram."
+
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.MultiCell(0, 5, note, "1", "C", true)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(8)
+}
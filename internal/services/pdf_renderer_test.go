@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf16"
+
+	"anor-kids/internal/pdftext"
+	"anor-kids/internal/validator"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// A complaint goes through the validator like one sent in the bot, so the
+// section gets the HTML-escaped text that is stored
+func TestSectionGoldenValidatedText(t *testing.T) {
+	fonts, err := pdftext.Load("../../fonts")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	input, err := os.ReadFile("testdata/complaint_validated.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := validator.ValidateComplaintText(string(input))
+	if err != nil {
+		t.Fatalf("ValidateComplaintText() error = %v", err)
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	fonts.Register(pdf)
+	pdf.AddPage()
+
+	r := &PDFRenderer{}
+	r.section(pdf, fonts, PDFSection{
+		Heading: "Shikoyat matni / Текст жалобы:",
+		Fields:  []PDFField{{Label: "Farzand / Ребенок", Value: validator.SanitizeInput("Ro'ziyeva O'g'iloy")}},
+		Text:    text,
+	})
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	got := shownText(buf.Bytes())
+
+	if strings.Contains(got, "&#") || strings.Contains(got, "&amp;") || strings.Contains(got, "&quot;") {
+		t.Errorf("PDF shows HTML entities: %q", got)
+	}
+
+	want := "Shikoyat matni / Текст жалобы:Farzand / Ребенок: Ro'ziyeva O'g'iloy" + string(input)
+	if withoutSpace(got) != withoutSpace(want) {
+		t.Errorf("PDF text differs from input\ngot:  %q\nwant: %q", got, want)
+	}
+
+	golden := "testdata/complaint_validated.golden"
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantGolden, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(wantGolden) {
+		t.Errorf("PDF text differs from %s\ngot:\n%s\nwant:\n%s", golden, got, wantGolden)
+	}
+}
+
+// shownText returns the strings an uncompressed document shows with Tj, with a
+// newline wherever the vertical position changes
+func shownText(data []byte) string {
+	var out strings.Builder
+	lastY := ""
+	for _, m := range regexp.MustCompile(`[\d.]+ ([\d.]+) Td \(((?:\\.|[^\\)])*)\)Tj`).FindAllSubmatch(data, -1) {
+		if y := string(m[1]); y != lastY {
+			if lastY != "" {
+				out.WriteString("\n")
+			}
+			lastY = y
+		}
+
+		var s []byte
+		for i := 0; i < len(m[2]); i++ {
+			c := m[2][i]
+			if c == '\\' && i+1 < len(m[2]) {
+				i++
+				switch c = m[2][i]; c {
+				case 'n':
+					c = '\n'
+				case 'r':
+					c = '\r'
+				}
+			}
+			s = append(s, c)
+		}
+		out.WriteString(decodeUTF16BE(string(s)))
+	}
+	return out.String()
+}
+
+// decodeUTF16BE decodes a UTF-16BE string, with or without a byte order mark
+func decodeUTF16BE(s string) string {
+	s = strings.TrimPrefix(s, "\xFE\xFF")
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// withoutSpace removes whitespace, which line wrapping adds and drops
+func withoutSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+	"anor-kids/internal/state"
+)
+
+// exportPageSize is how many complaints/proposals are loaded per query during export
+const exportPageSize = 100
+
+// PrivacyService handles parent self-service data export and account deletion
+type PrivacyService struct {
+	userRepo        *repository.UserRepository
+	complaintRepo   *repository.ComplaintRepository
+	proposalRepo    *repository.ProposalRepository
+	ratingRepo      *repository.RatingRepository
+	stateManager    *state.Manager
+	telegramService *TelegramService
+	tempDir         string
+}
+
+// NewPrivacyService creates a new privacy service
+func NewPrivacyService(
+	userRepo *repository.UserRepository,
+	complaintRepo *repository.ComplaintRepository,
+	proposalRepo *repository.ProposalRepository,
+	ratingRepo *repository.RatingRepository,
+	stateManager *state.Manager,
+	telegramService *TelegramService,
+	tempDir string,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepo:        userRepo,
+		complaintRepo:   complaintRepo,
+		proposalRepo:    proposalRepo,
+		ratingRepo:      ratingRepo,
+		stateManager:    stateManager,
+		telegramService: telegramService,
+		tempDir:         tempDir,
+	}
+}
+
+// CollectUserData gathers the user record with all their complaints, proposals and
+// image metadata, and the replies, earlier versions and ratings of their complaints
+func (s *PrivacyService) CollectUserData(user *models.User) (*models.UserDataExport, error) {
+	export := &models.UserDataExport{
+		ExportedAt: time.Now(),
+		User:       user,
+		Complaints: []models.ComplaintWithImages{},
+		Proposals:  []models.ProposalWithImages{},
+		Replies:    []models.ComplaintReply{},
+		Versions:   []models.ComplaintVersion{},
+		Ratings:    []models.ComplaintRating{},
+	}
+
+	for offset := 0; ; offset += exportPageSize {
+		complaints, err := s.complaintRepo.GetByUserID(user.ID, exportPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get complaints: %w", err)
+		}
+
+		for _, complaint := range complaints {
+			images, err := s.complaintRepo.GetComplaintImages(complaint.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get complaint images: %w", err)
+			}
+			export.Complaints = append(export.Complaints, models.ComplaintWithImages{Complaint: *complaint, Images: images})
+
+			if err := s.collectComplaintHistory(export, complaint.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(complaints) < exportPageSize {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += exportPageSize {
+		proposals, err := s.proposalRepo.GetByUserID(user.ID, exportPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get proposals: %w", err)
+		}
+
+		for _, proposal := range proposals {
+			images, err := s.proposalRepo.GetProposalImages(proposal.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get proposal images: %w", err)
+			}
+			export.Proposals = append(export.Proposals, models.ProposalWithImages{Proposal: *proposal, Images: images})
+		}
+
+		if len(proposals) < exportPageSize {
+			break
+		}
+	}
+
+	return export, nil
+}
+
+// collectComplaintHistory adds the replies, earlier versions and rating of a
+// complaint to an export. Staff Telegram IDs are not the parent's data and are
+// left out; the names they were shown stay.
+func (s *PrivacyService) collectComplaintHistory(export *models.UserDataExport, complaintID int) error {
+	replies, err := s.complaintRepo.GetReplies(complaintID)
+	if err != nil {
+		return fmt.Errorf("failed to get complaint replies: %w", err)
+	}
+	for _, reply := range replies {
+		reply.AdminTelegramID = 0
+		export.Replies = append(export.Replies, reply)
+	}
+
+	versions, err := s.complaintRepo.GetVersions(complaintID)
+	if err != nil {
+		return fmt.Errorf("failed to get complaint versions: %w", err)
+	}
+	export.Versions = append(export.Versions, versions...)
+
+	rating, err := s.ratingRepo.GetByComplaintID(complaintID)
+	if err != nil {
+		return fmt.Errorf("failed to get complaint rating: %w", err)
+	}
+	if rating != nil {
+		rating.StaffTelegramID = 0
+		export.Ratings = append(export.Ratings, *rating)
+	}
+
+	return nil
+}
+
+// ExportUserData builds a ZIP archive with a JSON dump of the user's data and their PDFs.
+// Returns the file path and filename; the caller must delete the file when done.
+func (s *PrivacyService) ExportUserData(user *models.User) (filePath, filename string, err error) {
+	export, err := s.CollectUserData(user)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := os.MkdirAll(s.tempDir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	filename = fmt.Sprintf("my_data_%s.zip", time.Now().Format("20060102_150405"))
+	filePath = filepath.Join(s.tempDir, fmt.Sprintf("export_%d_%s", user.TelegramID, filename))
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(filePath)
+		}
+	}()
+	defer out.Close()
+
+	archive := zip.NewWriter(out)
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode user data: %w", err)
+	}
+
+	w, err := archive.Create("data.json")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to add data.json: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", "", fmt.Errorf("failed to write data.json: %w", err)
+	}
+
+	// PDFs are stored on Telegram only - a missing file must not fail the whole export
+	for _, complaint := range export.Complaints {
+		name := fmt.Sprintf("complaints/%d_%s", complaint.ID, filepath.Base(complaint.PDFFilename))
+		if err := s.addTelegramFile(archive, complaint.PDFTelegramFileID, name); err != nil {
+			log.Printf("Failed to export complaint %d PDF: %v", complaint.ID, err)
+		}
+	}
+
+	for _, version := range export.Versions {
+		name := fmt.Sprintf("complaints/versions/%d_v%d_%s", version.ComplaintID, version.Version, filepath.Base(version.PDFFilename))
+		if err := s.addTelegramFile(archive, version.PDFTelegramFileID, name); err != nil {
+			log.Printf("Failed to export complaint %d version %d PDF: %v", version.ComplaintID, version.Version, err)
+		}
+	}
+
+	for _, proposal := range export.Proposals {
+		name := fmt.Sprintf("proposals/%d_%s", proposal.ID, filepath.Base(proposal.PDFFilename))
+		if err := s.addTelegramFile(archive, proposal.PDFTelegramFileID, name); err != nil {
+			log.Printf("Failed to export proposal %d PDF: %v", proposal.ID, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return filePath, filename, nil
+}
+
+// addTelegramFile downloads a Telegram file and stores it in the archive under name
+func (s *PrivacyService) addTelegramFile(archive *zip.Writer, fileID, name string) error {
+	if fileID == "" {
+		return fmt.Errorf("no file ID")
+	}
+
+	tmp, err := os.CreateTemp(s.tempDir, "export_file_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	if err := s.telegramService.DownloadFile(fileID, tmpPath); err != nil {
+		return err
+	}
+
+	in, err := os.Open(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to open downloaded file: %w", err)
+	}
+	defer in.Close()
+
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add file to archive: %w", err)
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		return fmt.Errorf("failed to write file to archive: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAccount permanently deletes the user, their complaints, proposals and images,
+// and clears their conversation state
+func (s *PrivacyService) DeleteAccount(user *models.User) error {
+	if err := s.userRepo.DeleteWithData(user.ID); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	if err := s.stateManager.Delete(user.TelegramID); err != nil {
+		return fmt.Errorf("failed to clear user state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"anor-kids/internal/config"
+	"anor-kids/internal/database"
+	"anor-kids/internal/encryption"
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+)
+
+// newTestDB connects a fresh database with all SQLite migrations applied.
+// Migrations are looked up from the repository root, as the bot does.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	t.Chdir("../..")
+
+	if err := database.Connect(&config.DatabaseConfig{Path: dbPath}); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	migrations, err := filepath.Glob("internal/database/migrations/*.sql")
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	for _, path := range migrations {
+		// 001_initial.sql is the PostgreSQL schema
+		if filepath.Base(path) == "001_initial.sql" {
+			continue
+		}
+		if err := database.RunMigrations(path); err != nil {
+			t.Fatalf("RunMigrations(%s) error = %v", path, err)
+		}
+	}
+
+	return database.DB
+}
+
+func TestCollectUserDataComplaintHistory(t *testing.T) {
+	db := newTestDB(t)
+
+	cipher, err := encryption.New(bytes.Repeat([]byte{1}, encryption.KeySize))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := cipher.AddDataKey(1, bytes.Repeat([]byte{2}, encryption.KeySize), true); err != nil {
+		t.Fatalf("AddDataKey() error = %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(db, cipher)
+	complaintRepo := repository.NewComplaintRepository(db, cipher)
+	proposalRepo := repository.NewProposalRepository(db, cipher)
+	ratingRepo := repository.NewRatingRepository(db, cipher)
+	service := NewPrivacyService(userRepo, complaintRepo, proposalRepo, ratingRepo, nil, nil, t.TempDir())
+
+	user, err := userRepo.Create(&models.CreateUserRequest{
+		TelegramID:  1001,
+		PhoneNumber: "+998901234567",
+		ChildName:   "Aliyev Vali",
+		ChildClass:  "9A",
+		Language:    "uz",
+	})
+	if err != nil {
+		t.Fatalf("Create(user) error = %v", err)
+	}
+
+	complaint, err := complaintRepo.Create(&models.CreateComplaintRequest{
+		UserID:            user.ID,
+		ComplaintText:     "Birinchi matn / Первый текст",
+		PDFTelegramFileID: "file-v1",
+		PDFFilename:       "complaint_v1.pdf",
+	})
+	if err != nil {
+		t.Fatalf("Create(complaint) error = %v", err)
+	}
+
+	if ok, err := complaintRepo.Revise(complaint.ID, user.ID, "Tuzatilgan matn / Исправленный текст"); err != nil || !ok {
+		t.Fatalf("Revise() = %v, %v", ok, err)
+	}
+	if _, err := complaintRepo.CreateReply(complaint.ID, 555, "Dilnoza", "Javob / Ответ"); err != nil {
+		t.Fatalf("CreateReply() error = %v", err)
+	}
+	if ok, err := complaintRepo.UpdateStatus(complaint.ID, 2, models.StatusReviewed); err != nil || !ok {
+		t.Fatalf("UpdateStatus() = %v, %v", ok, err)
+	}
+	if _, err := ratingRepo.CreateRequest(complaint.ID, 555, "Dilnoza"); err != nil {
+		t.Fatalf("CreateRequest() error = %v", err)
+	}
+	if ok, err := ratingRepo.SetRating(complaint.ID, 4); err != nil || !ok {
+		t.Fatalf("SetRating() = %v, %v", ok, err)
+	}
+	if ok, err := ratingRepo.SetComment(complaint.ID, "Rahmat / Спасибо"); err != nil || !ok {
+		t.Fatalf("SetComment() = %v, %v", ok, err)
+	}
+
+	export, err := service.CollectUserData(user)
+	if err != nil {
+		t.Fatalf("CollectUserData() error = %v", err)
+	}
+
+	if len(export.Complaints) != 1 {
+		t.Fatalf("got %d complaints, want 1", len(export.Complaints))
+	}
+
+	if len(export.Replies) != 1 {
+		t.Fatalf("got %d replies, want 1", len(export.Replies))
+	}
+	reply := export.Replies[0]
+	if reply.ComplaintID != complaint.ID || reply.ReplyText != "Javob / Ответ" || reply.AdminName != "Dilnoza" {
+		t.Errorf("reply = %+v, want the decrypted reply of complaint %d", reply, complaint.ID)
+	}
+	if reply.AdminTelegramID != 0 {
+		t.Errorf("reply staff Telegram ID = %d, want it left out", reply.AdminTelegramID)
+	}
+
+	if len(export.Versions) != 1 {
+		t.Fatalf("got %d versions, want 1", len(export.Versions))
+	}
+	version := export.Versions[0]
+	if version.ComplaintID != complaint.ID || version.ComplaintText != "Birinchi matn / Первый текст" || version.PDFTelegramFileID != "file-v1" {
+		t.Errorf("version = %+v, want the decrypted first text of complaint %d", version, complaint.ID)
+	}
+
+	if len(export.Ratings) != 1 {
+		t.Fatalf("got %d ratings, want 1", len(export.Ratings))
+	}
+	rating := export.Ratings[0]
+	if rating.ComplaintID != complaint.ID || rating.Rating == nil || *rating.Rating != 4 || rating.Comment != "Rahmat / Спасибо" {
+		t.Errorf("rating = %+v, want 4 with the decrypted comment", rating)
+	}
+	if rating.StaffTelegramID != 0 {
+		t.Errorf("rating staff Telegram ID = %d, want it left out", rating.StaffTelegramID)
+	}
+}
+
+func TestCollectUserDataEmpty(t *testing.T) {
+	db := newTestDB(t)
+
+	userRepo := repository.NewUserRepository(db, nil)
+	service := NewPrivacyService(userRepo, repository.NewComplaintRepository(db, nil), repository.NewProposalRepository(db, nil), repository.NewRatingRepository(db, nil), nil, nil, t.TempDir())
+
+	user, err := userRepo.Create(&models.CreateUserRequest{
+		TelegramID:  1002,
+		PhoneNumber: "+998907654321",
+		ChildName:   "Karimova Laylo",
+		ChildClass:  "5B",
+		Language:    "ru",
+	})
+	if err != nil {
+		t.Fatalf("Create(user) error = %v", err)
+	}
+
+	export, err := service.CollectUserData(user)
+	if err != nil {
+		t.Fatalf("CollectUserData() error = %v", err)
+	}
+
+	// Empty lists, not null, so the JSON lists every section
+	if export.Replies == nil || export.Versions == nil || export.Ratings == nil {
+		t.Errorf("export = %+v, want empty replies, versions and ratings", export)
+	}
+}
+
+func TestDeletionRemovesDocumentJobs(t *testing.T) {
+	db := newTestDB(t)
+
+	userRepo := repository.NewUserRepository(db, nil)
+	complaintRepo := repository.NewComplaintRepository(db, nil)
+	proposalRepo := repository.NewProposalRepository(db, nil)
+	jobRepo := repository.NewDocumentJobRepository(db)
+
+	countJobs := func() int {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM document_jobs`).Scan(&count); err != nil {
+			t.Fatalf("count document jobs: %v", err)
+		}
+		return count
+	}
+
+	for i, telegramID := range []int64{1003, 1004} {
+		user, err := userRepo.Create(&models.CreateUserRequest{
+			TelegramID:  telegramID,
+			PhoneNumber: fmt.Sprintf("+99890765432%d", i),
+			ChildName:   "Karimova Laylo",
+			ChildClass:  "5B",
+			Language:    "uz",
+		})
+		if err != nil {
+			t.Fatalf("Create(user) error = %v", err)
+		}
+
+		complaint, err := complaintRepo.Create(&models.CreateComplaintRequest{UserID: user.ID, ComplaintText: "Shikoyat matni / Текст жалобы"})
+		if err != nil {
+			t.Fatalf("Create(complaint) error = %v", err)
+		}
+		proposal, err := proposalRepo.Create(&models.CreateProposalRequest{UserID: user.ID, ProposalText: "Taklif matni / Текст предложения"})
+		if err != nil {
+			t.Fatalf("Create(proposal) error = %v", err)
+		}
+		if _, err := jobRepo.Create(models.DocumentJobKindComplaint, complaint.ID, telegramID, 0); err != nil {
+			t.Fatalf("Create(complaint job) error = %v", err)
+		}
+		if _, err := jobRepo.Create(models.DocumentJobKindProposal, proposal.ID, telegramID, 0); err != nil {
+			t.Fatalf("Create(proposal job) error = %v", err)
+		}
+
+		// The first parent deletes their account, the second one's items are purged
+		if i == 0 {
+			if err := userRepo.DeleteWithData(user.ID); err != nil {
+				t.Fatalf("DeleteWithData() error = %v", err)
+			}
+		} else {
+			retentionRepo := repository.NewRetentionRepository(db, nil)
+			for _, table := range []string{"complaints", "proposals"} {
+				if _, err := retentionRepo.Purge(table, "+1 day"); err != nil {
+					t.Fatalf("Purge(%s) error = %v", table, err)
+				}
+			}
+		}
+
+		if got := countJobs(); got != 0 {
+			t.Errorf("parent %d: %d document jobs left, want 0", i, got)
+		}
+	}
+}
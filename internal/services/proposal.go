@@ -103,6 +103,16 @@ func (s *ProposalService) UpdateProposalStatus(id int, status string) error {
 	return nil
 }
 
+// UpdateProposalPDF sets the generated PDF of a proposal
+func (s *ProposalService) UpdateProposalPDF(id int, fileID, filename string) error {
+	err := s.repo.UpdatePDF(id, fileID, filename)
+	if err != nil {
+		return fmt.Errorf("failed to update proposal PDF: %w", err)
+	}
+
+	return nil
+}
+
 // CountProposals counts total proposals
 func (s *ProposalService) CountProposals() (int, error) {
 	count, err := s.repo.Count()
@@ -133,6 +143,16 @@ func (s *ProposalService) CountUserProposals(userID int) (int, error) {
 	return count, nil
 }
 
+// GetProposalTimeline gets when a proposal changed status, nil if it doesn't exist
+func (s *ProposalService) GetProposalTimeline(id int) (*models.SubmissionTimeline, error) {
+	timeline, err := s.repo.GetTimeline(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposal timeline: %w", err)
+	}
+
+	return timeline, nil
+}
+
 // CreateProposalImage adds an image to a proposal
 func (s *ProposalService) CreateProposalImage(proposalID int, image *models.ImageData, orderIndex int) error {
 	err := s.repo.CreateProposalImage(proposalID, image, orderIndex)
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"anor-kids/internal/models"
+	"anor-kids/internal/registry"
+	"anor-kids/internal/repository"
+)
+
+// RegistryService records generated PDFs in the hash-chained document registry
+// and verifies documents against it
+type RegistryService struct {
+	repo *repository.RegistryRepository
+	mu   sync.Mutex // Serializes appends so each entry links to the latest one
+}
+
+// NewRegistryService creates a new registry service
+func NewRegistryService(repo *repository.RegistryRepository) *RegistryService {
+	return &RegistryService{repo: repo}
+}
+
+// Verification is the result of checking a code or document against the registry
+type Verification struct {
+	Entry         *models.RegistryEntry `json:"entry"`           // nil if the code or document is not registered
+	ChainIntact   bool                  `json:"chain_intact"`    // Every entry's hash verifies
+	ChainLength   int                   `json:"chain_length"`    // Number of entries checked
+	BrokenEntryID int                   `json:"broken_entry_id"` // First entry that fails verification, 0 if intact
+}
+
+// Authentic reports whether the code or document is registered and its entry
+// comes before any break in the chain
+func (v *Verification) Authentic() bool {
+	if v.Entry == nil {
+		return false
+	}
+	return v.ChainIntact || v.Entry.ID < v.BrokenEntryID
+}
+
+// Register hashes a generated PDF and appends it to the registry under its verification code
+func (s *RegistryService) Register(code, kind string, itemID int, filePath string) (*models.RegistryEntry, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	documentHash := registry.DocumentHash(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, err := s.repo.GetLast()
+	if err != nil {
+		return nil, err
+	}
+
+	prevHash := registry.GenesisHash
+	if last != nil {
+		prevHash = last.EntryHash
+	}
+
+	entry := registry.NewEntry(prevHash, code, kind, itemID, documentHash, time.Now())
+	if err := s.repo.Create(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// VerifyCode looks up a verification code and checks the chain
+func (s *RegistryService) VerifyCode(code string) (*Verification, error) {
+	entry, err := s.repo.GetByCode(registry.NormalizeCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verify(entry)
+}
+
+// VerifyDocument looks up a document by its SHA-256 and checks the chain. A
+// document that was edited after generation is not found.
+func (s *RegistryService) VerifyDocument(data []byte) (*Verification, error) {
+	entry, err := s.repo.GetByDocumentHash(registry.DocumentHash(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verify(entry)
+}
+
+// verify checks the whole chain and reports it along with entry
+func (s *RegistryService) verify(entry *models.RegistryEntry) (*Verification, error) {
+	entries, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Verification{
+		Entry:       entry,
+		ChainIntact: true,
+		ChainLength: len(entries),
+	}
+
+	if broken := registry.VerifyChain(entries); broken >= 0 {
+		result.ChainIntact = false
+		result.BrokenEntryID = entries[broken].ID
+	}
+
+	return result, nil
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"anor-kids/internal/config"
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+)
+
+// reportCheckInterval is how often the scheduler looks for due reports
+const reportCheckInterval = 10 * time.Minute
+
+// DefaultReportHour is the hour reports are sent at unless an admin picks another
+const DefaultReportHour = 8
+
+// ReportService compiles the weekly and monthly admin reports and sends them
+// to subscribed admins on schedule
+type ReportService struct {
+	reportRepo       *repository.ReportRepository
+	statsRepo        *repository.StatsRepository
+	userRepo         *repository.UserRepository
+	announcementRepo *repository.AnnouncementRepository
+	documents        *DocumentService
+	telegram         *TelegramService
+	cfg              config.ReportConfig
+}
+
+// NewReportService creates a new report service
+func NewReportService(
+	reportRepo *repository.ReportRepository,
+	statsRepo *repository.StatsRepository,
+	userRepo *repository.UserRepository,
+	announcementRepo *repository.AnnouncementRepository,
+	documents *DocumentService,
+	telegram *TelegramService,
+	cfg config.ReportConfig,
+) *ReportService {
+	return &ReportService{
+		reportRepo:       reportRepo,
+		statsRepo:        statsRepo,
+		userRepo:         userRepo,
+		announcementRepo: announcementRepo,
+		documents:        documents,
+		telegram:         telegram,
+		cfg:              cfg,
+	}
+}
+
+// IsReportFrequency reports whether s is a known report frequency
+func IsReportFrequency(s string) bool {
+	return s == models.ReportWeekly || s == models.ReportMonthly
+}
+
+// StaleAfterDays returns the age in days from which pending items are listed as unresolved
+func (s *ReportService) StaleAfterDays() int {
+	return s.cfg.StaleAfterDays
+}
+
+// Subscription returns an admin's subscription, nil if they are not subscribed
+func (s *ReportService) Subscription(adminID int) (*models.ReportSubscription, error) {
+	return s.reportRepo.GetByAdminID(adminID)
+}
+
+// Subscribe subscribes an admin to reports or changes their schedule.
+// Reports already due when the schedule changes are not sent.
+func (s *ReportService) Subscribe(adminID int, frequency string, sendHour int) error {
+	if !IsReportFrequency(frequency) {
+		return fmt.Errorf("unknown report frequency %q", frequency)
+	}
+	if sendHour < 0 || sendHour > 23 {
+		return fmt.Errorf("invalid report hour %d", sendHour)
+	}
+	return s.reportRepo.Subscribe(adminID, frequency, sendHour)
+}
+
+// Unsubscribe stops an admin's reports
+func (s *ReportService) Unsubscribe(adminID int) error {
+	return s.reportRepo.Unsubscribe(adminID)
+}
+
+// lastScheduled returns the most recent time a report was due at or before now:
+// Monday (weekly) or the 1st (monthly) at the send hour, in now's location
+func lastScheduled(frequency string, sendHour int, now time.Time) time.Time {
+	if frequency == models.ReportMonthly {
+		due := time.Date(now.Year(), now.Month(), 1, sendHour, 0, 0, 0, now.Location())
+		if now.Before(due) {
+			due = due.AddDate(0, -1, 0)
+		}
+		return due
+	}
+
+	due := weekStart(now).Add(time.Duration(sendHour) * time.Hour)
+	if now.Before(due) {
+		due = due.AddDate(0, 0, -7)
+	}
+	return due
+}
+
+// reportPeriod returns the last full week or month before at: [from, to)
+func reportPeriod(frequency string, at time.Time) (from, to time.Time) {
+	if frequency == models.ReportMonthly {
+		to = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, at.Location())
+		return to.AddDate(0, -1, 0), to
+	}
+
+	to = weekStart(at)
+	return to.AddDate(0, 0, -7), to
+}
+
+// Compile gathers the report of the last full week or month before at
+func (s *ReportService) Compile(frequency string, at time.Time) (*models.AdminReport, error) {
+	from, to := reportPeriod(frequency, at)
+
+	report := &models.AdminReport{
+		Frequency:      frequency,
+		From:           from,
+		To:             to,
+		StaleAfterDays: s.cfg.StaleAfterDays,
+		GeneratedAt:    at,
+	}
+
+	submissions, err := s.statsRepo.GetSubmissions(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var inPeriod []*models.Submission
+	var hours []float64
+	for _, submission := range submissions {
+		if !submission.CreatedAt.Before(to) {
+			continue
+		}
+		inPeriod = append(inPeriod, submission)
+
+		if submission.Kind == models.DocumentJobKindComplaint {
+			report.Complaints++
+		} else {
+			report.Proposals++
+		}
+
+		if h, ok := reviewHours(submission); ok {
+			hours = append(hours, h)
+		}
+	}
+	report.Classes = classStats(inPeriod)
+	report.Categories = categoryStats(inPeriod)
+	report.Statuses = statusStats(inPeriod)
+	report.MedianReview = median(hours)
+
+	if report.Unresolved, err = s.statsRepo.GetUnresolved(at.AddDate(0, 0, -s.cfg.StaleAfterDays)); err != nil {
+		return nil, err
+	}
+	sort.Slice(report.Unresolved, func(i, j int) bool {
+		return report.Unresolved[i].CreatedAt.Before(report.Unresolved[j].CreatedAt)
+	})
+
+	if report.Registrations, err = s.statsRepo.CountRegistrations(from, to); err != nil {
+		return nil, err
+	}
+
+	if report.PendingApprovals, err = s.userRepo.CountByApprovalStatus(models.ApprovalPending); err != nil {
+		return nil, err
+	}
+
+	if report.Announcements, err = s.announcementRepo.GetReach(from, to); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Send compiles the report of the last full period before now and sends it to a chat
+func (s *ReportService) Send(chatID int64, frequency string, now time.Time) error {
+	_, err := s.upload(chatID, frequency, now)
+	return err
+}
+
+// Start starts the background scheduler that sends due reports
+func (s *ReportService) Start() {
+	go func() {
+		ticker := time.NewTicker(reportCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			s.sendDue(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// sendDue sends the reports whose time has come since they were last sent or
+// subscribed to. A report missed while the bot was down is sent on the next check.
+func (s *ReportService) sendDue(now time.Time) {
+	subs, err := s.reportRepo.GetAll()
+	if err != nil {
+		log.Printf("Failed to get report subscriptions: %v", err)
+		return
+	}
+
+	// Admins with the same schedule share one PDF
+	type delivery struct {
+		fileID string
+		err    error
+	}
+	sent := make(map[time.Time]map[string]*delivery)
+
+	for _, sub := range subs {
+		due := lastScheduled(sub.Frequency, sub.SendHour, now)
+
+		since := sub.UpdatedAt
+		if sub.LastSentAt != nil && sub.LastSentAt.After(since) {
+			since = *sub.LastSentAt
+		}
+		if !due.After(since) {
+			continue
+		}
+
+		if sent[due] == nil {
+			sent[due] = make(map[string]*delivery)
+		}
+
+		var err error
+		if d := sent[due][sub.Frequency]; d == nil {
+			d = &delivery{}
+			d.fileID, d.err = s.upload(*sub.TelegramID, sub.Frequency, due)
+			sent[due][sub.Frequency] = d
+			err = d.err
+		} else if d.err != nil {
+			err = d.err // retried for everyone on the next check
+		} else {
+			err = s.telegram.SendDocumentByFileID(*sub.TelegramID, d.fileID, "", nil)
+		}
+
+		if err != nil {
+			log.Printf("Failed to send %s report to admin %d: %v", sub.Frequency, sub.AdminID, err)
+			continue
+		}
+
+		if err := s.reportRepo.MarkSent(sub.ID, now); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		log.Printf("✓ %s report sent to admin %d", sub.Frequency, sub.AdminID)
+	}
+}
+
+// upload renders the report due at a time and uploads it to a chat,
+// returning the file ID to forward it to other admins
+func (s *ReportService) upload(chatID int64, frequency string, due time.Time) (string, error) {
+	report, err := s.Compile(frequency, due)
+	if err != nil {
+		return "", err
+	}
+
+	filePath, filename, err := s.documents.GenerateReportPDF(report)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(filePath)
+
+	return s.telegram.UploadDocument(chatID, filePath, filename)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"anor-kids/internal/config"
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+	"anor-kids/internal/state"
+)
+
+// retentionDryRunGrace is how long super-admins have to review the dry-run
+// report before the first real retention run
+const retentionDryRunGrace = 24 * time.Hour
+
+// RetentionService applies the data retention rules
+type RetentionService struct {
+	repo         *repository.RetentionRepository
+	stateManager *state.Manager
+	cfg          config.RetentionConfig
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(repo *repository.RetentionRepository, stateManager *state.Manager, cfg config.RetentionConfig) *RetentionService {
+	return &RetentionService{
+		repo:         repo,
+		stateManager: stateManager,
+		cfg:          cfg,
+	}
+}
+
+// Enabled reports whether any retention rule is configured
+func (s *RetentionService) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+// DryRun reports what a retention run would change without changing anything
+func (s *RetentionService) DryRun() (*models.RetentionReport, error) {
+	report := &models.RetentionReport{DryRun: true, RunAt: time.Now()}
+	var err error
+
+	if s.cfg.ArchiveAfterDays > 0 {
+		modifier := daysModifier(s.cfg.ArchiveAfterDays)
+		if report.ArchivedComplaints, err = s.repo.CountArchivable("complaints", modifier); err != nil {
+			return nil, err
+		}
+		if report.ArchivedProposals, err = s.repo.CountArchivable("proposals", modifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.cfg.PurgeAfterMonths > 0 {
+		modifier := monthsModifier(s.cfg.PurgeAfterMonths)
+		if report.PurgedComplaints, err = s.repo.CountPurgeable("complaints", modifier); err != nil {
+			return nil, err
+		}
+		if report.PurgedProposals, err = s.repo.CountPurgeable("proposals", modifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.cfg.InactiveUserMonths > 0 {
+		users, err := s.repo.GetInactiveUsers(monthsModifier(s.cfg.InactiveUserMonths))
+		if err != nil {
+			return nil, err
+		}
+		report.DeletedUsers = len(users)
+	}
+
+	return report, nil
+}
+
+// Apply runs the retention rules
+func (s *RetentionService) Apply() (*models.RetentionReport, error) {
+	report := &models.RetentionReport{RunAt: time.Now()}
+	var err error
+
+	if s.cfg.ArchiveAfterDays > 0 {
+		modifier := daysModifier(s.cfg.ArchiveAfterDays)
+		if report.ArchivedComplaints, err = s.repo.Archive("complaints", modifier); err != nil {
+			return nil, err
+		}
+		if report.ArchivedProposals, err = s.repo.Archive("proposals", modifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.cfg.PurgeAfterMonths > 0 {
+		modifier := monthsModifier(s.cfg.PurgeAfterMonths)
+		if report.PurgedComplaints, err = s.repo.Purge("complaints", modifier); err != nil {
+			return nil, err
+		}
+		if report.PurgedProposals, err = s.repo.Purge("proposals", modifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.cfg.InactiveUserMonths > 0 {
+		users, err := s.repo.GetInactiveUsers(monthsModifier(s.cfg.InactiveUserMonths))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			if err := s.repo.DeleteUserKeepingStats(user.ID); err != nil {
+				log.Printf("Failed to delete inactive user %d: %v", user.ID, err)
+				continue
+			}
+			_ = s.stateManager.Delete(user.TelegramID)
+			report.DeletedUsers++
+		}
+	}
+
+	return report, nil
+}
+
+// RunScheduled is called by the background routine. The first time it only
+// records a dry run; real runs start once the dry run is older than
+// retentionDryRunGrace. Returns the report to send to super-admins, or nil
+// if nothing ran.
+func (s *RetentionService) RunScheduled() (*models.RetentionReport, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+
+	lastRun, err := s.repo.GetLastRun(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastRun == nil {
+		lastDryRun, err := s.repo.GetLastRun(true)
+		if err != nil {
+			return nil, err
+		}
+
+		if lastDryRun == nil {
+			report, err := s.DryRun()
+			if err != nil {
+				return nil, err
+			}
+			if err := s.repo.CreateRun(report); err != nil {
+				return nil, err
+			}
+			return report, nil
+		}
+
+		if time.Since(lastDryRun.RunAt) < retentionDryRunGrace {
+			return nil, nil
+		}
+	}
+
+	report, err := s.Apply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply retention rules: %w", err)
+	}
+
+	if err := s.repo.CreateRun(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Config returns the configured retention rules
+func (s *RetentionService) Config() config.RetentionConfig {
+	return s.cfg
+}
+
+// daysModifier returns an SQLite datetime modifier for N days ago
+func daysModifier(days int) string {
+	return fmt.Sprintf("-%d days", days)
+}
+
+// monthsModifier returns an SQLite datetime modifier for N months ago
+func monthsModifier(months int) string {
+	return fmt.Sprintf("-%d months", months)
+}
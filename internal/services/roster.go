@@ -0,0 +1,368 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+	"anor-kids/internal/utils"
+	"anor-kids/internal/validator"
+	"anor-kids/pkg/xlsx"
+)
+
+// Roster import limits
+const (
+	RosterMaxFileSize     = 5 * 1024 * 1024
+	rosterMaxRows         = 5000
+	rosterMaxClassNameLen = 20 // class names go into callback data, limited to 64 bytes
+)
+
+// ErrRosterUnreadable is returned when a roster file is neither CSV nor XLSX or has no usable columns
+var ErrRosterUnreadable = errors.New("roster file is not a readable CSV or XLSX table")
+
+// ErrRosterTooLarge is returned when a roster has more rows than one import takes
+var ErrRosterTooLarge = fmt.Errorf("roster has more than %d rows", rosterMaxRows)
+
+// RosterRowError is a roster row that was not imported
+type RosterRowError struct {
+	Row    int // row number as shown by the spreadsheet app
+	Reason string
+}
+
+// RosterImportReport is the outcome of a roster import
+type RosterImportReport struct {
+	ClassesCreated  []string
+	InactiveClasses []string // listed classes that exist but are switched off
+	Added           int      // parents pre-registered
+	Updated         int      // parents already on the roster, child or class replaced
+	Registered      int      // parents skipped because they already use the bot
+	Errors          []RosterRowError
+}
+
+// rosterColumn identifies a roster column by the words its header may contain
+type rosterColumn struct {
+	keywords []string
+}
+
+var (
+	rosterPhoneColumn = rosterColumn{keywords: []string{"telefon", "телефон", "phone", "tel"}}
+	rosterClassColumn = rosterColumn{keywords: []string{"guruh", "групп", "class", "sinf", "класс"}}
+	rosterChildColumn = rosterColumn{keywords: []string{"bola", "ребен", "ребён", "child", "ism", "имя", "фио", "fio", "name"}}
+)
+
+// matches reports whether a header cell names the column
+func (c rosterColumn) matches(header string) bool {
+	header = strings.ToLower(strings.TrimSpace(header))
+	for _, keyword := range c.keywords {
+		if strings.Contains(header, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// rosterRow is a non-empty roster row with its spreadsheet row number
+type rosterRow struct {
+	number int
+	cells  []string
+}
+
+// cell returns the trimmed cell of a column, "" if the row is shorter
+func (r rosterRow) cell(index int) string {
+	if index < 0 || index >= len(r.cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.cells[index])
+}
+
+// RosterService imports classes and expected parents from CSV/XLSX rosters
+// and hands the roster data to parents when they register
+type RosterService struct {
+	rosterRepo *repository.RosterRepository
+	classRepo  *repository.ClassRepository
+	userRepo   *repository.UserRepository
+}
+
+// NewRosterService creates a new roster service
+func NewRosterService(
+	rosterRepo *repository.RosterRepository,
+	classRepo *repository.ClassRepository,
+	userRepo *repository.UserRepository,
+) *RosterService {
+	return &RosterService{
+		rosterRepo: rosterRepo,
+		classRepo:  classRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Import reads a roster file and creates its classes and pre-registers its parents.
+// Columns are found by their header (phone, child, class in Uzbek, Russian or English);
+// without a header they are taken in that order. A row with only a class creates the
+// class. Invalid rows are skipped and listed in the report; the rest are imported.
+func (s *RosterService) Import(data []byte) (*RosterImportReport, error) {
+	rows, err := readRosterRows(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > rosterMaxRows {
+		return nil, ErrRosterTooLarge
+	}
+
+	phoneCol, childCol, classCol := 0, 1, 2
+	if len(rows) > 0 && isRosterHeader(rows[0]) {
+		phoneCol, childCol, classCol = -1, -1, -1
+		for i, header := range rows[0].cells {
+			switch {
+			case phoneCol < 0 && rosterPhoneColumn.matches(header):
+				phoneCol = i
+			case classCol < 0 && rosterClassColumn.matches(header):
+				classCol = i
+			case childCol < 0 && rosterChildColumn.matches(header):
+				childCol = i
+			}
+		}
+		if classCol < 0 {
+			return nil, fmt.Errorf("%w: no class column", ErrRosterUnreadable)
+		}
+		rows = rows[1:]
+	}
+
+	report := &RosterImportReport{}
+	classes := make(map[string]bool)  // class name -> checked
+	phoneRows := make(map[string]int) // phone -> row it was first seen on
+
+	for _, row := range rows {
+		className, reason := validateRosterClass(row.cell(classCol))
+		if reason != "" {
+			report.Errors = append(report.Errors, RosterRowError{Row: row.number, Reason: reason})
+			continue
+		}
+
+		phone, childName := row.cell(phoneCol), row.cell(childCol)
+
+		var entry *models.RosterEntry
+		if phone != "" || childName != "" {
+			entry, reason = validateRosterParent(phone, childName, className)
+			if reason == "" {
+				if first, seen := phoneRows[entry.PhoneNumber]; seen {
+					reason = fmt.Sprintf("telefon %d-qatorda takrorlangan / телефон повторяет строку %d", first, first)
+				}
+			}
+			if reason != "" {
+				report.Errors = append(report.Errors, RosterRowError{Row: row.number, Reason: reason})
+				continue
+			}
+			phoneRows[entry.PhoneNumber] = row.number
+		}
+
+		if !classes[className] {
+			if err := s.ensureClass(className, report); err != nil {
+				return nil, err
+			}
+			classes[className] = true
+		}
+
+		if entry != nil {
+			if err := s.saveEntry(entry, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// ensureClass creates a roster class unless it exists
+func (s *RosterService) ensureClass(className string, report *RosterImportReport) error {
+	class, err := s.classRepo.GetByName(className)
+	if err != nil {
+		return err
+	}
+
+	if class == nil {
+		if _, err := s.classRepo.Create(className); err != nil {
+			return err
+		}
+		report.ClassesCreated = append(report.ClassesCreated, className)
+		return nil
+	}
+
+	if !class.IsActive {
+		report.InactiveClasses = append(report.InactiveClasses, className)
+	}
+	return nil
+}
+
+// saveEntry pre-registers a parent unless the phone already uses the bot
+func (s *RosterService) saveEntry(entry *models.RosterEntry, report *RosterImportReport) error {
+	user, err := s.userRepo.GetByPhoneNumber(entry.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	if user != nil {
+		report.Registered++
+		return nil
+	}
+
+	existing, err := s.rosterRepo.GetByPhoneNumber(entry.PhoneNumber)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rosterRepo.Upsert(entry.PhoneNumber, entry.ChildName, entry.ChildClass); err != nil {
+		return err
+	}
+
+	if existing != nil {
+		report.Updated++
+	} else {
+		report.Added++
+	}
+	return nil
+}
+
+// Lookup returns the roster entry of a registering phone if its class is open
+// for registration, nil otherwise
+func (s *RosterService) Lookup(phoneNumber string) (*models.RosterEntry, error) {
+	entry, err := s.rosterRepo.GetByPhoneNumber(phoneNumber)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	active, err := s.classRepo.Exists(entry.ChildClass)
+	if err != nil || !active {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Claim removes a roster entry once its parent has registered
+func (s *RosterService) Claim(entry *models.RosterEntry) error {
+	return s.rosterRepo.Delete(entry.ID)
+}
+
+// Pending returns the number of pre-registered parents who haven't registered yet
+func (s *RosterService) Pending() (int, error) {
+	return s.rosterRepo.Count()
+}
+
+// validateRosterClass checks the class of a roster row, returning the reason it is invalid
+func validateRosterClass(value string) (string, string) {
+	className := utils.SanitizeClassName(value)
+	if className == "" {
+		return "", "guruh ko'rsatilmagan / группа не указана"
+	}
+	if utf8.RuneCountInString(className) > rosterMaxClassNameLen {
+		return "", fmt.Sprintf("guruh nomi juda uzun (maksimal %d) / название группы слишком длинное (максимум %d)",
+			rosterMaxClassNameLen, rosterMaxClassNameLen)
+	}
+	return className, ""
+}
+
+// validateRosterParent checks the parent of a roster row, returning the reason it is invalid
+func validateRosterParent(phone, childName, className string) (*models.RosterEntry, string) {
+	if phone == "" {
+		return nil, "telefon ko'rsatilmagan / телефон не указан"
+	}
+	validPhone, err := validator.ValidateUzbekPhone(phone)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	if childName == "" {
+		return nil, "bola ismi ko'rsatilmagan / имя ребёнка не указано"
+	}
+	validName, err := validator.ValidateName(childName)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	return &models.RosterEntry{PhoneNumber: validPhone, ChildName: validName, ChildClass: className}, ""
+}
+
+// isRosterHeader reports whether the first row is a header rather than data:
+// a header names a column, and has no cell that is a valid phone number
+func isRosterHeader(row rosterRow) bool {
+	named := false
+	for _, cell := range row.cells {
+		if _, err := validator.ValidateUzbekPhone(cell); err == nil {
+			return false
+		}
+		if rosterPhoneColumn.matches(cell) || rosterClassColumn.matches(cell) || rosterChildColumn.matches(cell) {
+			named = true
+		}
+	}
+	return named
+}
+
+// readRosterRows reads the non-empty rows of an XLSX workbook or a CSV file.
+// CSV may be separated by commas, semicolons (Excel with a Russian locale) or tabs.
+func readRosterRows(data []byte) ([]rosterRow, error) {
+	var rows []rosterRow
+
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		cells, err := xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRosterUnreadable, err)
+		}
+		for i, row := range cells {
+			rows = appendRosterRow(rows, i+1, row)
+		}
+		return rows, nil
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return nil, fmt.Errorf("%w: not UTF-8 text", ErrRosterUnreadable)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRosterUnreadable, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = appendRosterRow(rows, line, record)
+	}
+}
+
+// appendRosterRow appends a row unless all its cells are blank
+func appendRosterRow(rows []rosterRow, number int, cells []string) []rosterRow {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return append(rows, rosterRow{number: number, cells: cells})
+		}
+	}
+	return rows
+}
+
+// detectDelimiter picks the CSV delimiter that occurs most in the first line
+func detectDelimiter(data []byte) rune {
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+
+	delimiter, best := ',', bytes.Count(firstLine, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(firstLine, []byte(string(candidate))); n > best {
+			delimiter, best = candidate, n
+		}
+	}
+	return delimiter
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"anor-kids/internal/config"
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+	"anor-kids/internal/utils"
+)
+
+// slaCheckInterval is how often the checker looks for overdue items
+const slaCheckInterval = 15 * time.Minute
+
+// UrgentAlertInterval is how often admins are alerted again about an urgent
+// complaint nobody has acknowledged
+const UrgentAlertInterval = 15 * time.Minute
+
+// urgentCheckInterval is how often the checker looks for urgent complaints to alert about again
+const urgentCheckInterval = time.Minute
+
+// SLAService tracks the review deadlines of complaints and proposals and sends
+// escalating reminders about overdue ones: first to the assigned admin or the
+// class teacher, then to all admins, then to the director. Urgent complaints
+// are repeated to all admins until someone acknowledges them.
+type SLAService struct {
+	slaRepo   *repository.SLARepository
+	adminRepo *repository.AdminRepository
+	userRepo  *repository.UserRepository
+	telegram  *TelegramService
+	cfg       config.SLAConfig
+}
+
+// NewSLAService creates a new SLA service
+func NewSLAService(
+	slaRepo *repository.SLARepository,
+	adminRepo *repository.AdminRepository,
+	userRepo *repository.UserRepository,
+	telegram *TelegramService,
+	cfg config.SLAConfig,
+) *SLAService {
+	return &SLAService{
+		slaRepo:   slaRepo,
+		adminRepo: adminRepo,
+		userRepo:  userRepo,
+		telegram:  telegram,
+		cfg:       cfg,
+	}
+}
+
+// Deadline returns how long a complaint or proposal may stay pending, 0 if
+// the kind is not tracked
+func (s *SLAService) Deadline(kind string) time.Duration {
+	switch kind {
+	case models.DocumentJobKindComplaint:
+		return time.Duration(s.cfg.ComplaintHours) * time.Hour
+	case models.DocumentJobKindProposal:
+		return time.Duration(s.cfg.ProposalHours) * time.Hour
+	}
+	return 0
+}
+
+// Acknowledge records that staff took a complaint or proposal in hand.
+// Returns false if the item does not exist.
+func (s *SLAService) Acknowledge(kind string, id int, now time.Time) (bool, error) {
+	return s.slaRepo.Acknowledge(kind, id, now)
+}
+
+// MarkUrgentAlerted records that admins were alerted about an urgent complaint
+func (s *SLAService) MarkUrgentAlerted(id int, now time.Time) error {
+	return s.slaRepo.SetUrgentAlerted(id, now)
+}
+
+// AcknowledgeUrgent stops the alerts about an urgent complaint. Returns false
+// if the complaint is not urgent or someone acknowledged it before.
+func (s *SLAService) AcknowledgeUrgent(id int, telegramID int64, now time.Time) (bool, error) {
+	return s.slaRepo.AcknowledgeUrgent(id, telegramID, now)
+}
+
+// Overdue counts the pending complaints and proposals past their deadline
+func (s *SLAService) Overdue(now time.Time) (models.OverdueCounts, error) {
+	var counts models.OverdueCounts
+
+	for _, target := range []struct {
+		kind  string
+		count *int
+	}{
+		{models.DocumentJobKindComplaint, &counts.Complaints},
+		{models.DocumentJobKindProposal, &counts.Proposals},
+	} {
+		deadline := s.Deadline(target.kind)
+		if deadline == 0 {
+			continue
+		}
+
+		count, err := s.slaRepo.CountOverdue(target.kind, now.Add(-deadline))
+		if err != nil {
+			return counts, err
+		}
+		*target.count = count
+	}
+
+	return counts, nil
+}
+
+// escalationLevel returns the escalation level due for an item created at
+// created: the assignee or teacher at the deadline, then one step up every step
+func escalationLevel(created time.Time, deadline, step time.Duration, now time.Time) int {
+	late := now.Sub(created.Add(deadline))
+	if late < 0 {
+		return models.EscalationNone
+	}
+	return min(models.EscalationStaff+int(late/step), models.EscalationDirector)
+}
+
+// Start starts the background checkers that send reminders about overdue
+// items and alerts about unacknowledged urgent complaints
+func (s *SLAService) Start() {
+	go func() {
+		ticker := time.NewTicker(slaCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			s.check(time.Now())
+			<-ticker.C
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(urgentCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			s.checkUrgent(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// checkUrgent alerts all admins again about every urgent complaint nobody has
+// acknowledged within UrgentAlertInterval of the last alert
+func (s *SLAService) checkUrgent(now time.Time) {
+	items, err := s.slaRepo.GetUnacknowledgedUrgent(now.Add(-UrgentAlertInterval))
+	if err != nil {
+		log.Printf("Failed to get urgent complaints: %v", err)
+		return
+	}
+
+	if len(items) == 0 {
+		return
+	}
+
+	recipients, err := s.adminTelegramIDs()
+	if err != nil {
+		log.Printf("Failed to get urgent alert recipients: %v", err)
+		return
+	}
+
+	for _, item := range items {
+		// Record the alert first, so a failed send is retried by the next
+		// round rather than sent twice
+		if err := s.slaRepo.SetUrgentAlerted(item.ID, now); err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+
+		text := s.urgentAlertText(item, now)
+		keyboard := utils.MakeUrgentAckKeyboard(item.ID)
+		for _, chatID := range recipients {
+			if err := s.telegram.SendMessage(chatID, text, keyboard); err != nil {
+				log.Printf("Failed to send urgent alert to %d: %v", chatID, err)
+			}
+		}
+		log.Printf("✓ Urgent complaint #%d alert %d sent (%d recipients)", item.ID, item.AlertCount+1, len(recipients))
+	}
+}
+
+// urgentAlertText describes an unacknowledged urgent complaint; the complaint
+// itself was in the first alert
+func (s *SLAService) urgentAlertText(item *models.UrgentItem, now time.Time) string {
+	class := item.ChildClass
+	if class == "" {
+		class = "—"
+	}
+
+	text := "🚨 <b>SHOSHILINCH SHIKOYAT QABUL QILINMADI / СРОЧНАЯ ЖАЛОБА НЕ ПРИНЯТА</b>\n\n"
+	text += fmt.Sprintf("ID: #%d\n", item.ID)
+	text += fmt.Sprintf("Guruh / Группа: <b>%s</b>\n", class)
+	if item.Category != nil {
+		text += fmt.Sprintf("Toifa / Категория: %s\n", utils.EscapeHTML(item.Category.Label()))
+	}
+	text += fmt.Sprintf("Sana / Дата: %s\n", utils.FormatDateTime(item.CreatedAt))
+	text += fmt.Sprintf("Kutmoqda / Ожидает: %d daq / мин\n", int(now.Sub(item.CreatedAt).Minutes()))
+	text += fmt.Sprintf("Eslatma / Напоминание: #%d\n\n", item.AlertCount+1)
+	text += "Ota-ona javob kutmoqda. Qabul qilish tugmasini bosing.\n"
+	text += "Родитель ждёт ответа. Нажмите кнопку «Принято»."
+
+	return text
+}
+
+// check escalates every overdue item whose next step is due. Steps missed
+// while the bot was down are sent together, so nobody is skipped.
+func (s *SLAService) check(now time.Time) {
+	step := time.Duration(s.cfg.EscalationHours) * time.Hour
+
+	for _, kind := range []string{models.DocumentJobKindComplaint, models.DocumentJobKindProposal} {
+		deadline := s.Deadline(kind)
+		if deadline == 0 {
+			continue
+		}
+
+		items, err := s.slaRepo.GetOverdue(kind, now.Add(-deadline))
+		if err != nil {
+			log.Printf("Failed to get overdue items: %v", err)
+			return
+		}
+
+		for _, item := range items {
+			level := escalationLevel(item.CreatedAt, deadline, step, now)
+			if level <= item.EscalationLevel {
+				continue
+			}
+
+			recipients, err := s.recipients(item, level)
+			if err != nil {
+				log.Printf("Failed to get reminder recipients for %s #%d: %v", kind, item.ID, err)
+				continue
+			}
+
+			text := s.reminderText(item, level, now)
+			keyboard := utils.MakeReviewKeyboard(kind, item.ID)
+			for _, chatID := range recipients {
+				if err := s.telegram.SendMessage(chatID, text, keyboard); err != nil {
+					log.Printf("Failed to send SLA reminder to %d: %v", chatID, err)
+				}
+			}
+
+			if err := s.slaRepo.SetEscalationLevel(kind, item.ID, level, now); err != nil {
+				log.Printf("Warning: %v", err)
+				continue
+			}
+			log.Printf("✓ %s #%d escalated to level %d (%d recipients)", kind, item.ID, level, len(recipients))
+		}
+	}
+}
+
+// recipients returns who is reminded when an item reaches a level, together
+// with everyone of the steps it skipped. The first step goes to the assigned
+// admin, else the class teacher, else all admins.
+func (s *SLAService) recipients(item *models.SLAItem, level int) ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	add := func(id int64) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for step := item.EscalationLevel + 1; step <= level; step++ {
+		switch step {
+		case models.EscalationStaff:
+			if item.AssigneeTelegramID != nil {
+				add(*item.AssigneeTelegramID)
+				continue
+			}
+			if item.TeacherTelegramID != nil {
+				add(*item.TeacherTelegramID)
+				continue
+			}
+			fallthrough
+
+		case models.EscalationAdmins:
+			admins, err := s.adminTelegramIDs()
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range admins {
+				add(id)
+			}
+
+		case models.EscalationDirector:
+			directorID, err := s.directorTelegramID()
+			if err != nil {
+				return nil, err
+			}
+			if directorID == nil {
+				log.Printf("Warning: director not configured or not linked, %s #%d not escalated to the director", item.Kind, item.ID)
+				continue
+			}
+			add(*directorID)
+		}
+	}
+
+	return ids, nil
+}
+
+// adminTelegramIDs returns the Telegram IDs of all admins with a linked account
+func (s *SLAService) adminTelegramIDs() ([]int64, error) {
+	admins, err := s.adminRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, admin := range admins {
+		if admin.TelegramID != nil {
+			ids = append(ids, *admin.TelegramID)
+		}
+	}
+
+	return ids, nil
+}
+
+// directorTelegramID finds the director's Telegram account by DIRECTOR_PHONE,
+// as an admin or as a registered user. Returns nil if there is none.
+func (s *SLAService) directorTelegramID() (*int64, error) {
+	if s.cfg.DirectorPhone == "" {
+		return nil, nil
+	}
+
+	admin, err := s.adminRepo.GetByPhoneNumber(s.cfg.DirectorPhone)
+	if err != nil {
+		return nil, err
+	}
+	if admin != nil && admin.TelegramID != nil {
+		return admin.TelegramID, nil
+	}
+
+	user, err := s.userRepo.GetByPhoneNumber(s.cfg.DirectorPhone)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return &user.TelegramID, nil
+	}
+
+	return nil, nil
+}
+
+// reminderText describes an overdue item; it contains no parent data, since
+// reminders may go to people who don't see the submission itself
+func (s *SLAService) reminderText(item *models.SLAItem, level int, now time.Time) string {
+	icon := "⏰"
+	if level == models.EscalationDirector {
+		icon = "🚨"
+	}
+
+	title := icon + " <b>Muddati o'tgan shikoyat / Просроченная жалоба</b>"
+	if item.Kind == models.DocumentJobKindProposal {
+		title = icon + " <b>Muddati o'tgan taklif / Просроченное предложение</b>"
+	}
+
+	deadline := s.Deadline(item.Kind)
+	late := int(now.Sub(item.CreatedAt.Add(deadline)).Hours())
+
+	class := item.ChildClass
+	if class == "" {
+		class = "—"
+	}
+
+	text := title + "\n\n"
+	text += fmt.Sprintf("ID: #%d\n", item.ID)
+	text += fmt.Sprintf("Guruh / Группа: <b>%s</b>\n", class)
+	text += fmt.Sprintf("Sana / Дата: %s\n", utils.FormatDateTime(item.CreatedAt))
+	text += fmt.Sprintf("Muddat / Срок: %d soat / ч\n", int(deadline.Hours()))
+	text += fmt.Sprintf("Kechikish / Просрочка: %d soat / ч\n", late)
+
+	if item.FirstResponseAt != nil {
+		text += fmt.Sprintf("👀 Qabul qilingan / Принято: %s\n", utils.FormatDateTime(*item.FirstResponseAt))
+	} else {
+		text += "👀 Hali hech kim qabul qilmagan / Ещё никто не принял\n"
+	}
+
+	switch level {
+	case models.EscalationAdmins:
+		text += "\nMas'ul xodim javob bermadi / Ответственный сотрудник не ответил"
+	case models.EscalationDirector:
+		text += "\nMa'murlar javob bermadi, direktorga yuborildi / Администраторы не ответили, передано директору"
+	}
+
+	return text
+}
@@ -0,0 +1,515 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"anor-kids/internal/models"
+	"anor-kids/internal/repository"
+	"anor-kids/pkg/chart"
+)
+
+// Statistics periods in weeks
+const (
+	DefaultStatsWeeks = 12
+	MaxStatsWeeks     = 52
+)
+
+// Chart fonts, shared with the PDF documents
+const (
+	chartRegularFont = "DejaVuSans.ttf"
+	chartBoldFont    = "DejaVuSans-Bold.ttf"
+)
+
+// statsStatuses is the order statuses are listed in
+var statsStatuses = []string{models.StatusPending, models.StatusReviewed, models.StatusArchived, models.StatusWithdrawn}
+
+// StatsService computes submission trends and renders them as charts
+type StatsService struct {
+	statsRepo *repository.StatsRepository
+	renderer  func() (*chart.Renderer, error)
+}
+
+// NewStatsService creates a new statistics service. Chart fonts are loaded
+// from fontDir on first use.
+func NewStatsService(statsRepo *repository.StatsRepository, fontDir string) *StatsService {
+	return &StatsService{
+		statsRepo: statsRepo,
+		renderer: sync.OnceValues(func() (*chart.Renderer, error) {
+			regular, err := os.ReadFile(filepath.Join(fontDir, chartRegularFont))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read font: %w", err)
+			}
+			bold, err := os.ReadFile(filepath.Join(fontDir, chartBoldFont))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read font: %w", err)
+			}
+			return chart.NewRenderer(regular, bold)
+		}),
+	}
+}
+
+// weekStart returns the Monday 00:00 of the week t is in, in t's location
+func weekStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+	offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+	return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+}
+
+// Dashboard computes the statistics of the last weeks, the current week included.
+// Weeks start on Monday in now's location. A categoryID other than 0 counts
+// only the complaints of that category; proposals have no category.
+func (s *StatsService) Dashboard(weeks, categoryID int, now time.Time) (*models.StatsDashboard, error) {
+	weeks = max(1, min(weeks, MaxStatsWeeks))
+
+	current := weekStart(now)
+	from := current.AddDate(0, 0, -7*(weeks-1))
+	to := current.AddDate(0, 0, 7)
+
+	submissions, err := s.statsRepo.GetSubmissions(from)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &models.StatsDashboard{From: from, To: to}
+	if categoryID != 0 {
+		d.CategoryID = &categoryID
+		submissions = inCategory(submissions, categoryID)
+	}
+
+	weekParents := make([]map[int]bool, weeks)
+	weekReviewHours := make([][]float64, weeks)
+	for i := range weeks {
+		d.Weeks = append(d.Weeks, models.WeeklyStats{WeekStart: from.AddDate(0, 0, 7*i)})
+		weekParents[i] = make(map[int]bool)
+	}
+
+	var inPeriod []*models.Submission
+	parents := make(map[int]bool)
+	var allReviewHours []float64
+
+	for _, submission := range submissions {
+		created := submission.CreatedAt.In(now.Location())
+		if !created.Before(to) {
+			continue
+		}
+		inPeriod = append(inPeriod, submission)
+
+		// Weeks are counted by calendar day, so a DST change doesn't shift them
+		i := 0
+		for i+1 < weeks && !created.Before(d.Weeks[i+1].WeekStart) {
+			i++
+		}
+		week := &d.Weeks[i]
+
+		if submission.Kind == models.DocumentJobKindComplaint {
+			d.Complaints++
+			week.Complaints++
+		} else {
+			d.Proposals++
+			week.Proposals++
+		}
+
+		parents[submission.UserID] = true
+		weekParents[i][submission.UserID] = true
+
+		if hours, ok := reviewHours(submission); ok {
+			allReviewHours = append(allReviewHours, hours)
+			weekReviewHours[i] = append(weekReviewHours[i], hours)
+		}
+	}
+
+	for i := range d.Weeks {
+		d.Weeks[i].ActiveParents = len(weekParents[i])
+		d.Weeks[i].MedianReviewHours = median(weekReviewHours[i])
+	}
+	d.ActiveParents = len(parents)
+	d.MedianReviewHours = median(allReviewHours)
+	d.Classes = classStats(inPeriod)
+	d.Categories = categoryStats(inPeriod)
+	d.Statuses = statusStats(inPeriod)
+
+	ratings, err := s.statsRepo.GetRatings(from)
+	if err != nil {
+		return nil, err
+	}
+	d.Satisfaction = satisfactionStats(ratings, categoryID, to, now.Location())
+
+	return d, nil
+}
+
+// satisfactionStats averages the ratings given before to per staff member,
+// category and month; a categoryID other than 0 keeps only that category.
+// Staff are sorted by name, categories like categoryStats, months in order.
+func satisfactionStats(ratings []*models.RatedComplaint, categoryID int, to time.Time, loc *time.Location) models.Satisfaction {
+	var stats models.Satisfaction
+
+	type sum struct{ count, total int }
+	var all sum
+	staff := make(map[int64]*sum)
+	staffNames := make(map[int64]string)
+	categories := make(map[int]*sum) // 0 holds uncategorized complaints
+	categoryRefs := make(map[int]*models.Category)
+	months := make(map[time.Time]*sum)
+
+	add := func(s *sum, rating int) {
+		s.count++
+		s.total += rating
+	}
+
+	for _, rating := range ratings {
+		if !rating.RatedAt.Before(to) {
+			continue
+		}
+
+		id := 0
+		if rating.Category != nil {
+			id = rating.Category.ID
+		}
+		if categoryID != 0 && id != categoryID {
+			continue
+		}
+
+		add(&all, rating.Rating)
+
+		if staff[rating.StaffTelegramID] == nil {
+			staff[rating.StaffTelegramID] = &sum{}
+		}
+		add(staff[rating.StaffTelegramID], rating.Rating)
+		// Ratings are in order, so the latest name wins
+		if rating.StaffName != "" {
+			staffNames[rating.StaffTelegramID] = rating.StaffName
+		}
+
+		if categories[id] == nil {
+			categories[id] = &sum{}
+			categoryRefs[id] = rating.Category
+		}
+		add(categories[id], rating.Rating)
+
+		year, month, _ := rating.RatedAt.In(loc).Date()
+		start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		if months[start] == nil {
+			months[start] = &sum{}
+		}
+		add(months[start], rating.Rating)
+	}
+
+	average := func(s *sum) float64 {
+		return float64(s.total) / float64(s.count)
+	}
+
+	stats.Ratings = all.count
+	if all.count > 0 {
+		avg := average(&all)
+		stats.Average = &avg
+	}
+
+	for id, s := range staff {
+		stats.Staff = append(stats.Staff, models.StaffSatisfaction{
+			StaffTelegramID: id,
+			StaffName:       staffNames[id],
+			Ratings:         s.count,
+			Average:         average(s),
+		})
+	}
+	sort.Slice(stats.Staff, func(i, j int) bool {
+		a, b := stats.Staff[i], stats.Staff[j]
+		if a.StaffName != b.StaffName {
+			return a.StaffName < b.StaffName
+		}
+		return a.StaffTelegramID < b.StaffTelegramID
+	})
+
+	for id, s := range categories {
+		stats.Categories = append(stats.Categories, models.CategorySatisfaction{
+			Category: categoryRefs[id],
+			Ratings:  s.count,
+			Average:  average(s),
+		})
+	}
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		a, b := stats.Categories[i].Category, stats.Categories[j].Category
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.ID < b.ID
+	})
+
+	for month, s := range months {
+		stats.Months = append(stats.Months, models.MonthlySatisfaction{
+			Month:   month,
+			Ratings: s.count,
+			Average: average(s),
+		})
+	}
+	sort.Slice(stats.Months, func(i, j int) bool {
+		return stats.Months[i].Month.Before(stats.Months[j].Month)
+	})
+
+	return stats
+}
+
+// inCategory returns the complaints of a category
+func inCategory(submissions []*models.Submission, categoryID int) []*models.Submission {
+	var filtered []*models.Submission
+	for _, submission := range submissions {
+		if submission.Category != nil && submission.Category.ID == categoryID {
+			filtered = append(filtered, submission)
+		}
+	}
+	return filtered
+}
+
+// categoryStats counts complaints per category, sorted by category ID with
+// uncategorized complaints last; proposals are skipped
+func categoryStats(submissions []*models.Submission) []models.CategoryStats {
+	categories := make(map[int]*models.CategoryStats) // 0 holds uncategorized complaints
+	for _, submission := range submissions {
+		if submission.Kind != models.DocumentJobKindComplaint {
+			continue
+		}
+
+		id := 0
+		if submission.Category != nil {
+			id = submission.Category.ID
+		}
+
+		category := categories[id]
+		if category == nil {
+			category = &models.CategoryStats{Category: submission.Category}
+			categories[id] = category
+		}
+		category.Complaints++
+	}
+
+	var stats []models.CategoryStats
+	for _, category := range categories {
+		stats = append(stats, *category)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i].Category, stats[j].Category
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.ID < b.ID
+	})
+
+	return stats
+}
+
+// classStats counts submissions per class, sorted by class name
+func classStats(submissions []*models.Submission) []models.ClassStats {
+	classes := make(map[string]*models.ClassStats)
+	for _, submission := range submissions {
+		class := classes[submission.ChildClass]
+		if class == nil {
+			class = &models.ClassStats{Class: submission.ChildClass}
+			classes[submission.ChildClass] = class
+		}
+
+		if submission.Kind == models.DocumentJobKindComplaint {
+			class.Complaints++
+		} else {
+			class.Proposals++
+		}
+	}
+
+	var stats []models.ClassStats
+	for _, class := range classes {
+		stats = append(stats, *class)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Class < stats[j].Class
+	})
+
+	return stats
+}
+
+// statusStats counts submissions per status; every known status is listed,
+// in statsStatuses order
+func statusStats(submissions []*models.Submission) []models.StatusStats {
+	var stats []models.StatusStats
+	index := make(map[string]int)
+	for _, status := range statsStatuses {
+		index[status] = len(stats)
+		stats = append(stats, models.StatusStats{Status: status})
+	}
+
+	for _, submission := range submissions {
+		i, ok := index[submission.Status]
+		if !ok {
+			i = len(stats)
+			index[submission.Status] = i
+			stats = append(stats, models.StatusStats{Status: submission.Status})
+		}
+
+		if submission.Kind == models.DocumentJobKindComplaint {
+			stats[i].Complaints++
+		} else {
+			stats[i].Proposals++
+		}
+	}
+
+	return stats
+}
+
+// reviewHours returns how long a submission waited to be reviewed
+func reviewHours(submission *models.Submission) (float64, bool) {
+	if submission.ReviewedAt == nil || submission.ReviewedAt.Before(submission.CreatedAt) {
+		return 0, false
+	}
+	return submission.ReviewedAt.Sub(submission.CreatedAt).Hours(), true
+}
+
+// median returns the median of values rounded to 0.1, nil if there are none
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	m := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		m = (sorted[len(sorted)/2-1] + m) / 2
+	}
+	m = math.Round(m*10) / 10
+	return &m
+}
+
+// Charts renders the dashboard as PNG images: submissions per week, active
+// parents per week, median review time per week, submissions per class,
+// complaints per category and submissions per status
+func (s *StatsService) Charts(d *models.StatsDashboard) ([][]byte, error) {
+	renderer, err := s.renderer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart fonts: %w", err)
+	}
+
+	complaintsName := "Shikoyatlar / Жалобы"
+	proposalsName := "Takliflar / Предложения"
+
+	var weekLabels []string
+	var weekComplaints, weekProposals, weekParents, weekMedians []float64
+	for _, week := range d.Weeks {
+		weekLabels = append(weekLabels, week.WeekStart.Format("02.01"))
+		weekComplaints = append(weekComplaints, float64(week.Complaints))
+		weekProposals = append(weekProposals, float64(week.Proposals))
+		weekParents = append(weekParents, float64(week.ActiveParents))
+		if week.MedianReviewHours != nil {
+			weekMedians = append(weekMedians, *week.MedianReviewHours)
+		} else {
+			weekMedians = append(weekMedians, math.NaN())
+		}
+	}
+
+	var classLabels []string
+	var classComplaints, classProposals []float64
+	for _, class := range d.Classes {
+		label := class.Class
+		if label == "" {
+			label = "—"
+		}
+		classLabels = append(classLabels, label)
+		classComplaints = append(classComplaints, float64(class.Complaints))
+		classProposals = append(classProposals, float64(class.Proposals))
+	}
+
+	var categoryLabels []string
+	var categoryComplaints []float64
+	for _, category := range d.Categories {
+		label := category.Category.Label()
+		if label == "" {
+			label = "—"
+		}
+		categoryLabels = append(categoryLabels, label)
+		categoryComplaints = append(categoryComplaints, float64(category.Complaints))
+	}
+
+	var statusLabels []string
+	var statusComplaints, statusProposals []float64
+	for _, status := range d.Statuses {
+		statusLabels = append(statusLabels, statusLabel(status.Status))
+		statusComplaints = append(statusComplaints, float64(status.Complaints))
+		statusProposals = append(statusProposals, float64(status.Proposals))
+	}
+
+	charts := []struct {
+		render func(chart.Chart) ([]byte, error)
+		chart  chart.Chart
+	}{
+		{renderer.BarPNG, chart.Chart{
+			Title:      "Haftalik murojaatlar / Обращения по неделям",
+			Categories: weekLabels,
+			Series: []chart.Series{
+				{Name: complaintsName, Values: weekComplaints},
+				{Name: proposalsName, Values: weekProposals},
+			},
+		}},
+		{renderer.LinePNG, chart.Chart{
+			Title:      "Faol ota-onalar / Активные родители",
+			Categories: weekLabels,
+			Series:     []chart.Series{{Name: "Ota-onalar / Родители", Values: weekParents}},
+		}},
+		{renderer.LinePNG, chart.Chart{
+			Title:      "Ko'rib chiqish vaqti (mediana) / Время рассмотрения (медиана)",
+			Categories: weekLabels,
+			Series:     []chart.Series{{Name: "Soat / Часы", Values: weekMedians}},
+		}},
+		{renderer.BarPNG, chart.Chart{
+			Title:      "Guruhlar bo'yicha / По группам",
+			Categories: classLabels,
+			Series: []chart.Series{
+				{Name: complaintsName, Values: classComplaints},
+				{Name: proposalsName, Values: classProposals},
+			},
+		}},
+		{renderer.BarPNG, chart.Chart{
+			Title:      "Toifalar bo'yicha / По категориям",
+			Categories: categoryLabels,
+			Series:     []chart.Series{{Name: complaintsName, Values: categoryComplaints}},
+		}},
+		{renderer.BarPNG, chart.Chart{
+			Title:      "Holatlar bo'yicha / По статусам",
+			Categories: statusLabels,
+			Series: []chart.Series{
+				{Name: complaintsName, Values: statusComplaints},
+				{Name: proposalsName, Values: statusProposals},
+			},
+		}},
+	}
+
+	var images [][]byte
+	for _, c := range charts {
+		image, err := c.render(c.chart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render chart: %w", err)
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+// statusLabel returns the bilingual name of a status
+func statusLabel(status string) string {
+	switch status {
+	case models.StatusPending:
+		return "Kutilmoqda / Ожидание"
+	case models.StatusReviewed:
+		return "Ko'rildi / Рассмотрено"
+	case models.StatusArchived:
+		return "Arxiv / Архив"
+	case models.StatusWithdrawn:
+		return "Qaytarib olingan / Отозвано"
+	}
+	return status
+}
@@ -59,10 +59,14 @@ func (s *TelegramService) UploadDocument(chatID int64, docPath, filename string)
 }
 
 // SendDocumentByFileID sends a document using file_id
-func (s *TelegramService) SendDocumentByFileID(chatID int64, fileID, caption string) error {
+func (s *TelegramService) SendDocumentByFileID(chatID int64, fileID, caption string, replyMarkup interface{}) error {
 	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileID(fileID))
 	doc.Caption = caption
 
+	if replyMarkup != nil {
+		doc.ReplyMarkup = replyMarkup
+	}
+
 	_, err := s.bot.Send(doc)
 	if err != nil {
 		return fmt.Errorf("failed to send document: %w", err)
@@ -71,6 +75,61 @@ func (s *TelegramService) SendDocumentByFileID(chatID int64, fileID, caption str
 	return nil
 }
 
+// SendPhotoAlbum sends PNG images as one album (2 to 10 photos); the caption is
+// shown under the first photo
+func (s *TelegramService) SendPhotoAlbum(chatID int64, photos [][]byte, caption string) error {
+	var media []interface{}
+	for i, photo := range photos {
+		item := tgbotapi.NewInputMediaPhoto(tgbotapi.FileBytes{Name: fmt.Sprintf("chart%d.png", i+1), Bytes: photo})
+		if i == 0 {
+			item.Caption = caption
+			item.ParseMode = "HTML"
+		}
+		media = append(media, item)
+	}
+
+	_, err := s.bot.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media))
+	if err != nil {
+		return fmt.Errorf("failed to send photo album: %w", err)
+	}
+
+	return nil
+}
+
+// SendMediaGroupByFileID sends files already on Telegram as one album, as
+// photos or as documents since an album can't mix them. A single file is sent
+// on its own, albums need at least two.
+func (s *TelegramService) SendMediaGroupByFileID(chatID int64, fileIDs []string, asDocuments bool) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	var err error
+	if len(fileIDs) == 1 {
+		if asDocuments {
+			_, err = s.bot.Send(tgbotapi.NewDocument(chatID, tgbotapi.FileID(fileIDs[0])))
+		} else {
+			_, err = s.bot.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileID(fileIDs[0])))
+		}
+	} else {
+		var media []interface{}
+		for _, fileID := range fileIDs {
+			if asDocuments {
+				media = append(media, tgbotapi.NewInputMediaDocument(tgbotapi.FileID(fileID)))
+			} else {
+				media = append(media, tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(fileID)))
+			}
+		}
+		_, err = s.bot.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to send media group: %w", err)
+	}
+
+	return nil
+}
+
 // SendMessage sends a text message
 func (s *TelegramService) SendMessage(chatID int64, text string, replyMarkup interface{}) error {
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -183,9 +242,9 @@ func (s *TelegramService) NotifyAdmins(adminTelegramIDs []int64, message string)
 }
 
 // SendDocumentToAdmins sends a document to all admins
-func (s *TelegramService) SendDocumentToAdmins(adminTelegramIDs []int64, fileID, caption string) error {
+func (s *TelegramService) SendDocumentToAdmins(adminTelegramIDs []int64, fileID, caption string, replyMarkup interface{}) error {
 	for _, adminID := range adminTelegramIDs {
-		err := s.SendDocumentByFileID(adminID, fileID, caption)
+		err := s.SendDocumentByFileID(adminID, fileID, caption, replyMarkup)
 		if err != nil {
 			// Log error but continue sending to other admins
 			fmt.Printf("Failed to send document to admin %d: %v\n", adminID, err)
@@ -1,12 +1,16 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 
 	"anor-kids/internal/models"
 	"anor-kids/internal/repository"
 )
 
+// ErrPhoneNumberTaken is returned when a phone number is already linked to another account
+var ErrPhoneNumberTaken = errors.New("phone number already registered")
+
 // UserService handles user-related business logic
 type UserService struct {
 	repo *repository.UserRepository
@@ -36,7 +40,7 @@ func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.User, e
 	}
 
 	if existingPhone != nil {
-		return nil, fmt.Errorf("phone number already registered")
+		return nil, ErrPhoneNumberTaken
 	}
 
 	// Create user
@@ -58,6 +62,16 @@ func (s *UserService) GetUserByTelegramID(telegramID int64) (*models.User, error
 	return user, nil
 }
 
+// GetUserByID gets user by ID
+func (s *UserService) GetUserByID(id int) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
 // GetUserByPhoneNumber gets user by phone number
 func (s *UserService) GetUserByPhoneNumber(phoneNumber string) (*models.User, error) {
 	user, err := s.repo.GetByPhoneNumber(phoneNumber)
@@ -78,6 +92,49 @@ func (s *UserService) UpdateUser(telegramID int64, req *models.UpdateUserRequest
 	return nil
 }
 
+// ChangePhoneNumber changes the phone number of a registered user.
+// Returns ErrPhoneNumberTaken if another account uses the number.
+func (s *UserService) ChangePhoneNumber(telegramID int64, phoneNumber string) error {
+	existing, err := s.repo.GetByPhoneNumber(phoneNumber)
+	if err != nil {
+		return fmt.Errorf("failed to check existing phone: %w", err)
+	}
+
+	if existing != nil {
+		if existing.TelegramID == telegramID {
+			return nil
+		}
+		return ErrPhoneNumberTaken
+	}
+
+	err = s.repo.UpdatePhoneNumber(telegramID, phoneNumber)
+	if err != nil {
+		return fmt.Errorf("failed to change phone number: %w", err)
+	}
+
+	return nil
+}
+
+// TransferAccount moves a user record to a new telegram account.
+// Only call this after the new account has proven it owns the user's phone number.
+func (s *UserService) TransferAccount(userID int, telegramID int64, telegramUsername string) error {
+	existing, err := s.repo.GetByTelegramID(telegramID)
+	if err != nil {
+		return fmt.Errorf("failed to check existing user: %w", err)
+	}
+
+	if existing != nil {
+		return fmt.Errorf("user already registered")
+	}
+
+	err = s.repo.UpdateTelegramAccount(userID, telegramID, telegramUsername)
+	if err != nil {
+		return fmt.Errorf("failed to transfer account: %w", err)
+	}
+
+	return nil
+}
+
 // GetAllUsers gets all users with pagination
 func (s *UserService) GetAllUsers(limit, offset int) ([]*models.User, error) {
 	users, err := s.repo.GetAll(limit, offset)
@@ -98,6 +155,42 @@ func (s *UserService) GetUsersByClass(class string) ([]*models.User, error) {
 	return users, nil
 }
 
+// GetPendingUsers gets registrations waiting for approval, oldest first
+func (s *UserService) GetPendingUsers(limit, offset int) ([]*models.User, error) {
+	users, err := s.repo.GetByApprovalStatus(models.ApprovalPending, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending users: %w", err)
+	}
+
+	return users, nil
+}
+
+// CountPendingUsers counts registrations waiting for approval
+func (s *UserService) CountPendingUsers() (int, error) {
+	count, err := s.repo.CountByApprovalStatus(models.ApprovalPending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending users: %w", err)
+	}
+
+	return count, nil
+}
+
+// ReviewRegistration approves or rejects a pending registration.
+// Returns false if the registration was already reviewed.
+func (s *UserService) ReviewRegistration(userID int, approve bool) (bool, error) {
+	status := models.ApprovalRejected
+	if approve {
+		status = models.ApprovalApproved
+	}
+
+	updated, err := s.repo.UpdateApprovalStatus(userID, status)
+	if err != nil {
+		return false, fmt.Errorf("failed to review registration: %w", err)
+	}
+
+	return updated, nil
+}
+
 // CountUsers counts total users
 func (s *UserService) CountUsers() (int, error) {
 	count, err := s.repo.Count()
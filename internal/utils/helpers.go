@@ -2,14 +2,16 @@ package utils
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"strings"
 	"time"
 
+	"anor-kids/internal/models"
 	"anor-kids/internal/validator"
 )
 
-// GenerateComplaintFilename generates a filename for complaint document (legacy DOCX)
-// Format: Shikoyat_ParentName_ClassName_Date.docx
+// GenerateComplaintFilename generates a filename for complaint DOCX document
+// Format: Shikoyat_ChildName_ClassName_Date.docx
 func GenerateComplaintFilename(childName, childClass string) string {
 	date := time.Now().Format("2006-01-02")
 
@@ -23,6 +25,21 @@ func GenerateComplaintFilename(childName, childClass string) string {
 	return filename
 }
 
+// GenerateProposalFilename generates a filename for proposal DOCX document
+// Format: Taklif_ChildName_ClassName_Date.docx
+func GenerateProposalFilename(childName, childClass string) string {
+	date := time.Now().Format("2006-01-02")
+
+	// Sanitize name
+	safeName := validator.SanitizeFilename(childName)
+	safeName = strings.ReplaceAll(safeName, " ", "_")
+
+	// Create filename
+	filename := fmt.Sprintf("Taklif_%s_%s_sinf_%s.docx", safeName, childClass, date)
+
+	return filename
+}
+
 // GeneratePDFFilename generates a filename for complaint PDF document
 // Format: Shikoyat_ChildName_ClassName_Date.pdf
 func GeneratePDFFilename(childName, childClass string) string {
@@ -38,6 +55,18 @@ func GeneratePDFFilename(childName, childClass string) string {
 	return filename
 }
 
+// GenerateAnonymousPDFFilename generates a filename for an anonymous complaint PDF document
+// Format: Shikoyat_anonim_Date.pdf
+func GenerateAnonymousPDFFilename() string {
+	return fmt.Sprintf("Shikoyat_anonim_%s.pdf", time.Now().Format("2006-01-02"))
+}
+
+// GenerateAnonymousComplaintFilename generates a filename for an anonymous complaint DOCX document
+// Format: Shikoyat_anonim_Date.docx
+func GenerateAnonymousComplaintFilename() string {
+	return fmt.Sprintf("Shikoyat_anonim_%s.docx", time.Now().Format("2006-01-02"))
+}
+
 // GenerateProposalPDFFilename generates a filename for proposal PDF document
 // Format: Taklif_ChildName_ClassName_Date.pdf
 func GenerateProposalPDFFilename(childName, childClass string) string {
@@ -53,6 +82,12 @@ func GenerateProposalPDFFilename(childName, childClass string) string {
 	return filename
 }
 
+// GenerateReferenceNumber generates a document reference number
+// Format: PREFIX-YYMMDD-HHMM-XXXX (XXXX is random hex)
+func GenerateReferenceNumber(prefix string, t time.Time) string {
+	return fmt.Sprintf("%s-%s-%04X", prefix, t.Format("060102-1504"), rand.IntN(0x10000))
+}
+
 // GenerateComplaintCaption generates caption for complaint document
 func GenerateComplaintCaption(childName, childClass, phoneNumber string) string {
 	return fmt.Sprintf(
@@ -79,6 +114,20 @@ func TruncateText(text string, maxLen int) string {
 	return string(runes[:maxLen]) + "..."
 }
 
+// StatusIcon returns the icon shown to parents for the status of a complaint or proposal
+func StatusIcon(status string) string {
+	switch status {
+	case models.StatusReviewed:
+		return "✅"
+	case models.StatusWithdrawn:
+		return "↩️"
+	case models.StatusArchived:
+		return "📦"
+	default:
+		return "⏳"
+	}
+}
+
 // FormatPhoneNumber formats phone number for display
 func FormatPhoneNumber(phone string) string {
 	// +998 90 123 45 67
@@ -151,32 +200,3 @@ func SanitizeClassName(className string) string {
 	className = strings.Join(strings.Fields(className), " ")
 	return className
 }
-
-// StripEmojis removes emoji characters from text for PDF compatibility
-// DejaVu fonts don't support emojis, so we need to strip them
-func StripEmojis(text string) string {
-	var result strings.Builder
-
-	for _, r := range text {
-		// Keep characters in these ranges (basic Latin, Cyrillic, common punctuation)
-		// Basic Latin: 0x0000-0x007F
-		// Latin Extended: 0x0080-0x00FF
-		// Cyrillic: 0x0400-0x04FF
-		// General Punctuation: 0x2000-0x206F
-		// Currency: 0x20A0-0x20CF
-		if (r >= 0x0020 && r <= 0x007E) || // ASCII printable
-		   (r >= 0x00A0 && r <= 0x00FF) || // Latin Extended
-		   (r >= 0x0400 && r <= 0x04FF) || // Cyrillic
-		   (r >= 0x2000 && r <= 0x206F) || // General Punctuation
-		   (r >= 0x20A0 && r <= 0x20CF) || // Currency
-		   r == '\n' || r == '\r' || r == '\t' { // Whitespace
-			result.WriteRune(r)
-		} else {
-			// Skip emoji and other unsupported characters
-			// Optionally, you could replace with a space or placeholder
-			// For now, we just skip them
-		}
-	}
-
-	return result.String()
-}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"html"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"anor-kids/internal/i18n"
@@ -33,6 +34,62 @@ func MakePhoneKeyboard(lang i18n.Language) tgbotapi.ReplyKeyboardMarkup {
 	)
 }
 
+// MakePhoneChangeKeyboard creates phone number request keyboard with a cancel button
+func MakePhoneChangeKeyboard(lang i18n.Language) tgbotapi.ReplyKeyboardMarkup {
+	return tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButtonContact(i18n.Get(i18n.BtnSharePhone, lang)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(i18n.Get(i18n.BtnCancel, lang)),
+		),
+	)
+}
+
+// MakeSettingsKeyboard creates settings inline keyboard
+func MakeSettingsKeyboard(lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnChangePhone, lang),
+				"settings_change_phone",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnExportData, lang),
+				"settings_export_data",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnDeleteAccount, lang),
+				"settings_delete_account",
+			),
+		),
+	)
+}
+
+// MakeDeleteAccountKeyboard creates confirmation keyboard for account deletion.
+// The final step uses a different button so a double tap can't skip it.
+func MakeDeleteAccountKeyboard(lang i18n.Language, final bool) tgbotapi.InlineKeyboardMarkup {
+	confirmText := i18n.Get(i18n.BtnConfirmDelete, lang)
+	confirmData := "delete_account_confirm"
+	if final {
+		confirmText = i18n.Get(i18n.BtnConfirmDeleteFinal, lang)
+		confirmData = "delete_account_final"
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(confirmText, confirmData),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnCancel, lang), "delete_account_cancel"),
+		),
+	)
+}
+
 // MakeMainMenuKeyboard creates main menu keyboard
 func MakeMainMenuKeyboard(lang i18n.Language) tgbotapi.ReplyKeyboardMarkup {
 	return tgbotapi.NewReplyKeyboard(
@@ -88,8 +145,18 @@ func MakeMainMenuKeyboardForUser(lang i18n.Language, isAdmin bool) tgbotapi.Repl
 	return MakeMainMenuKeyboard(lang)
 }
 
-// MakeConfirmationKeyboard creates confirmation keyboard
-func MakeConfirmationKeyboard(lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
+// MakeConfirmationKeyboard creates complaint confirmation keyboard with the urgency and anonymity toggles
+func MakeConfirmationKeyboard(lang i18n.Language, urgent, anonymous bool) tgbotapi.InlineKeyboardMarkup {
+	urgentText := i18n.Get(i18n.BtnMarkUrgent, lang)
+	if urgent {
+		urgentText = i18n.Get(i18n.BtnUnmarkUrgent, lang)
+	}
+
+	anonymousText := i18n.Get(i18n.BtnSendAnonymously, lang)
+	if anonymous {
+		anonymousText = i18n.Get(i18n.BtnSendWithName, lang)
+	}
+
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
@@ -101,6 +168,12 @@ func MakeConfirmationKeyboard(lang i18n.Language) tgbotapi.InlineKeyboardMarkup
 				"cancel_complaint",
 			),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(urgentText, "urgent_toggle"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(anonymousText, "anonymous_toggle"),
+		),
 	)
 }
 
@@ -157,18 +230,36 @@ func MakeAdminKeyboard(lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
 				"admin_manage_classes",
 			),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnManageCategories, lang),
+				"admin_manage_categories",
+			),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
 				i18n.Get(i18n.BtnViewUsers, lang),
 				"admin_users",
 			),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnPendingRegistrations, lang),
+				"admin_pending_users",
+			),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
 				i18n.Get(i18n.BtnViewComplaints, lang),
 				"admin_complaints",
 			),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnMyAssigned, lang),
+				"admin_my_assigned",
+			),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
 				i18n.Get(i18n.BtnViewProposals, lang),
@@ -181,6 +272,18 @@ func MakeAdminKeyboard(lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
 				"admin_stats",
 			),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnExport, lang),
+				"admin_export",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				i18n.Get(i18n.BtnReports, lang),
+				"admin_reports",
+			),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
 				i18n.Get(i18n.BtnCreateAnnouncement, lang),
@@ -226,6 +329,32 @@ func MakeClassSelectionKeyboard(classes []*models.Class, lang i18n.Language) tgb
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+// MakeCategorySelectionKeyboard creates the complaint category selection inline keyboard
+func MakeCategorySelectionKeyboard(categories []*models.Category, lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	// Names are longer than class names, two buttons per row
+	var row []tgbotapi.InlineKeyboardButton
+	for i, category := range categories {
+		button := tgbotapi.NewInlineKeyboardButtonData(
+			category.Name(string(lang)),
+			fmt.Sprintf("complaint_cat_%d", category.ID),
+		)
+		row = append(row, button)
+
+		if (i+1)%2 == 0 || i == len(categories)-1 {
+			rows = append(rows, row)
+			row = []tgbotapi.InlineKeyboardButton{}
+		}
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnCancel, lang), "cancel_complaint"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
 // MakeProposalImagePromptKeyboard creates keyboard to ask if user wants to add images for proposal
 func MakeProposalImagePromptKeyboard(lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
 	yesText := "✅ Ha, rasm qo'shaman"
@@ -338,3 +467,188 @@ func MakeAnnouncementListKeyboard(currentPage, totalPages int, lang i18n.Languag
 
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
+
+// MakeRegistrationReviewKeyboard creates approve/reject keyboard for a pending registration
+func MakeRegistrationReviewKeyboard(userID int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"✅ Tasdiqlash / Подтвердить",
+				fmt.Sprintf("reg_approve_%d", userID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				"❌ Rad etish / Отклонить",
+				fmt.Sprintf("reg_reject_%d", userID),
+			),
+		),
+	)
+}
+
+// MakeAdminSubmissionKeyboard creates the buttons under an admin's PDF copy: the Word version, the review
+// actions and, for complaints, the assignment and a reply to the parent
+// kind is "complaint" or "proposal"
+func MakeAdminSubmissionKeyboard(kind string, id int) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"📄 Word (DOCX) yuklab olish / Скачать в Word (DOCX)",
+				fmt.Sprintf("docx_%s_%d", kind, id),
+			),
+		),
+		makeReviewRow(kind, id),
+	}
+
+	if kind == "complaint" {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🙋 Olaman / Беру", fmt.Sprintf("assign_take_%d", id)),
+				tgbotapi.NewInlineKeyboardButtonData("👥 Tayinlash… / Назначить…", fmt.Sprintf("assign_pick_%d", id)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("💬 Javob yozish / Ответить", fmt.Sprintf("reply_complaint_%d", id)),
+			),
+		)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// MakeReviewKeyboard creates the review actions sent with SLA reminders
+// kind is "complaint" or "proposal"
+func MakeReviewKeyboard(kind string, id int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(makeReviewRow(kind, id))
+}
+
+// MakeUrgentAckKeyboard creates the button that stops the alerts about an urgent complaint
+func MakeUrgentAckKeyboard(id int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚨 Qabul qildim / Принято", fmt.Sprintf("urgent_ack_%d", id)),
+		),
+	)
+}
+
+// makeReviewRow creates the buttons to take a complaint or proposal in hand or mark it reviewed
+func makeReviewRow(kind string, id int) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("👀 Qabul qilindi / Принято", fmt.Sprintf("review_ack_%s_%d", kind, id)),
+		tgbotapi.NewInlineKeyboardButtonData("✅ Ko'rib chiqildi / Рассмотрено", fmt.Sprintf("review_done_%s_%d", kind, id)),
+	)
+}
+
+// MakeMyComplaintsKeyboard creates a button to open each of the parent's complaints, and the page navigation
+func MakeMyComplaintsKeyboard(complaints []*models.Complaint, page, totalPages int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, c := range complaints {
+		label := mySubmissionLabel(c.Status, c.ID, c.ComplaintText)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("my_complaint_%d_%d", c.ID, page)),
+		))
+	}
+
+	if nav := makePageNavigationRow("my_complaints_page", page, totalPages); len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// MakeMyProposalsKeyboard creates a button to open each of the parent's proposals, and the page navigation
+func MakeMyProposalsKeyboard(proposals []*models.Proposal, page, totalPages int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, p := range proposals {
+		label := mySubmissionLabel(p.Status, p.ID, p.ProposalText)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("my_proposal_%d_%d", p.ID, page)),
+		))
+	}
+
+	if nav := makePageNavigationRow("my_proposals_page", page, totalPages); len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// MakeMyComplaintKeyboard creates the keyboard under an opened complaint: a pending
+// complaint can still be corrected or withdrawn. Back returns to the given list page.
+func MakeMyComplaintKeyboard(lang i18n.Language, complaint *models.Complaint, page int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if complaint.Status == models.StatusPending {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(i18n.Get(i18n.BtnEditComplaint, lang), complaint.ID), fmt.Sprintf("complaint_edit_%d", complaint.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(i18n.Get(i18n.BtnWithdrawComplaint, lang), complaint.ID), fmt.Sprintf("complaint_withdraw_%d", complaint.ID)),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnBack, lang), fmt.Sprintf("my_complaints_page_%d", page)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// MakeMyProposalKeyboard creates the keyboard under an opened proposal, back to the given list page
+func MakeMyProposalKeyboard(lang i18n.Language, page int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnBack, lang), fmt.Sprintf("my_proposals_page_%d", page)),
+		),
+	)
+}
+
+// mySubmissionLabel labels the button of a complaint or proposal in the parent's list
+func mySubmissionLabel(status string, id int, text string) string {
+	// The stored text is HTML-escaped, buttons are plain text
+	preview := TruncateText(html.UnescapeString(text), 30)
+	if preview == "" {
+		preview = "🗑"
+	}
+
+	return fmt.Sprintf("%s #%d %s", StatusIcon(status), id, preview)
+}
+
+// makePageNavigationRow creates the previous and next page buttons, <prefix>_<page>
+func makePageNavigationRow(prefix string, page, totalPages int) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("%s_%d", prefix, page-1)))
+	}
+
+	if page < totalPages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("%s_%d", prefix, page+1)))
+	}
+
+	return row
+}
+
+// MakeWithdrawComplaintKeyboard creates the confirmation keyboard for withdrawing a complaint
+func MakeWithdrawComplaintKeyboard(lang i18n.Language, id int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnConfirmWithdraw, lang), fmt.Sprintf("complaint_withdraw_yes_%d", id)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnCancel, lang), "complaint_withdraw_no"),
+		),
+	)
+}
+
+// MakeRatingKeyboard creates the 1 to 5 rating buttons for a reviewed complaint
+func MakeRatingKeyboard(id int) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for rating := models.MinRating; rating <= models.MaxRating; rating++ {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d ⭐", rating), fmt.Sprintf("rate_%d_%d", id, rating)))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// MakeRatingCommentKeyboard creates the button to finish a rating without a comment
+func MakeRatingCommentKeyboard(lang i18n.Language, id int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Get(i18n.BtnSkipComment, lang), fmt.Sprintf("rate_skip_%d", id)),
+		),
+	)
+}
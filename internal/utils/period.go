@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period codes offered to admins when filtering exports. A custom range is
+// encoded by CustomPeriod as "YYYYMMDD-YYYYMMDD" so it fits in callback data.
+const (
+	PeriodLast7Days  = "7d"
+	PeriodLast30Days = "30d"
+	PeriodThisMonth  = "month"
+	PeriodLastMonth  = "prevmonth"
+	PeriodAll        = "all"
+)
+
+// periodDateLayout is the date format of custom period codes
+const periodDateLayout = "20060102"
+
+// dateInputLayouts are the date formats accepted from admins
+var dateInputLayouts = []string{"2006-01-02", "02.01.2006"}
+
+// ParseDate parses a date typed by an admin, either 2025-09-30 or 30.09.2025, in loc
+func ParseDate(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateInputLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// CustomPeriod encodes the days from..to (both inclusive) as a period code
+func CustomPeriod(from, to time.Time) string {
+	return from.Format(periodDateLayout) + "-" + to.Format(periodDateLayout)
+}
+
+// PeriodRange returns the [from, to) range of a period code relative to now,
+// in now's location. A zero time means the range is unbounded on that side.
+// ok is false for an unknown or malformed code.
+func PeriodRange(code string, now time.Time) (from, to time.Time, ok bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	switch code {
+	case PeriodLast7Days:
+		return today.AddDate(0, 0, -6), time.Time{}, true
+	case PeriodLast30Days:
+		return today.AddDate(0, 0, -29), time.Time{}, true
+	case PeriodThisMonth:
+		return thisMonth, time.Time{}, true
+	case PeriodLastMonth:
+		return thisMonth.AddDate(0, -1, 0), thisMonth, true
+	case PeriodAll:
+		return time.Time{}, time.Time{}, true
+	}
+
+	start, end, found := strings.Cut(code, "-")
+	if !found {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, err := time.ParseInLocation(periodDateLayout, start, now.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	last, err := time.ParseInLocation(periodDateLayout, end, now.Location())
+	if err != nil || last.Before(from) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, last.AddDate(0, 0, 1), true
+}
+
+// FormatPeriod formats a range returned by PeriodRange for display
+func FormatPeriod(from, to time.Time) string {
+	switch {
+	case from.IsZero() && to.IsZero():
+		return "Barcha vaqt / За всё время"
+	case to.IsZero():
+		return FormatDate(from) + " – ..."
+	case from.IsZero():
+		return "... – " + FormatDate(to.AddDate(0, 0, -1))
+	default:
+		return FormatDate(from) + " – " + FormatDate(to.AddDate(0, 0, -1))
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodRange(t *testing.T) {
+	loc := time.FixedZone("UZT", 5*3600)
+	now := time.Date(2025, 3, 15, 10, 30, 0, 0, loc)
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantOK   bool
+	}{
+		{"Last 7 days", PeriodLast7Days, day(2025, 3, 9), time.Time{}, true},
+		{"Last 30 days", PeriodLast30Days, day(2025, 2, 14), time.Time{}, true},
+		{"This month", PeriodThisMonth, day(2025, 3, 1), time.Time{}, true},
+		{"Last month", PeriodLastMonth, day(2025, 2, 1), day(2025, 3, 1), true},
+		{"All time", PeriodAll, time.Time{}, time.Time{}, true},
+		{"Custom range", "20250101-20250131", day(2025, 1, 1), day(2025, 2, 1), true},
+		{"Single day", "20250310-20250310", day(2025, 3, 10), day(2025, 3, 11), true},
+		{"Reversed range", "20250131-20250101", time.Time{}, time.Time{}, false},
+		{"Malformed date", "20251301-20251331", time.Time{}, time.Time{}, false},
+		{"Unknown code", "year", time.Time{}, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, ok := PeriodRange(tt.code, now)
+			if ok != tt.wantOK || !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("PeriodRange(%q) = %v, %v, %v, want %v, %v, %v",
+					tt.code, from, to, ok, tt.wantFrom, tt.wantTo, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCustomPeriodRoundTrip(t *testing.T) {
+	from, err := ParseDate("2025-09-01", time.UTC)
+	if err != nil {
+		t.Fatalf("ParseDate() error = %v", err)
+	}
+	to, err := ParseDate("30.09.2025", time.UTC)
+	if err != nil {
+		t.Fatalf("ParseDate() error = %v", err)
+	}
+
+	code := CustomPeriod(from, to)
+	if code != "20250901-20250930" {
+		t.Errorf("CustomPeriod() = %q", code)
+	}
+
+	gotFrom, gotTo, ok := PeriodRange(code, time.Now().UTC())
+	if !ok || !gotFrom.Equal(from) || !gotTo.Equal(to.AddDate(0, 0, 1)) {
+		t.Errorf("PeriodRange(%q) = %v, %v, %v", code, gotFrom, gotTo, ok)
+	}
+
+	if got := FormatPeriod(gotFrom, gotTo); got != "01.09.2025 – 30.09.2025" {
+		t.Errorf("FormatPeriod() = %q", got)
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, input := range []string{"", "2025/09/01", "31.02.2025", "tomorrow"} {
+		if _, err := ParseDate(input, time.UTC); err == nil {
+			t.Errorf("ParseDate(%q) succeeded, want error", input)
+		}
+	}
+}
@@ -128,6 +128,40 @@ func ValidateComplaintText(text string) (string, error) {
 	return text, nil
 }
 
+// ValidateReplyText validates the text of a staff reply to a complaint
+func ValidateReplyText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+
+	if utf8.RuneCountInString(text) > 3000 {
+		return "", fmt.Errorf("javob juda uzun (maksimal 3000 ta belgi) / ответ слишком длинный (максимум 3000 символов)")
+	}
+
+	text = SanitizeInput(text)
+
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("javob bo'sh bo'lishi mumkin emas / ответ не может быть пустым")
+	}
+
+	return text, nil
+}
+
+// ValidateRatingComment validates a parent's comment on their rating of a complaint
+func ValidateRatingComment(text string) (string, error) {
+	text = strings.TrimSpace(text)
+
+	if utf8.RuneCountInString(text) > 1000 {
+		return "", fmt.Errorf("izoh juda uzun (maksimal 1000 ta belgi) / комментарий слишком длинный (максимум 1000 символов)")
+	}
+
+	text = SanitizeInput(text)
+
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("izoh bo'sh bo'lishi mumkin emas / комментарий не может быть пустым")
+	}
+
+	return text, nil
+}
+
 // ValidateProposalText validates proposal text
 func ValidateProposalText(text string) (string, error) {
 	// Trim whitespace
@@ -0,0 +1,405 @@
+// Package chart renders bar and line charts as PNG images.
+//
+// Charts are drawn with the standard image packages and TrueType fonts, so
+// no external tools are needed. A chart has a title, a legend with one entry
+// per series, a value axis starting at zero and one label per category.
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Default image size in pixels
+const (
+	DefaultWidth  = 1000
+	DefaultHeight = 560
+)
+
+// Layout in pixels
+const (
+	padding      = 24
+	titleSize    = 24
+	labelSize    = 15
+	valueSize    = 12
+	legendSwatch = 14
+	lineWidth    = 3
+	pointRadius  = 4
+	yTicks       = 5
+)
+
+// NoDataText is drawn when a chart has no categories
+const NoDataText = "Ma'lumot yo'q / Нет данных"
+
+// Palette holds the series colors, used in order
+var Palette = []color.RGBA{
+	{R: 0xE0, G: 0x4F, B: 0x5F, A: 0xFF}, // red
+	{R: 0x2F, G: 0x80, B: 0xED, A: 0xFF}, // blue
+	{R: 0x27, G: 0xAE, B: 0x60, A: 0xFF}, // green
+	{R: 0xF2, G: 0x99, B: 0x4A, A: 0xFF}, // orange
+	{R: 0x9B, G: 0x51, B: 0xE0, A: 0xFF}, // purple
+}
+
+var (
+	backgroundColor = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	textColor       = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xFF}
+	mutedColor      = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}
+	gridColor       = color.RGBA{R: 0xE6, G: 0xE6, B: 0xE6, A: 0xFF}
+	axisColor       = color.RGBA{R: 0xB0, G: 0xB0, B: 0xB0, A: 0xFF}
+)
+
+// Series is one set of values, one per category
+type Series struct {
+	Name   string
+	Values []float64 // NaN marks a missing value: no bar, a gap in the line
+}
+
+// Chart describes a chart to render
+type Chart struct {
+	Title      string
+	Categories []string
+	Series     []Series
+}
+
+// Renderer draws charts with a regular and a bold font.
+// It is safe for concurrent use.
+type Renderer struct {
+	regular *opentype.Font
+	bold    *opentype.Font
+	Width   int
+	Height  int
+}
+
+// NewRenderer creates a renderer from TrueType or OpenType font data
+func NewRenderer(regularFont, boldFont []byte) (*Renderer, error) {
+	regular, err := opentype.Parse(regularFont)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse regular font: %w", err)
+	}
+
+	bold, err := opentype.Parse(boldFont)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bold font: %w", err)
+	}
+
+	return &Renderer{regular: regular, bold: bold, Width: DefaultWidth, Height: DefaultHeight}, nil
+}
+
+// faces are the font faces of one drawing; faces can't be shared between goroutines
+type faces struct {
+	title font.Face
+	label font.Face
+	value font.Face
+}
+
+func (r *Renderer) newFaces() (*faces, error) {
+	newFace := func(f *opentype.Font, size float64) (font.Face, error) {
+		return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	}
+
+	var f faces
+	var err error
+	if f.title, err = newFace(r.bold, titleSize); err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	if f.label, err = newFace(r.regular, labelSize); err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	if f.value, err = newFace(r.regular, valueSize); err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return &f, nil
+}
+
+// BarPNG renders a grouped bar chart: for each category one bar per series
+func (r *Renderer) BarPNG(c Chart) ([]byte, error) {
+	return r.render(c, drawBars)
+}
+
+// LinePNG renders a line chart with one line per series
+func (r *Renderer) LinePNG(c Chart) ([]byte, error) {
+	return r.render(c, drawLines)
+}
+
+// plot is the drawing area of a chart and its value scale
+type plot struct {
+	img    *image.RGBA
+	faces  *faces
+	area   image.Rectangle
+	top    float64 // value at the top of the area
+	slot   float64 // width of one category
+	series []Series
+}
+
+// y returns the pixel row of a value
+func (p *plot) y(value float64) float64 {
+	return float64(p.area.Max.Y) - value/p.top*float64(p.area.Dy())
+}
+
+// x returns the pixel column of the center of a category
+func (p *plot) x(category int) float64 {
+	return float64(p.area.Min.X) + (float64(category)+0.5)*p.slot
+}
+
+func (r *Renderer) render(c Chart, drawSeries func(p *plot)) ([]byte, error) {
+	f, err := r.newFaces()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, r.Width, r.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	// Title
+	y := padding + ascent(f.title)
+	drawText(img, f.title, textColor, padding, y, fitText(f.title, c.Title, r.Width-2*padding))
+	y += descent(f.title) + 12
+
+	// Legend
+	x := padding
+	legendHeight := ascent(f.label) + descent(f.label)
+	for i, s := range c.Series {
+		fillRect(img, image.Rect(x, y+(legendHeight-legendSwatch)/2, x+legendSwatch, y+(legendHeight+legendSwatch)/2), seriesColor(i))
+		x += legendSwatch + 6
+		drawText(img, f.label, textColor, x, y+ascent(f.label), s.Name)
+		x += measure(f.label, s.Name) + 24
+	}
+	if len(c.Series) > 0 {
+		y += legendHeight + 16
+	}
+
+	if len(c.Categories) == 0 {
+		w := measure(f.label, NoDataText)
+		drawText(img, f.label, mutedColor, (r.Width-w)/2, (y+r.Height)/2, NoDataText)
+		return encode(img)
+	}
+
+	// Value axis
+	top, step := niceScale(maxValue(c.Series))
+	tickLabels := make([]string, 0, int(top/step)+1)
+	labelWidth := 0
+	for v := 0.0; v <= top+step/2; v += step {
+		label := formatValue(v)
+		tickLabels = append(tickLabels, label)
+		labelWidth = max(labelWidth, measure(f.label, label))
+	}
+
+	area := image.Rect(
+		padding+labelWidth+10,
+		y+valueSize+4, // room for the value labels of the highest bars
+		r.Width-padding,
+		r.Height-padding-ascent(f.label)-descent(f.label)-8,
+	)
+
+	p := &plot{
+		img:    img,
+		faces:  f,
+		area:   area,
+		top:    top,
+		slot:   float64(area.Dx()) / float64(len(c.Categories)),
+		series: c.Series,
+	}
+
+	for i, label := range tickLabels {
+		ty := int(math.Round(p.y(float64(i) * step)))
+		fillRect(img, image.Rect(area.Min.X, ty, area.Max.X, ty+1), gridColor)
+		drawText(img, f.label, mutedColor, area.Min.X-10-measure(f.label, label), ty+ascent(f.label)/2, label)
+	}
+	fillRect(img, image.Rect(area.Min.X, area.Max.Y, area.Max.X, area.Max.Y+1), axisColor)
+
+	// Category labels, thinned out when they don't fit
+	every := 1
+	for _, label := range c.Categories {
+		for float64(measure(f.label, label)+8) > p.slot*float64(every) {
+			every++
+		}
+	}
+	for i, label := range c.Categories {
+		if i%every != 0 {
+			continue
+		}
+		w := measure(f.label, label)
+		drawText(img, f.label, textColor, int(p.x(i))-w/2, area.Max.Y+8+ascent(f.label), label)
+	}
+
+	drawSeries(p)
+
+	return encode(img)
+}
+
+// drawBars draws the series as grouped bars with their values on top
+func drawBars(p *plot) {
+	if len(p.series) == 0 {
+		return
+	}
+
+	groupWidth := p.slot * 0.8
+	barWidth := groupWidth / float64(len(p.series))
+
+	for si, s := range p.series {
+		for ci, v := range s.Values {
+			if math.IsNaN(v) || v <= 0 {
+				continue
+			}
+
+			x0 := p.x(ci) - groupWidth/2 + float64(si)*barWidth
+			bar := image.Rect(int(math.Round(x0+1)), int(math.Round(p.y(v))), int(math.Round(x0+barWidth-1)), p.area.Max.Y)
+			fillRect(p.img, bar, seriesColor(si))
+
+			label := formatValue(v)
+			if w := measure(p.faces.value, label); float64(w) <= barWidth {
+				drawText(p.img, p.faces.value, textColor, (bar.Min.X+bar.Max.X-w)/2, bar.Min.Y-4, label)
+			}
+		}
+	}
+}
+
+// drawLines draws the series as lines with a dot on each value
+func drawLines(p *plot) {
+	b := p.img.Bounds()
+
+	for si, s := range p.series {
+		r := vector.NewRasterizer(b.Dx(), b.Dy())
+
+		prev := -1
+		for ci, v := range s.Values {
+			if math.IsNaN(v) {
+				prev = -1
+				continue
+			}
+
+			x, y := p.x(ci), p.y(v)
+			if prev >= 0 {
+				addSegment(r, p.x(prev), p.y(s.Values[prev]), x, y, lineWidth)
+			}
+			addCircle(r, x, y, pointRadius)
+			prev = ci
+		}
+
+		r.Draw(p.img, b, image.NewUniform(seriesColor(si)), image.Point{})
+	}
+}
+
+// addSegment adds a line segment of the given width to the rasterizer
+func addSegment(r *vector.Rasterizer, x0, y0, x1, y1, width float64) {
+	dx, dy := x1-x0, y1-y0
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	nx, ny := -dy/length*width/2, dx/length*width/2
+
+	r.MoveTo(float32(x0+nx), float32(y0+ny))
+	r.LineTo(float32(x1+nx), float32(y1+ny))
+	r.LineTo(float32(x1-nx), float32(y1-ny))
+	r.LineTo(float32(x0-nx), float32(y0-ny))
+	r.ClosePath()
+}
+
+// addCircle adds a filled circle to the rasterizer. It is wound like the
+// segments of addSegment, so overlapping shapes don't cancel each other out.
+func addCircle(r *vector.Rasterizer, cx, cy, radius float64) {
+	const segments = 16
+	r.MoveTo(float32(cx+radius), float32(cy))
+	for i := 1; i < segments; i++ {
+		angle := -2 * math.Pi * float64(i) / segments
+		r.LineTo(float32(cx+radius*math.Cos(angle)), float32(cy+radius*math.Sin(angle)))
+	}
+	r.ClosePath()
+}
+
+// niceScale returns the top of the value axis and the tick step for values up to maxValue.
+// Steps are 1, 2, 2.5 or 5 times a power of ten; counts up to 5 get a step of 1.
+func niceScale(maxValue float64) (top, step float64) {
+	if maxValue <= yTicks {
+		return math.Max(1, math.Ceil(maxValue)), 1
+	}
+
+	raw := maxValue / yTicks
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		step = m * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	return math.Ceil(maxValue/step) * step, step
+}
+
+// maxValue returns the largest value of all series, ignoring missing values
+func maxValue(series []Series) float64 {
+	m := 0.0
+	for _, s := range series {
+		for _, v := range s.Values {
+			if !math.IsNaN(v) && v > m {
+				m = v
+			}
+		}
+	}
+	return m
+}
+
+// formatValue formats a value with at most one decimal
+func formatValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+// seriesColor returns the palette color of the i-th series
+func seriesColor(i int) color.RGBA {
+	return Palette[i%len(Palette)]
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// drawText draws text with its baseline at y
+func drawText(img *image.RGBA, face font.Face, c color.Color, x, y int, text string) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
+
+// fitText shortens text with an ellipsis until it is at most width pixels wide
+func fitText(face font.Face, text string, width int) string {
+	if measure(face, text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && measure(face, string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func measure(face font.Face, text string) int {
+	return font.MeasureString(face, text).Ceil()
+}
+
+func ascent(face font.Face) int {
+	return face.Metrics().Ascent.Ceil()
+}
+
+func descent(face font.Face) int {
+	return face.Metrics().Descent.Ceil()
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+
+	r, err := NewRenderer(goregular.TTF, gobold.TTF)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+	return r
+}
+
+func decodePNG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("not a PNG image: %v", err)
+	}
+	return img
+}
+
+// hasColor reports whether any pixel of img has exactly the color c
+func hasColor(img image.Image, c image.Image) bool {
+	want := c.At(0, 0)
+	wr, wg, wb, _ := want.RGBA()
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			if r == wr && g == wg && bl == wb {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestBarPNG(t *testing.T) {
+	r := newTestRenderer(t)
+
+	data, err := r.BarPNG(Chart{
+		Title:      "Shikoyatlar / Жалобы",
+		Categories: []string{"9A", "9B", "10A"},
+		Series: []Series{
+			{Name: "Complaints", Values: []float64{3, 0, 12}},
+			{Name: "Proposals", Values: []float64{1, math.NaN(), 4}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BarPNG() error = %v", err)
+	}
+
+	img := decodePNG(t, data)
+	if got := img.Bounds().Size(); got != image.Pt(DefaultWidth, DefaultHeight) {
+		t.Errorf("size = %v, want %dx%d", got, DefaultWidth, DefaultHeight)
+	}
+
+	for i := range 2 {
+		if !hasColor(img, image.NewUniform(Palette[i])) {
+			t.Errorf("series %d color not drawn", i)
+		}
+	}
+}
+
+func TestLinePNG(t *testing.T) {
+	r := newTestRenderer(t)
+	r.Width, r.Height = 600, 300
+
+	data, err := r.LinePNG(Chart{
+		Title:      "Median",
+		Categories: []string{"01.09", "08.09", "15.09", "22.09"},
+		Series:     []Series{{Name: "Hours", Values: []float64{5.5, math.NaN(), 30, 12}}},
+	})
+	if err != nil {
+		t.Fatalf("LinePNG() error = %v", err)
+	}
+
+	img := decodePNG(t, data)
+	if got := img.Bounds().Size(); got != image.Pt(600, 300) {
+		t.Errorf("size = %v, want 600x300", got)
+	}
+	if !hasColor(img, image.NewUniform(Palette[0])) {
+		t.Error("line not drawn")
+	}
+}
+
+func TestEmptyChart(t *testing.T) {
+	r := newTestRenderer(t)
+
+	data, err := r.BarPNG(Chart{Title: "Empty", Series: []Series{{Name: "Complaints"}}})
+	if err != nil {
+		t.Fatalf("BarPNG() error = %v", err)
+	}
+	decodePNG(t, data)
+}
+
+func TestNiceScale(t *testing.T) {
+	tests := []struct {
+		max       float64
+		top, step float64
+	}{
+		{0, 1, 1},
+		{3, 3, 1},
+		{5, 5, 1},
+		{7, 8, 2},
+		{12, 12.5, 2.5},
+		{23, 25, 5},
+		{48, 50, 10},
+		{130, 150, 50},
+		{0.4, 1, 1},
+	}
+
+	for _, tt := range tests {
+		top, step := niceScale(tt.max)
+		if top != tt.top || step != tt.step {
+			t.Errorf("niceScale(%v) = %v, %v; want %v, %v", tt.max, top, step, tt.top, tt.step)
+		}
+	}
+}
@@ -3,37 +3,88 @@ package docx
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/fumiama/go-docx"
 )
 
-// ComplaintData holds data for complaint document
-type ComplaintData struct {
-	ChildName     string
-	ChildClass    string
-	PhoneNumber   string
-	ComplaintText string
-	ParentName    string
-	Date          time.Time
+// Kind is the type of submission a document is generated for
+type Kind string
+
+const (
+	KindComplaint Kind = "complaint"
+	KindProposal  Kind = "proposal"
+)
+
+// template holds what differs between complaint and proposal documents
+type template struct {
+	title       string
+	textHeading string
+}
+
+var templates = map[Kind]template{
+	KindComplaint: {
+		title:       "SHIKOYAT / ЖАЛОБА",
+		textHeading: "SHIKOYAT MATNI / ТЕКСТ ЖАЛОБЫ:",
+	},
+	KindProposal: {
+		title:       "TAKLIF / ПРЕДЛОЖЕНИЕ",
+		textHeading: "TAKLIF MATNI / ТЕКСТ ПРЕДЛОЖЕНИЯ:",
+	},
 }
 
-// Generate generates a formatted DOCX document for a complaint
-func Generate(data *ComplaintData, outputPath string) error {
+// maxImageHeight keeps a portrait image and its caption on one A4 page (22 cm in EMU)
+const maxImageHeight = 7920000
+
+// Data holds data for a complaint or proposal document
+type Data struct {
+	Kind        Kind
+	ID          int
+	ChildName   string
+	ChildClass  string
+	PhoneNumber string
+	Category    string // Complaint category, "" if none
+	Text        string
+	Date        time.Time
+	Images      []Image
+}
+
+// Image is an attached image. Data is JPEG, PNG or GIF; nil Data marks an
+// image that could not be downloaded and is listed with a placeholder.
+type Image struct {
+	Data []byte
+}
+
+// Generate generates a formatted DOCX document for a complaint or proposal
+func Generate(data *Data, outputPath string) error {
+	tmpl, ok := templates[data.Kind]
+	if !ok {
+		return fmt.Errorf("unknown document kind: %q", data.Kind)
+	}
+
 	// Create new document with default theme and A4 page
 	doc := docx.New().WithDefaultTheme().WithA4Page()
 
 	// Add header/title
 	para := doc.AddParagraph()
-	para.AddText("SHIKOYAT / ЖАЛОБА").Size("32").Bold()
+	para.AddText(tmpl.title).Size("32").Bold()
 	para.Justification("center")
 
 	// Add spacing
 	doc.AddParagraph()
 
-	// Add date
+	// Add number and date
+	para = doc.AddParagraph()
+	para.AddText(fmt.Sprintf("Raqam / Номер: #%d", data.ID))
+
 	para = doc.AddParagraph()
-	para.AddText(fmt.Sprintf("Sana / Дата: %s", data.Date.Format("02.01.2006")))
+	para.AddText(fmt.Sprintf("Sana / Дата: %s", data.Date.Format("02.01.2006 15:04")))
+
+	if data.Category != "" {
+		para = doc.AddParagraph()
+		para.AddText(fmt.Sprintf("Toifa / Категория: %s", data.Category))
+	}
 
 	// Add spacing
 	doc.AddParagraph()
@@ -54,7 +105,7 @@ func Generate(data *ComplaintData, outputPath string) error {
 
 	// Class
 	para = doc.AddParagraph()
-	para.AddText("Sinf / Класс:")
+	para.AddText("Guruh / Группа:")
 	para = doc.AddParagraph()
 	para.AddText(data.ChildClass)
 
@@ -70,15 +121,33 @@ func Generate(data *ComplaintData, outputPath string) error {
 	doc.AddParagraph()
 	doc.AddParagraph()
 
-	// Add complaint text section
+	// Add submission text section
 	para = doc.AddParagraph()
-	para.AddText("SHIKOYAT MATNI / ТЕКСТ ЖАЛОБЫ:").Bold()
+	para.AddText(tmpl.textHeading).Bold()
 
 	doc.AddParagraph()
 
-	// Add complaint text
-	para = doc.AddParagraph()
-	para.AddText(data.ComplaintText)
+	// One paragraph per line so line breaks survive
+	for _, line := range splitLines(data.Text) {
+		para = doc.AddParagraph()
+		para.AddText(line)
+	}
+
+	// Add images, each on its own page with a caption
+	for i, image := range data.Images {
+		para = doc.AddParagraph()
+		para.AddPageBreaks()
+
+		para = doc.AddParagraph()
+		para.AddText(fmt.Sprintf("Rasm %d / Изображение %d:", i+1, i+1)).Bold()
+
+		if err := addImage(doc, image.Data); err != nil {
+			// Keep a placeholder so admins know an image was attached
+			fmt.Printf("[WARN] Failed to add image %d to DOCX: %v\n", i, err)
+			para = doc.AddParagraph()
+			para.AddText("[Rasm yuklanmadi / Изображение не загружено]")
+		}
+	}
 
 	// Add spacing
 	doc.AddParagraph()
@@ -115,8 +184,47 @@ func Generate(data *ComplaintData, outputPath string) error {
 	return nil
 }
 
-// ValidateData validates complaint data before generating document
-func ValidateData(data *ComplaintData) error {
+// addImage adds an image in its own centred paragraph, scaled down if it is
+// taller than a page
+func addImage(doc *docx.Docx, data []byte) error {
+	if data == nil {
+		return fmt.Errorf("image not available")
+	}
+
+	para := doc.AddParagraph()
+	para.Justification("center")
+
+	run, err := para.AddInlineDrawing(data)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range run.Children {
+		drawing, ok := child.(*docx.Drawing)
+		if !ok || drawing.Inline == nil || drawing.Inline.Extent == nil {
+			continue
+		}
+		w, h := drawing.Inline.Extent.CX, drawing.Inline.Extent.CY
+		if h > maxImageHeight {
+			drawing.Inline.Size(w*maxImageHeight/h, maxImageHeight)
+		}
+	}
+
+	return nil
+}
+
+// splitLines splits text into lines, accepting any line ending
+func splitLines(text string) []string {
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+	return strings.Split(text, "\n")
+}
+
+// ValidateData validates submission data before generating document
+func ValidateData(data *Data) error {
+	if _, ok := templates[data.Kind]; !ok {
+		return fmt.Errorf("unknown document kind: %q", data.Kind)
+	}
+
 	if data.ChildName == "" {
 		return fmt.Errorf("child name is required")
 	}
@@ -129,12 +237,12 @@ func ValidateData(data *ComplaintData) error {
 		return fmt.Errorf("phone number is required")
 	}
 
-	if data.ComplaintText == "" {
-		return fmt.Errorf("complaint text is required")
+	if data.Text == "" {
+		return fmt.Errorf("text is required")
 	}
 
-	if len(data.ComplaintText) < 10 {
-		return fmt.Errorf("complaint text is too short")
+	if len(data.Text) < 10 {
+		return fmt.Errorf("text is too short")
 	}
 
 	return nil
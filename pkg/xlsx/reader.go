@@ -0,0 +1,329 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Excel's sheet size limits, used to reject cell references no workbook can hold
+const (
+	maxRows    = 1 << 20
+	maxColumns = 1 << 14
+)
+
+// ErrNoSheet is returned when a workbook has no worksheet
+var ErrNoSheet = errors.New("xlsx: workbook has no worksheet")
+
+// ReadRows reads the rows of the first worksheet of a workbook.
+//
+// Row i of the result is row i+1 of the sheet: rows the file skips are
+// returned empty, so row numbers can be shown to the user as Excel shows
+// them. Cells hold their text; numbers are returned as written, without
+// the cell's number format applied.
+func ReadRows(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrNoSheet
+	}
+
+	var sharedStrings []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if sharedStrings, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return readSheet(sheet, sharedStrings)
+}
+
+// firstSheetPath finds the part of the first sheet listed in the workbook.
+// Falls back to the conventional name if the workbook doesn't say.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return fallback, nil
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+
+	return fallback, nil
+}
+
+// decodePart unmarshals an XML part; a missing part leaves v unchanged
+func decodePart(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
+
+// readSharedStrings reads the shared string table. Rich text runs are joined;
+// phonetic hints are skipped.
+func readSharedStrings(f *zip.File) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shared strings: %w", err)
+	}
+	defer rc.Close()
+
+	var (
+		stringsTable []string
+		current      strings.Builder
+		inText       bool
+		inPhonetic   bool
+	)
+
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return stringsTable, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shared strings: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "rPh":
+				inPhonetic = true
+			case "t":
+				inText = !inPhonetic
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				stringsTable = append(stringsTable, current.String())
+			case "rPh":
+				inPhonetic = false
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+}
+
+// readSheet streams the cells of a worksheet into rows
+func readSheet(f *zip.File, sharedStrings []string) ([][]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worksheet: %w", err)
+	}
+	defer rc.Close()
+
+	var (
+		rows       [][]string
+		rowIndex   = -1
+		column     = -1
+		cellType   string
+		value      strings.Builder
+		inValue    bool
+		inInline   bool
+		inPhonetic bool
+	)
+
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read worksheet: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				rowIndex++
+				if ref := attr(t, "r"); ref != "" {
+					n, err := strconv.Atoi(ref)
+					if err != nil || n < rowIndex+1 || n > maxRows {
+						return nil, fmt.Errorf("xlsx: invalid row number %q", ref)
+					}
+					rowIndex = n - 1
+				}
+				for len(rows) <= rowIndex {
+					rows = append(rows, nil)
+				}
+				column = -1
+			case "c":
+				if rowIndex < 0 {
+					return nil, fmt.Errorf("xlsx: cell outside of a row")
+				}
+				column++
+				if ref := attr(t, "r"); ref != "" {
+					index, ok := columnIndex(ref)
+					if !ok || index < column {
+						return nil, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+					}
+					column = index
+				}
+				cellType = attr(t, "t")
+				value.Reset()
+			case "v":
+				inValue = true
+			case "is":
+				inInline = true
+			case "rPh":
+				inPhonetic = true
+			case "t":
+				inValue = inInline && !inPhonetic
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "c":
+				text, err := cellText(cellType, value.String(), sharedStrings)
+				if err != nil {
+					return nil, err
+				}
+				if text != "" {
+					row := rows[rowIndex]
+					for len(row) <= column {
+						row = append(row, "")
+					}
+					row[column] = text
+					rows[rowIndex] = row
+				}
+			case "v", "t":
+				inValue = false
+			case "is":
+				inInline = false
+			case "rPh":
+				inPhonetic = false
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+// cellText converts the raw value of a cell of the given type to its text
+func cellText(cellType, raw string, sharedStrings []string) (string, error) {
+	switch cellType {
+	case "s":
+		if raw == "" {
+			return "", nil
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || index < 0 || index >= len(sharedStrings) {
+			return "", fmt.Errorf("xlsx: invalid shared string index %q", raw)
+		}
+		return sharedStrings[index], nil
+	case "b":
+		if raw == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case "", "n":
+		// Large numbers may be written in exponent form (9.98901234567E+11)
+		if strings.ContainsAny(raw, "eE") {
+			if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				return strconv.FormatFloat(f, 'f', -1, 64), nil
+			}
+		}
+		return raw, nil
+	default:
+		// inlineStr, str (formula result) and e (error)
+		return raw, nil
+	}
+}
+
+// columnIndex returns the zero-based column of a cell reference such as "AB12"
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A') + 1
+		letters++
+		if index > maxColumns {
+			return 0, false
+		}
+	}
+	if letters == 0 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+// attr returns the value of an attribute of an element, "" if it is missing
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
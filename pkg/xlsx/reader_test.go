@@ -0,0 +1,132 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// buildWorkbook zips the given parts into an XLSX file
+func buildWorkbook(t *testing.T, parts map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("failed to close workbook: %v", err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadRowsRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"Telefon", "Bola", "Guruh"},
+		{"+998901234567", "Ali <Valiyev> & co", "9A"},
+		{"998907654321", "Иванова Анна", ""},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Roster")
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadRows() error = %v", err)
+	}
+
+	// Trailing empty cells are not kept
+	rows[2] = rows[2][:2]
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("ReadRows() = %q, want %q", got, rows)
+	}
+}
+
+// TestReadRowsExcel reads a workbook laid out the way Excel saves one:
+// shared strings, numbers, a renamed sheet part and skipped rows and cells
+func TestReadRowsExcel(t *testing.T) {
+	r := buildWorkbook(t, map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Ro'yxat" sheetId="1" r:id="rId3"/><sheet name="Other" sheetId="2" r:id="rId4"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/roster.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="3" uniqueCount="3">
+<si><t>Telefon</t></si>
+<si><r><t>Ali </t></r><r><rPr><b/></rPr><t>Valiyev</t></r><rPh><t>ignored</t></rPh></si>
+<si><t xml:space="preserve"> 9A </t></si>
+</sst>`,
+		"xl/worksheets/roster.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c></row>
+<row r="3"><c r="A3"><v>998901234567</v></c><c r="B3" t="s"><v>1</v></c><c r="D3" t="s"><v>2</v></c></row>
+<row r="4"><c r="A4"><v>9.98907654321E+11</v></c><c r="B4" t="inlineStr"><is><t>Anna</t></is></c><c r="C4" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>wrong sheet</t></is></c></row></sheetData></worksheet>`,
+	})
+
+	got, err := ReadRows(r, r.Size())
+	if err != nil {
+		t.Fatalf("ReadRows() error = %v", err)
+	}
+
+	want := [][]string{
+		{"Telefon"},
+		nil,
+		{"998901234567", "Ali Valiyev", "", " 9A "},
+		{"998907654321", "Anna", "TRUE"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadRows() = %q, want %q", got, want)
+	}
+}
+
+func TestReadRowsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"bad shared string": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>5</v></c></row></sheetData></worksheet>`,
+		"row out of order":  `<worksheet><sheetData><row r="2"></row><row r="1"></row></sheetData></worksheet>`,
+		"too many rows":     `<worksheet><sheetData><row r="99999999"></row></sheetData></worksheet>`,
+		"too many columns":  `<worksheet><sheetData><row r="1"><c r="ZZZZ1"><v>1</v></c></row></sheetData></worksheet>`,
+	}
+
+	for name, sheet := range tests {
+		r := buildWorkbook(t, map[string]string{"xl/worksheets/sheet1.xml": sheet})
+		if _, err := ReadRows(r, r.Size()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := ReadRows(bytes.NewReader([]byte("Telefon,Bola")), 12); err == nil {
+		t.Error("a CSV file should not be read as a workbook")
+	}
+
+	r := buildWorkbook(t, map[string]string{"docProps/app.xml": "<Properties/>"})
+	if _, err := ReadRows(r, r.Size()); err != ErrNoSheet {
+		t.Errorf("workbook without sheets: error = %v, want ErrNoSheet", err)
+	}
+}
@@ -0,0 +1,110 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+// sheetXML is the part of a worksheet read back in tests
+type sheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R     string `xml:"r,attr"`
+			Style string `xml:"s,attr"`
+			Text  string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Shikoyatlar / Жалобы")
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	rows := [][]string{
+		{"ID", "Matn / Текст"},
+		{"1", "Line one\nline two <b> & \"quoted\""},
+		{"2", "Emoji 😊 and control \x01 char"},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a ZIP file: %v", err)
+	}
+
+	parts := make(map[string][]byte)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = data
+
+		// Every part must be well-formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not valid XML: %v", f.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	var sheet sheetXML
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("failed to parse worksheet: %v", err)
+	}
+
+	if len(sheet.Rows) != len(rows) {
+		t.Fatalf("got %d rows, want %d", len(sheet.Rows), len(rows))
+	}
+
+	if got := sheet.Rows[1].Cells[1].Text; got != rows[1][1] {
+		t.Errorf("cell B2 = %q, want %q", got, rows[1][1])
+	}
+	if got := sheet.Rows[2].Cells[1].Text; got != "Emoji 😊 and control � char" {
+		t.Errorf("cell B3 = %q", got)
+	}
+	if sheet.Rows[0].Cells[0].Style != "1" || sheet.Rows[1].Cells[0].Style != "" {
+		t.Error("only the header row should be bold")
+	}
+	if sheet.Rows[2].R != 3 || sheet.Rows[2].Cells[1].R != "B3" {
+		t.Errorf("row 3 references = %d, %s", sheet.Rows[2].R, sheet.Rows[2].Cells[1].R)
+	}
+
+	if !bytes.Contains(parts["xl/workbook.xml"], []byte(`name="Shikoyatlar  Жалобы"`)) {
+		t.Errorf("sheet name not sanitized: %s", parts["xl/workbook.xml"])
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if got := ColumnName(index); got != want {
+			t.Errorf("ColumnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}